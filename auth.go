@@ -13,8 +13,11 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 // ErrCannotParseDockercfg is the error returned by NewAuthConfigurations when the dockercfg cannot be parsed.
@@ -123,30 +126,67 @@ func NewAuthConfigurationsFromDockerCfg() (*AuthConfigurations, error) {
 
 // NewAuthConfigurations returns AuthConfigurations from a JSON encoded string in the
 // same format as the .dockercfg file.
+//
+// When the configuration declares credential helpers (credHelpers) or a
+// credentials store (credsStore), the credentials of the corresponding
+// registries are resolved by running the docker-credential-* programs.
+// Registries whose credentials can't be resolved are left out.
 func NewAuthConfigurations(r io.Reader) (*AuthConfigurations, error) {
 	var auth *AuthConfigurations
-	confs, err := parseDockerConfig(r)
+	config, err := parseDockerConfig(r)
 	if err != nil {
 		return nil, err
 	}
-	auth, err = authConfigs(confs)
+	auth, err = authConfigs(config.Auths)
 	if err != nil {
 		return nil, err
 	}
+	helperAuthConfigs(config, newDockerConfigCredentialHelper(config), auth)
 	return auth, nil
 }
 
-func parseDockerConfig(r io.Reader) (map[string]dockerConfig, error) {
+// NewCredentialHelperFromDockerCfg returns a CredentialHelper that resolves
+// credentials using the credential helpers (credHelpers) and the credentials
+// store (credsStore) declared in the Docker configuration file. The files are
+// looked up in the same order as in NewAuthConfigurationsFromDockerCfg.
+//
+// The credentials returned by the helpers are cached per registry for the
+// lifetime of the returned CredentialHelper.
+func NewCredentialHelperFromDockerCfg() (CredentialHelper, error) {
+	err := fmt.Errorf("no docker configuration found")
+	for _, path := range cfgPaths(os.Getenv("DOCKER_CONFIG"), os.Getenv("HOME")) {
+		var r *os.File
+		r, err = os.Open(path)
+		if err != nil {
+			continue
+		}
+		var config *dockerConfigFile
+		config, err = parseDockerConfig(r)
+		r.Close()
+		if err == nil {
+			return newDockerConfigCredentialHelper(config), nil
+		}
+	}
+	return nil, err
+}
+
+// dockerConfigFile represents the credentials related fields of the
+// config.json file.
+type dockerConfigFile struct {
+	Auths       map[string]dockerConfig `json:"auths"`
+	CredHelpers map[string]string       `json:"credHelpers"`
+	CredsStore  string                  `json:"credsStore"`
+}
+
+func parseDockerConfig(r io.Reader) (*dockerConfigFile, error) {
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(r)
 	byteData := buf.Bytes()
 
-	confsWrapper := struct {
-		Auths map[string]dockerConfig `json:"auths"`
-	}{}
-	if err := json.Unmarshal(byteData, &confsWrapper); err == nil {
-		if len(confsWrapper.Auths) > 0 {
-			return confsWrapper.Auths, nil
+	var config dockerConfigFile
+	if err := json.Unmarshal(byteData, &config); err == nil {
+		if len(config.Auths) > 0 || len(config.CredHelpers) > 0 || config.CredsStore != "" {
+			return &config, nil
 		}
 	}
 
@@ -154,7 +194,31 @@ func parseDockerConfig(r io.Reader) (map[string]dockerConfig, error) {
 	if err := json.Unmarshal(byteData, &confs); err != nil {
 		return nil, err
 	}
-	return confs, nil
+	return &dockerConfigFile{Auths: confs}, nil
+}
+
+// helperAuthConfigs adds to auth the credentials of the registries handled by
+// credential helpers in config, resolving them with the given helper.
+// Registries configured in credHelpers take precedence over static
+// credentials, while the credentials store is only used for registries that
+// have no static credentials.
+func helperAuthConfigs(config *dockerConfigFile, helper CredentialHelper, auth *AuthConfigurations) {
+	for reg := range config.CredHelpers {
+		if resolved, err := helper.Get(reg); err == nil {
+			auth.Configs[reg] = resolved
+		}
+	}
+	if config.CredsStore == "" {
+		return
+	}
+	for reg := range config.Auths {
+		if _, ok := auth.Configs[reg]; ok {
+			continue
+		}
+		if resolved, err := helper.Get(reg); err == nil {
+			auth.Configs[reg] = resolved
+		}
+	}
 }
 
 // authConfigs converts a dockerConfigs map to a AuthConfigurations object.
@@ -234,3 +298,262 @@ func (c *Client) AuthCheck(conf *AuthConfiguration) (AuthStatus, error) {
 	}
 	return authStatus, nil
 }
+
+// RegistryLogin validates the given credentials against the registry, in the
+// same way as AuthCheck does.
+//
+// On success, the client remembers the credentials for the server address in
+// auth. If the registry issues an identity token, the token is stored in place
+// of the password. The stored credentials can be retrieved with RegistryAuth.
+//
+// See https://goo.gl/6nsZkH for more details.
+func (c *Client) RegistryLogin(auth AuthConfiguration) (AuthStatus, error) {
+	status, err := c.AuthCheck(&auth)
+	if err != nil {
+		return status, err
+	}
+	if status.IdentityToken != "" {
+		auth.Password = ""
+		auth.IdentityToken = status.IdentityToken
+	}
+	c.registryAuths.set(auth)
+	return status, nil
+}
+
+// RegistryAuth returns the credentials stored by RegistryLogin for the given
+// server address.
+func (c *Client) RegistryAuth(serverAddress string) (AuthConfiguration, bool) {
+	return c.registryAuths.get(serverAddress)
+}
+
+type registryAuthStore struct {
+	sync.RWMutex
+	configs map[string]AuthConfiguration
+}
+
+func (s *registryAuthStore) get(serverAddress string) (AuthConfiguration, bool) {
+	if s == nil {
+		return AuthConfiguration{}, false
+	}
+	s.RLock()
+	defer s.RUnlock()
+	auth, ok := s.configs[serverAddress]
+	return auth, ok
+}
+
+func (s *registryAuthStore) set(auth AuthConfiguration) {
+	s.Lock()
+	defer s.Unlock()
+	if s.configs == nil {
+		s.configs = make(map[string]AuthConfiguration)
+	}
+	s.configs[auth.ServerAddress] = auth
+}
+
+// ErrCredentialsNotFound is the error returned by a CredentialHelper when it
+// has no credentials stored for the given server address.
+var ErrCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// CredentialHelper is the interface implemented by credential stores that
+// can resolve and store registry credentials, such as the docker-credential-*
+// programs used by the Docker CLI.
+//
+// When a CredentialHelper is set in the Client, PullImage and PushImage
+// resolve the credentials for the image's registry using the helper, falling
+// back to the AuthConfiguration given by the caller when the helper fails.
+type CredentialHelper interface {
+	Get(serverAddress string) (AuthConfiguration, error)
+	Store(auth AuthConfiguration) error
+}
+
+// NewCredentialHelper returns a CredentialHelper that shells out to the
+// docker-credential-<name> program, which must be available in the PATH.
+func NewCredentialHelper(name string) CredentialHelper {
+	return &credentialHelper{program: "docker-credential-" + name}
+}
+
+// DefaultCredentialHelper returns the CredentialHelper for the native store
+// of the current platform: docker-credential-osxkeychain on macOS,
+// docker-credential-wincred on Windows and docker-credential-secretservice
+// elsewhere.
+func DefaultCredentialHelper() CredentialHelper {
+	switch runtime.GOOS {
+	case "darwin":
+		return NewCredentialHelper("osxkeychain")
+	case "windows":
+		return NewCredentialHelper("wincred")
+	default:
+		return NewCredentialHelper("secretservice")
+	}
+}
+
+// credentialHelperToken is the username used by credential helpers to flag
+// the secret as an identity token.
+const credentialHelperToken = "<token>"
+
+type credentialHelper struct {
+	program string
+}
+
+type credentialHelperPayload struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+func (h *credentialHelper) Get(serverAddress string) (AuthConfiguration, error) {
+	out, err := h.run("get", strings.NewReader(serverAddress))
+	if err != nil {
+		return AuthConfiguration{}, err
+	}
+	var payload credentialHelperPayload
+	if err := json.Unmarshal(out, &payload); err != nil {
+		return AuthConfiguration{}, err
+	}
+	auth := AuthConfiguration{ServerAddress: serverAddress}
+	if payload.Username == credentialHelperToken {
+		auth.IdentityToken = payload.Secret
+	} else {
+		auth.Username = payload.Username
+		auth.Password = payload.Secret
+	}
+	return auth, nil
+}
+
+func (h *credentialHelper) Store(auth AuthConfiguration) error {
+	payload := credentialHelperPayload{
+		ServerURL: auth.ServerAddress,
+		Username:  auth.Username,
+		Secret:    auth.Password,
+	}
+	if auth.IdentityToken != "" {
+		payload.Username = credentialHelperToken
+		payload.Secret = auth.IdentityToken
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", bytes.NewReader(data))
+	return err
+}
+
+func (h *credentialHelper) run(action string, in io.Reader) ([]byte, error) {
+	cmd := exec.Command(h.program, action)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == ErrCredentialsNotFound.Error() {
+			return nil, ErrCredentialsNotFound
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("%s %s: %s", h.program, action, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// dockerConfigCredentialHelper is a CredentialHelper that dispatches to the
+// credential helpers declared in the Docker configuration file, caching the
+// credentials it resolves.
+type dockerConfigCredentialHelper struct {
+	helpers   map[string]string
+	store     string
+	newHelper func(name string) CredentialHelper
+
+	mu    sync.Mutex
+	cache map[string]AuthConfiguration
+}
+
+func newDockerConfigCredentialHelper(config *dockerConfigFile) *dockerConfigCredentialHelper {
+	return &dockerConfigCredentialHelper{
+		helpers:   config.CredHelpers,
+		store:     config.CredsStore,
+		newHelper: NewCredentialHelper,
+		cache:     make(map[string]AuthConfiguration),
+	}
+}
+
+func (h *dockerConfigCredentialHelper) helper(serverAddress string) CredentialHelper {
+	name := h.helpers[serverAddress]
+	if name == "" {
+		name = h.store
+	}
+	if name == "" {
+		return nil
+	}
+	return h.newHelper(name)
+}
+
+func (h *dockerConfigCredentialHelper) Get(serverAddress string) (AuthConfiguration, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if auth, ok := h.cache[serverAddress]; ok {
+		return auth, nil
+	}
+	helper := h.helper(serverAddress)
+	if helper == nil {
+		return AuthConfiguration{}, ErrCredentialsNotFound
+	}
+	auth, err := helper.Get(serverAddress)
+	if err != nil {
+		return AuthConfiguration{}, err
+	}
+	h.cache[serverAddress] = auth
+	return auth, nil
+}
+
+func (h *dockerConfigCredentialHelper) Store(auth AuthConfiguration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	helper := h.helper(auth.ServerAddress)
+	if helper == nil {
+		return fmt.Errorf("no credential helper configured for %s", auth.ServerAddress)
+	}
+	if err := helper.Store(auth); err != nil {
+		return err
+	}
+	h.cache[auth.ServerAddress] = auth
+	return nil
+}
+
+// defaultRegistryServerAddress is the server address used by the Docker CLI
+// to store the credentials of Docker Hub.
+const defaultRegistryServerAddress = "https://index.docker.io/v1/"
+
+// registryServerAddress returns the server address of the registry hosting
+// the given image.
+func registryServerAddress(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return defaultRegistryServerAddress
+	}
+	host := image[:i]
+	if host == "localhost" || strings.ContainsAny(host, ".:") {
+		return host
+	}
+	return defaultRegistryServerAddress
+}
+
+// resolveAuth returns the credentials to use when talking to the registry
+// hosting the given image. The CredentialHelper is consulted first, then the
+// credentials stored by RegistryLogin, which replace the given ones when they
+// are empty or belong to the same user, so identity tokens issued by the
+// registry are reused instead of the password.
+func (c *Client) resolveAuth(image string, auth AuthConfiguration) AuthConfiguration {
+	serverAddress := registryServerAddress(image)
+	if c.CredentialHelper != nil {
+		if resolved, err := c.CredentialHelper.Get(serverAddress); err == nil {
+			return resolved
+		}
+	}
+	if auth.ServerAddress != "" {
+		serverAddress = auth.ServerAddress
+	}
+	if stored, ok := c.registryAuths.get(serverAddress); ok && (auth.isEmpty() || auth.Username == stored.Username) {
+		return stored
+	}
+	return auth
+}
@@ -6,11 +6,14 @@ package docker
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -237,3 +240,284 @@ func TestAuthCheck(t *testing.T) {
 		t.Fatal("expected failure from unauthorized auth")
 	}
 }
+
+func TestRegistryLogin(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{status: http.StatusOK, message: `{"Status":"Login Succeeded","IdentityToken":"9cbaf023786cd7"}`}
+	client := newTestClient(fakeRT)
+	auth := AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com"}
+	status, err := client.RegistryLogin(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedStatus := AuthStatus{Status: "Login Succeeded", IdentityToken: "9cbaf023786cd7"}
+	if status != expectedStatus {
+		t.Errorf("RegistryLogin: wrong status. Want %#v. Got %#v.", expectedStatus, status)
+	}
+	stored, ok := client.RegistryAuth("registry.example.com")
+	if !ok {
+		t.Fatal("RegistryLogin: credentials were not stored")
+	}
+	expectedAuth := AuthConfiguration{Username: "user", IdentityToken: "9cbaf023786cd7", ServerAddress: "registry.example.com"}
+	if stored != expectedAuth {
+		t.Errorf("RegistryLogin: wrong stored credentials. Want %#v. Got %#v.", expectedAuth, stored)
+	}
+}
+
+func TestRegistryLoginUnauthorized(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{status: http.StatusUnauthorized, message: "wrong login/password"})
+	if _, err := client.RegistryLogin(AuthConfiguration{Username: "user", Password: "wrong", ServerAddress: "registry.example.com"}); err == nil {
+		t.Fatal("expected failure from unauthorized auth")
+	}
+	if _, ok := client.RegistryAuth("registry.example.com"); ok {
+		t.Error("RegistryLogin: credentials should not be stored on failure")
+	}
+}
+
+type fakeCredentialHelper map[string]AuthConfiguration
+
+func (h fakeCredentialHelper) Get(serverAddress string) (AuthConfiguration, error) {
+	auth, ok := h[serverAddress]
+	if !ok {
+		return AuthConfiguration{}, ErrCredentialsNotFound
+	}
+	return auth, nil
+}
+
+func (h fakeCredentialHelper) Store(auth AuthConfiguration) error {
+	h[auth.ServerAddress] = auth
+	return nil
+}
+
+func TestCredentialHelper(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires a POSIX shell")
+	}
+	tmpDir, err := ioutil.TempDir("", "go-dockerclient-credential-helper-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	script := `#!/bin/sh
+case "$1" in
+get)
+	read server
+	if [ "$server" = "registry.example.com" ]; then
+		echo '{"ServerURL":"registry.example.com","Username":"user","Secret":"secret"}'
+	elif [ "$server" = "token.example.com" ]; then
+		echo '{"ServerURL":"token.example.com","Username":"<token>","Secret":"9cbaf023786cd7"}'
+	else
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	;;
+store)
+	cat > "$(dirname "$0")/stored"
+	;;
+esac
+`
+	program := path.Join(tmpDir, "docker-credential-test")
+	if err = ioutil.WriteFile(program, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	helper := &credentialHelper{program: program}
+	auth, err := helper.Get("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com"}
+	if auth != expected {
+		t.Errorf("CredentialHelper.Get: wrong credentials. Want %#v. Got %#v.", expected, auth)
+	}
+	auth, err = helper.Get("token.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = AuthConfiguration{IdentityToken: "9cbaf023786cd7", ServerAddress: "token.example.com"}
+	if auth != expected {
+		t.Errorf("CredentialHelper.Get: wrong credentials. Want %#v. Got %#v.", expected, auth)
+	}
+	if _, err = helper.Get("unknown.example.com"); err != ErrCredentialsNotFound {
+		t.Errorf("CredentialHelper.Get: wrong error. Want %#v. Got %#v.", ErrCredentialsNotFound, err)
+	}
+	err = helper.Store(AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := ioutil.ReadFile(path.Join(tmpDir, "stored"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedStored := `{"ServerURL":"registry.example.com","Username":"user","Secret":"secret"}`
+	if string(stored) != expectedStored {
+		t.Errorf("CredentialHelper.Store: wrong payload. Want %q. Got %q.", expectedStored, stored)
+	}
+}
+
+func TestRegistryServerAddress(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		image    string
+		expected string
+	}{
+		{"ubuntu", defaultRegistryServerAddress},
+		{"tsuru/python", defaultRegistryServerAddress},
+		{"registry.example.com/tsuru/python", "registry.example.com"},
+		{"localhost:5000/python", "localhost:5000"},
+		{"localhost/python", "localhost"},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.image, func(t *testing.T) {
+			t.Parallel()
+			if got := registryServerAddress(test.image); got != test.expected {
+				t.Errorf("registryServerAddress(%q): Want %q. Got %q.", test.image, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestPullImageCredentialHelper(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "Pulling 1/100", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	helperAuth := AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com"}
+	client.CredentialHelper = fakeCredentialHelper{"registry.example.com": helperAuth}
+	staticAuth := AuthConfiguration{Username: "static", Password: "static"}
+	var tests = []struct {
+		repository string
+		expected   AuthConfiguration
+	}{
+		{"registry.example.com/tsuru/python", helperAuth},
+		{"other.example.com/tsuru/python", staticAuth},
+	}
+	for i, test := range tests {
+		err := client.PullImage(PullImageOptions{Repository: test.repository}, staticAuth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got AuthConfiguration
+		data, err := base64.URLEncoding.DecodeString(fakeRT.requests[i].Header.Get("X-Registry-Auth"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("PullImage(%q): wrong credentials. Want %#v. Got %#v.", test.repository, test.expected, got)
+		}
+	}
+}
+
+func TestAuthConfigurationsCredentialHelpers(t *testing.T) {
+	t.Parallel()
+	authString := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	content := fmt.Sprintf(`{
+		"auths": {
+			"static.example.com": {"auth": "%s"},
+			"store.example.com": {},
+			"ecr.example.com": {"auth": "%s"}
+		},
+		"credHelpers": {"ecr.example.com": "ecr-login"},
+		"credsStore": "osxkeychain"
+	}`, authString, authString)
+	config, err := parseDockerConfig(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auths, err := authConfigs(config.Auths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helpers := map[string]fakeCredentialHelper{
+		"ecr-login": {
+			"ecr.example.com": {Username: "AWS", Password: "ecr-token", ServerAddress: "ecr.example.com"},
+		},
+		"osxkeychain": {
+			"store.example.com":  {Username: "store", Password: "store-pass", ServerAddress: "store.example.com"},
+			"static.example.com": {Username: "other", Password: "other-pass", ServerAddress: "static.example.com"},
+		},
+	}
+	helper := newDockerConfigCredentialHelper(config)
+	helper.newHelper = func(name string) CredentialHelper {
+		return helpers[name]
+	}
+	helperAuthConfigs(config, helper, auths)
+	expected := map[string]AuthConfiguration{
+		"static.example.com": {Username: "user", Password: "pass", ServerAddress: "static.example.com"},
+		"store.example.com":  {Username: "store", Password: "store-pass", ServerAddress: "store.example.com"},
+		"ecr.example.com":    {Username: "AWS", Password: "ecr-token", ServerAddress: "ecr.example.com"},
+	}
+	if !reflect.DeepEqual(auths.Configs, expected) {
+		t.Errorf("helperAuthConfigs: wrong configs. Want %#v. Got %#v.", expected, auths.Configs)
+	}
+
+	// resolved credentials are cached for the lifetime of the helper
+	delete(helpers["ecr-login"], "ecr.example.com")
+	auth, err := helper.Get("ecr.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != expected["ecr.example.com"] {
+		t.Errorf("Get: wrong cached credentials. Want %#v. Got %#v.", expected["ecr.example.com"], auth)
+	}
+}
+
+func TestAuthConfigurationsCredsStoreOnly(t *testing.T) {
+	t.Parallel()
+	config, err := parseDockerConfig(strings.NewReader(`{"credsStore": "osxkeychain"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.CredsStore != "osxkeychain" {
+		t.Errorf("parseDockerConfig: wrong credsStore. Want %q. Got %q.", "osxkeychain", config.CredsStore)
+	}
+	helper := newDockerConfigCredentialHelper(&dockerConfigFile{})
+	if _, err := helper.Get("registry.example.com"); err != ErrCredentialsNotFound {
+		t.Errorf("Get: wrong error. Want %#v. Got %#v.", ErrCredentialsNotFound, err)
+	}
+}
+
+func TestPushImageReusesIdentityToken(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{status: http.StatusOK, message: `{"Status":"Login Succeeded","IdentityToken":"9cbaf023786cd7"}`})
+	auth := AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com"}
+	if _, err := client.RegistryLogin(auth); err != nil {
+		t.Fatal(err)
+	}
+	expected := AuthConfiguration{Username: "user", IdentityToken: "9cbaf023786cd7", ServerAddress: "registry.example.com"}
+	var tests = []struct {
+		name string
+		auth AuthConfiguration
+	}{
+		{"registry.example.com/tsuru/python", auth},
+		{"registry.example.com/tsuru/python", AuthConfiguration{}},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "Pushing 1/100", status: http.StatusOK}
+		client.HTTPClient = &http.Client{Transport: fakeRT}
+		if err := client.PushImage(PushImageOptions{Name: test.name}, test.auth); err != nil {
+			t.Fatal(err)
+		}
+		var got AuthConfiguration
+		data, err := base64.URLEncoding.DecodeString(fakeRT.requests[0].Header.Get("X-Registry-Auth"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("PushImage: wrong credentials. Want %#v. Got %#v.", expected, got)
+		}
+	}
+
+	// credentials of other users are sent as given
+	other := AuthConfiguration{Username: "other", Password: "secret", ServerAddress: "registry.example.com"}
+	if got := client.resolveAuth("registry.example.com/tsuru/python", other); got != other {
+		t.Errorf("resolveAuth: wrong credentials. Want %#v. Got %#v.", other, got)
+	}
+}
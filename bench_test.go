@@ -0,0 +1,118 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// benchImage is the image used by the benchmarks, which run against the
+// daemon given by DOCKER_HOST, like:
+//
+//     DOCKER_HOST=unix:///var/run/docker.sock go test -run XXX -bench .
+const benchImage = "alpine:3.9"
+
+func newBenchClient(b *testing.B) *Client {
+	if os.Getenv("DOCKER_HOST") == "" {
+		b.Skip("DOCKER_HOST is not set")
+	}
+	client, err := NewClientFromEnv()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err = client.Ping(); err != nil {
+		b.Skipf("the Docker daemon is not available: %s", err)
+	}
+	return client
+}
+
+func benchPull(b *testing.B, client *Client) {
+	err := client.PullImage(PullImageOptions{Repository: benchImage, OutputStream: ioutil.Discard}, AuthConfiguration{})
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkPullImage removes benchImage from the daemon before each pull, so
+// it only runs when DOCKER_BENCH_PULL is set, as it would delete an image
+// the developer may be using.
+func BenchmarkPullImage(b *testing.B) {
+	if os.Getenv("DOCKER_BENCH_PULL") == "" {
+		b.Skip("DOCKER_BENCH_PULL is not set")
+	}
+	client := newBenchClient(b)
+	benchPull(b, client)
+	image, err := client.InspectImage(benchImage)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(image.Size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		if err = client.RemoveImageExtended(benchImage, RemoveImageOptions{Force: true}); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		benchPull(b, client)
+	}
+}
+
+func BenchmarkBuildImage(b *testing.B) {
+	client := newBenchClient(b)
+	benchPull(b, client)
+	var buildContext bytes.Buffer
+	tw := tar.NewWriter(&buildContext)
+	dockerfile := []byte(fmt.Sprintf("FROM %s\nRUN echo hello > /hello\n", benchImage))
+	tw.WriteHeader(&tar.Header{Name: "Dockerfile", Mode: 0644, Size: int64(len(dockerfile))})
+	tw.Write(dockerfile)
+	tw.Close()
+	b.SetBytes(int64(buildContext.Len()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := client.BuildImage(BuildImageOptions{
+			Name:           "go-dockerclient-bench",
+			InputStream:    bytes.NewReader(buildContext.Bytes()),
+			OutputStream:   ioutil.Discard,
+			NoCache:        true,
+			RmTmpContainer: true,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	client.RemoveImage("go-dockerclient-bench")
+}
+
+func BenchmarkCreateContainer(b *testing.B) {
+	client := newBenchClient(b)
+	benchPull(b, client)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		container, err := client.CreateContainer(CreateContainerOptions{Config: &Config{Image: benchImage, Cmd: []string{"true"}}})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err = client.RemoveContainer(RemoveContainerOptions{ID: container.ID, Force: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListContainers(b *testing.B) {
+	client := newBenchClient(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ListContainers(ListContainersOptions{All: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -7,6 +7,8 @@
 package docker
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
 	"io"
 	"io/ioutil"
@@ -14,7 +16,9 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/pkg/archive"
 )
@@ -164,3 +168,247 @@ func unpackBodyTarball(req io.ReadCloser) (tmpdir string, err error) {
 	})
 	return
 }
+
+func TestBuildImageCacheMounts(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	var input bytes.Buffer
+	tw := tar.NewWriter(&input)
+	for name, content := range map[string]string{
+		"build/Dockerfile": "FROM python:3.7\nCOPY requirements.txt .\nRUN pip install -r requirements.txt\n",
+		"requirements.txt": "requests==2.21.0\n",
+	} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	var buf bytes.Buffer
+	opts := BuildImageOptions{
+		Name:         "testImage",
+		Dockerfile:   "build/Dockerfile",
+		InputStream:  &input,
+		OutputStream: &buf,
+		CacheMounts:  []CacheMount{{ID: "pip", Target: "/root/.cache/pip", Sharing: "locked"}},
+	}
+	if err := client.BuildImage(opts); err != nil {
+		t.Fatal(err)
+	}
+	req := fakeRT.requests[0]
+	if version := req.URL.Query().Get("version"); version != "2" {
+		t.Errorf("BuildImage: wrong builder version. Want %q. Got %q.", "2", version)
+	}
+	files := make(map[string]string)
+	tr := tar.NewReader(req.Body)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := ioutil.ReadAll(tr)
+		files[hdr.Name] = string(content)
+	}
+	expected := map[string]string{
+		"build/Dockerfile": "# syntax=docker/dockerfile:experimental\nFROM python:3.7\nCOPY requirements.txt .\nRUN --mount=type=cache,id=pip,target=/root/.cache/pip,sharing=locked pip install -r requirements.txt\n",
+		"requirements.txt": "requests==2.21.0\n",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("BuildImage: wrong build context.\nWant %#v.\nGot  %#v.", expected, files)
+	}
+}
+
+func TestBuildImageCacheMountsRemote(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "", status: http.StatusOK})
+	var buf bytes.Buffer
+	opts := BuildImageOptions{
+		Name:         "testImage",
+		Remote:       "github.com/fsouza/go-dockerclient",
+		OutputStream: &buf,
+		CacheMounts:  []CacheMount{{Target: "/root/.cache"}},
+	}
+	if err := client.BuildImage(opts); err != ErrCacheMountsWithRemote {
+		t.Errorf("BuildImage: wrong error. Want %#v. Got %#v.", ErrCacheMountsWithRemote, err)
+	}
+}
+
+func readTarFiles(t *testing.T, r io.Reader) map[string]string {
+	files := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := ioutil.ReadAll(tr)
+		files[hdr.Name] = string(content)
+	}
+	return files
+}
+
+func TestBuildImageDockerfileContent(t *testing.T) {
+	t.Parallel()
+	dockerfile := "FROM alpine\nCOPY app.py /\n"
+	contextDir, err := ioutil.TempDir("", "go-dockerclient-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(contextDir)
+	if err = ioutil.WriteFile(filepath.Join(contextDir, "app.py"), []byte("print('hi')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var input bytes.Buffer
+	tw := tar.NewWriter(&input)
+	for name, content := range map[string]string{
+		"build/Dockerfile": "FROM scratch\n",
+		"app.py":           "print('hi')\n",
+	} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	var tests = []struct {
+		name               string
+		opts               BuildImageOptions
+		expectedDockerfile string
+		expectedFiles      map[string]string
+	}{
+		{
+			"no context",
+			BuildImageOptions{},
+			"Dockerfile",
+			map[string]string{"Dockerfile": dockerfile},
+		},
+		{
+			"input stream",
+			BuildImageOptions{InputStream: &input, Dockerfile: "build/Dockerfile"},
+			"build/Dockerfile",
+			map[string]string{"build/Dockerfile": dockerfile, "app.py": "print('hi')\n"},
+		},
+		{
+			"context dir",
+			BuildImageOptions{ContextDir: contextDir},
+			"Dockerfile",
+			map[string]string{"Dockerfile": dockerfile, "app.py": "print('hi')\n"},
+		},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+		client := newTestClient(fakeRT)
+		var buf bytes.Buffer
+		opts := test.opts
+		opts.Name = "app"
+		opts.OutputStream = &buf
+		opts.DockerfileContent = []byte(dockerfile)
+		if err := client.BuildImage(opts); err != nil {
+			t.Fatalf("BuildImage(%s): %s", test.name, err)
+		}
+		req := fakeRT.requests[0]
+		if got := req.URL.Query().Get("dockerfile"); got != test.expectedDockerfile {
+			t.Errorf("BuildImage(%s): wrong dockerfile. Want %q. Got %q.", test.name, test.expectedDockerfile, got)
+		}
+		if got := req.Header.Get("Content-Type"); got != "application/tar" {
+			t.Errorf("BuildImage(%s): wrong content type. Want %q. Got %q.", test.name, "application/tar", got)
+		}
+		if files := readTarFiles(t, req.Body); !reflect.DeepEqual(files, test.expectedFiles) {
+			t.Errorf("BuildImage(%s): wrong build context.\nWant %#v.\nGot  %#v.", test.name, test.expectedFiles, files)
+		}
+	}
+}
+
+// closeNotifier is a reader that closes its channel when it's closed.
+type closeNotifier struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (r *closeNotifier) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestBuildImageKeepsInputStreamOpen(t *testing.T) {
+	t.Parallel()
+	var input bytes.Buffer
+	tw := tar.NewWriter(&input)
+	content := "RUN make\n"
+	tw.WriteHeader(&tar.Header{Name: "Dockerfile", Mode: 0644, Size: int64(len(content))})
+	tw.Write([]byte(content))
+	tw.Close()
+	stream := &closeNotifier{Reader: &input, closed: make(chan struct{})}
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	err := client.BuildImage(BuildImageOptions{
+		Name:              "app",
+		InputStream:       stream,
+		DockerfileContent: []byte("FROM alpine\nRUN make\n"),
+		CacheMounts:       []CacheMount{{Target: "/root/.cache"}},
+		OutputStream:      ioutil.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readTarFiles(t, fakeRT.requests[0].Body)
+	select {
+	case <-stream.closed:
+		t.Error("BuildImage: the input stream of the caller was closed")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuildImageDockerfileContentRemote(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "", status: http.StatusOK})
+	var buf bytes.Buffer
+	opts := BuildImageOptions{
+		Name:              "testImage",
+		Remote:            "github.com/fsouza/go-dockerclient",
+		OutputStream:      &buf,
+		DockerfileContent: []byte("FROM alpine\n"),
+	}
+	if err := client.BuildImage(opts); err != ErrDockerfileContentWithRemote {
+		t.Errorf("BuildImage: wrong error. Want %#v. Got %#v.", ErrDockerfileContentWithRemote, err)
+	}
+}
+
+func TestAddCacheMountsToDockerfile(t *testing.T) {
+	t.Parallel()
+	mounts := []CacheMount{{Target: "/var/cache/apt"}, {ID: "go", Target: "/root/.cache/go-build"}}
+	flags := "--mount=type=cache,target=/var/cache/apt --mount=type=cache,id=go,target=/root/.cache/go-build"
+	var tests = []struct {
+		input    string
+		expected string
+	}{
+		{
+			"FROM golang\nrun\tgo build ./...\n",
+			"# syntax=docker/dockerfile:experimental\nFROM golang\nrun " + flags + "\tgo build ./...\n",
+		},
+		{
+			"# syntax=docker/dockerfile:1.0-experimental\nFROM golang\n  RUN apt-get update && \\\n    # run the install\n    run-parts /etc/setup.d\nRUN go test\n",
+			"# syntax=docker/dockerfile:1.0-experimental\nFROM golang\n  RUN " + flags + " apt-get update && \\\n    # run the install\n    run-parts /etc/setup.d\nRUN " + flags + " go test\n",
+		},
+		{
+			"FROM golang\nRUNNING=1\n",
+			"# syntax=docker/dockerfile:experimental\nFROM golang\nRUNNING=1\n",
+		},
+	}
+	for _, test := range tests {
+		got, err := addCacheMountsToDockerfile([]byte(test.input), mounts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.expected {
+			t.Errorf("addCacheMountsToDockerfile(%q): wrong output.\nWant %q.\nGot  %q.", test.input, test.expected, got)
+		}
+	}
+	longLine := "RUN echo " + strings.Repeat("a", bufio.MaxScanTokenSize) + "\n"
+	if _, err := addCacheMountsToDockerfile([]byte(longLine), mounts); err != bufio.ErrTooLong {
+		t.Errorf("addCacheMountsToDockerfile: wrong error for a long line. Want %#v. Got %#v.", bufio.ErrTooLong, err)
+	}
+}
@@ -33,17 +33,20 @@ import (
 
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/homedir"
-	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/fsouza/go-dockerclient/internal/jsonmessage"
 )
 
 const (
-	userAgent = "go-dockerclient"
-
 	unixProtocol      = "unix"
 	namedPipeProtocol = "npipe"
 )
 
+// version is the version of go-dockerclient sent in the default User-Agent
+// header, go-dockerclient/<version>. It's set at build time with:
+//
+//	go build -ldflags "-X github.com/fsouza/go-dockerclient.version=v1.4.0"
+var version = "dev"
+
 var (
 	// ErrInvalidEndpoint is returned when the endpoint is not a valid HTTP URL.
 	ErrInvalidEndpoint = errors.New("invalid endpoint")
@@ -54,11 +57,28 @@ var (
 	// ErrInactivityTimeout is returned when a streamable call has been inactive for some time.
 	ErrInactivityTimeout = errors.New("inactivity time exceeded timeout")
 
+	// ErrUnauthorized is returned when the progress stream of a streamable
+	// call, such as PullImage or PushImage, reports an authentication
+	// failure.
+	ErrUnauthorized = jsonmessage.ErrUnauthorized
+
+	// ErrTLSNotConfigured is returned by WithTLSServerName and
+	// WithInsecureSkipVerify when the client has no TLS configuration.
+	ErrTLSNotConfigured = errors.New("the client has no TLS configuration")
+
+	// ErrIncompleteKeyPair is returned by WithTLSFromBytes when only one of
+	// the client certificate and its key is given.
+	ErrIncompleteKeyPair = errors.New("both the client certificate and its key are required")
+
 	apiVersion112, _ = NewAPIVersion("1.12")
 	apiVersion119, _ = NewAPIVersion("1.19")
 	apiVersion124, _ = NewAPIVersion("1.24")
 	apiVersion125, _ = NewAPIVersion("1.25")
 	apiVersion135, _ = NewAPIVersion("1.35")
+
+	// maxAPIVersion is the latest API version supported by the client, used
+	// by NegotiateAPIVersion when no API version was requested.
+	maxAPIVersion, _ = NewAPIVersion("1.39")
 )
 
 // APIVersion is an internal representation of a version of the Remote API.
@@ -145,12 +165,37 @@ type Client struct {
 	TLSConfig              *tls.Config
 	Dialer                 Dialer
 
-	endpoint            string
-	endpointURL         *url.URL
-	eventMonitor        *eventMonitoringState
-	requestedAPIVersion APIVersion
-	serverAPIVersion    APIVersion
-	expectedAPIVersion  APIVersion
+	// CredentialHelper, when set, is used to resolve the credentials of
+	// registries before falling back to the AuthConfiguration given to
+	// PullImage and PushImage.
+	CredentialHelper CredentialHelper
+
+	// AutoNegotiateVersion makes the client call NegotiateAPIVersion
+	// before its first request.
+	AutoNegotiateVersion bool
+
+	// Timeouts limits the duration of the requests by category of
+	// operation. It should not be changed concurrently with any other Client
+	// methods.
+	Timeouts Timeouts
+
+	retryPolicy   RetryPolicy
+	requestLogger func(method, url string, statusCode int, duration time.Duration)
+	bodyLogger    *bodyLogger
+	requestHook   func(*http.Request)
+	responseHook  func(*http.Request, *http.Response, error)
+	tracer        Tracer
+	metrics       Metrics
+	userAgent     string
+
+	endpoint             string
+	endpointURL          *url.URL
+	eventMonitor         *eventMonitoringState
+	registryAuths        *registryAuthStore
+	requestedAPIVersion  APIVersion
+	serverAPIVersion     APIVersion
+	expectedAPIVersion   APIVersion
+	negotiatedAPIVersion APIVersion
 }
 
 // Dialer is an interface that allows network connections to be dialed
@@ -196,29 +241,38 @@ func NewTLSClientFromBytes(endpoint string, certPEMBlock, keyPEMBlock, caPEMCert
 	return client, nil
 }
 
+// NewTLSClientFromCertPath returns a Client instance ready for TLS
+// communications with the given server endpoint, loading the client
+// certificate, key and CA from the cert.pem, key.pem and ca.pem files in
+// certPath, following the layout used by DOCKER_CERT_PATH. Mutual TLS is
+// enabled when both cert.pem and key.pem are present, and the server
+// certificate is verified when ca.pem is present. It will use the latest
+// remote API version available in the server.
+func NewTLSClientFromCertPath(endpoint string, certPath string) (*Client, error) {
+	cert := filepath.Join(certPath, "cert.pem")
+	key := filepath.Join(certPath, "key.pem")
+	ca := filepath.Join(certPath, "ca.pem")
+	return NewTLSClient(endpoint, cert, key, ca)
+}
+
 // NewVersionedClient returns a Client instance ready for communication with
 // the given server endpoint, using a specific remote API version.
 func NewVersionedClient(endpoint string, apiVersionString string) (*Client, error) {
-	u, err := parseEndpoint(endpoint, false)
+	return newVersionedClient(apiVersionString, WithEndpoint(endpoint))
+}
+
+// newVersionedClient returns a client configured by the given options, using
+// the remote API version in apiVersionString when it's a version number, as
+// the NewVersioned* constructors do.
+func newVersionedClient(apiVersionString string, opts ...ClientOption) (*Client, error) {
+	if strings.Contains(apiVersionString, ".") {
+		opts = append(opts, WithAPIVersion(apiVersionString))
+	}
+	c, err := NewClientWithOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
-	var requestedAPIVersion APIVersion
-	if strings.Contains(apiVersionString, ".") {
-		requestedAPIVersion, err = NewAPIVersion(apiVersionString)
-		if err != nil {
-			return nil, err
-		}
-	}
-	c := &Client{
-		HTTPClient:          defaultClient(),
-		Dialer:              &net.Dialer{},
-		endpoint:            endpoint,
-		endpointURL:         u,
-		eventMonitor:        new(eventMonitoringState),
-		requestedAPIVersion: requestedAPIVersion,
-	}
-	c.initializeNativeClient(defaultTransport)
+	c.SkipServerVersionCheck = false
 	return c, nil
 }
 
@@ -264,6 +318,7 @@ func NewVersionedTLSClient(endpoint string, cert, key, ca, apiVersionString stri
 
 // NewClientFromEnv returns a Client instance ready for communication created from
 // Docker's default logic for the environment variables DOCKER_HOST, DOCKER_TLS_VERIFY, and DOCKER_CERT_PATH.
+// DOCKER_CONTEXT is also respected, as described in NewVersionedClientFromEnv.
 //
 // See https://github.com/docker/docker/blob/1f963af697e8df3a78217f6fdbf67b8123a7db94/docker/docker.go#L68.
 // See https://github.com/docker/compose/blob/81707ef1ad94403789166d2fe042c8a718a4c748/compose/cli/docker_client.py#L7.
@@ -280,9 +335,22 @@ func NewClientFromEnv() (*Client, error) {
 // Docker's default logic for the environment variables DOCKER_HOST, DOCKER_TLS_VERIFY, and DOCKER_CERT_PATH,
 // and using a specific remote API version.
 //
+// When DOCKER_HOST is not set and DOCKER_CONTEXT names a context other than
+// "default", the client is configured from that context instead, as
+// NewClientFromContext does.
+//
 // See https://github.com/docker/docker/blob/1f963af697e8df3a78217f6fdbf67b8123a7db94/docker/docker.go#L68.
 // See https://github.com/docker/compose/blob/81707ef1ad94403789166d2fe042c8a718a4c748/compose/cli/docker_client.py#L7.
 func NewVersionedClientFromEnv(apiVersionString string) (*Client, error) {
+	if os.Getenv("DOCKER_HOST") == "" {
+		if contextName := os.Getenv("DOCKER_CONTEXT"); contextName != "" && contextName != defaultContextName {
+			return newVersionedClientFromContext(contextName, apiVersionString)
+		}
+	}
+	return newVersionedClientFromDockerEnv(apiVersionString)
+}
+
+func newVersionedClientFromDockerEnv(apiVersionString string) (*Client, error) {
 	dockerEnv, err := getDockerEnv()
 	if err != nil {
 		return nil, err
@@ -305,17 +373,18 @@ func NewVersionedClientFromEnv(apiVersionString string) (*Client, error) {
 // server endpoint, key and certificates (passed inline to the function as opposed to being
 // read from a local file), using a specific remote API version.
 func NewVersionedTLSClientFromBytes(endpoint string, certPEMBlock, keyPEMBlock, caPEMCert []byte, apiVersionString string) (*Client, error) {
-	u, err := parseEndpoint(endpoint, true)
+	tlsConfig, err := tlsConfigFromBytes(certPEMBlock, keyPEMBlock, caPEMCert)
 	if err != nil {
 		return nil, err
 	}
-	var requestedAPIVersion APIVersion
-	if strings.Contains(apiVersionString, ".") {
-		requestedAPIVersion, err = NewAPIVersion(apiVersionString)
-		if err != nil {
-			return nil, err
-		}
-	}
+	return newVersionedClient(apiVersionString, WithTLSConfig(tlsConfig), WithEndpoint(endpoint))
+}
+
+// tlsConfigFromBytes returns the TLS configuration for the given PEM encoded
+// client certificate, key and CA. The client certificate is only used when
+// both the certificate and the key are given, and the certificate of the
+// server isn't verified when no CA is given.
+func tlsConfigFromBytes(certPEMBlock, keyPEMBlock, caPEMCert []byte) (*tls.Config, error) {
 	tlsConfig := &tls.Config{}
 	if certPEMBlock != nil && keyPEMBlock != nil {
 		tlsCert, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
@@ -333,24 +402,247 @@ func NewVersionedTLSClientFromBytes(endpoint string, certPEMBlock, keyPEMBlock,
 		}
 		tlsConfig.RootCAs = caPool
 	}
-	tr := defaultTransport()
-	tr.TLSClientConfig = tlsConfig
-	if err != nil {
-		return nil, err
+	return tlsConfig, nil
+}
+
+// WithTLSFromBytes configures the client for TLS communications using the
+// PEM encoded certificate, key and CA, like NewTLSClientFromBytes does, for
+// clients created with NewClient or NewVersionedClient. The certificate and
+// the key must be given together, and must match. Without a CA, the
+// certificate of the server isn't verified. The configuration is applied as
+// with WithTLSConfig.
+func WithTLSFromBytes(cert, key, ca []byte) ClientOption {
+	return func(c *Client) error {
+		if (cert == nil) != (key == nil) {
+			return ErrIncompleteKeyPair
+		}
+		tlsConfig, err := tlsConfigFromBytes(cert, key, ca)
+		if err != nil {
+			return err
+		}
+		return WithTLSConfig(tlsConfig)(c)
+	}
+}
+
+// ClientOption configures a Client, see ApplyOptions.
+type ClientOption func(*Client) error
+
+// ApplyOptions configures the client with the given options, stopping at the
+// first one that fails. It should not be called concurrently with any other
+// Client methods.
+func (c *Client) ApplyOptions(opts ...ClientOption) error {
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// NewClientWithOptions returns a Client configured by the given options,
+// which must include WithEndpoint. Options are applied in order, so
+// WithHTTPClient, which replaces the HTTP client, should come before
+// WithTimeout. Unless WithAPIVersion is given, it will use the
+// latest remote API version available in the server, like NewClient.
+//
+//	client, err := docker.NewClientWithOptions(
+//		docker.WithEndpoint("tcp://docker.example.com:2376"),
+//		docker.WithTLSConfig(tlsConfig),
+//		docker.WithTimeout(30*time.Second),
+//	)
+func NewClientWithOptions(opts ...ClientOption) (*Client, error) {
 	c := &Client{
-		HTTPClient:          &http.Client{Transport: tr},
-		TLSConfig:           tlsConfig,
-		Dialer:              &net.Dialer{},
-		endpoint:            endpoint,
-		endpointURL:         u,
-		eventMonitor:        new(eventMonitoringState),
-		requestedAPIVersion: requestedAPIVersion,
-	}
-	c.initializeNativeClient(defaultTransport)
+		SkipServerVersionCheck: true,
+		HTTPClient:             defaultClient(),
+		Dialer:                 &net.Dialer{},
+		eventMonitor:           new(eventMonitoringState),
+		registryAuths:          new(registryAuthStore),
+	}
+	if err := c.ApplyOptions(opts...); err != nil {
+		return nil, err
+	}
+	if c.endpointURL == nil {
+		return nil, ErrInvalidEndpoint
+	}
 	return c, nil
 }
 
+// WithEndpoint sets the endpoint of the Docker daemon, in any of the formats
+// accepted by NewClient. Endpoints using the tcp scheme are switched to https
+// when the client has a TLS configuration. For unix sockets and named pipes,
+// it sets up the transport of the HTTP client to dial them, so WithHTTPClient
+// should come after it.
+func WithEndpoint(endpoint string) ClientOption {
+	return func(c *Client) error {
+		u, err := parseEndpoint(endpoint, c.TLSConfig != nil)
+		if err != nil {
+			return err
+		}
+		c.endpoint = endpoint
+		c.endpointURL = u
+		if c.HTTPClient == nil {
+			c.HTTPClient = defaultClient()
+		}
+		c.initializeNativeClient(defaultTransport)
+		return nil
+	}
+}
+
+// WithTLSConfig makes the client connect to the daemon using the given TLS
+// configuration, switching endpoints using the http scheme to https. It
+// replaces the HTTP client with one using the configuration, keeping its
+// timeout.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) error {
+		tr := defaultTransport()
+		tr.TLSClientConfig = config
+		httpClient := &http.Client{Transport: tr}
+		if c.HTTPClient != nil {
+			httpClient.Timeout = c.HTTPClient.Timeout
+		}
+		c.TLSConfig = config
+		c.HTTPClient = httpClient
+		if c.endpointURL != nil {
+			if c.endpointURL.Scheme == "http" {
+				u := *c.endpointURL
+				u.Scheme = "https"
+				c.endpointURL = &u
+			}
+			c.initializeNativeClient(defaultTransport)
+		}
+		return nil
+	}
+}
+
+// WithTLSServerName sets the name used to verify the certificate of the
+// daemon, and sent to it with SNI, for daemons reached through an IP address
+// or a load balancer whose name doesn't match the certificate. It requires a
+// TLS configuration, so it must come after WithTLSConfig or be applied to a
+// client created by one of the TLS constructors:
+//
+//	client, err := docker.NewTLSClient("tcp://10.0.0.5:2376", cert, key, ca)
+//	if err != nil {
+//		// handle error
+//	}
+//	err = client.ApplyOptions(docker.WithTLSServerName("docker.example.com"))
+func WithTLSServerName(name string) ClientOption {
+	return func(c *Client) error {
+		return c.updateTLSConfig(func(config *tls.Config) {
+			config.ServerName = name
+		})
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the certificate of the
+// daemon. Any certificate is then accepted, so anyone able to intercept the
+// connection can impersonate the daemon and read the requests, including
+// registry credentials, which must never be done outside of tests. It's
+// meant for testing against daemons with self-signed certificates; when the
+// certificate is valid but issued for another name, WithTLSServerName should
+// be used instead. Like WithTLSServerName, it requires a TLS configuration.
+//
+// The option isn't logged: the client writes no logs of its own, and the
+// loggers set with WithRequestLogger and WithBodyLogger only report requests.
+// Callers that must record it can check InsecureSkipVerify in the TLSConfig
+// of the client.
+func WithInsecureSkipVerify() ClientOption {
+	return func(c *Client) error {
+		return c.updateTLSConfig(func(config *tls.Config) {
+			config.InsecureSkipVerify = true
+		})
+	}
+}
+
+// updateTLSConfig applies the given update to a copy of the TLS
+// configuration of the client, leaving the configuration given to
+// WithTLSConfig untouched.
+func (c *Client) updateTLSConfig(update func(*tls.Config)) error {
+	if c.TLSConfig == nil {
+		return ErrTLSNotConfigured
+	}
+	config := c.TLSConfig.Clone()
+	update(config)
+	return WithTLSConfig(config)(c)
+}
+
+// WithHTTPClient makes the client send its requests through the given HTTP
+// client, which is used as is: its transport must be able to reach the
+// endpoint of the client.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) error {
+		c.HTTPClient = httpClient
+		return nil
+	}
+}
+
+// WithAuth stores the credentials of a registry in the client, as
+// RegistryLogin does after validating them, so that they're used by the
+// operations on images of the registry given no credentials.
+func WithAuth(auth AuthConfiguration) ClientOption {
+	return func(c *Client) error {
+		c.registryAuths.set(auth)
+		return nil
+	}
+}
+
+// WithAPIVersion makes the client use the given remote API version, like
+// NewVersionedClient, instead of the latest one available in the server.
+func WithAPIVersion(version string) ClientOption {
+	return func(c *Client) error {
+		requestedAPIVersion, err := NewAPIVersion(version)
+		if err != nil {
+			return err
+		}
+		c.requestedAPIVersion = requestedAPIVersion
+		c.expectedAPIVersion = nil
+		c.SkipServerVersionCheck = false
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent by the client in its
+// requests, as SetUserAgent does.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) error {
+		c.SetUserAgent(ua)
+		return nil
+	}
+}
+
+// WithTimeout limits the duration of the requests of the client, as
+// SetTimeout does.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.SetTimeout(d)
+		return nil
+	}
+}
+
+// WithTimeouts limits the duration of the requests of the client by category
+// of operation, replacing its Timeouts. Unlike WithTimeout, it can limit the
+// unary requests without interrupting streams such as logs and events.
+func WithTimeouts(t Timeouts) ClientOption {
+	return func(c *Client) error {
+		c.Timeouts = t
+		return nil
+	}
+}
+
+// SetUserAgent sets the User-Agent header sent by the client in all of its
+// requests to the daemon, which defaults to go-dockerclient/<version>. An
+// empty string restores the default. It should not be called concurrently
+// with any other Client methods.
+func (c *Client) SetUserAgent(ua string) {
+	c.userAgent = ua
+}
+
+func (c *Client) getUserAgent() string {
+	if c.userAgent != "" {
+		return c.userAgent
+	}
+	return "go-dockerclient/" + version
+}
+
 // SetTimeout takes a timeout and applies it to the HTTPClient. It should not
 // be called concurrently with any other Client methods.
 func (c *Client) SetTimeout(t time.Duration) {
@@ -359,8 +651,103 @@ func (c *Client) SetTimeout(t time.Duration) {
 	}
 }
 
+// WithDefaultTimeout returns a shallow copy of the client that limits each of
+// its unary requests, the ones covered by ShortOperationTimeout, to the given
+// duration. Streaming operations, such as logs, events, attach, stats, wait
+// and exec, and the transfers of images and filesystems keep the timeouts of
+// c, so they aren't interrupted. The copy shares the HTTP client, the event
+// listeners and the registry credentials of c.
+func (c *Client) WithDefaultTimeout(d time.Duration) *Client {
+	clone := *c
+	clone.Timeouts.ShortOperationTimeout = d
+	return &clone
+}
+
+// Timeouts specifies the maximum duration of the requests to the Docker
+// daemon, by category of operation. The category of a request is given by
+// its endpoint, and a zero timeout means no limit. For operations that return
+// a stream, the timeout covers reading the whole stream.
+type Timeouts struct {
+	// ShortOperationTimeout applies to the operations that don't fall
+	// into the other categories, such as inspecting and listing
+	// containers.
+	ShortOperationTimeout time.Duration
+
+	// LongOperationTimeout applies to the operations that transfer images
+	// or filesystems, such as pulling, pushing, building, loading and
+	// exporting images, committing and exporting containers, and copying
+	// files.
+	LongOperationTimeout time.Duration
+
+	// StreamingOperationTimeout applies to the operations that follow a
+	// container or the daemon, such as logs, attach, stats, wait, exec and
+	// events.
+	StreamingOperationTimeout time.Duration
+}
+
+// forPath returns the timeout of the request to the given path, which may
+// include the query string.
+func (t Timeouts) forPath(path string) time.Duration {
+	if i := strings.IndexByte(path, '?'); i > -1 {
+		path = path[:i]
+	}
+	switch {
+	case isStreamingOperation(path):
+		return t.StreamingOperationTimeout
+	case isLongOperation(path):
+		return t.LongOperationTimeout
+	default:
+		return t.ShortOperationTimeout
+	}
+}
+
+func isStreamingOperation(path string) bool {
+	switch {
+	case path == "/events":
+		return true
+	case strings.HasPrefix(path, "/containers/"):
+		for _, suffix := range []string{"/logs", "/attach", "/attach/ws", "/stats", "/wait"} {
+			if strings.HasSuffix(path, suffix) {
+				return true
+			}
+		}
+	case strings.HasPrefix(path, "/exec/"):
+		return strings.HasSuffix(path, "/start")
+	}
+	return false
+}
+
+func isLongOperation(path string) bool {
+	switch path {
+	case "/build", "/commit", "/images/create", "/images/load", "/images/get", "/plugins/pull":
+		return true
+	}
+	switch {
+	case strings.HasPrefix(path, "/images/"):
+		return strings.HasSuffix(path, "/push") || strings.HasSuffix(path, "/get")
+	case strings.HasPrefix(path, "/containers/"):
+		return strings.HasSuffix(path, "/export") || strings.HasSuffix(path, "/archive") || strings.HasSuffix(path, "/copy")
+	case strings.HasPrefix(path, "/plugins/"):
+		return strings.HasSuffix(path, "/upgrade")
+	}
+	return false
+}
+
+// cancelReadCloser cancels the context of a request when its body is
+// closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
 func (c *Client) checkAPIVersion() error {
-	serverAPIVersionString, err := c.getServerAPIVersionString()
+	serverAPIVersionString, err := c.getServerAPIVersionString(doOptions{})
 	if err != nil {
 		return err
 	}
@@ -376,6 +763,62 @@ func (c *Client) checkAPIVersion() error {
 	return nil
 }
 
+// NegotiateAPIVersion queries the daemon for its API version and makes the
+// client use, in all subsequent requests, the lowest between it and the
+// version supported by the client. The version supported by the client is
+// the one requested at construction time or, when no version was requested,
+// the latest version known by go-dockerclient.
+//
+// It's called automatically before the first request when
+// AutoNegotiateVersion is set.
+func (c *Client) NegotiateAPIVersion() error {
+	serverAPIVersionString, err := c.getServerAPIVersionString(doOptions{unversioned: true})
+	if err != nil {
+		return err
+	}
+	serverAPIVersion, err := NewAPIVersion(serverAPIVersionString)
+	if err != nil {
+		return err
+	}
+	negotiated := c.requestedAPIVersion
+	if negotiated == nil {
+		negotiated = maxAPIVersion
+	}
+	if serverAPIVersion.LessThan(negotiated) {
+		negotiated = serverAPIVersion
+	}
+	c.serverAPIVersion = serverAPIVersion
+	c.expectedAPIVersion = negotiated
+	c.negotiatedAPIVersion = negotiated
+	return nil
+}
+
+// maybeNegotiateAPIVersion calls NegotiateAPIVersion before the first request
+// to path when AutoNegotiateVersion is set.
+func (c *Client) maybeNegotiateAPIVersion(path string) error {
+	if path == "/version" || !c.AutoNegotiateVersion || c.negotiatedAPIVersion != nil {
+		return nil
+	}
+	return c.NegotiateAPIVersion()
+}
+
+// NegotiatedAPIVersion returns the API version chosen by NegotiateAPIVersion,
+// or an empty string if the version hasn't been negotiated.
+func (c *Client) NegotiatedAPIVersion() string {
+	if c.negotiatedAPIVersion == nil {
+		return ""
+	}
+	return c.negotiatedAPIVersion.String()
+}
+
+// apiVersion returns the API version used in the requests, if any.
+func (c *Client) apiVersion() APIVersion {
+	if c.negotiatedAPIVersion != nil {
+		return c.negotiatedAPIVersion
+	}
+	return c.requestedAPIVersion
+}
+
 // Endpoint returns the current endpoint. It's useful for getting the endpoint
 // when using functions that get this data from the environment (like
 // NewClientFromEnv.
@@ -395,20 +838,49 @@ func (c *Client) Ping() error {
 //
 // See https://goo.gl/wYfgY1 for more details.
 func (c *Client) PingWithContext(ctx context.Context) error {
+	_, err := c.PingWithResponse(ctx)
+	return err
+}
+
+// PingResponse is the information about the daemon sent in the headers of
+// the response to a ping.
+type PingResponse struct {
+	APIVersion     string
+	OSType         string
+	Experimental   bool
+	BuilderVersion string
+
+	// SwarmStatus is the state of the node in the swarm, such as
+	// "inactive" or "active/manager". It's only sent by recent daemons.
+	SwarmStatus string
+}
+
+// PingWithResponse pings the docker server, returning the information about
+// the daemon sent in the response. The context object can be used to cancel
+// the ping request.
+//
+// See https://goo.gl/wYfgY1 for more details.
+func (c *Client) PingWithResponse(ctx context.Context) (*PingResponse, error) {
 	path := "/_ping"
 	resp, err := c.do("GET", path, doOptions{context: ctx})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		return newError(resp)
+		return nil, newError(resp)
 	}
 	resp.Body.Close()
-	return nil
+	return &PingResponse{
+		APIVersion:     resp.Header.Get("API-Version"),
+		OSType:         resp.Header.Get("OSType"),
+		Experimental:   resp.Header.Get("Docker-Experimental") == "true",
+		BuilderVersion: resp.Header.Get("Builder-Version"),
+		SwarmStatus:    resp.Header.Get("Swarm"),
+	}, nil
 }
 
-func (c *Client) getServerAPIVersionString() (version string, err error) {
-	resp, err := c.do("GET", "/version", doOptions{})
+func (c *Client) getServerAPIVersionString(opts doOptions) (version string, err error) {
+	resp, err := c.do("GET", "/version", opts)
 	if err != nil {
 		return "", err
 	}
@@ -431,16 +903,22 @@ type doOptions struct {
 	forceJSON bool
 	headers   map[string]string
 	context   context.Context
+
+	// unversioned makes the request skip the API version prefix.
+	unversioned bool
 }
 
 func (c *Client) do(method, path string, doOptions doOptions) (*http.Response, error) {
-	var params io.Reader
+	var body []byte
 	if doOptions.data != nil || doOptions.forceJSON {
-		buf, err := json.Marshal(doOptions.data)
+		var err error
+		body, err = json.Marshal(doOptions.data)
 		if err != nil {
 			return nil, err
 		}
-		params = bytes.NewBuffer(buf)
+	}
+	if err := c.maybeNegotiateAPIVersion(path); err != nil {
+		return nil, err
 	}
 	if path != "/version" && !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
 		err := c.checkAPIVersion()
@@ -448,20 +926,56 @@ func (c *Client) do(method, path string, doOptions doOptions) (*http.Response, e
 			return nil, err
 		}
 	}
+	version := c.apiVersion()
+	if doOptions.unversioned {
+		version = nil
+	}
 	protocol := c.endpointURL.Scheme
 	var u string
 	switch protocol {
 	case unixProtocol, namedPipeProtocol:
-		u = c.getFakeNativeURL(path)
+		u = c.getFakeNativeURLWithVersion(path, version)
 	default:
-		u = c.getURL(path)
+		u = c.getURLWithVersion(path, version)
+	}
+	ctx := doOptions.context
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	idempotent := isIdempotentMethod(method)
+	retryable := c.retryPolicy != nil && (idempotent || isRetryableContext(ctx))
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doRequest(ctx, method, u, path, body, doOptions)
+		if err == nil && attempt > 1 && !idempotent && resp.StatusCode == http.StatusNotModified {
+			// the previous attempt took effect, but its response was lost
+			resp.StatusCode = http.StatusNoContent
+		}
+		if err == nil || !retryable {
+			return resp, err
+		}
+		retry, backoff := c.retryPolicy.ShouldRetry(attempt, err)
+		if !retry {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+	}
+}
 
+// doRequest sends a single request to the daemon, on behalf of do.
+func (c *Client) doRequest(ctx context.Context, method, u, path string, body []byte, doOptions doOptions) (*http.Response, error) {
+	var params io.Reader
+	if body != nil {
+		params = bytes.NewReader(body)
+	}
 	req, err := http.NewRequest(method, u, params)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", c.getUserAgent())
 	if doOptions.data != nil {
 		req.Header.Set("Content-Type", "application/json")
 	} else if method == "POST" {
@@ -472,13 +986,14 @@ func (c *Client) do(method, path string, doOptions doOptions) (*http.Response, e
 		req.Header.Set(k, v)
 	}
 
-	ctx := doOptions.context
-	if ctx == nil {
-		ctx = context.Background()
+	cancel := func() {}
+	if timeout := c.Timeouts.forPath(path); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 	}
 
-	resp, err := c.HTTPClient.Do(req.WithContext(ctx))
+	resp, err := c.roundTrip(req.WithContext(ctx), c.HTTPClient.Do)
 	if err != nil {
+		cancel()
 		if strings.Contains(err.Error(), "connection refused") {
 			return nil, ErrConnectionRefused
 		}
@@ -486,8 +1001,11 @@ func (c *Client) do(method, path string, doOptions doOptions) (*http.Response, e
 		return nil, chooseError(ctx, err)
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		defer cancel()
 		return nil, newError(resp)
 	}
+	// the timeout also covers reading the body
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
 	return resp, nil
 }
 
@@ -522,6 +1040,9 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 	if (method == "POST" || method == "PUT") && streamOptions.in == nil {
 		streamOptions.in = bytes.NewReader(nil)
 	}
+	if err := c.maybeNegotiateAPIVersion(path); err != nil {
+		return err
+	}
 	if path != "/version" && !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
 		err := c.checkAPIVersion()
 		if err != nil {
@@ -532,7 +1053,7 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 	if err != nil {
 		return err
 	}
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", c.getUserAgent())
 	if method == "POST" {
 		req.Header.Set("Content-Type", "plain/text")
 	}
@@ -554,6 +1075,11 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if timeout := c.Timeouts.forPath(path); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	subCtx, cancelRequest := context.WithCancel(ctx)
 	defer cancelRequest()
 
@@ -568,20 +1094,22 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 			dial.Close()
 		}()
 		breader := bufio.NewReader(dial)
-		err = req.Write(dial)
-		if err != nil {
-			return chooseError(subCtx, err)
-		}
+		resp, err = c.roundTrip(req, func(req *http.Request) (*http.Response, error) {
+			if err := req.Write(dial); err != nil {
+				return nil, err
+			}
 
-		// ReadResponse may hang if server does not replay
-		if streamOptions.timeout > 0 {
-			dial.SetDeadline(time.Now().Add(streamOptions.timeout))
-		}
+			// ReadResponse may hang if server does not replay
+			if streamOptions.timeout > 0 {
+				dial.SetDeadline(time.Now().Add(streamOptions.timeout))
+			}
 
-		if streamOptions.reqSent != nil {
-			close(streamOptions.reqSent)
-		}
-		if resp, err = http.ReadResponse(breader, req); err != nil {
+			if streamOptions.reqSent != nil {
+				close(streamOptions.reqSent)
+			}
+			return http.ReadResponse(breader, req)
+		})
+		if err != nil {
 			// Cancel timeout for future I/O operations
 			if streamOptions.timeout > 0 {
 				dial.SetDeadline(time.Time{})
@@ -593,7 +1121,7 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 			return chooseError(subCtx, err)
 		}
 	} else {
-		if resp, err = c.HTTPClient.Do(req.WithContext(subCtx)); err != nil {
+		if resp, err = c.roundTrip(req.WithContext(subCtx), c.HTTPClient.Do); err != nil {
 			if strings.Contains(err.Error(), "connection refused") {
 				return ErrConnectionRefused
 			}
@@ -607,6 +1135,7 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
 		return newError(resp)
 	}
+	defer c.streamOpened()()
 	var canceled uint32
 	if streamOptions.inactivityTimeout > 0 {
 		var ch chan<- struct{}
@@ -629,7 +1158,7 @@ func handleStreamResponse(resp *http.Response, streamOptions *streamOptions) err
 		if streamOptions.setRawTerminal {
 			_, err = io.Copy(streamOptions.stdout, resp.Body)
 		} else {
-			_, err = stdcopy.StdCopy(streamOptions.stdout, streamOptions.stderr, resp.Body)
+			_, err = StdCopy(streamOptions.stdout, streamOptions.stderr, resp.Body)
 		}
 		return err
 	}
@@ -715,6 +1244,9 @@ type closerFunc func() error
 func (c closerFunc) Close() error { return c() }
 
 func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (CloseWaiter, error) {
+	if err := c.maybeNegotiateAPIVersion(path); err != nil {
+		return nil, err
+	}
 	if path != "/version" && !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
 		err := c.checkAPIVersion()
 		if err != nil {
@@ -733,6 +1265,7 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("User-Agent", c.getUserAgent())
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Connection", "Upgrade")
 	req.Header.Set("Upgrade", "tcp")
@@ -758,14 +1291,26 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 			return nil, err
 		}
 	}
+	if timeout := c.Timeouts.forPath(path); timeout > 0 {
+		dial.SetDeadline(time.Now().Add(timeout))
+	}
 
 	errs := make(chan error, 1)
 	quit := make(chan struct{})
+	streamClosed := c.streamOpened()
 	go func() {
+		defer streamClosed()
 		//lint:ignore SA1019 this is needed here
 		clientconn := httputil.NewClientConn(dial, nil)
 		defer clientconn.Close()
-		clientconn.Do(req)
+		c.hooked(func(req *http.Request) (*http.Response, error) {
+			resp, err := clientconn.Do(req)
+			if err == httputil.ErrPersistEOF {
+				// the connection is about to be hijacked
+				err = nil
+			}
+			return resp, err
+		})(req)
 		if hijackOptions.success != nil {
 			hijackOptions.success <- struct{}{}
 			<-hijackOptions.success
@@ -803,7 +1348,7 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 				if hijackOptions.setRawTerminal {
 					_, err = io.Copy(hijackOptions.stdout, br)
 				} else {
-					_, err = stdcopy.StdCopy(hijackOptions.stdout, hijackOptions.stderr, br)
+					_, err = StdCopy(hijackOptions.stdout, hijackOptions.stderr, br)
 				}
 				errChanOut <- err
 			}()
@@ -849,12 +1394,16 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 }
 
 func (c *Client) getURL(path string) string {
+	return c.getURLWithVersion(path, c.apiVersion())
+}
+
+func (c *Client) getURLWithVersion(path string, version APIVersion) string {
 	urlStr := strings.TrimRight(c.endpointURL.String(), "/")
 	if c.endpointURL.Scheme == unixProtocol || c.endpointURL.Scheme == namedPipeProtocol {
 		urlStr = ""
 	}
-	if c.requestedAPIVersion != nil {
-		return fmt.Sprintf("%s/v%s%s", urlStr, c.requestedAPIVersion, path)
+	if version != nil {
+		return fmt.Sprintf("%s/v%s%s", urlStr, version, path)
 	}
 	return fmt.Sprintf("%s%s", urlStr, path)
 }
@@ -862,6 +1411,10 @@ func (c *Client) getURL(path string) string {
 // getFakeNativeURL returns the URL needed to make an HTTP request over a UNIX
 // domain socket to the given path.
 func (c *Client) getFakeNativeURL(path string) string {
+	return c.getFakeNativeURLWithVersion(path, c.apiVersion())
+}
+
+func (c *Client) getFakeNativeURLWithVersion(path string, version APIVersion) string {
 	u := *c.endpointURL // Copy.
 
 	// Override URL so that net/http will not complain.
@@ -869,8 +1422,8 @@ func (c *Client) getFakeNativeURL(path string) string {
 	u.Host = "unix.sock" // Doesn't matter what this is - it's not used.
 	u.Path = ""
 	urlStr := strings.TrimRight(u.String(), "/")
-	if c.requestedAPIVersion != nil {
-		return fmt.Sprintf("%s/v%s%s", urlStr, c.requestedAPIVersion, path)
+	if version != nil {
+		return fmt.Sprintf("%s/v%s%s", urlStr, version, path)
 	}
 	return fmt.Sprintf("%s%s", urlStr, path)
 }
@@ -948,9 +1501,16 @@ func addQueryStringValue(items url.Values, key string, v reflect.Value) {
 }
 
 // Error represents failures in the API. It represents a failure from the API.
+//
+// Responses with an error status are returned as an *Error, unless mapped to
+// a more specific error, such as NoSuchContainer, so the status code can be
+// checked with a type assertion instead of matching the message. RequestID
+// is set when the daemon, or a proxy in front of it, identifies the request
+// in the X-Request-Id header of the response.
 type Error struct {
-	Status  int
-	Message string
+	Status    int
+	Message   string
+	RequestID string
 }
 
 func newError(resp *http.Response) *Error {
@@ -958,16 +1518,17 @@ func newError(resp *http.Response) *Error {
 		Message string `json:"message"`
 	}
 	defer resp.Body.Close()
+	requestID := resp.Header.Get("X-Request-Id")
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return &Error{Status: resp.StatusCode, Message: fmt.Sprintf("cannot read body, err: %v", err)}
+		return &Error{Status: resp.StatusCode, Message: fmt.Sprintf("cannot read body, err: %v", err), RequestID: requestID}
 	}
 	var emsg ErrMsg
 	err = json.Unmarshal(data, &emsg)
 	if err != nil {
-		return &Error{Status: resp.StatusCode, Message: string(data)}
+		return &Error{Status: resp.StatusCode, Message: string(data), RequestID: requestID}
 	}
-	return &Error{Status: resp.StatusCode, Message: emsg.Message}
+	return &Error{Status: resp.StatusCode, Message: emsg.Message, RequestID: requestID}
 }
 
 func (e *Error) Error() string {
@@ -0,0 +1,268 @@
+// Code generated by testutil/gen.go. DO NOT EDIT.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// DockerClient is the set of methods of Client. Code that depends on
+// DockerClient rather than on *Client can be tested with a fake
+// implementation, such as the MockDockerClient of the testutil package,
+// instead of a Docker daemon. Code that only needs some of the methods can
+// depend on the smaller interfaces embedded in DockerClient instead.
+type DockerClient interface {
+	ContainerAPI
+	ExecAPI
+	ImageAPI
+	NetworkAPI
+	PluginAPI
+	SwarmAPI
+	SystemAPI
+	VolumeAPI
+}
+
+// ContainerAPI is the subset of DockerClient that manages containers.
+type ContainerAPI interface {
+	AttachAndWait(id string, stdout io.Writer, stderr io.Writer, ctx context.Context) (int, error)
+	AttachToContainer(opts AttachToContainerOptions) error
+	AttachToContainerDemux(opts AttachToContainerOptions) (io.ReadCloser, io.ReadCloser, io.WriteCloser, error)
+	AttachToContainerNonBlocking(opts AttachToContainerOptions) (CloseWaiter, error)
+	AttachToContainerStream(opts AttachToContainerOptions) (io.ReadWriteCloser, error)
+	CollectStats(id string, interval time.Duration, ctx context.Context) (*StatsCollector, error)
+	CommitContainer(opts CommitContainerOptions) (*Image, error)
+	ContainerChanges(id string) ([]Change, error)
+	ContainerLogsReader(id string, opts LogsOptions, ctx context.Context) (io.ReadCloser, error)
+	CopyFromContainer(opts CopyFromContainerOptions) error
+	CreateContainer(opts CreateContainerOptions) (*Container, error)
+	DownloadFromContainer(id string, opts DownloadFromContainerOptions) error
+	ExportContainer(opts ExportContainerOptions) error
+	FreezeContainerFS(id string, ctx context.Context) error
+	GetContainerAppArmorProfile(id string, ctx context.Context) (string, error)
+	GetContainerCFSQuota(id string, ctx context.Context) (int64, int64, error)
+	GetContainerEnvVar(id string, key string, ctx context.Context) (string, bool, error)
+	GetContainerLogsStructured(id string, opts LogsOptions) ([]LogEntry, error)
+	GetContainerNetNamespace(id string, ctx context.Context) (uint64, error)
+	GetContainerNetworkStats(id string, interfaceName string, ctx context.Context) (*NetworkStats, error)
+	GetContainerPIDNamespace(id string, ctx context.Context) (uint64, error)
+	GetContainerPortInfo(id string, ctx context.Context) (*PortInfo, error)
+	GetContainerRootFSPath(id string, ctx context.Context) (string, error)
+	GetContainerSeccompProfile(id string, ctx context.Context) (string, error)
+	GetContainerStatsOnce(id string, ctx context.Context) (*Stats, error)
+	GetContainerTiming(id string, ctx context.Context) (*ContainerTiming, error)
+	GetContainerWritableLayerSize(id string, ctx context.Context) (int64, error)
+	InspectContainer(id string) (*Container, error)
+	InspectContainerRaw(id string) (*Container, json.RawMessage, error)
+	InspectContainerStats(id string, ctx context.Context) (*Stats, error)
+	InspectContainerWithContext(id string, ctx context.Context) (*Container, error)
+	KillContainer(opts KillContainerOptions) error
+	ListContainerNetworkInterfaces(id string, ctx context.Context) ([]string, error)
+	ListContainers(opts ListContainersOptions) ([]APIContainers, error)
+	Logs(opts LogsOptions) error
+	MeasureContainerNetworkThroughput(id string, duration time.Duration, ctx context.Context) (*ThroughputMeasurement, error)
+	MultiLogs(specs []LogSpec, out io.Writer, ctx context.Context) error
+	PauseContainer(id string) error
+	PruneContainers(opts PruneContainersOptions) (*PruneContainersResults, error)
+	RemoveContainer(opts RemoveContainerOptions) error
+	RenameContainer(opts RenameContainerOptions) error
+	ResizeContainerTTY(id string, height int, width int) error
+	RestartContainer(id string, timeout uint) error
+	RestartWithEnv(id string, newEnv map[string]string, ctx context.Context) (*Container, error)
+	RunContainer(opts RunOptions) (*RunResult, error)
+	SetContainerAppArmorProfile(id string, profile string, ctx context.Context) (*Container, error)
+	SetContainerTimezone(id string, timezone string, ctx context.Context) (*Container, error)
+	StartContainer(id string, hostConfig *HostConfig) error
+	StartContainerWithContext(id string, hostConfig *HostConfig, ctx context.Context) error
+	StatContainerPath(id string, path string) (ContainerPathStat, error)
+	Stats(opts StatsOptions) error
+	StopContainer(id string, timeout uint) error
+	StopContainerWithContext(id string, timeout uint, ctx context.Context) error
+	ThawContainerFS(id string, ctx context.Context) error
+	TopContainer(id string, psArgs string) (TopResult, error)
+	UnpauseContainer(id string) error
+	UpdateContainer(id string, opts UpdateContainerOptions) error
+	UploadToContainer(id string, opts UploadToContainerOptions) error
+	WaitContainer(id string) (int, error)
+	WaitContainerWithContext(id string, ctx context.Context) (int, error)
+	WaitForLogPattern(id string, pattern *regexp.Regexp, timeout time.Duration, ctx context.Context) (string, error)
+}
+
+// ExecAPI is the subset of DockerClient that runs commands in containers.
+type ExecAPI interface {
+	CreateExec(opts CreateExecOptions) (*Exec, error)
+	ExecTimeout(containerID string, timeout time.Duration, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, ctx context.Context) (int, error)
+	InspectExec(id string) (*ExecInspect, error)
+	ResizeExecTTY(id string, height int, width int) error
+	StartExec(id string, opts StartExecOptions) error
+	StartExecNonBlocking(id string, opts StartExecOptions) (CloseWaiter, error)
+}
+
+// ImageAPI is the subset of DockerClient that manages images.
+type ImageAPI interface {
+	BuildImage(opts BuildImageOptions) error
+	ExportImage(opts ExportImageOptions) error
+	ExportImages(opts ExportImagesOptions) error
+	GetImageBaseImage(nameOrID string, ctx context.Context) (string, error)
+	GetImageLayerCount(nameOrID string, ctx context.Context) (int, error)
+	ImageExists(name string) (bool, error)
+	ImageHistory(name string) ([]ImageHistory, error)
+	ImportImage(opts ImportImageOptions) error
+	ImportImageFromReader(r io.Reader, repo string, tag string, w io.Writer, ctx context.Context) (string, error)
+	ImportImageFromTar(tarPath string, repo string, tag string, config *Config, ctx context.Context) (string, error)
+	ImportImageFromURL(url string, repo string, tag string, w io.Writer, ctx context.Context) (string, error)
+	InspectDistribution(name string) (*registry.DistributionInspect, error)
+	InspectDistributionWithAuth(name string, auth AuthConfiguration) (*registry.DistributionInspect, error)
+	InspectImage(name string) (*Image, error)
+	InspectImageRaw(name string) (*Image, json.RawMessage, error)
+	ListImages(opts ListImagesOptions) ([]APIImages, error)
+	LoadImage(opts LoadImageOptions) error
+	PruneImages(opts PruneImagesOptions) (*PruneImagesResults, error)
+	PullImage(opts PullImageOptions, auth AuthConfiguration) error
+	PushImage(opts PushImageOptions, auth AuthConfiguration) error
+	RemoveImage(name string) error
+	RemoveImageExtended(name string, opts RemoveImageOptions) error
+	SearchImages(term string) ([]APIImageSearch, error)
+	SearchImagesEx(term string, auth AuthConfiguration) ([]APIImageSearch, error)
+	TagImage(name string, opts TagImageOptions) error
+}
+
+// NetworkAPI is the subset of DockerClient that manages networks.
+type NetworkAPI interface {
+	ConnectNetwork(id string, opts NetworkConnectionOptions) error
+	CreateNetwork(opts CreateNetworkOptions) (*Network, error)
+	DisconnectNetwork(id string, opts NetworkConnectionOptions) error
+	FilteredListNetworks(opts NetworkFilterOpts) ([]Network, error)
+	ListNetworks() ([]Network, error)
+	NetworkByName(name string, ctx context.Context) (*Network, error)
+	NetworkIDByName(name string, ctx context.Context) (string, error)
+	NetworkInfo(id string) (*Network, error)
+	NetworkInfoRaw(id string) (*Network, json.RawMessage, error)
+	PruneNetworks(opts PruneNetworksOptions) (*PruneNetworksResults, error)
+	RemoveNetwork(id string) error
+}
+
+// PluginAPI is the subset of DockerClient that manages plugins.
+type PluginAPI interface {
+	ConfigurePlugin(opts ConfigurePluginOptions) error
+	CreatePlugin(opts CreatePluginOptions) (string, error)
+	DisablePlugin(opts DisablePluginOptions) error
+	EnablePlugin(opts EnablePluginOptions) error
+	GetPluginPrivileges(name string, ctx context.Context) ([]PluginPrivilege, error)
+	InspectPlugins(name string, ctx context.Context) (*PluginDetail, error)
+	InstallPlugin(opts InstallPluginOptions) (string, error)
+	InstallPlugins(opts InstallPluginOptions) error
+	ListFilteredPlugins(opts ListFilteredPluginsOptions) ([]PluginDetail, error)
+	ListPlugins(ctx context.Context) ([]PluginDetail, error)
+	PushPlugin(opts PushPluginOptions) error
+	RemovePlugin(opts RemovePluginOptions) (*PluginDetail, error)
+	UpgradePlugin(opts UpgradePluginOptions) error
+}
+
+// SwarmAPI is the subset of DockerClient that manages a swarm and its nodes, services, tasks, secrets and configs.
+type SwarmAPI interface {
+	ActivateNode(id string) error
+	CreateConfig(opts CreateConfigOptions) (*swarm.Config, error)
+	CreateSecret(opts CreateSecretOptions) (*swarm.Secret, error)
+	CreateService(opts CreateServiceOptions) (*swarm.Service, error)
+	DemoteNode(id string) error
+	DrainNode(id string) error
+	ForceRedeployService(serviceID string, ctx context.Context) error
+	GetContainerNodeID(containerID string, ctx context.Context) (string, error)
+	GetContainerServiceID(containerID string, ctx context.Context) (string, error)
+	GetContainerTaskID(containerID string, ctx context.Context) (string, error)
+	GetServiceLogs(opts LogsServiceOptions) error
+	InitSwarm(opts InitSwarmOptions) (string, error)
+	InitSwarmWithTokens(opts InitSwarmOptions) (*InitSwarmResult, error)
+	InspectConfig(id string) (*swarm.Config, error)
+	InspectNode(id string) (*swarm.Node, error)
+	InspectSecret(id string) (*swarm.Secret, error)
+	InspectService(id string) (*swarm.Service, error)
+	InspectSwarm(ctx context.Context) (swarm.Swarm, error)
+	InspectTask(id string) (*swarm.Task, error)
+	JoinSwarm(opts JoinSwarmOptions) error
+	LeaveSwarm(opts LeaveSwarmOptions) error
+	ListConfigs(opts ListConfigsOptions) ([]swarm.Config, error)
+	ListNodes(opts ListNodesOptions) ([]swarm.Node, error)
+	ListSecrets(opts ListSecretsOptions) ([]swarm.Secret, error)
+	ListServices(opts ListServicesOptions) ([]swarm.Service, error)
+	ListTasks(opts ListTasksOptions) ([]swarm.Task, error)
+	ModifyNode(id string, opts ModifyNodeOptions) error
+	ModifyService(id string, opts ModifyServiceOptions) error
+	PauseNode(id string) error
+	PopulateEnvFromSecrets(containerID string, secretNames []string, ctx context.Context) ([]string, error)
+	PromoteNode(id string) error
+	RemoveConfig(opts RemoveConfigOptions) error
+	RemoveNode(opts RemoveNodeOptions) error
+	RemoveSecret(opts RemoveSecretOptions) error
+	RemoveService(opts RemoveServiceOptions) error
+	RollbackService(id string) error
+	RotateSecret(opts RotateSecretOptions) (*swarm.Secret, error)
+	RotateSwarmTokens(opts RotateSwarmTokensOptions) (swarm.JoinTokens, error)
+	ScaleService(id string, replicas uint64) error
+	ServiceLogs(opts ServiceLogsOptions) (<-chan ServiceLogEntry, <-chan error)
+	UpdateConfig(id string, opts UpdateConfigOptions) error
+	UpdateNode(id string, opts UpdateNodeOptions) error
+	UpdateSecret(id string, opts UpdateSecretOptions) error
+	UpdateService(id string, opts UpdateServiceOptions) error
+	UpdateServiceImage(id string, image string) error
+	UpdateSwarm(opts UpdateSwarmOptions) error
+	WaitServiceConverged(serviceID string, timeout time.Duration) error
+}
+
+// SystemAPI is the subset of DockerClient that queries the daemon, authenticates with registries and configures the client.
+type SystemAPI interface {
+	AddEventListener(listener chan<- *APIEvents) error
+	ApplyOptions(opts ...ClientOption) error
+	AuthCheck(conf *AuthConfiguration) (AuthStatus, error)
+	ContainersDiskUsage(opts DiskUsageOptions) (map[string]int64, error)
+	DiskUsage(opts DiskUsageOptions) (*DiskUsage, error)
+	Endpoint() string
+	GetCgroupVersion(ctx context.Context) (int, error)
+	GetDaemonMetrics(ctx context.Context) (map[string]float64, error)
+	GetRegistryMirrors(ctx context.Context) ([]string, error)
+	GetStorageDriverInfo(ctx context.Context) (*StorageDriverInfo, error)
+	Info() (*DockerInfo, error)
+	InfoWithContext(ctx context.Context) (*DockerInfo, error)
+	ListenEvents(opts EventsOptions, ctx context.Context) (<-chan APIEvents, <-chan error)
+	NegotiateAPIVersion() error
+	NegotiatedAPIVersion() string
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	PingWithResponse(ctx context.Context) (*PingResponse, error)
+	RegistryAuth(serverAddress string) (AuthConfiguration, bool)
+	RegistryLogin(auth AuthConfiguration) (AuthStatus, error)
+	RegistryMirrorsMatch(mirrors []string, ctx context.Context) (bool, error)
+	RemoveEventListener(listener chan *APIEvents) error
+	ReplayEvents(since time.Time, opts EventsOptions, ctx context.Context) ([]APIEvents, error)
+	ServerVersion() (*DockerVersion, error)
+	ServerVersionWithContext(ctx context.Context) (*DockerVersion, error)
+	SetRegistryMirrors(mirrors []string, ctx context.Context) error
+	SetTimeout(t time.Duration)
+	SetUserAgent(ua string)
+	Version() (*Env, error)
+	VersionWithContext(ctx context.Context) (*Env, error)
+	WatchSpotTermination(gracePeriod time.Duration, fn func(remainingTime time.Duration), ctx context.Context) error
+	WithDefaultTimeout(d time.Duration) *Client
+	WithTransport(trFunc func() *http.Transport)
+}
+
+// VolumeAPI is the subset of DockerClient that manages volumes.
+type VolumeAPI interface {
+	CreateVolume(opts CreateVolumeOptions) (*Volume, error)
+	InspectVolume(name string) (*Volume, error)
+	ListContainersByVolume(volumeName string, ctx context.Context) ([]APIContainers, error)
+	ListVolumes(opts ListVolumesOptions) ([]Volume, error)
+	PruneVolumes(opts PruneVolumesOptions) (*PruneVolumesResults, error)
+	RemoveVolume(name string) error
+	RemoveVolumeWithOptions(opts RemoveVolumeOptions) error
+}
+
+var _ DockerClient = (*Client)(nil)
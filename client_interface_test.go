@@ -0,0 +1,41 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDockerClientHasAllMethods(t *testing.T) {
+	t.Parallel()
+	iface := reflect.TypeOf((*DockerClient)(nil)).Elem()
+	client := reflect.TypeOf(&Client{})
+	for i := 0; i < client.NumMethod(); i++ {
+		name := client.Method(i).Name
+		if _, ok := iface.MethodByName(name); !ok {
+			t.Errorf("DockerClient: missing method %s, run go generate in the testutil directory", name)
+		}
+	}
+}
+
+func TestDockerClientSubInterfaces(t *testing.T) {
+	t.Parallel()
+	subInterfaces := []interface{}{
+		(*ContainerAPI)(nil), (*ExecAPI)(nil), (*ImageAPI)(nil), (*NetworkAPI)(nil),
+		(*PluginAPI)(nil), (*SwarmAPI)(nil), (*SystemAPI)(nil), (*VolumeAPI)(nil),
+	}
+	var total int
+	for _, iface := range subInterfaces {
+		typ := reflect.TypeOf(iface).Elem()
+		if typ.NumMethod() == 0 {
+			t.Errorf("%s: expected at least one method", typ.Name())
+		}
+		total += typ.NumMethod()
+	}
+	if n := reflect.TypeOf((*DockerClient)(nil)).Elem().NumMethod(); total != n {
+		t.Errorf("DockerClient: wrong number of methods in the sub-interfaces. Want %d. Got %d.", n, total)
+	}
+}
@@ -7,6 +7,8 @@ package docker
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -18,6 +20,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -76,6 +79,316 @@ func TestNewTSLAPIClient(t *testing.T) {
 	}
 }
 
+func TestNewTLSClientFromCertPath(t *testing.T) {
+	t.Parallel()
+	endpoint := "https://localhost:4243"
+	client, err := NewTLSClientFromCertPath(endpoint, "testing/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.endpoint != endpoint {
+		t.Errorf("Expected endpoint %s. Got %s.", endpoint, client.endpoint)
+	}
+	if len(client.TLSConfig.Certificates) != 1 {
+		t.Errorf("Expected 1 client certificate. Got %d.", len(client.TLSConfig.Certificates))
+	}
+	if client.TLSConfig.RootCAs == nil || client.TLSConfig.InsecureSkipVerify {
+		t.Error("Expected the server certificate to be verified against the CA")
+	}
+}
+
+func TestNewTLSClientFromCertPathWithoutCerts(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "go-dockerclient-tls-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	client, err := NewTLSClientFromCertPath("https://localhost:4243", tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.TLSConfig.Certificates) != 0 {
+		t.Errorf("Expected no client certificates. Got %d.", len(client.TLSConfig.Certificates))
+	}
+	if !client.TLSConfig.InsecureSkipVerify {
+		t.Error("Expected InsecureSkipVerify to be true when there's no CA")
+	}
+}
+
+func TestWithTLSFromBytes(t *testing.T) {
+	t.Parallel()
+	cert, _ := ioutil.ReadFile("testing/data/cert.pem")
+	key, _ := ioutil.ReadFile("testing/data/key.pem")
+	ca, _ := ioutil.ReadFile("testing/data/ca.pem")
+	client, err := NewClient("tcp://localhost:2376")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.ApplyOptions(WithTLSFromBytes(cert, key, ca)); err != nil {
+		t.Fatal(err)
+	}
+	if client.endpointURL.Scheme != "https" {
+		t.Errorf("WithTLSFromBytes: wrong scheme. Want %q. Got %q.", "https", client.endpointURL.Scheme)
+	}
+	if len(client.TLSConfig.Certificates) != 1 {
+		t.Errorf("Expected 1 client certificate. Got %d.", len(client.TLSConfig.Certificates))
+	}
+	if client.TLSConfig.RootCAs == nil {
+		t.Error("Expected RootCAs to be set")
+	}
+	tr, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig != client.TLSConfig {
+		t.Error("Expected the transport to use the TLS configuration of the client")
+	}
+}
+
+func TestWithTLSFromBytesInvalidKeyPair(t *testing.T) {
+	t.Parallel()
+	cert, _ := ioutil.ReadFile("testing/data/cert.pem")
+	key, _ := ioutil.ReadFile("testing/data/key.pem")
+	serverKey, _ := ioutil.ReadFile("testing/data/serverkey.pem")
+	var tests = []struct {
+		name string
+		cert []byte
+		key  []byte
+	}{
+		{"mismatched key", cert, serverKey},
+		{"missing key", cert, nil},
+		{"missing certificate", nil, key},
+	}
+	for _, tt := range tests {
+		client, err := NewClient("https://localhost:2376")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = client.ApplyOptions(WithTLSFromBytes(tt.cert, tt.key, nil)); err == nil {
+			t.Errorf("WithTLSFromBytes (%s): expected error, got <nil>", tt.name)
+		}
+		if client.TLSConfig != nil {
+			t.Errorf("WithTLSFromBytes (%s): expected the client to be left unchanged", tt.name)
+		}
+	}
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	t.Parallel()
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	auth := AuthConfiguration{Username: "gopher", Password: "secret", ServerAddress: "registry.example.com"}
+	client, err := NewClientWithOptions(
+		WithEndpoint(server.URL),
+		WithAPIVersion("1.25"),
+		WithUserAgent("my-app/1.0"),
+		WithAuth(auth),
+		WithTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.endpoint != server.URL {
+		t.Errorf("NewClientWithOptions: wrong endpoint. Want %q. Got %q.", server.URL, client.endpoint)
+	}
+	if client.HTTPClient.Timeout != time.Minute {
+		t.Errorf("NewClientWithOptions: wrong timeout. Want %s. Got %s.", time.Minute, client.HTTPClient.Timeout)
+	}
+	if stored, ok := client.RegistryAuth("registry.example.com"); !ok || stored != auth {
+		t.Errorf("NewClientWithOptions: wrong credentials. Want %#v. Got %#v.", auth, stored)
+	}
+	client.SkipServerVersionCheck = true
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	req := requests[len(requests)-1]
+	if req.URL.Path != "/v1.25/_ping" {
+		t.Errorf("NewClientWithOptions: wrong path. Want %q. Got %q.", "/v1.25/_ping", req.URL.Path)
+	}
+	if ua := req.Header.Get("User-Agent"); ua != "my-app/1.0" {
+		t.Errorf("NewClientWithOptions: wrong User-Agent. Want %q. Got %q.", "my-app/1.0", ua)
+	}
+}
+
+func TestNewClientWithOptionsDefaults(t *testing.T) {
+	t.Parallel()
+	client, err := NewClientWithOptions(WithEndpoint("http://localhost:4243"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.SkipServerVersionCheck {
+		t.Error("Expected SkipServerVersionCheck to be true, got false")
+	}
+	if client.requestedAPIVersion != nil {
+		t.Errorf("NewClientWithOptions: wrong requestedAPIVersion. Want <nil>. Got %q.", client.requestedAPIVersion)
+	}
+	if ua := client.getUserAgent(); ua != "go-dockerclient/dev" {
+		t.Errorf("NewClientWithOptions: wrong User-Agent. Want %q. Got %q.", "go-dockerclient/dev", ua)
+	}
+}
+
+func TestNewClientWithOptionsErrors(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name string
+		opts []ClientOption
+	}{
+		{"no endpoint", nil},
+		{"invalid endpoint", []ClientOption{WithEndpoint("htp://localhost:-1")}},
+		{"invalid API version", []ClientOption{WithEndpoint("http://localhost:4243"), WithAPIVersion("latest")}},
+	}
+	for _, tt := range tests {
+		if _, err := NewClientWithOptions(tt.opts...); err == nil {
+			t.Errorf("NewClientWithOptions (%s): expected error, got <nil>", tt.name)
+		}
+	}
+}
+
+func TestNewClientWithOptionsTLS(t *testing.T) {
+	t.Parallel()
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	httpClient := &http.Client{}
+	var tests = []struct {
+		name string
+		opts []ClientOption
+	}{
+		{"TLS before endpoint", []ClientOption{WithTLSConfig(tlsConfig), WithEndpoint("tcp://localhost:2376")}},
+		{"TLS after endpoint", []ClientOption{WithEndpoint("tcp://localhost:2376"), WithTLSConfig(tlsConfig)}},
+	}
+	for _, tt := range tests {
+		client, err := NewClientWithOptions(tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.endpointURL.Scheme != "https" {
+			t.Errorf("NewClientWithOptions (%s): wrong scheme. Want %q. Got %q.", tt.name, "https", client.endpointURL.Scheme)
+		}
+		if tr := client.HTTPClient.Transport.(*http.Transport); tr.TLSClientConfig != tlsConfig {
+			t.Errorf("NewClientWithOptions (%s): expected the transport to use the TLS configuration", tt.name)
+		}
+	}
+	client, err := NewClientWithOptions(WithEndpoint("http://localhost:4243"), WithHTTPClient(httpClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.HTTPClient != httpClient {
+		t.Error("NewClientWithOptions: expected the given HTTP client to be used")
+	}
+}
+
+func TestWithTLSServerName(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	tlsConfig := &tls.Config{RootCAs: roots}
+	// the certificate of the server is issued for example.com and 127.0.0.1,
+	// so requests to localhost fail verification
+	endpoint := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	client, err := NewClientWithOptions(WithTLSConfig(tlsConfig), WithEndpoint(endpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Ping(); err == nil {
+		t.Fatal("Ping: expected a certificate verification error, got <nil>")
+	}
+	if err = client.ApplyOptions(WithTLSServerName("example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if client.TLSConfig.ServerName != "example.com" {
+		t.Errorf("WithTLSServerName: wrong ServerName. Want %q. Got %q.", "example.com", client.TLSConfig.ServerName)
+	}
+	if tlsConfig.ServerName != "" {
+		t.Errorf("WithTLSServerName: the given TLS configuration was modified: %#v", tlsConfig)
+	}
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	tlsConfig := &tls.Config{}
+	client, err := NewClientWithOptions(WithEndpoint(server.URL), WithTLSConfig(tlsConfig), WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if !client.TLSConfig.InsecureSkipVerify {
+		t.Error("WithInsecureSkipVerify: expected InsecureSkipVerify to be true")
+	}
+	if tlsConfig.InsecureSkipVerify {
+		t.Error("WithInsecureSkipVerify: the given TLS configuration was modified")
+	}
+}
+
+func TestTLSOptionsWithoutTLS(t *testing.T) {
+	t.Parallel()
+	for _, opt := range []ClientOption{WithTLSServerName("example.com"), WithInsecureSkipVerify()} {
+		_, err := NewClientWithOptions(WithEndpoint("http://localhost:4243"), opt)
+		if err != ErrTLSNotConfigured {
+			t.Errorf("NewClientWithOptions: wrong error. Want %#v. Got %#v.", ErrTLSNotConfigured, err)
+		}
+	}
+}
+
+func TestSetUserAgent(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		mu.Unlock()
+		if !strings.HasSuffix(r.URL.Path, "/attach") {
+			w.Write([]byte("OK"))
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/vnd.docker.raw-stream\r\n\r\n"))
+		conn.Close()
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	client.SetUserAgent("my-app/1.0")
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	err = client.AttachToContainer(AttachToContainerOptions{Container: "c1", OutputStream: ioutil.Discard, Stdout: true, RawTerminal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetUserAgent("")
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"go-dockerclient/dev", "my-app/1.0", "my-app/1.0", "go-dockerclient/dev"}
+	if !reflect.DeepEqual(userAgents, expected) {
+		t.Errorf("SetUserAgent: wrong User-Agent headers. Want %#v. Got %#v.", expected, userAgents)
+	}
+}
+
 func TestNewVersionedClient(t *testing.T) {
 	t.Parallel()
 	endpoint := "http://localhost:4243"
@@ -360,6 +673,29 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestErrorRequestID(t *testing.T) {
+	t.Parallel()
+	resp := &http.Response{
+		StatusCode: 500,
+		Header:     http.Header{"X-Request-Id": {"req-42"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"boom"}`)),
+	}
+	err := newError(resp)
+	expected := Error{Status: 500, Message: "boom", RequestID: "req-42"}
+	if !reflect.DeepEqual(expected, *err) {
+		t.Errorf("Wrong error type. Want %#v. Got %#v.", expected, *err)
+	}
+}
+
+func TestNoSuchContainerUnwrap(t *testing.T) {
+	t.Parallel()
+	cause := &Error{Status: 404, Message: "not found"}
+	err := &NoSuchContainer{ID: "abc", Err: cause}
+	if err.Unwrap() != cause {
+		t.Errorf("Unwrap: wrong error. Want %#v. Got %#v.", cause, err.Unwrap())
+	}
+}
+
 func TestQueryString(t *testing.T) {
 	t.Parallel()
 	v := float32(2.4)
@@ -458,6 +794,112 @@ func TestPing(t *testing.T) {
 	}
 }
 
+func TestPingWithResponse(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.40")
+		w.Header().Set("OSType", "linux")
+		w.Header().Set("Docker-Experimental", "true")
+		w.Header().Set("Builder-Version", "2")
+		w.Header().Set("Swarm", "active/manager")
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.PingWithResponse(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PingResponse{
+		APIVersion:     "1.40",
+		OSType:         "linux",
+		Experimental:   true,
+		BuilderVersion: "2",
+		SwarmStatus:    "active/manager",
+	}
+	if *resp != expected {
+		t.Errorf("PingWithResponse: wrong response. Want %#v. Got %#v.", expected, *resp)
+	}
+}
+
+func TestPingWithResponseFailing(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "", status: http.StatusInternalServerError})
+	resp, err := client.PingWithResponse(context.Background())
+	if resp != nil {
+		t.Errorf("PingWithResponse: unexpected response %#v.", resp)
+	}
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusInternalServerError {
+		t.Errorf("PingWithResponse: wrong error. Want API error (500). Got %#v.", err)
+	}
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		requested string
+		server    string
+		expected  string
+	}{
+		{"", "1.24", "1.24"},
+		{"", "1.40", "1.39"},
+		{"1.30", "1.35", "1.30"},
+		{"1.35", "1.30", "1.30"},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.requested+"-"+test.server, func(t *testing.T) {
+			t.Parallel()
+			fakeRT := &FakeRoundTripper{message: `{"ApiVersion":"` + test.server + `"}`, status: http.StatusOK}
+			client := newTestClient(fakeRT)
+			if test.requested != "" {
+				client.requestedAPIVersion, _ = NewAPIVersion(test.requested)
+			}
+			if err := client.NegotiateAPIVersion(); err != nil {
+				t.Fatal(err)
+			}
+			if got := client.NegotiatedAPIVersion(); got != test.expected {
+				t.Errorf("NegotiateAPIVersion: wrong version. Want %q. Got %q.", test.expected, got)
+			}
+			if path := fakeRT.requests[0].URL.Path; path != "/version" {
+				t.Errorf("NegotiateAPIVersion: wrong request path. Want %q. Got %q.", "/version", path)
+			}
+			if err := client.Ping(); err != nil {
+				t.Fatal(err)
+			}
+			if path, expected := fakeRT.requests[1].URL.Path, "/v"+test.expected+"/_ping"; path != expected {
+				t.Errorf("Ping: wrong request path. Want %q. Got %q.", expected, path)
+			}
+		})
+	}
+}
+
+func TestAutoNegotiateVersion(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ApiVersion":"1.25"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	client.AutoNegotiateVersion = true
+	if got := client.NegotiatedAPIVersion(); got != "" {
+		t.Errorf("NegotiatedAPIVersion: expected empty version before negotiation. Got %q.", got)
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Ping(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var paths []string
+	for _, req := range fakeRT.requests {
+		paths = append(paths, req.URL.Path)
+	}
+	expected := []string{"/version", "/v1.25/_ping", "/v1.25/_ping"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("AutoNegotiateVersion: wrong requests. Want %#v. Got %#v.", expected, paths)
+	}
+}
+
 func TestPingFailing(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusInternalServerError}
@@ -572,6 +1014,143 @@ func TestClientStreamInactivityTimeout(t *testing.T) {
 	}
 }
 
+func TestTimeoutsForPath(t *testing.T) {
+	t.Parallel()
+	timeouts := Timeouts{
+		ShortOperationTimeout:     time.Second,
+		LongOperationTimeout:      time.Minute,
+		StreamingOperationTimeout: time.Hour,
+	}
+	var tests = []struct {
+		path     string
+		expected time.Duration
+	}{
+		{"/containers/json?all=1", time.Second},
+		{"/containers/abc/json", time.Second},
+		{"/containers/abc/start", time.Second},
+		{"/exec/abc/json", time.Second},
+		{"/images/create?fromImage=busybox", time.Minute},
+		{"/images/quay.io/org/app/push?tag=latest", time.Minute},
+		{"/images/busybox/get", time.Minute},
+		{"/build?t=app", time.Minute},
+		{"/commit?container=abc", time.Minute},
+		{"/containers/abc/export", time.Minute},
+		{"/containers/abc/archive?path=/etc", time.Minute},
+		{"/containers/abc/logs?follow=1", time.Hour},
+		{"/containers/abc/attach?stream=1", time.Hour},
+		{"/containers/abc/wait", time.Hour},
+		{"/exec/abc/start", time.Hour},
+		{"/events?since=1", time.Hour},
+	}
+	for _, test := range tests {
+		if got := timeouts.forPath(test.path); got != test.expected {
+			t.Errorf("forPath(%q): wrong timeout. Want %s. Got %s.", test.path, test.expected, got)
+		}
+	}
+}
+
+func TestClientDoOperationTimeout(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/containers/json" {
+			time.Sleep(300 * time.Millisecond)
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	client.Timeouts = Timeouts{ShortOperationTimeout: 50 * time.Millisecond}
+	_, err = client.ListContainers(ListContainersOptions{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("ListContainers: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+	if _, err = client.ListImages(ListImagesOptions{}); err != nil {
+		t.Errorf("ListImages: unexpected error: %s", err)
+	}
+}
+
+func TestClientWithDefaultTimeout(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/logs") {
+			w.Write([]byte("done\n"))
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	clone := client.WithDefaultTimeout(50 * time.Millisecond)
+	if _, err = clone.ListContainers(ListContainersOptions{}); err != context.DeadlineExceeded {
+		t.Errorf("ListContainers: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+	var buf bytes.Buffer
+	err = clone.Logs(LogsOptions{Container: "abc123", OutputStream: &buf, Stdout: true, RawTerminal: true})
+	if err != nil {
+		t.Errorf("Logs: unexpected error: %s", err)
+	}
+	if buf.String() != "done\n" {
+		t.Errorf("Logs: wrong output. Want %q. Got %q.", "done\n", buf.String())
+	}
+	if _, err = client.ListContainers(ListContainersOptions{}); err != nil {
+		t.Errorf("ListContainers: the original client should not time out. Got %s.", err)
+	}
+	if client.Timeouts.ShortOperationTimeout != 0 {
+		t.Errorf("WithDefaultTimeout: the original client was changed: %#v", client.Timeouts)
+	}
+}
+
+func TestWithTimeouts(t *testing.T) {
+	t.Parallel()
+	timeouts := Timeouts{ShortOperationTimeout: time.Second, StreamingOperationTimeout: time.Hour}
+	client, err := NewClientWithOptions(WithEndpoint("http://localhost:4243"), WithTimeouts(timeouts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeouts != timeouts {
+		t.Errorf("WithTimeouts: wrong timeouts. Want %#v. Got %#v.", timeouts, client.Timeouts)
+	}
+}
+
+func TestClientStreamOperationTimeout(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(w, "%d\n", i)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Timeouts = Timeouts{LongOperationTimeout: 300 * time.Millisecond}
+	var w bytes.Buffer
+	err = client.stream("POST", "/images/create", streamOptions{
+		setRawTerminal: true,
+		stdout:         &w,
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded error, got: %v", err)
+	}
+	if expected := "0\n1\n"; w.String() != expected {
+		t.Fatalf("expected stream result %q, got: %q", expected, w.String())
+	}
+}
+
 func TestClientStreamContextDeadline(t *testing.T) {
 	t.Parallel()
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -875,12 +1454,15 @@ type FakeRoundTripper struct {
 	message  string
 	status   int
 	header   map[string]string
+	mu       sync.Mutex
 	requests []*http.Request
 }
 
 func (rt *FakeRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	body := strings.NewReader(rt.message)
+	rt.mu.Lock()
 	rt.requests = append(rt.requests, r)
+	rt.mu.Unlock()
 	res := &http.Response{
 		StatusCode: rt.status,
 		Body:       ioutil.NopCloser(body),
@@ -5,24 +5,80 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/go-units"
 )
 
-// ErrContainerAlreadyExists is the error returned by CreateContainer when the
-// container already exists.
+// ErrContainerAlreadyExists is the error returned by CreateContainer and
+// RenameContainer when a container with the given name already exists.
 var ErrContainerAlreadyExists = errors.New("container already exists")
 
+// ErrInvalidContainerName is the error returned by RenameContainer when the
+// new name isn't a valid container name.
+var ErrInvalidContainerName = errors.New("invalid container name")
+
+// containerNameRegexp matches the container names accepted by the Docker
+// daemon.
+var containerNameRegexp = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// ErrInterfaceNotFound is the error returned by GetContainerNetworkStats when
+// the container doesn't have the requested network interface.
+var ErrInterfaceNotFound = errors.New("network interface not found")
+
+// ErrInvalidTimezone is the error returned by SetContainerTimezone when the
+// timezone is not in the IANA time zone database.
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// ErrRootFSPathUnavailable is the error returned by GetContainerRootFSPath
+// when the path of the container's root filesystem can't be determined, either
+// because the client is connected to a remote daemon or because the storage
+// driver doesn't expose it.
+var ErrRootFSPathUnavailable = errors.New("container root filesystem path is unavailable")
+
+// ErrNamespaceNotFound is the error returned by GetContainerPIDNamespace and
+// GetContainerNetNamespace when the namespace can't be found in the /proc
+// filesystem of the container.
+var ErrNamespaceNotFound = errors.New("namespace not found in the container")
+
+// ErrFSFreezeUnsupported is the error returned by FreezeContainerFS and
+// ThawContainerFS when the fsfreeze command isn't available in the
+// container.
+var ErrFSFreezeUnsupported = errors.New("fsfreeze is not available in the container")
+
+// ErrContainerPathNotFound is the error returned by StatContainerPath when
+// the path doesn't exist in the container.
+var ErrContainerPathNotFound = errors.New("path not found in the container")
+
+// ErrPatternNotFound is the error returned by WaitForLogPattern when the logs
+// of the container end, usually because it exited, before a line matching
+// the pattern is found.
+var ErrPatternNotFound = errors.New("pattern not found in the container logs")
+
+// ErrStdinNotAttached is the error returned when writing to the stream
+// returned by AttachToContainerStream when opts.Stdin isn't set.
+var ErrStdinNotAttached = errors.New("stdin of the container is not attached")
+
+// errStopLogs is used to stop reading the logs of a container.
+var errStopLogs = errors.New("stop reading logs")
+
 // ListContainersOptions specify parameters to the ListContainers function.
 //
 // See https://goo.gl/kaOHGw for more details.
@@ -129,6 +185,23 @@ type Health struct {
 	Log           []HealthCheck `json:"Log,omitempty" yaml:"Log,omitempty" toml:"Log,omitempty"`
 }
 
+// HealthState is the name used by the Docker API for the health of a
+// container, reported in State.Health.
+type HealthState = Health
+
+// HealthCheckResult is the name used by the Docker API for the result of
+// one health check, found in HealthState.Log.
+type HealthCheckResult = HealthCheck
+
+// Health statuses of a container, reported in State.Health.Status and in
+// the health_status events.
+const (
+	NoHealthcheck  = "none"
+	HealthStarting = "starting"
+	Healthy        = "healthy"
+	Unhealthy      = "unhealthy"
+)
+
 // State represents the state of a container.
 type State struct {
 	Status            string    `json:"Status,omitempty" yaml:"Status,omitempty" toml:"Status,omitempty"`
@@ -214,16 +287,17 @@ type PortMapping map[string]string
 
 // ContainerNetwork represents the networking settings of a container per network.
 type ContainerNetwork struct {
-	Aliases             []string `json:"Aliases,omitempty" yaml:"Aliases,omitempty" toml:"Aliases,omitempty"`
-	MacAddress          string   `json:"MacAddress,omitempty" yaml:"MacAddress,omitempty" toml:"MacAddress,omitempty"`
-	GlobalIPv6PrefixLen int      `json:"GlobalIPv6PrefixLen,omitempty" yaml:"GlobalIPv6PrefixLen,omitempty" toml:"GlobalIPv6PrefixLen,omitempty"`
-	GlobalIPv6Address   string   `json:"GlobalIPv6Address,omitempty" yaml:"GlobalIPv6Address,omitempty" toml:"GlobalIPv6Address,omitempty"`
-	IPv6Gateway         string   `json:"IPv6Gateway,omitempty" yaml:"IPv6Gateway,omitempty" toml:"IPv6Gateway,omitempty"`
-	IPPrefixLen         int      `json:"IPPrefixLen,omitempty" yaml:"IPPrefixLen,omitempty" toml:"IPPrefixLen,omitempty"`
-	IPAddress           string   `json:"IPAddress,omitempty" yaml:"IPAddress,omitempty" toml:"IPAddress,omitempty"`
-	Gateway             string   `json:"Gateway,omitempty" yaml:"Gateway,omitempty" toml:"Gateway,omitempty"`
-	EndpointID          string   `json:"EndpointID,omitempty" yaml:"EndpointID,omitempty" toml:"EndpointID,omitempty"`
-	NetworkID           string   `json:"NetworkID,omitempty" yaml:"NetworkID,omitempty" toml:"NetworkID,omitempty"`
+	IPAMConfig          *EndpointIPAMConfig `json:"IPAMConfig,omitempty" yaml:"IPAMConfig,omitempty" toml:"IPAMConfig,omitempty"`
+	Aliases             []string            `json:"Aliases,omitempty" yaml:"Aliases,omitempty" toml:"Aliases,omitempty"`
+	MacAddress          string              `json:"MacAddress,omitempty" yaml:"MacAddress,omitempty" toml:"MacAddress,omitempty"`
+	GlobalIPv6PrefixLen int                 `json:"GlobalIPv6PrefixLen,omitempty" yaml:"GlobalIPv6PrefixLen,omitempty" toml:"GlobalIPv6PrefixLen,omitempty"`
+	GlobalIPv6Address   string              `json:"GlobalIPv6Address,omitempty" yaml:"GlobalIPv6Address,omitempty" toml:"GlobalIPv6Address,omitempty"`
+	IPv6Gateway         string              `json:"IPv6Gateway,omitempty" yaml:"IPv6Gateway,omitempty" toml:"IPv6Gateway,omitempty"`
+	IPPrefixLen         int                 `json:"IPPrefixLen,omitempty" yaml:"IPPrefixLen,omitempty" toml:"IPPrefixLen,omitempty"`
+	IPAddress           string              `json:"IPAddress,omitempty" yaml:"IPAddress,omitempty" toml:"IPAddress,omitempty"`
+	Gateway             string              `json:"Gateway,omitempty" yaml:"Gateway,omitempty" toml:"Gateway,omitempty"`
+	EndpointID          string              `json:"EndpointID,omitempty" yaml:"EndpointID,omitempty" toml:"EndpointID,omitempty"`
+	NetworkID           string              `json:"NetworkID,omitempty" yaml:"NetworkID,omitempty" toml:"NetworkID,omitempty"`
 }
 
 // NetworkSettings contains network-related information about a container
@@ -473,38 +547,48 @@ type Container struct {
 	RestartCount int `json:"RestartCount,omitempty" yaml:"RestartCount,omitempty" toml:"RestartCount,omitempty"`
 
 	AppArmorProfile string `json:"AppArmorProfile,omitempty" yaml:"AppArmorProfile,omitempty" toml:"AppArmorProfile,omitempty"`
+
+	SizeRw     int64 `json:"SizeRw,omitempty" yaml:"SizeRw,omitempty" toml:"SizeRw,omitempty"`
+	SizeRootFs int64 `json:"SizeRootFs,omitempty" yaml:"SizeRootFs,omitempty" toml:"SizeRootFs,omitempty"`
 }
 
 // UpdateContainerOptions specify parameters to the UpdateContainer function.
+// Fields with zero values are not sent, so the daemon leaves the
+// corresponding limits unchanged, and so is the restart policy when its name
+// is empty.
 //
 // See https://goo.gl/Y6fXUy for more details.
 type UpdateContainerOptions struct {
-	BlkioWeight        int           `json:"BlkioWeight"`
-	CPUShares          int           `json:"CpuShares"`
-	CPUPeriod          int           `json:"CpuPeriod"`
-	CPURealtimePeriod  int64         `json:"CpuRealtimePeriod"`
-	CPURealtimeRuntime int64         `json:"CpuRealtimeRuntime"`
-	CPUQuota           int           `json:"CpuQuota"`
-	CpusetCpus         string        `json:"CpusetCpus"`
-	CpusetMems         string        `json:"CpusetMems"`
-	Memory             int           `json:"Memory"`
-	MemorySwap         int           `json:"MemorySwap"`
-	MemoryReservation  int           `json:"MemoryReservation"`
-	KernelMemory       int           `json:"KernelMemory"`
-	RestartPolicy      RestartPolicy `json:"RestartPolicy,omitempty"`
-	Context            context.Context
-}
-
-// UpdateContainer updates the container at ID with the options
+	BlkioWeight        int             `json:"BlkioWeight,omitempty"`
+	CPUShares          int             `json:"CpuShares,omitempty"`
+	CPUPeriod          int             `json:"CpuPeriod,omitempty"`
+	CPURealtimePeriod  int64           `json:"CpuRealtimePeriod,omitempty"`
+	CPURealtimeRuntime int64           `json:"CpuRealtimeRuntime,omitempty"`
+	CPUQuota           int             `json:"CpuQuota,omitempty"`
+	CpusetCpus         string          `json:"CpusetCpus,omitempty"`
+	CpusetMems         string          `json:"CpusetMems,omitempty"`
+	Memory             int             `json:"Memory,omitempty"`
+	MemorySwap         int             `json:"MemorySwap,omitempty"`
+	MemoryReservation  int             `json:"MemoryReservation,omitempty"`
+	KernelMemory       int             `json:"KernelMemory,omitempty"`
+	RestartPolicy      RestartPolicy   `json:"RestartPolicy,omitempty"`
+	Context            context.Context `json:"-"`
+}
+
+// UpdateContainer updates the resource limits and the restart policy of the
+// container at ID, which may be running.
 //
 // See https://goo.gl/Y6fXUy for more details.
 func (c *Client) UpdateContainer(id string, opts UpdateContainerOptions) error {
-	resp, err := c.do("POST", fmt.Sprintf("/containers/"+id+"/update"), doOptions{
+	resp, err := c.do("POST", "/containers/"+id+"/update", doOptions{
 		data:      opts,
 		forceJSON: true,
 		context:   opts.Context,
 	})
 	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return &NoSuchContainer{ID: id}
+		}
 		return err
 	}
 	defer resp.Body.Close()
@@ -525,12 +609,28 @@ type RenameContainerOptions struct {
 
 // RenameContainer updates and existing containers name
 //
+// The new name is validated before sending the request, returning
+// ErrInvalidContainerName if it's not a valid container name. It returns
+// ErrContainerAlreadyExists if another container has the name, and
+// NoSuchContainer if the container doesn't exist.
+//
 // See https://goo.gl/46inai for more details.
 func (c *Client) RenameContainer(opts RenameContainerOptions) error {
+	if !containerNameRegexp.MatchString(opts.Name) {
+		return ErrInvalidContainerName
+	}
 	resp, err := c.do("POST", fmt.Sprintf("/containers/"+opts.ID+"/rename?%s", queryString(opts)), doOptions{
 		context: opts.Context,
 	})
 	if err != nil {
+		if e, ok := err.(*Error); ok {
+			switch e.Status {
+			case http.StatusNotFound:
+				return &NoSuchContainer{ID: opts.ID}
+			case http.StatusConflict:
+				return ErrContainerAlreadyExists
+			}
+		}
 		return err
 	}
 	resp.Body.Close()
@@ -541,7 +641,7 @@ func (c *Client) RenameContainer(opts RenameContainerOptions) error {
 //
 // See https://goo.gl/FaI5JT for more details.
 func (c *Client) InspectContainer(id string) (*Container, error) {
-	return c.inspectContainer(id, doOptions{})
+	return c.inspectContainer(id, false, doOptions{})
 }
 
 // InspectContainerWithContext returns information about a container by its ID.
@@ -549,11 +649,14 @@ func (c *Client) InspectContainer(id string) (*Container, error) {
 //
 // See https://goo.gl/FaI5JT for more details.
 func (c *Client) InspectContainerWithContext(id string, ctx context.Context) (*Container, error) {
-	return c.inspectContainer(id, doOptions{context: ctx})
+	return c.inspectContainer(id, false, doOptions{context: ctx})
 }
 
-func (c *Client) inspectContainer(id string, opts doOptions) (*Container, error) {
+func (c *Client) inspectContainer(id string, size bool, opts doOptions) (*Container, error) {
 	path := "/containers/" + id + "/json"
+	if size {
+		path += "?size=1"
+	}
 	resp, err := c.do("GET", path, opts)
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
@@ -569,6 +672,160 @@ func (c *Client) inspectContainer(id string, opts doOptions) (*Container, error)
 	return &container, nil
 }
 
+// InspectContainerRaw returns information about a container by its ID, along
+// with the raw JSON payload sent by the daemon. The raw payload gives access to
+// fields that are not yet modeled by the Container type.
+//
+// See https://goo.gl/FaI5JT for more details.
+func (c *Client) InspectContainerRaw(id string) (*Container, json.RawMessage, error) {
+	path := "/containers/" + id + "/json"
+	resp, err := c.do("GET", path, doOptions{})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, nil, &NoSuchContainer{ID: id}
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var container Container
+	if err := json.Unmarshal(raw, &container); err != nil {
+		return nil, nil, err
+	}
+	return &container, raw, nil
+}
+
+// GetContainerWritableLayerSize returns the size, in bytes, of the writable
+// layer of the given container (the SizeRw field of the inspect output). The
+// context object can be used to cancel the request.
+//
+// The daemon only computes the size when explicitly asked to, and doing so
+// requires walking the container filesystem, which can be slow for large
+// containers. Use ContainersDiskUsage when the sizes of several containers are
+// needed.
+//
+// See https://goo.gl/FaI5JT for more details.
+func (c *Client) GetContainerWritableLayerSize(id string, ctx context.Context) (int64, error) {
+	container, err := c.inspectContainer(id, true, doOptions{context: ctx})
+	if err != nil {
+		return 0, err
+	}
+	return container.SizeRw, nil
+}
+
+// GetContainerRootFSPath returns the path, on the daemon host, where the root
+// filesystem of the given container is mounted. The context object can be
+// used to cancel the request.
+//
+// The path is only meaningful to processes running on the same host as the
+// daemon, with enough privileges to access the daemon's data directory, so
+// ErrRootFSPathUnavailable is returned when the client is not connected
+// through a local unix socket or named pipe. It's also returned when the
+// storage driver doesn't report the mount point of the container, which
+// usually happens when the container is not running.
+//
+// The path is reported by the overlay, overlay2, zfs and windowsfilter
+// storage drivers. Other drivers, like aufs, btrfs, devicemapper and vfs,
+// mount the container under an ID that isn't exposed by the API, and
+// *UnsupportedStorageDriver is returned for them.
+func (c *Client) GetContainerRootFSPath(id string, ctx context.Context) (string, error) {
+	if c.endpointURL.Scheme != unixProtocol && c.endpointURL.Scheme != namedPipeProtocol {
+		return "", ErrRootFSPathUnavailable
+	}
+	if _, ok := c.Dialer.(*sshDialer); ok {
+		return "", ErrRootFSPathUnavailable
+	}
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return "", err
+	}
+	if container.GraphDriver == nil {
+		return "", ErrRootFSPathUnavailable
+	}
+	var key string
+	switch container.GraphDriver.Name {
+	case "overlay", "overlay2":
+		key = "MergedDir"
+	case "zfs":
+		key = "Mountpoint"
+	case "windowsfilter":
+		key = "dir"
+	default:
+		return "", &UnsupportedStorageDriver{Driver: container.GraphDriver.Name}
+	}
+	if path := container.GraphDriver.Data[key]; path != "" {
+		return path, nil
+	}
+	return "", ErrRootFSPathUnavailable
+}
+
+// PortInfo describes the ports of a container, as returned by
+// GetContainerPortInfo.
+type PortInfo struct {
+	// ExposedPorts are all the ports exposed by the container, sorted.
+	ExposedPorts []Port
+
+	// BoundPorts are the exposed ports bound to host ports, along with
+	// their bindings.
+	BoundPorts map[Port][]PortBinding
+
+	// UnboundExposedPorts are the exposed ports not bound to any host
+	// port, sorted.
+	UnboundExposedPorts []Port
+}
+
+// GetContainerPortInfo returns the ports exposed by the given container and
+// their bindings to host ports. The context object can be used to cancel the
+// request.
+//
+// Ports are only bound while the container is running, so all the exposed
+// ports of a stopped container are reported as unbound.
+func (c *Client) GetContainerPortInfo(id string, ctx context.Context) (*PortInfo, error) {
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	exposed := make(map[Port]struct{})
+	if container.Config != nil {
+		for port := range container.Config.ExposedPorts {
+			exposed[port] = struct{}{}
+		}
+	}
+	info := PortInfo{BoundPorts: make(map[Port][]PortBinding)}
+	if container.NetworkSettings != nil {
+		for port, bindings := range container.NetworkSettings.Ports {
+			exposed[port] = struct{}{}
+			if len(bindings) > 0 {
+				info.BoundPorts[port] = bindings
+			}
+		}
+	}
+	for port := range exposed {
+		info.ExposedPorts = append(info.ExposedPorts, port)
+		if _, ok := info.BoundPorts[port]; !ok {
+			info.UnboundExposedPorts = append(info.UnboundExposedPorts, port)
+		}
+	}
+	sortPorts(info.ExposedPorts)
+	sortPorts(info.UnboundExposedPorts)
+	return &info, nil
+}
+
+// sortPorts sorts ports by protocol and then by number.
+func sortPorts(ports []Port) {
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Proto() != ports[j].Proto() {
+			return ports[i].Proto() < ports[j].Proto()
+		}
+		pi, _ := strconv.Atoi(ports[i].Port())
+		pj, _ := strconv.Atoi(ports[j].Port())
+		return pi < pj
+	})
+}
+
 // ContainerChanges returns changes in the filesystem of the given container.
 //
 // See https://goo.gl/15KKzh for more details.
@@ -750,17 +1007,19 @@ type HostConfig struct {
 	SecurityOpt          []string               `json:"SecurityOpt,omitempty" yaml:"SecurityOpt,omitempty" toml:"SecurityOpt,omitempty"`
 	Cgroup               string                 `json:"Cgroup,omitempty" yaml:"Cgroup,omitempty" toml:"Cgroup,omitempty"`
 	CgroupParent         string                 `json:"CgroupParent,omitempty" yaml:"CgroupParent,omitempty" toml:"CgroupParent,omitempty"`
+	CgroupnsMode         string                 `json:"CgroupnsMode,omitempty" yaml:"CgroupnsMode,omitempty" toml:"CgroupnsMode,omitempty"`
 	Memory               int64                  `json:"Memory,omitempty" yaml:"Memory,omitempty" toml:"Memory,omitempty"`
 	MemoryReservation    int64                  `json:"MemoryReservation,omitempty" yaml:"MemoryReservation,omitempty" toml:"MemoryReservation,omitempty"`
 	KernelMemory         int64                  `json:"KernelMemory,omitempty" yaml:"KernelMemory,omitempty" toml:"KernelMemory,omitempty"`
 	MemorySwap           int64                  `json:"MemorySwap,omitempty" yaml:"MemorySwap,omitempty" toml:"MemorySwap,omitempty"`
-	MemorySwappiness     int64                  `json:"MemorySwappiness,omitempty" yaml:"MemorySwappiness,omitempty" toml:"MemorySwappiness,omitempty"`
+	MemorySwappiness     *int64                 `json:"MemorySwappiness,omitempty" yaml:"MemorySwappiness,omitempty" toml:"MemorySwappiness,omitempty"`
 	CPUShares            int64                  `json:"CpuShares,omitempty" yaml:"CpuShares,omitempty" toml:"CpuShares,omitempty"`
 	CPUSet               string                 `json:"Cpuset,omitempty" yaml:"Cpuset,omitempty" toml:"Cpuset,omitempty"`
 	CPUSetCPUs           string                 `json:"CpusetCpus,omitempty" yaml:"CpusetCpus,omitempty" toml:"CpusetCpus,omitempty"`
 	CPUSetMEMs           string                 `json:"CpusetMems,omitempty" yaml:"CpusetMems,omitempty" toml:"CpusetMems,omitempty"`
 	CPUQuota             int64                  `json:"CpuQuota,omitempty" yaml:"CpuQuota,omitempty" toml:"CpuQuota,omitempty"`
 	CPUPeriod            int64                  `json:"CpuPeriod,omitempty" yaml:"CpuPeriod,omitempty" toml:"CpuPeriod,omitempty"`
+	NanoCPUs             int64                  `json:"NanoCpus,omitempty" yaml:"NanoCpus,omitempty" toml:"NanoCpus,omitempty"`
 	CPURealtimePeriod    int64                  `json:"CpuRealtimePeriod,omitempty" yaml:"CpuRealtimePeriod,omitempty" toml:"CpuRealtimePeriod,omitempty"`
 	CPURealtimeRuntime   int64                  `json:"CpuRealtimeRuntime,omitempty" yaml:"CpuRealtimeRuntime,omitempty" toml:"CpuRealtimeRuntime,omitempty"`
 	BlkioWeight          int64                  `json:"BlkioWeight,omitempty" yaml:"BlkioWeight,omitempty" toml:"BlkioWeight,omitempty"`
@@ -896,6 +1155,357 @@ func (c *Client) RestartContainer(id string, timeout uint) error {
 	return nil
 }
 
+// restartWithEnvStopTimeout is the time, in seconds, RestartWithEnv waits
+// for the container to stop before killing it.
+const restartWithEnvStopTimeout = 10
+
+// RestartWithEnv replaces the given container with a new one that has the
+// same configuration, except for the environment variables in newEnv, which
+// are added to the environment of the container, overriding existing
+// variables with the same name. The context object can be used to cancel the
+// operation.
+//
+// The container is stopped, the new container is created and started, and
+// the old one is removed, leaving the new container with the name of the
+// old one. Networks connected to the container after its creation are not
+// reconnected to the new container. It returns a *ContainerNotStopped error
+// if the container can't be stopped, in which case nothing else is changed.
+// If the new container can't be created or started, or the old one can't be
+// removed, the new container is removed and the old one is started again.
+func (c *Client) RestartWithEnv(id string, newEnv map[string]string, ctx context.Context) (*Container, error) {
+	return c.recreateContainer(ctx, id, func(config *Config, hostConfig *HostConfig) {
+		config.Env = mergeEnv(config.Env, newEnv)
+	})
+}
+
+// recreateContainer replaces the given container with a new one, as
+// described in RestartWithEnv, calling change with copies of the
+// configuration of the container before creating the new one.
+func (c *Client) recreateContainer(ctx context.Context, id string, change func(*Config, *HostConfig)) (*Container, error) {
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	wasRunning := true
+	err = c.StopContainerWithContext(container.ID, restartWithEnvStopTimeout, ctx)
+	if err != nil {
+		if _, ok := err.(*ContainerNotRunning); !ok {
+			return nil, &ContainerNotStopped{ID: container.ID, Err: err}
+		}
+		wasRunning = false
+	}
+	// restore puts the old container back as it was, without the context, so
+	// it's also done when the operation is canceled.
+	restore := func(newID string) {
+		if newID != "" {
+			c.RemoveContainer(RemoveContainerOptions{ID: newID, Force: true})
+		}
+		if wasRunning {
+			c.StartContainer(container.ID, nil)
+		}
+	}
+	config := *container.Config
+	var hostConfig *HostConfig
+	if container.HostConfig != nil {
+		hc := *container.HostConfig
+		hostConfig = &hc
+	} else {
+		hostConfig = &HostConfig{}
+	}
+	change(&config, hostConfig)
+	newContainer, err := c.CreateContainer(CreateContainerOptions{
+		Config:     &config,
+		HostConfig: hostConfig,
+		Context:    ctx,
+	})
+	if err != nil {
+		restore("")
+		return nil, err
+	}
+	err = c.StartContainerWithContext(newContainer.ID, nil, ctx)
+	if err != nil {
+		restore(newContainer.ID)
+		return nil, err
+	}
+	err = c.RemoveContainer(RemoveContainerOptions{ID: container.ID, Context: ctx})
+	if err != nil {
+		restore(newContainer.ID)
+		return nil, err
+	}
+	err = c.RenameContainer(RenameContainerOptions{
+		ID:      newContainer.ID,
+		Name:    strings.TrimPrefix(container.Name, "/"),
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.InspectContainerWithContext(newContainer.ID, ctx)
+}
+
+// SetContainerTimezone sets the TZ environment variable of the container to
+// the given IANA timezone, such as America/Sao_Paulo, replacing the
+// container with RestartWithEnv. It returns ErrInvalidTimezone if the
+// timezone is not known.
+//
+// The timezone is looked up in the time zone database of the client, so it
+// must also be available in the image for the container to use it.
+func (c *Client) SetContainerTimezone(id, timezone string, ctx context.Context) (*Container, error) {
+	if timezone == "" || timezone == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return c.RestartWithEnv(id, map[string]string{"TZ": timezone}, ctx)
+}
+
+// GetContainerEnvVar returns the value of the given environment variable of a
+// container, as set in its configuration. Like os.LookupEnv, the boolean
+// reports whether the variable is set, so it can tell an empty variable from
+// a missing one.
+func (c *Client) GetContainerEnvVar(id, key string, ctx context.Context) (string, bool, error) {
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if container.Config == nil {
+		return "", false, nil
+	}
+	prefix := key + "="
+	for _, env := range container.Config.Env {
+		if strings.HasPrefix(env, prefix) {
+			return env[len(prefix):], true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// defaultAppArmorProfile is the AppArmor profile applied by Docker to
+// containers that don't set one.
+const defaultAppArmorProfile = "docker-default"
+
+// GetContainerAppArmorProfile returns the AppArmor profile set in the
+// security options of a container, or "docker-default", the profile Docker
+// applies when none is set.
+func (c *Client) GetContainerAppArmorProfile(id string, ctx context.Context) (string, error) {
+	profile, ok, err := c.containerSecurityOpt(ctx, id, "apparmor")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return defaultAppArmorProfile, nil
+	}
+	return profile, nil
+}
+
+// SetContainerAppArmorProfile replaces the container with one that has the
+// given AppArmor profile, as RestartWithEnv does. An empty profile removes
+// the option, so the default profile is applied.
+func (c *Client) SetContainerAppArmorProfile(id, profile string, ctx context.Context) (*Container, error) {
+	return c.recreateContainer(ctx, id, func(config *Config, hostConfig *HostConfig) {
+		opts := make([]string, 0, len(hostConfig.SecurityOpt)+1)
+		for _, opt := range hostConfig.SecurityOpt {
+			if key, _ := splitSecurityOpt(opt); key != "apparmor" {
+				opts = append(opts, opt)
+			}
+		}
+		if profile != "" {
+			opts = append(opts, "apparmor="+profile)
+		}
+		hostConfig.SecurityOpt = opts
+	})
+}
+
+// GetContainerSeccompProfile returns the seccomp profile set in the security
+// options of a container: the JSON of the profile, or "unconfined". It
+// returns an empty string when no profile is set, in which case the default
+// profile of the daemon is applied.
+func (c *Client) GetContainerSeccompProfile(id string, ctx context.Context) (string, error) {
+	profile, _, err := c.containerSecurityOpt(ctx, id, "seccomp")
+	return profile, err
+}
+
+// containerSecurityOpt returns the value of the given security option of a
+// container.
+func (c *Client) containerSecurityOpt(ctx context.Context, id, key string) (string, bool, error) {
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if container.HostConfig == nil {
+		return "", false, nil
+	}
+	for _, opt := range container.HostConfig.SecurityOpt {
+		if k, value := splitSecurityOpt(opt); k == key {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// splitSecurityOpt splits a security option in the key=value format, or in
+// the key:value format used by older versions of Docker.
+func splitSecurityOpt(opt string) (string, string) {
+	i := strings.IndexAny(opt, "=:")
+	if i < 0 {
+		return opt, ""
+	}
+	return opt[:i], opt[i+1:]
+}
+
+// GetContainerPIDNamespace returns the inode number of the PID namespace of
+// a container, which is the same for containers that share the namespace. It
+// runs ls in the container, which must be running and have the command.
+func (c *Client) GetContainerPIDNamespace(id string, ctx context.Context) (uint64, error) {
+	return c.containerNamespace(ctx, id, "pid")
+}
+
+// GetContainerNetNamespace returns the inode number of the network namespace
+// of a container, as GetContainerPIDNamespace does for the PID namespace.
+func (c *Client) GetContainerNetNamespace(id string, ctx context.Context) (uint64, error) {
+	return c.containerNamespace(ctx, id, "net")
+}
+
+func (c *Client) containerNamespace(ctx context.Context, id, namespace string) (uint64, error) {
+	output, err := c.execOutput(ctx, id, []string{"ls", "-l", "/proc/1/ns/" + namespace})
+	if err != nil {
+		return 0, err
+	}
+	// the link points to a name like pid:[4026531836]
+	prefix := namespace + ":["
+	i := strings.Index(output, prefix)
+	if i < 0 {
+		return 0, ErrNamespaceNotFound
+	}
+	value := output[i+len(prefix):]
+	if j := strings.Index(value, "]"); j >= 0 {
+		value = value[:j]
+	}
+	inode, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, ErrNamespaceNotFound
+	}
+	return inode, nil
+}
+
+// FreezeContainerFS suspends the writes to the root filesystem of a running
+// container, by running fsfreeze -f / in it, so that a backup of its volumes
+// doesn't capture partial writes. Processes writing to the filesystem block
+// until ThawContainerFS is called, which should happen as soon as the backup
+// is done.
+//
+// Freezing a filesystem is a privileged operation: the container must be
+// privileged, or at least have the CAP_SYS_ADMIN capability, otherwise the
+// command fails. ErrFSFreezeUnsupported is returned when the container
+// doesn't have the fsfreeze command, which is part of util-linux.
+func (c *Client) FreezeContainerFS(id string, ctx context.Context) error {
+	return c.fsfreeze(ctx, id, "-f")
+}
+
+// ThawContainerFS resumes the writes to the root filesystem of a container
+// frozen by FreezeContainerFS, by running fsfreeze -u / in it. It's a
+// privileged operation as well.
+func (c *Client) ThawContainerFS(id string, ctx context.Context) error {
+	return c.fsfreeze(ctx, id, "-u")
+}
+
+func (c *Client) fsfreeze(ctx context.Context, id, flag string) error {
+	_, err := c.execOutput(ctx, id, []string{"fsfreeze", flag, "/"})
+	if e, ok := err.(*execError); ok && (e.exitCode == 126 || e.exitCode == 127) {
+		// the exit codes of commands that can't be found or executed
+		return ErrFSFreezeUnsupported
+	}
+	return err
+}
+
+// execError is the error returned by execOutput when the command exits with
+// a non-zero status.
+type execError struct {
+	cmd      []string
+	exitCode int
+	stderr   string
+}
+
+func (e *execError) Error() string {
+	return fmt.Sprintf("command %q exited with status %d: %s", strings.Join(e.cmd, " "), e.exitCode, e.stderr)
+}
+
+// execExitPollInterval is the interval between the checks of an exec
+// instance whose output ended before the daemon recorded its exit.
+const execExitPollInterval = 50 * time.Millisecond
+
+// execOutput runs the given command in a container, returning its standard
+// output, or an *execError including its standard error if it fails.
+func (c *Client) execOutput(ctx context.Context, id string, cmd []string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	exec, err := c.CreateExec(CreateExecOptions{
+		Container:    id,
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+		Context:      ctx,
+	})
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	err = c.StartExec(exec.ID, StartExecOptions{
+		OutputStream: &stdout,
+		ErrorStream:  &stderr,
+		Context:      ctx,
+	})
+	if err != nil {
+		return "", err
+	}
+	// the output may end before the daemon records the exit code
+	inspect, err := c.InspectExec(exec.ID)
+	for err == nil && inspect.Running {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(execExitPollInterval):
+		}
+		inspect, err = c.InspectExec(exec.ID)
+	}
+	if err != nil {
+		return "", err
+	}
+	if inspect.ExitCode != 0 {
+		return "", &execError{cmd: cmd, exitCode: inspect.ExitCode, stderr: strings.TrimSpace(stderr.String())}
+	}
+	return stdout.String(), nil
+}
+
+// mergeEnv returns env, in the KEY=value format, with the variables in
+// newEnv added to it. Variables in newEnv replace the ones with the same name
+// in env.
+func mergeEnv(env []string, newEnv map[string]string) []string {
+	merged := make([]string, 0, len(env)+len(newEnv))
+	seen := make(map[string]bool, len(newEnv))
+	for _, v := range env {
+		key := strings.SplitN(v, "=", 2)[0]
+		if value, ok := newEnv[key]; ok {
+			v = key + "=" + value
+			seen[key] = true
+		}
+		merged = append(merged, v)
+	}
+	keys := make([]string, 0, len(newEnv))
+	for key := range newEnv {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		merged = append(merged, key+"="+newEnv[key])
+	}
+	return merged
+}
+
 // PauseContainer pauses the given container.
 //
 // See https://goo.gl/D1Yaii for more details.
@@ -937,6 +1547,85 @@ type TopResult struct {
 	Processes [][]string
 }
 
+// ErrUnrecognizedTopTitles is the error returned by TopResult.ProcessList when
+// the titles of the process table don't include the PID and the command of
+// the processes, for example because of custom ps arguments.
+var ErrUnrecognizedTopTitles = errors.New("unrecognized titles in the process table")
+
+// Process represents a process running in a container, as parsed from a
+// TopResult.
+type Process struct {
+	PID       int
+	PPID      int
+	User      string
+	TTY       string
+	StartTime string
+	Time      string
+	Command   string
+
+	// Fields holds the value of every column of the process table, keyed by
+	// title, including the ones without a matching field.
+	Fields map[string]string
+}
+
+// ProcessList parses the process table, mapping the columns to the fields of
+// Process by their titles, so it works with the default output of ps and
+// with common ps arguments such as "aux". It returns
+// ErrUnrecognizedTopTitles when the table has no PID or command column, in
+// which case Titles and Processes must be used instead.
+func (r TopResult) ProcessList() ([]Process, error) {
+	var hasPID, hasCommand bool
+	for _, title := range r.Titles {
+		switch title {
+		case "PID":
+			hasPID = true
+		case "CMD", "COMMAND", "Name":
+			hasCommand = true
+		}
+	}
+	if !hasPID || !hasCommand {
+		return nil, ErrUnrecognizedTopTitles
+	}
+	processes := make([]Process, 0, len(r.Processes))
+	for _, row := range r.Processes {
+		if len(row) != len(r.Titles) {
+			return nil, fmt.Errorf("process table row has %d columns, expected %d", len(row), len(r.Titles))
+		}
+		process := Process{Fields: make(map[string]string, len(row))}
+		for i, value := range row {
+			title := r.Titles[i]
+			process.Fields[title] = value
+			if err := process.setField(title, value); err != nil {
+				return nil, fmt.Errorf("invalid %s in process table: %q", title, value)
+			}
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// setField sets the field of the process matching the given title of a
+// column of ps, on Linux, or of the process list, on Windows.
+func (p *Process) setField(title, value string) (err error) {
+	switch title {
+	case "PID":
+		p.PID, err = strconv.Atoi(value)
+	case "PPID":
+		p.PPID, err = strconv.Atoi(value)
+	case "UID", "USER":
+		p.User = value
+	case "TTY", "TT":
+		p.TTY = value
+	case "STIME", "START":
+		p.StartTime = value
+	case "TIME":
+		p.Time = value
+	case "CMD", "COMMAND", "Name":
+		p.Command = value
+	}
+	return err
+}
+
 // TopContainer returns processes running inside a container
 //
 // See https://goo.gl/FLwpPl for more details.
@@ -1031,6 +1720,10 @@ type Stats struct {
 		WriteCountNormalized uint64 `json:"write_count_normalized,omitempty" yaml:"write_count_normalized,omitempty" toml:"write_count_normalized,omitempty"`
 		WriteSizeBytes       uint64 `json:"write_size_bytes,omitempty" yaml:"write_size_bytes,omitempty" toml:"write_size_bytes,omitempty"`
 	} `json:"storage_stats,omitempty" yaml:"storage_stats,omitempty" toml:"storage_stats,omitempty"`
+
+	// CPUPercentage is the CPU usage of the container, computed by
+	// InspectContainerStats. It's not reported by the daemon.
+	CPUPercentage float64 `json:"-" yaml:"-" toml:"-"`
 }
 
 // NetworkStats is a stats entry for network stats
@@ -1163,48 +1856,210 @@ func (c *Client) Stats(opts StatsOptions) (retErr error) {
 	return nil
 }
 
-// KillContainerOptions represents the set of options that can be used in a
-// call to KillContainer.
+// GetContainerStatsOnce returns a single sample of the statistics of the
+// given container, instead of streaming them like Stats does. The context
+// object can be used to cancel the request.
 //
-// See https://goo.gl/JnTxXZ for more details.
-type KillContainerOptions struct {
-	// The ID of the container.
-	ID string `qs:"-"`
+// See https://goo.gl/Dk3Xio for more details.
+func (c *Client) GetContainerStatsOnce(id string, ctx context.Context) (*Stats, error) {
+	path := "/containers/" + id + "/stats?stream=false"
+	resp, err := c.do("GET", path, doOptions{context: ctx})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, &NoSuchContainer{ID: id}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var stats Stats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
 
-	// The signal to send to the container. When omitted, Docker server
-	// will assume SIGKILL.
-	Signal  Signal
-	Context context.Context
+// InspectContainerStats returns a sample of the statistics of the given
+// container with CPUPercentage set. Since the percentage requires two
+// samples, see ComputeCPUPercent, it gets the statistics twice, as
+// GetContainerStatsOnce does, taking about as long as the interval between
+// the samples collected by the daemon, usually a second. The context object
+// can be used to cancel the requests.
+func (c *Client) InspectContainerStats(id string, ctx context.Context) (*Stats, error) {
+	previous, err := c.GetContainerStatsOnce(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := c.GetContainerStatsOnce(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUPercentage = ComputeCPUPercent(stats, previous)
+	return stats, nil
 }
 
-// KillContainer sends a signal to a container, returning an error in case of
-// failure.
-//
-// See https://goo.gl/JnTxXZ for more details.
-func (c *Client) KillContainer(opts KillContainerOptions) error {
-	path := "/containers/" + opts.ID + "/kill" + "?" + queryString(opts)
-	resp, err := c.do("POST", path, doOptions{context: opts.Context})
+// GetContainerNetworkStats returns the statistics of the given network
+// interface of the container, such as eth0. It returns ErrInterfaceNotFound
+// if the container has no interface with that name. The context object can
+// be used to cancel the request.
+func (c *Client) GetContainerNetworkStats(id, interfaceName string, ctx context.Context) (*NetworkStats, error) {
+	stats, err := c.GetContainerStatsOnce(id, ctx)
 	if err != nil {
-		e, ok := err.(*Error)
-		if !ok {
-			return err
-		}
-		switch e.Status {
-		case http.StatusNotFound:
-			return &NoSuchContainer{ID: opts.ID}
-		case http.StatusConflict:
-			return &ContainerNotRunning{ID: opts.ID}
-		default:
-			return err
-		}
+		return nil, err
 	}
-	resp.Body.Close()
-	return nil
+	networkStats, ok := stats.Networks[interfaceName]
+	if !ok {
+		return nil, ErrInterfaceNotFound
+	}
+	return &networkStats, nil
 }
 
-// RemoveContainerOptions encapsulates options to remove a container.
-//
-// See https://goo.gl/hL5IPC for more details.
+// ContainerTiming is the lifecycle timestamps of a container, as returned by
+// GetContainerTiming.
+type ContainerTiming struct {
+	CreatedAt time.Time
+
+	// StartedAt is the zero time when the container was never started.
+	StartedAt time.Time
+
+	// FinishedAt is the zero time when the container didn't stop since
+	// it was last started.
+	FinishedAt time.Time
+
+	// Uptime is the time since the container was last started, or zero
+	// when it isn't running.
+	Uptime time.Duration
+
+	// TotalCPUTime is the CPU time consumed by the container, as reported
+	// in its statistics, or zero when it isn't running.
+	TotalCPUTime time.Duration
+}
+
+// GetContainerTiming returns the lifecycle timestamps of the given
+// container, along with its uptime and CPU time when it's running. The
+// context object can be used to cancel the requests.
+func (c *Client) GetContainerTiming(id string, ctx context.Context) (*ContainerTiming, error) {
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	timing := ContainerTiming{
+		CreatedAt: container.Created,
+		StartedAt: container.State.StartedAt,
+	}
+	// the daemon keeps the FinishedAt of the previous run when a container
+	// is restarted
+	if container.State.FinishedAt.After(container.State.StartedAt) {
+		timing.FinishedAt = container.State.FinishedAt
+	}
+	if container.State.Running {
+		timing.Uptime = time.Since(container.State.StartedAt)
+		stats, err := c.GetContainerStatsOnce(id, ctx)
+		if err != nil {
+			return nil, err
+		}
+		timing.TotalCPUTime = time.Duration(stats.CPUStats.CPUUsage.TotalUsage)
+	}
+	return &timing, nil
+}
+
+// defaultCFSPeriod is the CFS period, in microseconds, used by the daemon
+// when a container doesn't set one.
+const defaultCFSPeriod = 100000
+
+// GetContainerCFSQuota returns the CFS quota and period of the given
+// container, in microseconds, which limit its CPU usage to quota out of each
+// period. The daemon defaults are returned for the values the container
+// doesn't set: a period of 100000 and a quota of -1, meaning no limit. The
+// limit of containers started with the --cpus flag of docker run, which is
+// stored in NanoCPUs, is converted to the quota it's enforced with.
+func (c *Client) GetContainerCFSQuota(id string, ctx context.Context) (quota, period int64, err error) {
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	quota, period = -1, defaultCFSPeriod
+	if hostConfig := container.HostConfig; hostConfig != nil {
+		if hostConfig.CPUPeriod > 0 {
+			period = hostConfig.CPUPeriod
+		}
+		if hostConfig.CPUQuota > 0 {
+			quota = hostConfig.CPUQuota
+		} else if hostConfig.NanoCPUs > 0 {
+			quota = hostConfig.NanoCPUs * period / 1e9
+		}
+	}
+	return quota, period, nil
+}
+
+// CPUQuotaPercent converts a CFS quota and period, as returned by
+// GetContainerCFSQuota, to a percentage of one CPU, which exceeds 100 for
+// containers allowed to use more than one CPU. It returns zero when there's
+// no quota.
+func CPUQuotaPercent(quota, period int64) float64 {
+	if quota <= 0 || period <= 0 {
+		return 0
+	}
+	return float64(quota) / float64(period) * 100
+}
+
+// ListContainerNetworkInterfaces returns the sorted names of the network
+// interfaces of the given container, as reported in its statistics. The
+// context object can be used to cancel the request.
+func (c *Client) ListContainerNetworkInterfaces(id string, ctx context.Context) ([]string, error) {
+	stats, err := c.GetContainerStatsOnce(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(stats.Networks))
+	for name := range stats.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// KillContainerOptions represents the set of options that can be used in a
+// call to KillContainer.
+//
+// See https://goo.gl/JnTxXZ for more details.
+type KillContainerOptions struct {
+	// The ID of the container.
+	ID string `qs:"-"`
+
+	// The signal to send to the container. When omitted, Docker server
+	// will assume SIGKILL.
+	Signal  Signal
+	Context context.Context
+}
+
+// KillContainer sends a signal to a container, returning an error in case of
+// failure.
+//
+// See https://goo.gl/JnTxXZ for more details.
+func (c *Client) KillContainer(opts KillContainerOptions) error {
+	path := "/containers/" + opts.ID + "/kill" + "?" + queryString(opts)
+	resp, err := c.do("POST", path, doOptions{context: opts.Context})
+	if err != nil {
+		e, ok := err.(*Error)
+		if !ok {
+			return err
+		}
+		switch e.Status {
+		case http.StatusNotFound:
+			return &NoSuchContainer{ID: opts.ID}
+		case http.StatusConflict:
+			return &ContainerNotRunning{ID: opts.ID}
+		default:
+			return err
+		}
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RemoveContainerOptions encapsulates options to remove a container.
+//
+// See https://goo.gl/hL5IPC for more details.
 type RemoveContainerOptions struct {
 	// The ID of the container.
 	ID string `qs:"-"`
@@ -1284,6 +2139,46 @@ func (c *Client) DownloadFromContainer(id string, opts DownloadFromContainerOpti
 	})
 }
 
+// ContainerPathStat is the information about a path in the filesystem of a
+// container, as returned by StatContainerPath.
+type ContainerPathStat struct {
+	Name       string      `json:"name" yaml:"name" toml:"name"`
+	Size       int64       `json:"size" yaml:"size" toml:"size"`
+	Mode       os.FileMode `json:"mode" yaml:"mode" toml:"mode"`
+	Mtime      time.Time   `json:"mtime" yaml:"mtime" toml:"mtime"`
+	LinkTarget string      `json:"linkTarget" yaml:"linkTarget" toml:"linkTarget"`
+}
+
+// StatContainerPath returns the information about the given path in the
+// filesystem of a container, such as whether it's a directory, without
+// downloading it. It returns ErrContainerPathNotFound when the path doesn't
+// exist in the container.
+//
+// See https://goo.gl/W49jxK for more details.
+func (c *Client) StatContainerPath(id, path string) (ContainerPathStat, error) {
+	var stat ContainerPathStat
+	url := fmt.Sprintf("/containers/%s/archive?", id) + queryString(DownloadFromContainerOptions{Path: path})
+	resp, err := c.do("HEAD", url, doOptions{})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			// the daemon answers with 404 both for missing containers
+			// and missing paths, and HEAD responses have no message
+			if _, err := c.InspectContainer(id); err != nil {
+				return stat, err
+			}
+			return stat, ErrContainerPathNotFound
+		}
+		return stat, err
+	}
+	resp.Body.Close()
+	data, err := base64.StdEncoding.DecodeString(resp.Header.Get("X-Docker-Container-Path-Stat"))
+	if err != nil {
+		return stat, err
+	}
+	err = json.Unmarshal(data, &stat)
+	return stat, err
+}
+
 // CopyFromContainerOptions contains the set of options used for copying
 // files from a container.
 //
@@ -1357,6 +2252,235 @@ func (c *Client) waitContainer(id string, opts doOptions) (int, error) {
 	return r.StatusCode, nil
 }
 
+// RunOptions specify parameters to the RunContainer function.
+type RunOptions struct {
+	Name             string
+	Config           *Config
+	HostConfig       *HostConfig
+	NetworkingConfig *NetworkingConfig
+
+	// CaptureOutput makes RunContainer attach to the container before
+	// starting it, returning its stdout and stderr in the RunResult.
+	CaptureOutput bool
+
+	// AutoRemove makes RunContainer remove the container and its anonymous
+	// volumes once it's done, even when the run fails.
+	AutoRemove bool
+
+	// Timeout limits the duration of the whole run. When it's reached, or
+	// when the context is canceled, the container is killed.
+	Timeout time.Duration
+
+	Context context.Context
+}
+
+// RunResult is the result of RunContainer. Stdout and Stderr are only filled
+// when RunOptions.CaptureOutput is set, and Stderr is always empty for
+// containers with a TTY.
+type RunResult struct {
+	ContainerID string
+	ExitCode    int
+	Stdout      []byte
+	Stderr      []byte
+}
+
+// RunContainer creates a container, starts it and waits for it to exit,
+// returning its exit code, much like "docker run" does. A non-zero exit code
+// is not an error.
+func (c *Client) RunContainer(opts RunOptions) (*RunResult, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	container, err := c.CreateContainer(CreateContainerOptions{
+		Name:             opts.Name,
+		Config:           opts.Config,
+		HostConfig:       opts.HostConfig,
+		NetworkingConfig: opts.NetworkingConfig,
+		Context:          ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.AutoRemove {
+		// the context may be done already, and the container must be
+		// removed anyway
+		defer c.RemoveContainer(RemoveContainerOptions{ID: container.ID, RemoveVolumes: true, Force: true})
+	}
+	result := RunResult{ContainerID: container.ID}
+	var stdout, stderr bytes.Buffer
+	var attach *outputAttachment
+	if opts.CaptureOutput {
+		tty := opts.Config != nil && opts.Config.Tty
+		attach, err = c.attachOutput(ctx, container.ID, &stdout, &stderr, tty, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = c.StartContainerWithContext(container.ID, nil, ctx); err != nil {
+		if attach != nil {
+			attach.stop()
+		}
+		return nil, err
+	}
+	result.ExitCode, err = c.WaitContainerWithContext(container.ID, ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.KillContainer(KillContainerOptions{ID: container.ID})
+		}
+		if attach != nil {
+			attach.stop()
+		}
+		return nil, err
+	}
+	if attach != nil {
+		<-attach.done
+		if attach.err != nil {
+			return nil, attach.err
+		}
+		result.Stdout = stdout.Bytes()
+		result.Stderr = stderr.Bytes()
+	}
+	return &result, nil
+}
+
+// outputAttachment is a connection attached to the output of a container,
+// as returned by attachOutput. done is closed when the connection ends, with
+// its error in err.
+type outputAttachment struct {
+	cw     CloseWaiter
+	done   chan struct{}
+	err    error
+	once   sync.Once
+	stdout *stoppableWriter
+	stderr *stoppableWriter
+}
+
+// attachOutput attaches to the stdout and stderr of the container, and, when
+// logs is set, to the output written before the attach, returning once the
+// connection is established.
+func (c *Client) attachOutput(ctx context.Context, id string, stdout, stderr io.Writer, tty, logs bool) (*outputAttachment, error) {
+	attach := outputAttachment{
+		done:   make(chan struct{}),
+		stdout: &stoppableWriter{w: stdout},
+		stderr: &stoppableWriter{w: stderr},
+	}
+	success := make(chan struct{})
+	cw, err := c.AttachToContainerNonBlocking(AttachToContainerOptions{
+		Container:    id,
+		OutputStream: attach.stdout,
+		ErrorStream:  attach.stderr,
+		Success:      success,
+		RawTerminal:  tty,
+		Logs:         logs,
+		Stream:       true,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-success:
+		success <- struct{}{}
+	case <-ctx.Done():
+		// nothing is copied to the writers before the success signal
+		cw.Close()
+		return nil, ctx.Err()
+	}
+	attach.cw = cw
+	go func() {
+		attach.err = cw.Wait()
+		close(attach.done)
+	}()
+	return &attach, nil
+}
+
+// stop closes the connection and waits for it to end. Nothing is written to
+// the writers after it returns.
+func (a *outputAttachment) stop() {
+	a.once.Do(func() {
+		a.cw.Close()
+	})
+	<-a.done
+	a.stdout.stop()
+	a.stderr.stop()
+}
+
+// stoppableWriter is a writer that discards what is written to it once it's
+// stopped.
+type stoppableWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	stopped bool
+}
+
+func (w *stoppableWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return len(p), nil
+	}
+	return w.w.Write(p)
+}
+
+func (w *stoppableWriter) stop() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+}
+
+// attachDrainTimeout is how long AttachAndWait keeps reading the output of a
+// container after it exits.
+const attachDrainTimeout = 5 * time.Second
+
+// AttachAndWait attaches to the stdout and stderr of the running container,
+// from the beginning of its logs, and waits for it to exit, returning its
+// exit code. The output is written to the given writers, which may be nil to
+// discard it, and nothing is written to them after AttachAndWait returns.
+//
+// The exit code is given by WaitContainer: when the attach connection ends
+// before the container exits, AttachAndWait keeps waiting, and once the
+// container exits, the remaining output is read for at most a few seconds.
+// When ctx is done, it returns -1 and the error of the context. A nil ctx is
+// the same as context.Background().
+func (c *Client) AttachAndWait(id string, stdout, stderr io.Writer, ctx context.Context) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return -1, err
+	}
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+	tty := container.Config != nil && container.Config.Tty
+	attach, err := c.attachOutput(ctx, id, stdout, stderr, tty, true)
+	if err != nil {
+		return -1, err
+	}
+	defer attach.stop()
+	exitCode, err := c.WaitContainerWithContext(id, ctx)
+	if err != nil {
+		return -1, err
+	}
+	select {
+	case <-attach.done:
+	case <-ctx.Done():
+	case <-time.After(attachDrainTimeout):
+	}
+	return exitCode, nil
+}
+
 // CommitContainerOptions aggregates parameters to the CommitContainer method.
 //
 // See https://goo.gl/CzIguf for more details.
@@ -1366,16 +2490,32 @@ type CommitContainerOptions struct {
 	Tag        string
 	Message    string `qs:"comment"`
 	Author     string
-	Changes    []string `qs:"changes"`
-	Run        *Config  `qs:"-"`
-	Context    context.Context
+
+	// Changes are Dockerfile instructions applied to the image, such as
+	// "CMD [\"python\", \"app.py\"]", "ENV PORT=8080", "EXPOSE 8080" or
+	// "LABEL version=1.0".
+	Changes []string `qs:"changes"`
+
+	// Run is the configuration of the image, replacing the configuration of
+	// the container.
+	Run *Config `qs:"-"`
+
+	// NoPause disables pausing the container while it's committed, which
+	// the daemon does by default.
+	NoPause bool `qs:"-"`
+
+	Context context.Context
 }
 
-// CommitContainer creates a new image from a container's changes.
+// CommitContainer creates a new image from a container's changes. The ID of
+// the new image is set in the returned Image.
 //
 // See https://goo.gl/CzIguf for more details.
 func (c *Client) CommitContainer(opts CommitContainerOptions) (*Image, error) {
 	path := "/commit?" + queryString(opts)
+	if opts.NoPause {
+		path += "&pause=0"
+	}
 	resp, err := c.do("POST", path, doOptions{
 		data:    opts.Run,
 		context: opts.Context,
@@ -1446,11 +2586,7 @@ func (c *Client) AttachToContainer(opts AttachToContainerOptions) error {
 //
 // See https://goo.gl/NKpkFk for more details.
 func (c *Client) AttachToContainerNonBlocking(opts AttachToContainerOptions) (CloseWaiter, error) {
-	if opts.Container == "" {
-		return nil, &NoSuchContainer{ID: opts.Container}
-	}
-	path := "/containers/" + opts.Container + "/attach?" + queryString(opts)
-	return c.hijack("POST", path, hijackOptions{
+	return c.attachToContainer(opts, hijackOptions{
 		success:        opts.Success,
 		setRawTerminal: opts.RawTerminal,
 		in:             opts.InputStream,
@@ -1459,6 +2595,145 @@ func (c *Client) AttachToContainerNonBlocking(opts AttachToContainerOptions) (Cl
 	})
 }
 
+// StreamType identifies the stream of a frame in the multiplexed streams
+// returned by the attach and logs endpoints of containers without a TTY.
+type StreamType byte
+
+// Streams multiplexed in the attach and logs endpoints. StreamSystemErr
+// frames carry errors from the daemon.
+const (
+	StreamStdin     StreamType = 0
+	StreamStdout    StreamType = 1
+	StreamStderr    StreamType = 2
+	StreamSystemErr StreamType = 3
+)
+
+// ReadStreamHeader reads the 8-byte header of a frame from a multiplexed
+// stream, returning the stream of the frame and the size of its payload,
+// which follows the header.
+func ReadStreamHeader(r io.Reader) (StreamType, uint32, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, err
+	}
+	return StreamType(header[0]), binary.BigEndian.Uint32(header[4:]), nil
+}
+
+// AttachToContainerStream attaches to a container, returning the connection
+// to the container as a single stream: reads return the output of the
+// container, multiplexed unless opts.RawTerminal is set (the frames can be
+// read with ReadStreamHeader), and writes are sent to the stdin of the
+// container, when opts.Stdin is set, failing with ErrStdinNotAttached
+// otherwise.
+//
+// The InputStream, OutputStream, ErrorStream and Success fields of opts are
+// ignored. The returned stream also implements CloseWrite, which signals the
+// end of the input to the container. Closing the stream ends the attach
+// session.
+func (c *Client) AttachToContainerStream(opts AttachToContainerOptions) (io.ReadWriteCloser, error) {
+	outR, outW := io.Pipe()
+	hijackOpts := hijackOptions{setRawTerminal: true, stdout: outW}
+	var (
+		stdinR *io.PipeReader
+		stdinW *io.PipeWriter
+	)
+	if opts.Stdin {
+		stdinR, stdinW = io.Pipe()
+		hijackOpts.in = stdinR
+	}
+	cw, err := c.attachToContainer(opts, hijackOpts)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		err := cw.Wait()
+		outW.CloseWithError(err)
+		if stdinR != nil {
+			stdinR.CloseWithError(io.ErrClosedPipe)
+		}
+	}()
+	return &attachStream{Reader: outR, stdin: stdinW, out: outR, cw: cw}, nil
+}
+
+// AttachToContainerDemux attaches to a container, returning the stdout and
+// stderr of the container as separate streams, along with its stdin, which
+// is nil unless opts.Stdin is set. The output of containers with a TTY,
+// signaled by opts.RawTerminal, is all returned in stdout.
+//
+// The stdout and stderr streams must be read concurrently, or closed when
+// not needed, as reading the output blocks until both can receive it. The
+// InputStream, OutputStream, ErrorStream and Success fields of opts are
+// ignored. Closing stdin signals the end of the input to the container.
+func (c *Client) AttachToContainerDemux(opts AttachToContainerOptions) (stdout, stderr io.ReadCloser, stdin io.WriteCloser, err error) {
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	hijackOpts := hijackOptions{setRawTerminal: opts.RawTerminal, stdout: stdoutW, stderr: stderrW}
+	var stdinR *io.PipeReader
+	if opts.Stdin {
+		var stdinW *io.PipeWriter
+		stdinR, stdinW = io.Pipe()
+		hijackOpts.in = stdinR
+		stdin = stdinW
+	}
+	cw, err := c.attachToContainer(opts, hijackOpts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	go func() {
+		err := cw.Wait()
+		stdoutW.CloseWithError(err)
+		stderrW.CloseWithError(err)
+		if stdinR != nil {
+			stdinR.CloseWithError(io.ErrClosedPipe)
+		}
+	}()
+	return stdoutR, stderrR, stdin, nil
+}
+
+func (c *Client) attachToContainer(opts AttachToContainerOptions, hijackOpts hijackOptions) (CloseWaiter, error) {
+	if opts.Container == "" {
+		return nil, &NoSuchContainer{ID: opts.Container}
+	}
+	path := "/containers/" + opts.Container + "/attach?" + queryString(opts)
+	return c.hijack("POST", path, hijackOpts)
+}
+
+// attachStream is the stream returned by AttachToContainerStream. stdin is
+// nil when the stdin of the container isn't attached.
+type attachStream struct {
+	io.Reader
+	stdin *io.PipeWriter
+	out   *io.PipeReader
+	cw    CloseWaiter
+	once  sync.Once
+}
+
+func (s *attachStream) Write(p []byte) (int, error) {
+	if s.stdin == nil {
+		return 0, ErrStdinNotAttached
+	}
+	return s.stdin.Write(p)
+}
+
+// CloseWrite signals the end of the input to the container.
+func (s *attachStream) CloseWrite() error {
+	if s.stdin == nil {
+		return nil
+	}
+	return s.stdin.Close()
+}
+
+func (s *attachStream) Close() error {
+	s.once.Do(func() {
+		if s.stdin != nil {
+			s.stdin.Close()
+		}
+		s.out.Close()
+		s.cw.Close()
+	})
+	return nil
+}
+
 // LogsOptions represents the set of options used when getting logs from a
 // container.
 //
@@ -1509,6 +2784,394 @@ func (c *Client) Logs(opts LogsOptions) error {
 	})
 }
 
+// LogEntry is a line of the logs of a container, as returned by
+// GetContainerLogsStructured.
+type LogEntry struct {
+	// Stream is either stdout or stderr.
+	Stream    string
+	Timestamp time.Time
+	Message   string
+}
+
+// GetContainerLogsStructured returns the logs of the given container as a
+// list of entries, keeping the stream and the timestamp of each line.
+//
+// The Container, OutputStream, ErrorStream, Follow and Timestamps fields of
+// opts are ignored, and both streams are returned when neither Stdout nor
+// Stderr is set. RawTerminal must be set for containers that have a TTY,
+// whose logs are not multiplexed and are all reported as stdout.
+func (c *Client) GetContainerLogsStructured(id string, opts LogsOptions) ([]LogEntry, error) {
+	opts.Follow = false
+	opts.Timestamps = true
+	if !opts.Stdout && !opts.Stderr {
+		opts.Stdout, opts.Stderr = true, true
+	}
+	if opts.Tail == "" {
+		opts.Tail = "all"
+	}
+	path := "/containers/" + id + "/logs?" + queryString(opts)
+	resp, err := c.do("GET", path, doOptions{context: opts.Context})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, &NoSuchContainer{ID: id}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var entries []LogEntry
+	err = demuxLogs(resp.Body, opts.RawTerminal, func(e []LogEntry) error {
+		entries = append(entries, e...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ContainerLogsReader returns a reader of the logs of the given container as
+// plain text. The caller must close it to release the connection to the
+// daemon.
+//
+// Unless opts.RawTerminal is set, which must be done for containers that
+// have a TTY, the logs are demultiplexed: stdout and stderr are merged in the
+// reader, or stderr is written to opts.ErrorStream when it's set. The
+// Container, OutputStream and Context fields of opts are ignored, and both
+// streams are returned when neither Stdout nor Stderr is set.
+func (c *Client) ContainerLogsReader(id string, opts LogsOptions, ctx context.Context) (io.ReadCloser, error) {
+	if !opts.Stdout && !opts.Stderr {
+		opts.Stdout, opts.Stderr = true, true
+	}
+	if opts.Tail == "" {
+		opts.Tail = "all"
+	}
+	path := "/containers/" + id + "/logs?" + queryString(opts)
+	resp, err := c.do("GET", path, doOptions{context: ctx})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, &NoSuchContainer{ID: id}
+		}
+		return nil, err
+	}
+	if opts.RawTerminal {
+		return resp.Body, nil
+	}
+	stderr := opts.ErrorStream
+	pr, pw := io.Pipe()
+	if stderr == nil {
+		stderr = pw
+	}
+	go func() {
+		_, err := StdCopy(pw, stderr, resp.Body)
+		pw.CloseWithError(err)
+	}()
+	return struct {
+		io.Reader
+		io.Closer
+	}{pr, closerFunc(func() error {
+		pr.Close()
+		return resp.Body.Close()
+	})}, nil
+}
+
+// LogSpec is a container whose logs are followed by MultiLogs.
+type LogSpec struct {
+	// Container is the ID or name of the container.
+	Container string
+
+	// Name is the prefix of the lines of the container, which defaults to
+	// Container.
+	Name string
+
+	// Tail, Since and Timestamps are used as in LogsOptions.
+	Tail       string
+	Since      int64
+	Timestamps bool
+
+	// RawTerminal must be set for containers with a TTY, as in
+	// LogsOptions.
+	RawTerminal bool
+}
+
+// MultiLogs follows the logs of several containers at once, both stdout and
+// stderr, writing their lines to out as they arrive, each one prefixed with
+// the name of its container, padded to the longest name, like docker-compose
+// does:
+//
+//	web | Listening on :8080
+//	db  | ready to accept connections
+//
+// Lines of different containers aren't mixed, but there's no ordering among
+// containers. The logs of a container end when it stops, without affecting
+// the others; MultiLogs returns once all of them end, or when the context is
+// done, with the error of the context. Otherwise, it returns the first error
+// following the logs of a container, such as *NoSuchContainer.
+func (c *Client) MultiLogs(specs []LogSpec, out io.Writer, ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	names := make([]string, len(specs))
+	var width int
+	for i, spec := range specs {
+		names[i] = spec.Name
+		if names[i] == "" {
+			names[i] = spec.Container
+		}
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+	var mu sync.Mutex
+	errs := make(chan error, len(specs))
+	for i, spec := range specs {
+		prefix := fmt.Sprintf("%-*s | ", width, names[i])
+		go func(spec LogSpec, prefix string) {
+			errs <- c.prefixedLogs(ctx, spec, prefix, out, &mu)
+		}(spec, prefix)
+	}
+	var err error
+	for range specs {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// prefixedLogs follows the logs of a container for MultiLogs, writing each
+// line to out with the given prefix while holding mu. The lines of stdout
+// and stderr are split separately, so partial lines of one stream aren't
+// joined with the other.
+func (c *Client) prefixedLogs(ctx context.Context, spec LogSpec, prefix string, out io.Writer, mu *sync.Mutex) error {
+	stdout := &prefixWriter{prefix: prefix, out: out, mu: mu}
+	stderr := &prefixWriter{prefix: prefix, out: out, mu: mu}
+	logs, err := c.ContainerLogsReader(spec.Container, LogsOptions{
+		Follow:      true,
+		Tail:        spec.Tail,
+		Since:       spec.Since,
+		Timestamps:  spec.Timestamps,
+		RawTerminal: spec.RawTerminal,
+		ErrorStream: stderr,
+	}, ctx)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	if _, err = io.Copy(stdout, logs); err != nil {
+		return err
+	}
+	// stderr is done once the reader reaches EOF
+	if err = stdout.flush(); err != nil {
+		return err
+	}
+	return stderr.flush()
+}
+
+// prefixWriter writes the lines written to it to out, each one with the
+// given prefix, while holding mu. Incomplete lines are kept until they're
+// completed or flushed.
+type prefixWriter struct {
+	prefix  string
+	out     io.Writer
+	mu      *sync.Mutex
+	partial []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(data[:i+1]); err != nil {
+			return 0, err
+		}
+		data = data[i+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// flush writes the incomplete line, if any, ending it with a newline.
+func (w *prefixWriter) flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := append(w.partial, '\n')
+	w.partial = nil
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.out, w.prefix+string(line))
+	return err
+}
+
+// WaitForLogPattern follows the logs of the given container, both stdout and
+// stderr, from the beginning, until a line matching the pattern is found, and
+// returns that line, without the trailing newline.
+//
+// It returns ErrPatternNotFound if the logs end without a matching line, and
+// the error of the context if it's canceled or the timeout expires first. A
+// zero timeout means no timeout.
+func (c *Client) WaitForLogPattern(id string, pattern *regexp.Regexp, timeout time.Duration, ctx context.Context) (matchedLine string, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	container, err := c.InspectContainerWithContext(id, ctx)
+	if err != nil {
+		return "", err
+	}
+	// timestamps are requested so lines starting with a timestamp aren't
+	// mistaken for it
+	opts := LogsOptions{Tail: "all", Follow: true, Stdout: true, Stderr: true, Timestamps: true}
+	resp, err := c.do("GET", "/containers/"+id+"/logs?"+queryString(opts), doOptions{context: ctx})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return "", &NoSuchContainer{ID: id}
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+	rawTerminal := container.Config != nil && container.Config.Tty
+	err = demuxLogs(resp.Body, rawTerminal, func(entries []LogEntry) error {
+		for _, entry := range entries {
+			if pattern.MatchString(entry.Message) {
+				matchedLine = entry.Message
+				return errStopLogs
+			}
+		}
+		return nil
+	})
+	if err == errStopLogs {
+		return matchedLine, nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", err
+	}
+	return "", ErrPatternNotFound
+}
+
+// demuxLogs reads the logs in r, multiplexed unless rawTerminal is set,
+// calling fn with the complete log entries read so far whenever a chunk of
+// the stream is read.
+func demuxLogs(r io.Reader, rawTerminal bool, fn func([]LogEntry) error) error {
+	parser := logEntryParser{trimCR: rawTerminal}
+	emit := func() error {
+		entries := parser.entries
+		parser.entries = nil
+		if len(entries) == 0 {
+			return nil
+		}
+		return fn(entries)
+	}
+	if rawTerminal {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				parser.write("stdout", buf[:n])
+				if fnErr := emit(); fnErr != nil {
+					return fnErr
+				}
+			}
+			if err == io.EOF {
+				parser.flush()
+				return emit()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		streamType, size, err := ReadStreamHeader(r)
+		if err != nil {
+			if err == io.EOF {
+				parser.flush()
+				return emit()
+			}
+			return err
+		}
+		stream := "stdout"
+		if streamType == StreamStderr {
+			stream = "stderr"
+		}
+		frame := make([]byte, size)
+		if _, err = io.ReadFull(r, frame); err != nil {
+			return err
+		}
+		parser.write(stream, frame)
+		if err := emit(); err != nil {
+			return err
+		}
+	}
+}
+
+// logEntryParser splits the logs of each stream in lines, turning them into
+// log entries. When trimCR is set, as for containers with a TTY, the
+// carriage returns ending the lines are dropped.
+type logEntryParser struct {
+	entries []LogEntry
+	partial map[string][]byte
+	trimCR  bool
+}
+
+func (p *logEntryParser) write(stream string, data []byte) {
+	if p.partial == nil {
+		p.partial = make(map[string][]byte)
+	}
+	data = append(p.partial[stream], data...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		p.add(stream, data[:i])
+		data = data[i+1:]
+	}
+	p.partial[stream] = data
+}
+
+func (p *logEntryParser) add(stream string, line []byte) {
+	if p.trimCR {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	entry := LogEntry{Stream: stream, Message: string(line)}
+	if i := bytes.IndexByte(line, ' '); i > 0 {
+		if ts, err := time.Parse(time.RFC3339Nano, string(line[:i])); err == nil {
+			entry.Timestamp = ts
+			entry.Message = string(line[i+1:])
+		}
+	}
+	p.entries = append(p.entries, entry)
+}
+
+// flush adds the lines not terminated by a newline and returns the entries.
+func (p *logEntryParser) flush() []LogEntry {
+	for _, stream := range []string{"stdout", "stderr"} {
+		if len(p.partial[stream]) > 0 {
+			p.add(stream, p.partial[stream])
+			p.partial[stream] = nil
+		}
+	}
+	return p.entries
+}
+
 // ResizeContainerTTY resizes the terminal to the given height and width.
 //
 // See https://goo.gl/FImjeq for more details.
@@ -1598,6 +3261,11 @@ func (err *NoSuchContainer) Error() string {
 	return "No such container: " + err.ID
 }
 
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchContainer) Unwrap() error {
+	return err.Err
+}
+
 // ContainerAlreadyRunning is the error returned when a given container is
 // already running.
 type ContainerAlreadyRunning struct {
@@ -1617,3 +3285,30 @@ type ContainerNotRunning struct {
 func (err *ContainerNotRunning) Error() string {
 	return "Container not running: " + err.ID
 }
+
+// UnsupportedStorageDriver is the error returned by GetContainerRootFSPath
+// when the storage driver of the container doesn't report where its root
+// filesystem is mounted.
+type UnsupportedStorageDriver struct {
+	Driver string
+}
+
+func (err *UnsupportedStorageDriver) Error() string {
+	return "Unsupported storage driver: " + err.Driver
+}
+
+// ContainerNotStopped is the error returned by RestartWithEnv when the
+// container can't be stopped, wrapping the error returned by the daemon.
+type ContainerNotStopped struct {
+	ID  string
+	Err error
+}
+
+func (err *ContainerNotStopped) Error() string {
+	return "Container could not be stopped: " + err.ID + ": " + err.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (err *ContainerNotStopped) Unwrap() error {
+	return err.Err
+}
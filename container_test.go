@@ -8,9 +8,12 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -18,8 +21,11 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -812,6 +818,76 @@ func TestInspectContainerNotFound(t *testing.T) {
 	}
 }
 
+func TestInspectContainerRaw(t *testing.T) {
+	t.Parallel()
+	jsonContainer := `{"Id": "4fa6e0f0c678", "Name": "/web", "Platform": "linux"}`
+	fakeRT := &FakeRoundTripper{message: jsonContainer, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	container, raw, err := client.InspectContainerRaw("4fa6e0f0c678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.ID != "4fa6e0f0c678" || container.Name != "/web" {
+		t.Errorf("InspectContainerRaw: wrong container. Got %#v.", container)
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["Platform"] != "linux" {
+		t.Errorf("InspectContainerRaw: raw payload missing unmodeled field. Got %s.", raw)
+	}
+	expectedURL, _ := url.Parse(client.getURL("/containers/4fa6e0f0c678/json"))
+	if gotPath := fakeRT.requests[0].URL.Path; gotPath != expectedURL.Path {
+		t.Errorf("InspectContainerRaw: Wrong path in request. Want %q. Got %q.", expectedURL.Path, gotPath)
+	}
+}
+
+func TestInspectContainerRawNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: 404})
+	container, raw, err := client.InspectContainerRaw("abe033")
+	if container != nil || raw != nil {
+		t.Errorf("InspectContainerRaw: Expected <nil> container and payload, got %#v and %s", container, raw)
+	}
+	expected := &NoSuchContainer{ID: "abe033"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("InspectContainerRaw: Wrong error information. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestGetContainerWritableLayerSize(t *testing.T) {
+	t.Parallel()
+	jsonContainer := `{"Id": "4fa6e0f0c678", "SizeRw": 1024, "SizeRootFs": 4096}`
+	fakeRT := &FakeRoundTripper{message: jsonContainer, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	size, err := client.GetContainerWritableLayerSize("4fa6e0f0c678", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 1024 {
+		t.Errorf("GetContainerWritableLayerSize: wrong size. Want %d. Got %d.", 1024, size)
+	}
+	req := fakeRT.requests[0]
+	expectedURL, _ := url.Parse(client.getURL("/containers/4fa6e0f0c678/json"))
+	if gotPath := req.URL.Path; gotPath != expectedURL.Path {
+		t.Errorf("GetContainerWritableLayerSize: Wrong path in request. Want %q. Got %q.", expectedURL.Path, gotPath)
+	}
+	if size := req.URL.Query().Get("size"); size != "1" {
+		t.Errorf("GetContainerWritableLayerSize: Wrong size parameter. Want %q. Got %q.", "1", size)
+	}
+}
+
+func TestGetContainerWritableLayerSizeNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: 404})
+	_, err := client.GetContainerWritableLayerSize("abe033", nil)
+	expected := &NoSuchContainer{ID: "abe033"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerWritableLayerSize: Wrong error information. Want %#v. Got %#v.", expected, err)
+	}
+}
+
 func TestContainerChanges(t *testing.T) {
 	t.Parallel()
 	jsonChanges := `[
@@ -976,6 +1052,46 @@ func TestCreateContainerWithHostConfig(t *testing.T) {
 	}
 }
 
+func TestCreateContainerWithDeviceCgroupRules(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	hostConfig := HostConfig{DeviceCgroupRules: []string{"c 13:* rmw", "b 8:0 r"}}
+	opts := CreateContainerOptions{Config: &Config{}, HostConfig: &hostConfig}
+	if _, err := client.CreateContainer(opts); err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(fakeRT.requests[0].Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `"DeviceCgroupRules":["c 13:* rmw","b 8:0 r"]`
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("CreateContainer: wrong body. Want %s in %s.", expected, body)
+	}
+}
+
+func TestCreateContainerWithCgroupOptions(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	swappiness := int64(0)
+	hostConfig := HostConfig{CgroupnsMode: "private", MemorySwappiness: &swappiness}
+	opts := CreateContainerOptions{Config: &Config{}, HostConfig: &hostConfig}
+	if _, err := client.CreateContainer(opts); err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(fakeRT.requests[0].Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"CgroupnsMode":"private"`, `"MemorySwappiness":0`} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("CreateContainer: wrong body. Want %s in %s.", expected, body)
+		}
+	}
+}
+
 func TestUpdateContainer(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -1434,6 +1550,192 @@ func TestResizeContainerTTY(t *testing.T) {
 	}
 }
 
+func newRunContainerServer(t *testing.T, wait http.HandlerFunc) (*httptest.Server, func() []string) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			mu.Lock()
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/containers/create":
+			w.Write([]byte(`{"Id":"c1"}`))
+		case "/containers/c1/json":
+			w.Write([]byte(`{"Id":"c1","Config":{"Tty":false},"State":{"Running":true}}`))
+		case "/containers/c1/attach":
+			w.WriteHeader(http.StatusOK)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Write([]byte(logFrame(1, "hello\n") + logFrame(2, "oops\n")))
+			conn.Close()
+		case "/containers/c1/wait":
+			wait(w, r)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestRunContainer(t *testing.T) {
+	t.Parallel()
+	server, requests := newRunContainerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"StatusCode":3}`))
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	result, err := client.RunContainer(RunOptions{
+		Config:        &Config{Image: "busybox", Cmd: []string{"false"}},
+		CaptureOutput: true,
+		AutoRemove:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &RunResult{ContainerID: "c1", ExitCode: 3, Stdout: []byte("hello\n"), Stderr: []byte("oops\n")}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("RunContainer: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+	expectedRequests := []string{
+		"POST /containers/create",
+		"POST /containers/c1/attach",
+		"POST /containers/c1/start",
+		"POST /containers/c1/wait",
+		"DELETE /containers/c1",
+	}
+	if got := requests(); !reflect.DeepEqual(got, expectedRequests) {
+		t.Errorf("RunContainer: wrong requests. Want %#v. Got %#v.", expectedRequests, got)
+	}
+}
+
+func TestRunContainerTimeout(t *testing.T) {
+	t.Parallel()
+	server, requests := newRunContainerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	_, err = client.RunContainer(RunOptions{
+		Config:     &Config{Image: "busybox", Cmd: []string{"sleep", "60"}},
+		AutoRemove: true,
+		Timeout:    50 * time.Millisecond,
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("RunContainer: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+	expectedRequests := []string{
+		"POST /containers/create",
+		"POST /containers/c1/start",
+		"POST /containers/c1/wait",
+		"POST /containers/c1/kill",
+		"DELETE /containers/c1",
+	}
+	if got := requests(); !reflect.DeepEqual(got, expectedRequests) {
+		t.Errorf("RunContainer: wrong requests. Want %#v. Got %#v.", expectedRequests, got)
+	}
+}
+
+func TestAttachAndWait(t *testing.T) {
+	t.Parallel()
+	server, requests := newRunContainerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// the container exits after the attach connection is closed
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"StatusCode":2}`))
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var stdout, stderr bytes.Buffer
+	exitCode, err := client.AttachAndWait("c1", &stdout, &stderr, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 2 {
+		t.Errorf("AttachAndWait: wrong exit code. Want 2. Got %d.", exitCode)
+	}
+	if stdout.String() != "hello\n" || stderr.String() != "oops\n" {
+		t.Errorf("AttachAndWait: wrong output. Want %q and %q. Got %q and %q.", "hello\n", "oops\n", stdout.String(), stderr.String())
+	}
+	expectedRequests := []string{
+		"GET /containers/c1/json",
+		"POST /containers/c1/attach",
+		"POST /containers/c1/wait",
+	}
+	if got := requests(); !reflect.DeepEqual(got, expectedRequests) {
+		t.Errorf("AttachAndWait: wrong requests. Want %#v. Got %#v.", expectedRequests, got)
+	}
+}
+
+func TestAttachAndWaitNilContext(t *testing.T) {
+	t.Parallel()
+	server, _ := newRunContainerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"StatusCode":0}`))
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	exitCode, err := client.AttachAndWait("c1", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("AttachAndWait: wrong exit code. Want 0. Got %d.", exitCode)
+	}
+}
+
+func TestAttachAndWaitContextCancel(t *testing.T) {
+	t.Parallel()
+	server, _ := newRunContainerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	exitCode, err := client.AttachAndWait("c1", nil, nil, ctx)
+	if exitCode != -1 || err != context.DeadlineExceeded {
+		t.Errorf("AttachAndWait: wrong result. Want -1, %#v. Got %d, %#v.", context.DeadlineExceeded, exitCode, err)
+	}
+}
+
+func TestAttachAndWaitNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.AttachAndWait("c2", nil, nil, context.Background())
+	if expected := (&NoSuchContainer{ID: "c2"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("AttachAndWait: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
 func TestWaitContainer(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: `{"StatusCode": 56}`, status: http.StatusOK}
@@ -1539,6 +1841,16 @@ func TestCommitContainerParams(t *testing.T) {
 			map[string][]string{"container": {"44c004db4b17"}},
 			json,
 		},
+		{
+			CommitContainerOptions{Container: "44c004db4b17", Changes: []string{`CMD ["python", "app.py"]`, "ENV PORT=8080", "EXPOSE 8080", "LABEL version=1.0"}},
+			map[string][]string{"container": {"44c004db4b17"}, "changes": {`CMD ["python", "app.py"]`, "ENV PORT=8080", "EXPOSE 8080", "LABEL version=1.0"}},
+			nil,
+		},
+		{
+			CommitContainerOptions{Container: "44c004db4b17", NoPause: true},
+			map[string][]string{"container": {"44c004db4b17"}, "pause": {"0"}},
+			nil,
+		},
 	}
 	const expectedPath = "/commit"
 	for _, tt := range tests {
@@ -2171,6 +2483,54 @@ func TestUploadToContainer(t *testing.T) {
 
 }
 
+func TestStatContainerPath(t *testing.T) {
+	t.Parallel()
+	var req *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		switch {
+		case r.URL.Path == "/containers/web/archive" && r.URL.Query().Get("path") == "/etc":
+			stat := `{"name":"etc","size":4096,"mode":2147484141,"mtime":"2019-04-02T10:20:30Z","linkTarget":""}`
+			w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString([]byte(stat)))
+		case r.URL.Path == "/containers/web/json":
+			json.NewEncoder(w).Encode(Container{ID: "web"})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := client.StatContainerPath("web", "/etc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ContainerPathStat{
+		Name:  "etc",
+		Size:  4096,
+		Mode:  os.ModeDir | 0755,
+		Mtime: time.Date(2019, 4, 2, 10, 20, 30, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(stat, expected) {
+		t.Errorf("StatContainerPath: wrong stat. Want %#v. Got %#v.", expected, stat)
+	}
+	if !stat.Mode.IsDir() {
+		t.Error("StatContainerPath: expected a directory")
+	}
+	if req.Method != "HEAD" {
+		t.Errorf("StatContainerPath: wrong method. Want %q. Got %q.", "HEAD", req.Method)
+	}
+	if _, err = client.StatContainerPath("web", "/missing"); err != ErrContainerPathNotFound {
+		t.Errorf("StatContainerPath: wrong error. Want %#v. Got %#v.", ErrContainerPathNotFound, err)
+	}
+	_, err = client.StatContainerPath("db", "/etc")
+	if expectedErr := (&NoSuchContainer{ID: "db"}); !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("StatContainerPath: wrong error. Want %#v. Got %#v.", expectedErr, err)
+	}
+}
+
 func TestDownloadFromContainer(t *testing.T) {
 	t.Parallel()
 	filecontent := "File content"
@@ -2359,6 +2719,75 @@ func TestTopContainer(t *testing.T) {
 	}
 }
 
+func TestTopResultProcessList(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		result   TopResult
+		expected []Process
+	}{
+		{
+			"default",
+			TopResult{
+				Titles:    []string{"UID", "PID", "PPID", "C", "STIME", "TTY", "TIME", "CMD"},
+				Processes: [][]string{{"root", "3087", "815", "0", "01:44", "?", "00:00:01", "sleep 60"}},
+			},
+			[]Process{{
+				PID: 3087, PPID: 815, User: "root", TTY: "?", StartTime: "01:44", Time: "00:00:01", Command: "sleep 60",
+				Fields: map[string]string{"UID": "root", "PID": "3087", "PPID": "815", "C": "0", "STIME": "01:44", "TTY": "?", "TIME": "00:00:01", "CMD": "sleep 60"},
+			}},
+		},
+		{
+			"aux",
+			TopResult{
+				Titles:    []string{"USER", "PID", "%CPU", "%MEM", "COMMAND"},
+				Processes: [][]string{{"nobody", "1", "0.5", "1.0", "nginx"}},
+			},
+			[]Process{{
+				PID: 1, User: "nobody", Command: "nginx",
+				Fields: map[string]string{"USER": "nobody", "PID": "1", "%CPU": "0.5", "%MEM": "1.0", "COMMAND": "nginx"},
+			}},
+		},
+		{
+			"windows",
+			TopResult{
+				Titles:    []string{"Name", "PID", "CPU", "Private Working Set"},
+				Processes: [][]string{{"cmd.exe", "4", "00:00:00.125", "1.5MB"}},
+			},
+			[]Process{{
+				PID: 4, Command: "cmd.exe",
+				Fields: map[string]string{"Name": "cmd.exe", "PID": "4", "CPU": "00:00:00.125", "Private Working Set": "1.5MB"},
+			}},
+		},
+	}
+	for _, test := range tests {
+		processes, err := test.result.ProcessList()
+		if err != nil {
+			t.Errorf("ProcessList(%s): unexpected error: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(processes, test.expected) {
+			t.Errorf("ProcessList(%s): wrong result.\nWant %#v.\nGot  %#v.", test.name, test.expected, processes)
+		}
+	}
+}
+
+func TestTopResultProcessListErrors(t *testing.T) {
+	t.Parallel()
+	result := TopResult{Titles: []string{"PID", "RSS"}, Processes: [][]string{{"1", "1024"}}}
+	if _, err := result.ProcessList(); err != ErrUnrecognizedTopTitles {
+		t.Errorf("ProcessList: wrong error. Want %#v. Got %#v.", ErrUnrecognizedTopTitles, err)
+	}
+	result = TopResult{Titles: []string{"PID", "CMD"}, Processes: [][]string{{"one", "sh"}}}
+	if _, err := result.ProcessList(); err == nil {
+		t.Error("ProcessList: unexpected <nil> error for an invalid PID")
+	}
+	result = TopResult{Titles: []string{"PID", "CMD"}, Processes: [][]string{{"1"}}}
+	if _, err := result.ProcessList(); err == nil {
+		t.Error("ProcessList: unexpected <nil> error for a short row")
+	}
+}
+
 func TestTopContainerNotFound(t *testing.T) {
 	t.Parallel()
 	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
@@ -2732,6 +3161,33 @@ func TestRenameContainer(t *testing.T) {
 	}
 }
 
+func TestRenameContainerErrors(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		status   int
+		expected error
+	}{
+		{"-invalid", http.StatusNoContent, ErrInvalidContainerName},
+		{"a", http.StatusNoContent, ErrInvalidContainerName},
+		{"with space", http.StatusNoContent, ErrInvalidContainerName},
+		{"", http.StatusNoContent, ErrInvalidContainerName},
+		{"taken", http.StatusConflict, ErrContainerAlreadyExists},
+		{"new_name", http.StatusNotFound, &NoSuchContainer{ID: "old"}},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "error", status: test.status}
+		client := newTestClient(fakeRT)
+		err := client.RenameContainer(RenameContainerOptions{ID: "old", Name: test.name})
+		if !reflect.DeepEqual(err, test.expected) {
+			t.Errorf("RenameContainer(%q): wrong error. Want %#v. Got %#v.", test.name, test.expected, err)
+		}
+		if test.expected == ErrInvalidContainerName && len(fakeRT.requests) > 0 {
+			t.Errorf("RenameContainer(%q): unexpected request to the daemon.", test.name)
+		}
+	}
+}
+
 // sleepyRoundTripper implements the http.RoundTripper interface. It sleeps
 // for the 'sleep' duration and then returns an error for RoundTrip method.
 type sleepyRoudTripper struct {
@@ -2826,3 +3282,1236 @@ func TestPruneContainers(t *testing.T) {
 		t.Errorf("PruneContainers: Expected %#v. Got %#v.", expected, got)
 	}
 }
+
+func TestGetContainerRootFSPath(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name        string
+		graphDriver string
+		expected    string
+		err         error
+	}{
+		{
+			"overlay2",
+			`{"Name":"overlay2","Data":{"LowerDir":"/var/lib/docker/overlay2/abc-init/diff","MergedDir":"/var/lib/docker/overlay2/abc/merged","UpperDir":"/var/lib/docker/overlay2/abc/diff"}}`,
+			"/var/lib/docker/overlay2/abc/merged",
+			nil,
+		},
+		{
+			"zfs",
+			`{"Name":"zfs","Data":{"Dataset":"zroot/docker/abc","Mountpoint":"/var/lib/docker/zfs/graph/abc"}}`,
+			"/var/lib/docker/zfs/graph/abc",
+			nil,
+		},
+		{
+			"not mounted",
+			`{"Name":"overlay2","Data":{"LowerDir":"/var/lib/docker/overlay2/abc-init/diff"}}`,
+			"",
+			ErrRootFSPathUnavailable,
+		},
+		{
+			"windowsfilter",
+			`{"Name":"windowsfilter","Data":{"dir":"C:\\ProgramData\\docker\\windowsfilter\\abc"}}`,
+			`C:\ProgramData\docker\windowsfilter\abc`,
+			nil,
+		},
+		{
+			"devicemapper",
+			`{"Name":"devicemapper","Data":{"DeviceId":"12","DeviceName":"docker-253:0-abc","DeviceSize":"10737418240"}}`,
+			"",
+			&UnsupportedStorageDriver{Driver: "devicemapper"},
+		},
+		{
+			"vfs",
+			`{"Name":"vfs","Data":null}`,
+			"",
+			&UnsupportedStorageDriver{Driver: "vfs"},
+		},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			jsonContainer := `{"Id":"4fa6e0f0c678","GraphDriver":` + test.graphDriver + `}`
+			client := newTestClient(&FakeRoundTripper{message: jsonContainer, status: http.StatusOK})
+			client.endpointURL, _ = parseEndpoint("unix:///var/run/docker.sock", false)
+			path, err := client.GetContainerRootFSPath("4fa6e0f0c678", context.Background())
+			if !reflect.DeepEqual(err, test.err) {
+				t.Fatalf("GetContainerRootFSPath: wrong error. Want %#v. Got %#v.", test.err, err)
+			}
+			if path != test.expected {
+				t.Errorf("GetContainerRootFSPath: wrong path. Want %q. Got %q.", test.expected, path)
+			}
+		})
+	}
+}
+
+func TestGetContainerRootFSPathRemoteDaemon(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"4fa6e0f0c678"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	_, err := client.GetContainerRootFSPath("4fa6e0f0c678", context.Background())
+	if err != ErrRootFSPathUnavailable {
+		t.Errorf("GetContainerRootFSPath: wrong error. Want %#v. Got %#v.", ErrRootFSPathUnavailable, err)
+	}
+	if len(fakeRT.requests) != 0 {
+		t.Errorf("GetContainerRootFSPath: unexpected requests to a remote daemon: %d", len(fakeRT.requests))
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	t.Parallel()
+	env := []string{"PATH=/usr/bin:/bin", "PORT=8080", "DEBUG"}
+	got := mergeEnv(env, map[string]string{"PORT": "9090", "WORKERS": "4", "APP": "web"})
+	expected := []string{"PATH=/usr/bin:/bin", "PORT=9090", "DEBUG", "APP=web", "WORKERS=4"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("mergeEnv: wrong env. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestGetContainerStatsOnce(t *testing.T) {
+	t.Parallel()
+	jsonStats := `{
+		"read": "2015-01-08T22:57:31.547920715Z",
+		"networks": {
+			"eth0": {"rx_bytes": 5338, "rx_packets": 36, "tx_bytes": 648, "tx_packets": 8},
+			"eth1": {"rx_bytes": 1024, "rx_packets": 4, "tx_bytes": 512, "tx_packets": 2}
+		},
+		"memory_stats": {"usage": 6537216, "limit": 67108864}
+	}`
+	fakeRT := &FakeRoundTripper{message: jsonStats, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	stats, err := client.GetContainerStatsOnce("4fa6e0f0c678", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MemoryStats.Usage != 6537216 {
+		t.Errorf("GetContainerStatsOnce: wrong memory usage. Want %d. Got %d.", 6537216, stats.MemoryStats.Usage)
+	}
+	req := fakeRT.requests[0]
+	if req.URL.Path != "/containers/4fa6e0f0c678/stats" || req.URL.Query().Get("stream") != "false" {
+		t.Errorf("GetContainerStatsOnce: wrong request. Got %s.", req.URL)
+	}
+	networkStats, err := client.GetContainerNetworkStats("4fa6e0f0c678", "eth1", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := NetworkStats{RxBytes: 1024, RxPackets: 4, TxBytes: 512, TxPackets: 2}
+	if *networkStats != expected {
+		t.Errorf("GetContainerNetworkStats: wrong stats. Want %#v. Got %#v.", expected, *networkStats)
+	}
+	if _, err = client.GetContainerNetworkStats("4fa6e0f0c678", "eth2", context.Background()); err != ErrInterfaceNotFound {
+		t.Errorf("GetContainerNetworkStats: wrong error. Want %#v. Got %#v.", ErrInterfaceNotFound, err)
+	}
+	names, err := client.ListContainerNetworkInterfaces("4fa6e0f0c678", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedNames := []string{"eth0", "eth1"}; !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("ListContainerNetworkInterfaces: wrong names. Want %#v. Got %#v.", expectedNames, names)
+	}
+}
+
+func TestGetContainerTimingRestarted(t *testing.T) {
+	t.Parallel()
+	startedAt := time.Now().Add(-time.Hour).UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/web/json":
+			json.NewEncoder(w).Encode(Container{
+				ID:      "web",
+				Created: startedAt.Add(-time.Hour),
+				State: State{
+					Running:    true,
+					StartedAt:  startedAt,
+					FinishedAt: startedAt.Add(-time.Minute),
+				},
+			})
+		case "/containers/web/stats":
+			w.Write([]byte(`{"cpu_stats": {"cpu_usage": {"total_usage": 2000000000}}}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timing, err := client.GetContainerTiming("web", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !timing.StartedAt.Equal(startedAt) || !timing.CreatedAt.Equal(startedAt.Add(-time.Hour)) {
+		t.Errorf("GetContainerTiming: wrong timestamps. Got %#v.", timing)
+	}
+	if !timing.FinishedAt.IsZero() {
+		t.Errorf("GetContainerTiming: expected no FinishedAt for the current run. Got %s.", timing.FinishedAt)
+	}
+	if timing.Uptime < time.Hour {
+		t.Errorf("GetContainerTiming: wrong uptime. Want at least %s. Got %s.", time.Hour, timing.Uptime)
+	}
+	if timing.TotalCPUTime != 2*time.Second {
+		t.Errorf("GetContainerTiming: wrong CPU time. Want %s. Got %s.", 2*time.Second, timing.TotalCPUTime)
+	}
+	_, err = client.GetContainerTiming("db", context.Background())
+	if expected := (&NoSuchContainer{ID: "db"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerTiming: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestGetContainerCFSQuota(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/limited/json":
+			json.NewEncoder(w).Encode(Container{ID: "limited", HostConfig: &HostConfig{CPUQuota: 25000, CPUPeriod: 50000}})
+		case "/containers/unlimited/json":
+			json.NewEncoder(w).Encode(Container{ID: "unlimited", HostConfig: &HostConfig{}})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quota, period, err := client.GetContainerCFSQuota("limited", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota != 25000 || period != 50000 {
+		t.Errorf("GetContainerCFSQuota: wrong quota and period. Want 25000 and 50000. Got %d and %d.", quota, period)
+	}
+	if percent := CPUQuotaPercent(quota, period); percent != 50 {
+		t.Errorf("CPUQuotaPercent: wrong percentage. Want 50. Got %g.", percent)
+	}
+	quota, period, err = client.GetContainerCFSQuota("unlimited", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota != -1 || period != 100000 {
+		t.Errorf("GetContainerCFSQuota: wrong defaults. Want -1 and 100000. Got %d and %d.", quota, period)
+	}
+	if percent := CPUQuotaPercent(quota, period); percent != 0 {
+		t.Errorf("CPUQuotaPercent: wrong percentage without quota. Want 0. Got %g.", percent)
+	}
+	if percent := CPUQuotaPercent(150000, 100000); percent != 150 {
+		t.Errorf("CPUQuotaPercent: wrong percentage. Want 150. Got %g.", percent)
+	}
+	_, _, err = client.GetContainerCFSQuota("missing", context.Background())
+	if expected := (&NoSuchContainer{ID: "missing"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerCFSQuota: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestGetContainerCFSQuotaNanoCPUs(t *testing.T) {
+	t.Parallel()
+	body := `{"Id":"limited","HostConfig":{"NanoCpus":1500000000,"CpuQuota":0,"CpuPeriod":0}}`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	quota, period, err := client.GetContainerCFSQuota("limited", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota != 150000 || period != 100000 {
+		t.Errorf("GetContainerCFSQuota: wrong quota and period. Want 150000 and 100000. Got %d and %d.", quota, period)
+	}
+}
+
+func TestGetContainerStatsOnceNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.GetContainerStatsOnce("a2334", context.Background())
+	expected := &NoSuchContainer{ID: "a2334"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerStatsOnce: Wrong error returned. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestInspectContainerHealth(t *testing.T) {
+	t.Parallel()
+	jsonContainer := `{
+  "Id": "4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2",
+  "State": {
+    "Status": "running",
+    "Running": true,
+    "Health": {
+      "Status": "unhealthy",
+      "FailingStreak": 2,
+      "Log": [
+        {
+          "Start": "2019-01-10T13:20:01.5Z",
+          "End": "2019-01-10T13:20:02Z",
+          "ExitCode": 1,
+          "Output": "connection refused"
+        }
+      ]
+    }
+  }
+}`
+	client := newTestClient(&FakeRoundTripper{message: jsonContainer, status: http.StatusOK})
+	container, err := client.InspectContainer("4fa6e0f0c678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := HealthState{
+		Status:        Unhealthy,
+		FailingStreak: 2,
+		Log: []HealthCheckResult{
+			{
+				Start:    time.Date(2019, 1, 10, 13, 20, 1, 5e8, time.UTC),
+				End:      time.Date(2019, 1, 10, 13, 20, 2, 0, time.UTC),
+				ExitCode: 1,
+				Output:   "connection refused",
+			},
+		},
+	}
+	if !reflect.DeepEqual(container.State.Health, expected) {
+		t.Errorf("InspectContainer: wrong health. Want %#v. Got %#v.", expected, container.State.Health)
+	}
+}
+
+func TestGetContainerPortInfo(t *testing.T) {
+	t.Parallel()
+	jsonContainer := `{
+  "Id": "4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2",
+  "Config": {
+    "ExposedPorts": {"80/tcp": {}, "443/tcp": {}, "53/udp": {}, "8080/tcp": {}}
+  },
+  "NetworkSettings": {
+    "Ports": {
+      "80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "32768"}],
+      "443/tcp": null,
+      "53/udp": [{"HostIp": "127.0.0.1", "HostPort": "5353"}],
+      "8080/tcp": null
+    }
+  }
+}`
+	fakeRT := &FakeRoundTripper{message: jsonContainer, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	info, err := client.GetContainerPortInfo("4fa6e0f0c678", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &PortInfo{
+		ExposedPorts: []Port{"80/tcp", "443/tcp", "8080/tcp", "53/udp"},
+		BoundPorts: map[Port][]PortBinding{
+			"80/tcp": {{HostIP: "0.0.0.0", HostPort: "32768"}},
+			"53/udp": {{HostIP: "127.0.0.1", HostPort: "5353"}},
+		},
+		UnboundExposedPorts: []Port{"443/tcp", "8080/tcp"},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("GetContainerPortInfo: wrong port info.\nWant %#v.\nGot  %#v.", expected, info)
+	}
+	if path := fakeRT.requests[0].URL.Path; path != "/containers/4fa6e0f0c678/json" {
+		t.Errorf("GetContainerPortInfo: wrong path. Want %q. Got %q.", "/containers/4fa6e0f0c678/json", path)
+	}
+}
+
+func TestGetContainerPortInfoNoPorts(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: `{"Id": "4fa6e0f0c678", "Config": {}}`, status: http.StatusOK})
+	info, err := client.GetContainerPortInfo("4fa6e0f0c678", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.ExposedPorts) != 0 || len(info.BoundPorts) != 0 || len(info.UnboundExposedPorts) != 0 {
+		t.Errorf("GetContainerPortInfo: expected no ports. Got %#v.", info)
+	}
+}
+
+func TestGetContainerPortInfoNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.GetContainerPortInfo("a2344", context.Background())
+	expected := &NoSuchContainer{ID: "a2344"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerPortInfo: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func logFrame(stream byte, data string) string {
+	header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	return string(header) + data
+}
+
+func TestGetContainerLogsStructured(t *testing.T) {
+	t.Parallel()
+	logs := logFrame(1, "2019-01-10T13:20:01.123456789Z server started\n") +
+		logFrame(2, "2019-01-10T13:20:02Z warning: ") +
+		logFrame(1, "2019-01-10T13:20:03Z listening on :8080\n") +
+		logFrame(2, "low memory\n") +
+		logFrame(1, "2019-01-10T13:20:04Z no newline")
+	fakeRT := &FakeRoundTripper{message: logs, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	entries, err := client.GetContainerLogsStructured("a123456", LogsOptions{Follow: true, Tail: "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LogEntry{
+		{Stream: "stdout", Timestamp: time.Date(2019, 1, 10, 13, 20, 1, 123456789, time.UTC), Message: "server started"},
+		{Stream: "stdout", Timestamp: time.Date(2019, 1, 10, 13, 20, 3, 0, time.UTC), Message: "listening on :8080"},
+		{Stream: "stderr", Timestamp: time.Date(2019, 1, 10, 13, 20, 2, 0, time.UTC), Message: "warning: low memory"},
+		{Stream: "stdout", Timestamp: time.Date(2019, 1, 10, 13, 20, 4, 0, time.UTC), Message: "no newline"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("GetContainerLogsStructured: wrong entries.\nWant %#v.\nGot  %#v.", expected, entries)
+	}
+	req := fakeRT.requests[0]
+	if req.URL.Path != "/containers/a123456/logs" {
+		t.Errorf("GetContainerLogsStructured: wrong path. Want %q. Got %q.", "/containers/a123456/logs", req.URL.Path)
+	}
+	expectedQs := map[string][]string{
+		"stdout":     {"1"},
+		"stderr":     {"1"},
+		"timestamps": {"1"},
+		"tail":       {"10"},
+	}
+	if got := map[string][]string(req.URL.Query()); !reflect.DeepEqual(got, expectedQs) {
+		t.Errorf("GetContainerLogsStructured: wrong query string. Want %#v. Got %#v.", expectedQs, got)
+	}
+}
+
+func TestGetContainerLogsStructuredRawTerminal(t *testing.T) {
+	t.Parallel()
+	logs := "2019-01-10T13:20:01Z $ ls\n2019-01-10T13:20:02Z bin etc\n"
+	client := newTestClient(&FakeRoundTripper{message: logs, status: http.StatusOK})
+	entries, err := client.GetContainerLogsStructured("a123456", LogsOptions{RawTerminal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LogEntry{
+		{Stream: "stdout", Timestamp: time.Date(2019, 1, 10, 13, 20, 1, 0, time.UTC), Message: "$ ls"},
+		{Stream: "stdout", Timestamp: time.Date(2019, 1, 10, 13, 20, 2, 0, time.UTC), Message: "bin etc"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("GetContainerLogsStructured: wrong entries.\nWant %#v.\nGot  %#v.", expected, entries)
+	}
+}
+
+func TestGetContainerLogsStructuredTruncatedFrame(t *testing.T) {
+	t.Parallel()
+	logs := logFrame(1, "2019-01-10T13:20:01Z server started\n")
+	client := newTestClient(&FakeRoundTripper{message: logs[:len(logs)-5], status: http.StatusOK})
+	if _, err := client.GetContainerLogsStructured("a123456", LogsOptions{}); err != io.ErrUnexpectedEOF {
+		t.Errorf("GetContainerLogsStructured: wrong error. Want %#v. Got %#v.", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestContainerLogsReader(t *testing.T) {
+	t.Parallel()
+	logs := logFrame(1, "server started\n") + logFrame(2, "warning: low memory\n") + logFrame(1, "listening on :8080\n")
+	fakeRT := &FakeRoundTripper{message: logs, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	r, err := client.ContainerLogsReader("a123456", LogsOptions{Tail: "10"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "server started\nwarning: low memory\nlistening on :8080\n"
+	if string(data) != expected {
+		t.Errorf("ContainerLogsReader: wrong logs. Want %q. Got %q.", expected, string(data))
+	}
+	req := fakeRT.requests[0]
+	if req.URL.Path != "/containers/a123456/logs" {
+		t.Errorf("ContainerLogsReader: wrong path. Want %q. Got %q.", "/containers/a123456/logs", req.URL.Path)
+	}
+	expectedQs := map[string][]string{"stdout": {"1"}, "stderr": {"1"}, "tail": {"10"}}
+	if got := map[string][]string(req.URL.Query()); !reflect.DeepEqual(got, expectedQs) {
+		t.Errorf("ContainerLogsReader: wrong query string. Want %#v. Got %#v.", expectedQs, got)
+	}
+}
+
+func TestMultiLogs(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	queries := map[string]url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries[r.URL.Path] = r.URL.Query()
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/containers/a1b2c3/logs":
+			w.Write([]byte(logFrame(1, "Listening on :8080\n") + logFrame(1, "by") + logFrame(2, "warning: low memory\n") + logFrame(2, "oo") + logFrame(1, "e")))
+		case "/containers/db/logs":
+			w.Write([]byte("ready to accept connections\n"))
+		default:
+			http.Error(w, "no such container", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	specs := []LogSpec{
+		{Container: "a1b2c3", Name: "web", Tail: "10"},
+		{Container: "db", RawTerminal: true},
+		{Container: "cache"},
+	}
+	err = client.MultiLogs(specs, &out, context.Background())
+	if expected := (&NoSuchContainer{ID: "cache"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("MultiLogs: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	sort.Strings(lines)
+	expected := []string{
+		"db    | ready to accept connections",
+		"web   | Listening on :8080",
+		"web   | bye",
+		"web   | oo",
+		"web   | warning: low memory",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("MultiLogs: wrong output.\nWant %#v.\nGot  %#v.", expected, lines)
+	}
+	query := queries["/containers/a1b2c3/logs"]
+	if query.Get("follow") != "1" || query.Get("tail") != "10" {
+		t.Errorf("MultiLogs: wrong query string. Got %#v.", query)
+	}
+	if specs[1].Name != "" {
+		t.Errorf("MultiLogs: the specs were modified: %#v", specs)
+	}
+}
+
+func TestMultiLogsContextCanceled(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(logFrame(1, "started\n")))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r, w := io.Pipe()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.MultiLogs([]LogSpec{{Container: "web"}, {Container: "worker"}}, w, ctx)
+		w.Close()
+	}()
+	reader := bufio.NewReader(r)
+	for i := 0; i < 2; i++ {
+		if _, err = reader.ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	ioutil.ReadAll(reader)
+	if err = <-errs; err != context.Canceled {
+		t.Errorf("MultiLogs: wrong error. Want %#v. Got %#v.", context.Canceled, err)
+	}
+}
+
+func TestContainerLogsReaderSeparateStreams(t *testing.T) {
+	t.Parallel()
+	logs := logFrame(1, "server started\n") + logFrame(2, "warning: low memory\n")
+	client := newTestClient(&FakeRoundTripper{message: logs, status: http.StatusOK})
+	var stderr bytes.Buffer
+	r, err := client.ContainerLogsReader("a123456", LogsOptions{ErrorStream: &stderr}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "server started\n"; string(data) != expected {
+		t.Errorf("ContainerLogsReader: wrong stdout. Want %q. Got %q.", expected, string(data))
+	}
+	if expected := "warning: low memory\n"; stderr.String() != expected {
+		t.Errorf("ContainerLogsReader: wrong stderr. Want %q. Got %q.", expected, stderr.String())
+	}
+}
+
+func TestContainerLogsReaderRawTerminal(t *testing.T) {
+	t.Parallel()
+	logs := "$ ls\r\nbin etc\r\n"
+	client := newTestClient(&FakeRoundTripper{message: logs, status: http.StatusOK})
+	r, err := client.ContainerLogsReader("a123456", LogsOptions{RawTerminal: true}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != logs {
+		t.Errorf("ContainerLogsReader: wrong logs. Want %q. Got %q.", logs, string(data))
+	}
+}
+
+func TestContainerLogsReaderNoContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.ContainerLogsReader("a123456", LogsOptions{}, context.Background())
+	expected := &NoSuchContainer{ID: "a123456"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("ContainerLogsReader: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestGetContainerLogsStructuredNoContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.GetContainerLogsStructured("a123456", LogsOptions{})
+	expected := &NoSuchContainer{ID: "a123456"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerLogsStructured: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+// newAttachEchoServer returns a server that reads the stdin of the attach
+// session until it's closed, and then writes it back to stdout, followed by
+// "done" in stderr.
+func newAttachEchoServer(t *testing.T, req *http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*req = *r
+		w.WriteHeader(http.StatusOK)
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		input, _ := ioutil.ReadAll(buf)
+		header := []byte{1, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(header[4:], uint32(len(input)))
+		conn.Write(header)
+		conn.Write(input)
+		conn.Write([]byte{2, 0, 0, 0, 0, 0, 0, 4})
+		conn.Write([]byte("done"))
+	}))
+}
+
+func TestAttachToContainerStream(t *testing.T) {
+	t.Parallel()
+	var req http.Request
+	server := newAttachEchoServer(t, &req)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	stream, err := client.AttachToContainerStream(AttachToContainerOptions{
+		Container: "a123456",
+		Stdin:     true,
+		Stdout:    true,
+		Stderr:    true,
+		Stream:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if _, err = stream.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.(interface{ CloseWrite() error }).CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	var frames []string
+	for {
+		streamType, size, err := ReadStreamHeader(stream)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload := make([]byte, size)
+		if _, err = io.ReadFull(stream, payload); err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, fmt.Sprintf("%d:%s", streamType, payload))
+	}
+	expected := []string{"1:ping", "2:done"}
+	if !reflect.DeepEqual(frames, expected) {
+		t.Errorf("AttachToContainerStream: wrong frames. Want %#v. Got %#v.", expected, frames)
+	}
+	if req.URL.Path != "/containers/a123456/attach" {
+		t.Errorf("AttachToContainerStream: wrong path. Want %q. Got %q.", "/containers/a123456/attach", req.URL.Path)
+	}
+}
+
+func TestAttachToContainerStreamWithoutStdin(t *testing.T) {
+	t.Parallel()
+	var req http.Request
+	server := newAttachEchoServer(t, &req)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	stream, err := client.AttachToContainerStream(AttachToContainerOptions{
+		Container: "a123456",
+		Stdout:    true,
+		Stream:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if _, err = stream.Write([]byte("ping")); err != ErrStdinNotAttached {
+		t.Errorf("AttachToContainerStream: wrong error. Want %#v. Got %#v.", ErrStdinNotAttached, err)
+	}
+}
+
+func TestAttachToContainerDemux(t *testing.T) {
+	t.Parallel()
+	var req http.Request
+	server := newAttachEchoServer(t, &req)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	stdout, stderr, stdin, err := client.AttachToContainerDemux(AttachToContainerOptions{
+		Container: "a123456",
+		Stdin:     true,
+		Stdout:    true,
+		Stderr:    true,
+		Stream:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stdin.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	stdin.Close()
+	var errOutput []byte
+	errRead := make(chan error, 1)
+	go func() {
+		var err error
+		errOutput, err = ioutil.ReadAll(stderr)
+		errRead <- err
+	}()
+	output, err := ioutil.ReadAll(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-errRead; err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "ping" {
+		t.Errorf("AttachToContainerDemux: wrong stdout. Want %q. Got %q.", "ping", output)
+	}
+	if string(errOutput) != "done" {
+		t.Errorf("AttachToContainerDemux: wrong stderr. Want %q. Got %q.", "done", errOutput)
+	}
+}
+
+func TestAttachToContainerDemuxWithoutContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "", status: http.StatusOK})
+	_, _, _, err := client.AttachToContainerDemux(AttachToContainerOptions{})
+	expected := &NoSuchContainer{ID: ""}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("AttachToContainerDemux: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+// newLogPatternServer returns a server that sends the given frames as the
+// logs of the container c1, waiting delay before each of them. When hold is
+// set, the logs are kept open until the client goes away, which closes the
+// returned channel.
+func newLogPatternServer(tty bool, frames []string, delay time.Duration, hold bool) (*httptest.Server, <-chan struct{}) {
+	gone := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/c1/json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Container{ID: "c1", Config: &Config{Tty: tty}})
+	})
+	mux.HandleFunc("/containers/c1/logs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("follow") != "1" || r.URL.Query().Get("timestamps") != "1" {
+			http.Error(w, "logs not followed with timestamps", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for _, frame := range frames {
+			time.Sleep(delay)
+			w.Write([]byte(frame))
+			w.(http.Flusher).Flush()
+		}
+		if hold {
+			<-r.Context().Done()
+			close(gone)
+		}
+	})
+	return httptest.NewServer(mux), gone
+}
+
+func TestWaitForLogPattern(t *testing.T) {
+	t.Parallel()
+	frames := []string{
+		logFrame(1, "2019-01-10T13:20:01Z starting server\n"),
+		logFrame(2, "2019-01-10T13:20:02Z 2019-01-10 warning: no config\n"),
+		logFrame(1, "2019-01-10T13:20:03Z server started on port 8080 (ready-7c1e)\n"),
+		logFrame(1, "2019-01-10T13:20:04Z server started again\n"),
+	}
+	server, gone := newLogPatternServer(false, frames, 20*time.Millisecond, true)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := client.WaitForLogPattern("c1", regexp.MustCompile(`server started.*ready-7c1e`), 5*time.Second, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "server started on port 8080 (ready-7c1e)"
+	if line != expected {
+		t.Errorf("WaitForLogPattern: wrong line. Want %q. Got %q.", expected, line)
+	}
+	select {
+	case <-gone:
+	case <-time.After(5 * time.Second):
+		t.Error("WaitForLogPattern: logs still followed after the match")
+	}
+}
+
+func TestWaitForLogPatternTTY(t *testing.T) {
+	t.Parallel()
+	server, _ := newLogPatternServer(true, []string{"2019-01-10T13:20:01Z booting\r\n2019-01-10T13:20", ":02Z ready\r\n"}, 0, false)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := client.WaitForLogPattern("c1", regexp.MustCompile(`^ready$`), 0, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "ready" {
+		t.Errorf("WaitForLogPattern: wrong line. Want %q. Got %q.", "ready", line)
+	}
+}
+
+func TestWaitForLogPatternContainerExited(t *testing.T) {
+	t.Parallel()
+	server, _ := newLogPatternServer(false, []string{logFrame(1, "2019-01-10T13:20:01Z starting server\n"), logFrame(2, "2019-01-10T13:20:02Z fatal error\n")}, 0, false)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.WaitForLogPattern("c1", regexp.MustCompile("server started"), 5*time.Second, context.Background())
+	if err != ErrPatternNotFound {
+		t.Errorf("WaitForLogPattern: wrong error. Want %#v. Got %#v.", ErrPatternNotFound, err)
+	}
+}
+
+func TestWaitForLogPatternTimeout(t *testing.T) {
+	t.Parallel()
+	server, _ := newLogPatternServer(false, []string{logFrame(1, "2019-01-10T13:20:01Z starting server\n")}, 0, true)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.WaitForLogPattern("c1", regexp.MustCompile("server started"), 50*time.Millisecond, context.Background())
+	if err != context.DeadlineExceeded {
+		t.Errorf("WaitForLogPattern: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+}
+
+func TestWaitForLogPatternNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.WaitForLogPattern("c1", regexp.MustCompile("ready"), time.Second, context.Background())
+	expected := &NoSuchContainer{ID: "c1"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("WaitForLogPattern: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestUpdateContainerOmitsZeroFields(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	update := UpdateContainerOptions{
+		CPUShares:     512,
+		CPUQuota:      50000,
+		MemorySwap:    -1,
+		RestartPolicy: RestartOnFailure(3),
+		Context:       context.Background(),
+	}
+	if err := client.UpdateContainer("abc123", update); err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"CpuShares":     float64(512),
+		"CpuQuota":      float64(50000),
+		"MemorySwap":    float64(-1),
+		"RestartPolicy": map[string]interface{}{"Name": "on-failure", "MaximumRetryCount": float64(3)},
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("UpdateContainer: wrong body.\nWant %#v.\nGot  %#v.", expected, body)
+	}
+}
+
+func TestUpdateContainerNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	err := client.UpdateContainer("abc123", UpdateContainerOptions{Memory: 1 << 30})
+	expected := &NoSuchContainer{ID: "abc123"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("UpdateContainer: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+// newNamespaceServer returns a server that runs the exec instances created
+// in its containers as ls of their /proc/1/ns directory, with namespaces
+// given by the inodes map, keyed by container and namespace.
+func newNamespaceServer(inodes map[string]uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(parts) == 3 && parts[0] == "containers" && parts[2] == "exec":
+			var opts CreateExecOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			ns := strings.TrimPrefix(opts.Cmd[len(opts.Cmd)-1], "/proc/1/ns/")
+			if _, ok := inodes[parts[1]+"/"+ns]; !ok {
+				http.Error(w, "no such container", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(Exec{ID: parts[1] + "/" + ns})
+		case len(parts) == 4 && parts[0] == "exec" && parts[3] == "start":
+			id := parts[1] + "/" + parts[2]
+			line := fmt.Sprintf("lrwxrwxrwx 1 root root 0 Jan 10 13:20 /proc/1/ns/%s -> %s:[%d]\n", parts[2], parts[2], inodes[id])
+			w.Write([]byte(logFrame(1, line)))
+		case len(parts) == 4 && parts[0] == "exec" && parts[3] == "json":
+			json.NewEncoder(w).Encode(ExecInspect{ID: parts[1] + "/" + parts[2]})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGetContainerNamespaces(t *testing.T) {
+	t.Parallel()
+	server := newNamespaceServer(map[string]uint64{
+		"web/pid":     4026532200,
+		"sidecar/pid": 4026532200,
+		"other/pid":   4026532300,
+		"web/net":     4026532400,
+		"sidecar/net": 4026532500,
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	web, err := client.GetContainerPIDNamespace("web", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar, err := client.GetContainerPIDNamespace("sidecar", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := client.GetContainerPIDNamespace("other", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web != 4026532200 || sidecar != web {
+		t.Errorf("GetContainerPIDNamespace: containers sharing the namespace have different inodes: %d and %d", web, sidecar)
+	}
+	if other == web {
+		t.Errorf("GetContainerPIDNamespace: containers not sharing the namespace have the same inode: %d", other)
+	}
+	netNS, err := client.GetContainerNetNamespace("sidecar", ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if netNS != 4026532500 {
+		t.Errorf("GetContainerNetNamespace: wrong inode. Want %d. Got %d.", 4026532500, netNS)
+	}
+	_, err = client.GetContainerNetNamespace("other", ctx)
+	if expected := (&NoSuchContainer{ID: "other"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerNetNamespace: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+// newFSFreezeServer returns a server that runs the exec instances created in
+// its containers with the exit code given by the exitCodes map, recording
+// their commands.
+func newFSFreezeServer(exitCodes map[string]int, cmds *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(parts) == 3 && parts[0] == "containers" && parts[2] == "exec":
+			var opts CreateExecOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			mu.Lock()
+			*cmds = append(*cmds, parts[1]+": "+strings.Join(opts.Cmd, " "))
+			mu.Unlock()
+			json.NewEncoder(w).Encode(Exec{ID: parts[1]})
+		case len(parts) == 3 && parts[0] == "exec" && parts[2] == "start":
+			if exitCodes[parts[1]] == 1 {
+				w.Write([]byte(logFrame(2, "fsfreeze: /: freeze failed: Operation not permitted\n")))
+			}
+		case len(parts) == 3 && parts[0] == "exec" && parts[2] == "json":
+			json.NewEncoder(w).Encode(ExecInspect{ID: parts[1], ExitCode: exitCodes[parts[1]]})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+}
+
+func TestFreezeAndThawContainerFS(t *testing.T) {
+	t.Parallel()
+	var cmds []string
+	server := newFSFreezeServer(map[string]int{"db": 0}, &cmds)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.FreezeContainerFS("db", context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.ThawContainerFS("db", context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"db: fsfreeze -f /", "db: fsfreeze -u /"}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("FreezeContainerFS: wrong commands. Want %#v. Got %#v.", expected, cmds)
+	}
+}
+
+func TestFreezeContainerFSErrors(t *testing.T) {
+	t.Parallel()
+	var cmds []string
+	server := newFSFreezeServer(map[string]int{"alpine": 127, "busybox": 126, "unprivileged": 1}, &cmds)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"alpine", "busybox"} {
+		if err = client.FreezeContainerFS(id, context.Background()); err != ErrFSFreezeUnsupported {
+			t.Errorf("FreezeContainerFS(%q): wrong error. Want %#v. Got %#v.", id, ErrFSFreezeUnsupported, err)
+		}
+	}
+	err = client.ThawContainerFS("unprivileged", context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Operation not permitted") {
+		t.Errorf("ThawContainerFS: wrong error. Want the output of fsfreeze. Got %#v.", err)
+	}
+}
+
+// createdContainer returns the configuration sent in the request to create a
+// container recorded by fakeRT.
+func createdContainer(t *testing.T, fakeRT *FakeRoundTripper) (Config, HostConfig) {
+	for _, req := range fakeRT.requests {
+		if req.Method != "POST" || req.URL.Path != "/containers/create" {
+			continue
+		}
+		var body struct {
+			Config
+			HostConfig HostConfig
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Config, body.HostConfig
+	}
+	t.Fatal("no container created")
+	return Config{}, HostConfig{}
+}
+
+func TestSetContainerTimezone(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"c1","Name":"/web","Config":{"Env":["PORT=8080"]},"State":{"Running":true}}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	for _, timezone := range []string{"UTC", "Asia/Tokyo"} {
+		fakeRT.Reset()
+		if _, err := client.SetContainerTimezone("web", timezone, context.Background()); err != nil {
+			t.Fatalf("SetContainerTimezone(%q): %s", timezone, err)
+		}
+		config, _ := createdContainer(t, fakeRT)
+		expectedEnv := []string{"PORT=8080", "TZ=" + timezone}
+		if !reflect.DeepEqual(config.Env, expectedEnv) {
+			t.Errorf("SetContainerTimezone(%q): wrong env. Want %#v. Got %#v.", timezone, expectedEnv, config.Env)
+		}
+	}
+	for _, timezone := range []string{"Mars/Olympus_Mons", "", "Local"} {
+		fakeRT.Reset()
+		_, err := client.SetContainerTimezone("web", timezone, context.Background())
+		if err != ErrInvalidTimezone {
+			t.Errorf("SetContainerTimezone(%q): wrong error. Want %#v. Got %#v.", timezone, ErrInvalidTimezone, err)
+		}
+		if len(fakeRT.requests) != 0 {
+			t.Errorf("SetContainerTimezone(%q): container replaced for an invalid timezone", timezone)
+		}
+	}
+}
+
+func TestGetContainerEnvVar(t *testing.T) {
+	t.Parallel()
+	container := Container{ID: "c1", Config: &Config{Env: []string{"PORT=8080", "DEBUG=", "DATABASE_URL=postgres://db/app?sslmode=disable", "PORT_RANGE=8000-9000"}}}
+	body, _ := json.Marshal(container)
+	client := newTestClient(&FakeRoundTripper{message: string(body), status: http.StatusOK})
+	var tests = []struct {
+		key   string
+		value string
+		ok    bool
+	}{
+		{"PORT", "8080", true},
+		{"DEBUG", "", true},
+		{"DATABASE_URL", "postgres://db/app?sslmode=disable", true},
+		{"PORT_RANGE", "8000-9000", true},
+		{"HOME", "", false},
+		{"POR", "", false},
+	}
+	for _, test := range tests {
+		value, ok, err := client.GetContainerEnvVar("c1", test.key, context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != test.value || ok != test.ok {
+			t.Errorf("GetContainerEnvVar(%q): wrong result. Want (%q, %v). Got (%q, %v).", test.key, test.value, test.ok, value, ok)
+		}
+	}
+}
+
+func TestGetContainerEnvVarNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, _, err := client.GetContainerEnvVar("missing", "PORT", context.Background())
+	if expected := (&NoSuchContainer{ID: "missing"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerEnvVar: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestContainerAppArmorProfile(t *testing.T) {
+	t.Parallel()
+	seccomp := `{"defaultAction":"SCMP_ACT_ALLOW"}`
+	container := Container{
+		ID:         "c1",
+		Name:       "/web",
+		Config:     &Config{},
+		HostConfig: &HostConfig{SecurityOpt: []string{"no-new-privileges", "seccomp=" + seccomp}},
+	}
+	body, _ := json.Marshal(container)
+	fakeRT := &FakeRoundTripper{message: string(body), status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	profile, err := client.GetContainerAppArmorProfile("web", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile != "docker-default" {
+		t.Errorf("GetContainerAppArmorProfile: wrong profile. Want %q. Got %q.", "docker-default", profile)
+	}
+	if profile, err = client.GetContainerSeccompProfile("web", context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if profile != seccomp {
+		t.Errorf("GetContainerSeccompProfile: wrong profile. Want %q. Got %q.", seccomp, profile)
+	}
+	if _, err = client.SetContainerAppArmorProfile("web", "restricted", context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, hostConfig := createdContainer(t, fakeRT)
+	expectedOpts := []string{"no-new-privileges", "seccomp=" + seccomp, "apparmor=restricted"}
+	if !reflect.DeepEqual(hostConfig.SecurityOpt, expectedOpts) {
+		t.Errorf("SetContainerAppArmorProfile: wrong security options. Want %#v. Got %#v.", expectedOpts, hostConfig.SecurityOpt)
+	}
+}
+
+func TestGetContainerAppArmorProfileSet(t *testing.T) {
+	t.Parallel()
+	body := `{"Id":"c1","HostConfig":{"SecurityOpt":["apparmor:restricted"]}}`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	profile, err := client.GetContainerAppArmorProfile("c1", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile != "restricted" {
+		t.Errorf("GetContainerAppArmorProfile: wrong profile. Want %q. Got %q.", "restricted", profile)
+	}
+}
+
+func TestSetContainerAppArmorProfileDefault(t *testing.T) {
+	t.Parallel()
+	body := `{"Id":"c1","Name":"/web","Config":{},"HostConfig":{"SecurityOpt":["no-new-privileges","apparmor=restricted"]}}`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if _, err := client.SetContainerAppArmorProfile("web", "", context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, hostConfig := createdContainer(t, fakeRT)
+	expectedOpts := []string{"no-new-privileges"}
+	if !reflect.DeepEqual(hostConfig.SecurityOpt, expectedOpts) {
+		t.Errorf("SetContainerAppArmorProfile: wrong security options. Want %#v. Got %#v.", expectedOpts, hostConfig.SecurityOpt)
+	}
+}
+
+func TestGetContainerAppArmorProfileNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	profile, err := client.GetContainerAppArmorProfile("db", context.Background())
+	if expected := (&NoSuchContainer{ID: "db"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerAppArmorProfile: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	if profile != "" {
+		t.Errorf("GetContainerAppArmorProfile: wrong profile. Want %q. Got %q.", "", profile)
+	}
+}
+
+func TestFreezeContainerFSWaitsForExit(t *testing.T) {
+	t.Parallel()
+	var (
+		mu       sync.Mutex
+		inspects int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/db/exec":
+			json.NewEncoder(w).Encode(Exec{ID: "e1"})
+		case "/exec/e1/start":
+			w.Write([]byte(logFrame(2, "fsfreeze: /: freeze failed: Operation not permitted\n")))
+		case "/exec/e1/json":
+			mu.Lock()
+			inspects++
+			running := inspects < 3
+			mu.Unlock()
+			// the exit code is only known once the command is done
+			if running {
+				json.NewEncoder(w).Encode(ExecInspect{ID: "e1", Running: true})
+				return
+			}
+			json.NewEncoder(w).Encode(ExecInspect{ID: "e1", ExitCode: 1})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.FreezeContainerFS("db", context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Operation not permitted") {
+		t.Errorf("FreezeContainerFS: wrong error. Want the output of fsfreeze. Got %#v.", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if inspects != 3 {
+		t.Errorf("FreezeContainerFS: wrong number of inspects. Want 3. Got %d.", inspects)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/homedir"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultContextName is the name of the Docker context that represents the
+// configuration given by the DOCKER_HOST, DOCKER_TLS_VERIFY and
+// DOCKER_CERT_PATH environment variables.
+const defaultContextName = "default"
+
+// contextMetadata is the content of the meta.json file of a context in the
+// Docker context store.
+type contextMetadata struct {
+	Name      string
+	Endpoints map[string]contextEndpoint
+}
+
+type contextEndpoint struct {
+	Host          string
+	SkipTLSVerify bool
+}
+
+// NewClientFromContext returns a Client instance configured from the given
+// context of the Docker context store, located in the contexts directory of
+// $DOCKER_CONFIG, or of $HOME/.docker. The endpoint and the TLS material of
+// the context are used to build the client. For SSH endpoints, the keys
+// available in the SSH agent are used for authentication, and the host key is
+// checked against ~/.ssh/known_hosts.
+//
+// The "default" context is not in the store: a client is created from the
+// environment variables, as NewClientFromEnv does.
+func NewClientFromContext(contextName string) (*Client, error) {
+	var (
+		client *Client
+		err    error
+	)
+	if contextName == "" || contextName == defaultContextName {
+		client, err = newVersionedClientFromDockerEnv("")
+	} else {
+		client, err = newVersionedClientFromContext(contextName, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	client.SkipServerVersionCheck = true
+	return client, nil
+}
+
+func newVersionedClientFromContext(contextName, apiVersionString string) (*Client, error) {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		home := homedir.Get()
+		if home == "" {
+			return nil, errors.New("environment variable HOME must be set if DOCKER_CONFIG is not set")
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+	return newVersionedClientFromContextStore(filepath.Join(configDir, "contexts"), contextName, apiVersionString)
+}
+
+func newVersionedClientFromContextStore(storeDir, contextName, apiVersionString string) (*Client, error) {
+	// contexts are stored in directories named after the digest of their
+	// names
+	digest := sha256.Sum256([]byte(contextName))
+	id := hex.EncodeToString(digest[:])
+	data, err := ioutil.ReadFile(filepath.Join(storeDir, "meta", id, "meta.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("context %q not found", contextName)
+		}
+		return nil, err
+	}
+	var metadata contextMetadata
+	if err = json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata for context %q: %s", contextName, err)
+	}
+	endpoint, ok := metadata.Endpoints["docker"]
+	if !ok || endpoint.Host == "" {
+		return nil, fmt.Errorf("context %q has no docker endpoint", contextName)
+	}
+	if strings.HasPrefix(endpoint.Host, sshProtocol+"://") {
+		return newVersionedSSHClientFromContext(endpoint.Host, apiVersionString)
+	}
+	tlsDir := filepath.Join(storeDir, "tls", id, "docker")
+	ca, err := readContextTLSFile(tlsDir, "ca.pem")
+	if err != nil {
+		return nil, err
+	}
+	cert, err := readContextTLSFile(tlsDir, "cert.pem")
+	if err != nil {
+		return nil, err
+	}
+	key, err := readContextTLSFile(tlsDir, "key.pem")
+	if err != nil {
+		return nil, err
+	}
+	if ca == nil && cert == nil && key == nil {
+		return NewVersionedClient(endpoint.Host, apiVersionString)
+	}
+	if endpoint.SkipTLSVerify {
+		ca = nil
+	}
+	return NewVersionedTLSClientFromBytes(endpoint.Host, cert, key, ca, apiVersionString)
+}
+
+func readContextTLSFile(dir, name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func newVersionedSSHClientFromContext(endpoint, apiVersionString string) (*Client, error) {
+	config, err := defaultSSHClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewSSHClient(endpoint, config)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(apiVersionString, ".") {
+		client.requestedAPIVersion, err = NewAPIVersion(apiVersionString)
+		if err != nil {
+			return nil, err
+		}
+		client.SkipServerVersionCheck = false
+	}
+	return client, nil
+}
+
+// defaultSSHClientConfig returns an SSH client configuration that mimics the
+// defaults of the ssh command: the current user, the keys available in the
+// SSH agent and the host keys in ~/.ssh/known_hosts.
+func defaultSSHClientConfig() (*ssh.ClientConfig, error) {
+	var config ssh.ClientConfig
+	if u, err := user.Current(); err == nil {
+		config.User = u.Username
+	}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		conn, err := net.Dial(unixProtocol, socket)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to the SSH agent: %s", err)
+		}
+		config.Auth = []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(conn).Signers)}
+	}
+	home := homedir.Get()
+	if home == "" {
+		return nil, errors.New("environment variable HOME must be set to find the SSH known hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, err
+	}
+	config.HostKeyCallback = hostKeyCallback
+	return &config, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestContext(t *testing.T, storeDir, name, meta string, tlsFiles ...string) {
+	digest := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(digest[:])
+	metaDir := filepath.Join(storeDir, "meta", id)
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tlsDir := filepath.Join(storeDir, "tls", id, "docker")
+	for _, file := range tlsFiles {
+		data, err := ioutil.ReadFile(filepath.Join("testing", "data", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.MkdirAll(tlsDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(tlsDir, file), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewClientFromContextStore(t *testing.T) {
+	t.Parallel()
+	storeDir, err := ioutil.TempDir("", "go-dockerclient-contexts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storeDir)
+	writeTestContext(t, storeDir, "local", `{"Name":"local","Metadata":{},"Endpoints":{"docker":{"Host":"unix:///var/run/docker.sock","SkipTLSVerify":false}}}`)
+	writeTestContext(t, storeDir, "plain", `{"Name":"plain","Metadata":{},"Endpoints":{"docker":{"Host":"tcp://10.0.0.1:2375","SkipTLSVerify":false}}}`)
+	writeTestContext(t, storeDir, "secure", `{"Name":"secure","Metadata":{},"Endpoints":{"docker":{"Host":"tcp://10.0.0.2:2376","SkipTLSVerify":false}}}`, "ca.pem", "cert.pem", "key.pem")
+	writeTestContext(t, storeDir, "insecure", `{"Name":"insecure","Metadata":{},"Endpoints":{"docker":{"Host":"tcp://10.0.0.3:2376","SkipTLSVerify":true}}}`, "ca.pem", "cert.pem", "key.pem")
+	var tests = []struct {
+		name        string
+		endpointURL string
+		tls         bool
+		insecure    bool
+	}{
+		{"local", "unix:///var/run/docker.sock", false, false},
+		{"plain", "http://10.0.0.1:2375", false, false},
+		{"secure", "https://10.0.0.2:2376", true, false},
+		{"insecure", "https://10.0.0.3:2376", true, true},
+	}
+	for _, test := range tests {
+		client, err := newVersionedClientFromContextStore(storeDir, test.name, "1.25")
+		if err != nil {
+			t.Errorf("newVersionedClientFromContextStore(%q): unexpected error: %s", test.name, err)
+			continue
+		}
+		if got := client.endpointURL.String(); got != test.endpointURL {
+			t.Errorf("newVersionedClientFromContextStore(%q): wrong endpoint URL. Want %q. Got %q.", test.name, test.endpointURL, got)
+		}
+		if (client.TLSConfig != nil) != test.tls {
+			t.Errorf("newVersionedClientFromContextStore(%q): wrong TLS config. Want TLS: %v. Got %#v.", test.name, test.tls, client.TLSConfig)
+		}
+		if client.TLSConfig != nil {
+			if client.TLSConfig.InsecureSkipVerify != test.insecure {
+				t.Errorf("newVersionedClientFromContextStore(%q): wrong InsecureSkipVerify. Want %v. Got %v.", test.name, test.insecure, client.TLSConfig.InsecureSkipVerify)
+			}
+			if len(client.TLSConfig.Certificates) != 1 {
+				t.Errorf("newVersionedClientFromContextStore(%q): wrong number of client certificates. Want 1. Got %d.", test.name, len(client.TLSConfig.Certificates))
+			}
+		}
+		if version := client.requestedAPIVersion.String(); version != "1.25" {
+			t.Errorf("newVersionedClientFromContextStore(%q): wrong API version. Want %q. Got %q.", test.name, "1.25", version)
+		}
+	}
+}
+
+func TestNewClientFromContextStoreErrors(t *testing.T) {
+	t.Parallel()
+	storeDir, err := ioutil.TempDir("", "go-dockerclient-contexts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storeDir)
+	writeTestContext(t, storeDir, "k8s", `{"Name":"k8s","Metadata":{},"Endpoints":{"kubernetes":{"Host":"https://10.0.0.1:6443"}}}`)
+	writeTestContext(t, storeDir, "broken", `{"Name":`)
+	var tests = []struct {
+		name     string
+		expected string
+	}{
+		{"unknown", `context "unknown" not found`},
+		{"k8s", `context "k8s" has no docker endpoint`},
+		{"broken", `invalid metadata for context "broken"`},
+	}
+	for _, test := range tests {
+		_, err := newVersionedClientFromContextStore(storeDir, test.name, "")
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("newVersionedClientFromContextStore(%q): wrong error. Want %q. Got %v.", test.name, test.expected, err)
+		}
+	}
+}
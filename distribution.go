@@ -6,15 +6,36 @@ package docker
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http"
 
 	"github.com/docker/docker/api/types/registry"
 )
 
+// ErrDistributionUnauthorized is the error returned by InspectDistribution
+// when the registry rejects the credentials provided for the image.
+var ErrDistributionUnauthorized = errors.New("unauthorized to inspect image in the registry")
+
 // InspectDistribution returns image digest and platform information by contacting the registry
 func (c *Client) InspectDistribution(name string) (*registry.DistributionInspect, error) {
+	return c.InspectDistributionWithAuth(name, AuthConfiguration{})
+}
+
+// InspectDistributionWithAuth returns image digest and platform information
+// by contacting the registry, authenticating with the given credentials. It
+// returns ErrDistributionUnauthorized when the registry denies access to the
+// image.
+func (c *Client) InspectDistributionWithAuth(name string, auth AuthConfiguration) (*registry.DistributionInspect, error) {
+	headers, err := headersWithAuth(c.resolveAuth(name, auth))
+	if err != nil {
+		return nil, err
+	}
 	path := "/distribution/" + name + "/json"
-	resp, err := c.do("GET", path, doOptions{})
+	resp, err := c.do("GET", path, doOptions{headers: headers})
 	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusUnauthorized {
+			return nil, ErrDistributionUnauthorized
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"reflect"
@@ -56,3 +57,42 @@ func TestInspectDistribution(t *testing.T) {
 		t.Errorf("InspectDistribution(%q): Expected %#v. Got %#v.", "", expected, distributionInspect)
 	}
 }
+
+func TestInspectDistributionWithAuth(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Descriptor":{"Size":3987495}}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	auth := AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com"}
+	distributionInspect, err := client.InspectDistributionWithAuth("registry.example.com/tsuru/python", auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if distributionInspect.Descriptor.Size != 3987495 {
+		t.Errorf("InspectDistributionWithAuth: wrong size. Want 3987495. Got %d.", distributionInspect.Descriptor.Size)
+	}
+	req := fakeRT.requests[0]
+	expectedPath := "/distribution/registry.example.com/tsuru/python/json"
+	if req.URL.Path != expectedPath {
+		t.Errorf("InspectDistributionWithAuth: wrong request path. Want %q. Got %q.", expectedPath, req.URL.Path)
+	}
+	var got AuthConfiguration
+	data, err := base64.URLEncoding.DecodeString(req.Header.Get("X-Registry-Auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != auth {
+		t.Errorf("InspectDistributionWithAuth: wrong credentials. Want %#v. Got %#v.", auth, got)
+	}
+}
+
+func TestInspectDistributionUnauthorized(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "authentication required", status: http.StatusUnauthorized})
+	_, err := client.InspectDistributionWithAuth("registry.example.com/tsuru/python", AuthConfiguration{})
+	if err != ErrDistributionUnauthorized {
+		t.Errorf("InspectDistributionWithAuth: wrong error. Want %#v. Got %#v.", ErrDistributionUnauthorized, err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dockermetrics exposes Prometheus metrics of the requests sent by
+// go-dockerclient to the Docker daemon. It's a separate package so that the
+// docker package doesn't depend on the Prometheus client.
+package dockermetrics
+
+import (
+	"strconv"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsCollector is a prometheus.Collector that records the requests sent
+// by the clients configured with it, through WithMetrics or
+// docker.WithMetrics. It implements docker.Metrics and exports:
+//
+//	docker_client_request_duration_seconds{operation}, a histogram of the
+//	duration of the requests
+//	docker_client_request_errors_total{status_code}, a counter of the
+//	requests that failed, with the status code 0 when no response was
+//	received
+//	docker_client_active_streams{operation}, a gauge of the streaming
+//	connections, such as the ones of Logs and Events
+//
+// Operations are named after the method of the client that sent the
+// request, like "CreateContainer".
+type MetricsCollector struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	streams  *prometheus.GaugeVec
+}
+
+// NewMetricsCollector returns a new MetricsCollector, which must be
+// registered in a prometheus.Registerer to be exported.
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "docker_client",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the requests sent to the Docker daemon.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "docker_client",
+			Name:      "request_errors_total",
+			Help:      "Number of requests to the Docker daemon that failed.",
+		}, []string{"status_code"}),
+		streams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "docker_client",
+			Name:      "active_streams",
+			Help:      "Number of active streaming connections to the Docker daemon.",
+		}, []string{"operation"}),
+	}
+}
+
+// WithMetrics returns a docker.ClientOption that makes the client record its
+// requests in a MetricsCollector registered in reg. Clients configured with
+// the same reg share the collector.
+func WithMetrics(reg prometheus.Registerer) docker.ClientOption {
+	return func(c *docker.Client) error {
+		collector := NewMetricsCollector()
+		if err := reg.Register(collector); err != nil {
+			are, ok := err.(prometheus.AlreadyRegisteredError)
+			if !ok {
+				return err
+			}
+			existing, ok := are.ExistingCollector.(*MetricsCollector)
+			if !ok {
+				return err
+			}
+			collector = existing
+		}
+		return docker.WithMetrics(collector)(c)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.errors.Describe(ch)
+	m.streams.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.errors.Collect(ch)
+	m.streams.Collect(ch)
+}
+
+// ObserveRequest implements docker.Metrics.
+func (m *MetricsCollector) ObserveRequest(operation string, statusCode int, duration time.Duration) {
+	m.duration.WithLabelValues(operation).Observe(duration.Seconds())
+	if statusCode == 0 || statusCode >= 400 {
+		m.errors.WithLabelValues(strconv.Itoa(statusCode)).Inc()
+	}
+}
+
+// StreamOpened implements docker.Metrics.
+func (m *MetricsCollector) StreamOpened(operation string) {
+	m.streams.WithLabelValues(operation).Inc()
+}
+
+// StreamClosed implements docker.Metrics.
+func (m *MetricsCollector) StreamClosed(operation string) {
+	m.streams.WithLabelValues(operation).Dec()
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockermetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func newClient(t *testing.T, url string, reg prometheus.Registerer) *docker.Client {
+	client, err := docker.NewClientWithOptions(docker.WithEndpoint(url), WithMetrics(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// findMetric returns the metric of the given family with the given label, or
+// nil if there's none.
+func findMetric(families []*dto.MetricFamily, name, label, value string) *dto.Metric {
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label && pair.GetValue() == value {
+					return metric
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/containers/c1/json" {
+			w.Write([]byte(`{"Id":"c1"}`))
+			return
+		}
+		http.Error(w, "no such container", http.StatusNotFound)
+	}))
+	defer server.Close()
+	reg := prometheus.NewRegistry()
+	client := newClient(t, server.URL, reg)
+	if _, err := client.InspectContainer("c1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.InspectContainer("c2"); err == nil {
+		t.Fatal("InspectContainer: unexpected <nil> error")
+	}
+	// clients configured with the same registerer share the collector
+	if _, err := newClient(t, server.URL, reg).InspectContainer("c1"); err != nil {
+		t.Fatal(err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	duration := findMetric(families, "docker_client_request_duration_seconds", "operation", "InspectContainer")
+	if duration == nil || duration.GetHistogram().GetSampleCount() != 3 {
+		t.Errorf("WithMetrics: wrong duration of InspectContainer. Want 3 samples. Got %v.", duration)
+	}
+	errors := findMetric(families, "docker_client_request_errors_total", "status_code", "404")
+	if errors == nil || errors.GetCounter().GetValue() != 1 {
+		t.Errorf("WithMetrics: wrong errors with status 404. Want 1. Got %v.", errors)
+	}
+	if findMetric(families, "docker_client_request_errors_total", "status_code", "200") != nil {
+		t.Error("WithMetrics: successful requests counted as errors")
+	}
+}
+
+func TestMetricsCollectorStreams(t *testing.T) {
+	t.Parallel()
+	collector := NewMetricsCollector()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collector)
+	collector.StreamOpened("Logs")
+	collector.StreamOpened("Logs")
+	collector.StreamClosed("Logs")
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	streams := findMetric(families, "docker_client_active_streams", "operation", "Logs")
+	if streams == nil || streams.GetGauge().GetValue() != 1 {
+		t.Errorf("MetricsCollector: wrong active streams. Want 1. Got %v.", streams)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import "net/http"
+
+// matchError reports whether err, or any error it wraps, matches the given
+// function. Wrapped errors are found through their Unwrap method.
+func matchError(err error, match func(error) bool) bool {
+	for err != nil {
+		if match(err) {
+			return true
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = wrapper.Unwrap()
+	}
+	return false
+}
+
+func isAPIError(err error, status int) bool {
+	e, ok := err.(*Error)
+	return ok && e.Status == status
+}
+
+// IsErrNotFound reports whether err means that the requested object doesn't
+// exist, be it one of the specific errors, such as NoSuchContainer or
+// ErrNoSuchImage, or an API error with a 404 status.
+func IsErrNotFound(err error) bool {
+	return matchError(err, func(err error) bool {
+		switch err.(type) {
+		case *NoSuchContainer, *NoSuchNetwork, *NoSuchNetworkOrContainer, *NoSuchExec,
+			*NoSuchPlugin, *NoSuchService, *NoSuchTask, *NoSuchNode, *NoSuchSecret, *NoSuchConfig:
+			return true
+		}
+		return err == ErrNoSuchImage || err == ErrNoSuchVolume || isAPIError(err, http.StatusNotFound)
+	})
+}
+
+// IsErrConflict reports whether err means that the request conflicts with
+// the current state of the daemon, be it one of the specific errors, such as
+// ErrContainerAlreadyExists or ErrVolumeInUse, or an API error with a 409
+// status.
+func IsErrConflict(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrContainerAlreadyExists || err == ErrNetworkAlreadyExists ||
+			err == ErrVolumeInUse || isAPIError(err, http.StatusConflict)
+	})
+}
+
+// IsErrNoSuchContainer reports whether err is a NoSuchContainer error.
+func IsErrNoSuchContainer(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(*NoSuchContainer)
+		return ok
+	})
+}
+
+// IsErrContainerAlreadyExists reports whether err is
+// ErrContainerAlreadyExists.
+func IsErrContainerAlreadyExists(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrContainerAlreadyExists
+	})
+}
+
+// IsErrContainerAlreadyRunning reports whether err is a
+// ContainerAlreadyRunning error.
+func IsErrContainerAlreadyRunning(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(*ContainerAlreadyRunning)
+		return ok
+	})
+}
+
+// IsErrContainerNotRunning reports whether err is a ContainerNotRunning
+// error.
+func IsErrContainerNotRunning(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(*ContainerNotRunning)
+		return ok
+	})
+}
+
+// IsErrImageNotFound reports whether err is ErrNoSuchImage.
+func IsErrImageNotFound(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrNoSuchImage
+	})
+}
+
+// IsErrNetworkNotFound reports whether err is a NoSuchNetwork error.
+func IsErrNetworkNotFound(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(*NoSuchNetwork)
+		return ok
+	})
+}
+
+// IsErrNetworkAlreadyExists reports whether err is ErrNetworkAlreadyExists.
+func IsErrNetworkAlreadyExists(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrNetworkAlreadyExists
+	})
+}
+
+// IsErrVolumeNotFound reports whether err is ErrNoSuchVolume.
+func IsErrVolumeNotFound(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrNoSuchVolume
+	})
+}
+
+// IsErrVolumeInUse reports whether err is ErrVolumeInUse.
+func IsErrVolumeInUse(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrVolumeInUse
+	})
+}
+
+// IsErrExecNotFound reports whether err is a NoSuchExec error.
+func IsErrExecNotFound(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(*NoSuchExec)
+		return ok
+	})
+}
+
+// IsErrUnauthorized reports whether err is ErrUnauthorized, reported by the
+// progress stream of operations such as PullImage and PushImage.
+func IsErrUnauthorized(err error) bool {
+	return matchError(err, func(err error) bool {
+		return err == ErrUnauthorized
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return "wrapped: " + e.err.Error()
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+func TestErrorHelpers(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		check    func(error) bool
+		matching []error
+		other    []error
+	}{
+		{
+			"IsErrNotFound",
+			IsErrNotFound,
+			[]error{&NoSuchContainer{ID: "c"}, ErrNoSuchImage, &NoSuchNetwork{ID: "n"}, ErrNoSuchVolume, &NoSuchService{ID: "s"}, &Error{Status: http.StatusNotFound}},
+			[]error{ErrVolumeInUse, &Error{Status: http.StatusConflict}, nil},
+		},
+		{
+			"IsErrConflict",
+			IsErrConflict,
+			[]error{ErrContainerAlreadyExists, ErrNetworkAlreadyExists, ErrVolumeInUse, &Error{Status: http.StatusConflict}},
+			[]error{ErrNoSuchImage, &Error{Status: http.StatusNotFound}, nil},
+		},
+		{"IsErrNoSuchContainer", IsErrNoSuchContainer, []error{&NoSuchContainer{ID: "c"}}, []error{ErrNoSuchImage, nil}},
+		{"IsErrContainerAlreadyExists", IsErrContainerAlreadyExists, []error{ErrContainerAlreadyExists}, []error{ErrNetworkAlreadyExists}},
+		{"IsErrContainerAlreadyRunning", IsErrContainerAlreadyRunning, []error{&ContainerAlreadyRunning{ID: "c"}}, []error{&ContainerNotRunning{ID: "c"}}},
+		{"IsErrContainerNotRunning", IsErrContainerNotRunning, []error{&ContainerNotRunning{ID: "c"}}, []error{&ContainerAlreadyRunning{ID: "c"}}},
+		{"IsErrImageNotFound", IsErrImageNotFound, []error{ErrNoSuchImage}, []error{&NoSuchContainer{ID: "c"}}},
+		{"IsErrNetworkNotFound", IsErrNetworkNotFound, []error{&NoSuchNetwork{ID: "n"}}, []error{&NoSuchNetworkOrContainer{NetworkID: "n"}}},
+		{"IsErrNetworkAlreadyExists", IsErrNetworkAlreadyExists, []error{ErrNetworkAlreadyExists}, []error{ErrContainerAlreadyExists}},
+		{"IsErrVolumeNotFound", IsErrVolumeNotFound, []error{ErrNoSuchVolume}, []error{ErrVolumeInUse}},
+		{"IsErrVolumeInUse", IsErrVolumeInUse, []error{ErrVolumeInUse}, []error{ErrNoSuchVolume}},
+		{"IsErrExecNotFound", IsErrExecNotFound, []error{&NoSuchExec{ID: "e"}}, []error{errors.New("no such exec")}},
+	}
+	for _, test := range tests {
+		for _, err := range test.matching {
+			if !test.check(err) {
+				t.Errorf("%s(%#v): want true, got false", test.name, err)
+			}
+			if wrapped := (&wrappedError{err: err}); !test.check(wrapped) {
+				t.Errorf("%s(%#v): want true, got false", test.name, wrapped)
+			}
+		}
+		for _, err := range test.other {
+			if test.check(err) {
+				t.Errorf("%s(%#v): want false, got true", test.name, err)
+			}
+		}
+	}
+}
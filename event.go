@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,9 @@ import (
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -52,6 +56,341 @@ type APIActor struct {
 	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
+// ContainerName returns the name of the container of a container event, or
+// an empty string for other events.
+//
+// The attributes of container events are the name and the image of the
+// container and its labels, plus the exitCode on die events, the signal on
+// kill events and the execID on exec events.
+func (e *APIEvents) ContainerName() string {
+	if e.Type != "container" {
+		return ""
+	}
+	return e.Actor.Attributes["name"]
+}
+
+// ImageName returns the image of a container event, or the name of the image
+// of an image event, as given by the user. It returns an empty string for
+// other events.
+//
+// The attributes of image events are the name of the image and its labels.
+func (e *APIEvents) ImageName() string {
+	switch e.Type {
+	case "container":
+		return e.Actor.Attributes["image"]
+	case "image":
+		if name := e.Actor.Attributes["name"]; name != "" {
+			return name
+		}
+		return e.Actor.ID
+	}
+	return ""
+}
+
+// ExitCode returns the exit code of the container of a die event. The
+// boolean is false for other events.
+func (e *APIEvents) ExitCode() (int, bool) {
+	if e.Type != "container" {
+		return 0, false
+	}
+	value, ok := e.Actor.Attributes["exitCode"]
+	if !ok {
+		return 0, false
+	}
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
+
+// Signal returns the signal sent to the container of a kill event, as a
+// number such as "9". The boolean is false for other events.
+func (e *APIEvents) Signal() (string, bool) {
+	if e.Type != "container" {
+		return "", false
+	}
+	signal, ok := e.Actor.Attributes["signal"]
+	return signal, ok
+}
+
+// EventsOptions specify parameters to the functions that retrieve events
+// from the Docker API.
+//
+// See https://docs.docker.com/engine/api/v1.39/#operation/SystemEvents for more
+// details.
+type EventsOptions struct {
+	// Since and Until bound the events by time, either as Unix timestamps
+	// or as durations relative to the daemon time.
+	Since string
+	Until string
+
+	// Filters filter the events, see EventFilters for the available keys.
+	Filters map[string][]string
+}
+
+// filterSet holds the filters of the filter builders, such as EventFilters
+// and TaskFilters, keyed by filter.
+type filterSet map[string][]string
+
+func (s filterSet) add(key, value string) {
+	s[key] = append(s[key], value)
+}
+
+// copy returns a copy of the filters, so the builder can still be changed
+// after Build.
+func (s filterSet) copy() map[string][]string {
+	filters := make(map[string][]string, len(s))
+	for key, values := range s {
+		filters[key] = append([]string(nil), values...)
+	}
+	return filters
+}
+
+// EventFilters builds the filters of an EventsOptions, sparing the caller
+// from dealing with the filter keys of the Docker API. Calling a method
+// several times adds alternatives to the same filter, for example:
+//
+//	opts := NewEventFilters().Type("container").Event("start").Event("stop").Build()
+type EventFilters struct {
+	filters filterSet
+}
+
+// NewEventFilters returns an empty EventFilters.
+func NewEventFilters() *EventFilters {
+	return &EventFilters{filters: make(filterSet)}
+}
+
+func (f *EventFilters) add(key, value string) *EventFilters {
+	f.filters.add(key, value)
+	return f
+}
+
+// Config filters events by swarm config name or ID.
+func (f *EventFilters) Config(config string) *EventFilters {
+	return f.add("config", config)
+}
+
+// Container filters events by container name or ID.
+func (f *EventFilters) Container(container string) *EventFilters {
+	return f.add("container", container)
+}
+
+// Daemon filters events by daemon name or ID.
+func (f *EventFilters) Daemon(daemon string) *EventFilters {
+	return f.add("daemon", daemon)
+}
+
+// Event filters events by action, such as start, die or health_status.
+func (f *EventFilters) Event(event string) *EventFilters {
+	return f.add("event", event)
+}
+
+// Image filters events by image name or ID.
+func (f *EventFilters) Image(image string) *EventFilters {
+	return f.add("image", image)
+}
+
+// Label filters events by label, in the format key or key=value.
+func (f *EventFilters) Label(label string) *EventFilters {
+	return f.add("label", label)
+}
+
+// Network filters events by network name or ID.
+func (f *EventFilters) Network(network string) *EventFilters {
+	return f.add("network", network)
+}
+
+// Node filters events by swarm node ID.
+func (f *EventFilters) Node(node string) *EventFilters {
+	return f.add("node", node)
+}
+
+// Plugin filters events by plugin name or ID.
+func (f *EventFilters) Plugin(plugin string) *EventFilters {
+	return f.add("plugin", plugin)
+}
+
+// Scope filters events by scope, either local or swarm.
+func (f *EventFilters) Scope(scope string) *EventFilters {
+	return f.add("scope", scope)
+}
+
+// Secret filters events by swarm secret name or ID.
+func (f *EventFilters) Secret(secret string) *EventFilters {
+	return f.add("secret", secret)
+}
+
+// Service filters events by swarm service name or ID.
+func (f *EventFilters) Service(service string) *EventFilters {
+	return f.add("service", service)
+}
+
+// Type filters events by object type, such as container, image, network or
+// volume.
+func (f *EventFilters) Type(eventType string) *EventFilters {
+	return f.add("type", eventType)
+}
+
+// Volume filters events by volume name.
+func (f *EventFilters) Volume(volume string) *EventFilters {
+	return f.add("volume", volume)
+}
+
+// Build returns an EventsOptions with the filters.
+func (f *EventFilters) Build() EventsOptions {
+	return EventsOptions{Filters: f.filters.copy()}
+}
+
+// ListenEvents streams the events of the Docker daemon matching opts,
+// decoded as APIEvents, until the context is canceled or, when opts.Until is
+// set, until the daemon closes the stream after the last event.
+//
+// When the connection to the daemon is lost, for example because the daemon
+// was restarted, ListenEvents reconnects and resumes the stream from the last
+// event received. The error channel receives at most one value, the error
+// that terminated the stream, and is closed along with the events channel
+// when the stream ends.
+//
+// ListenEvents is an alternative to AddEventListener that supports filters
+// and doesn't share a single connection among all listeners.
+//
+// See https://docs.docker.com/engine/api/v1.39/#operation/SystemEvents for more
+// details.
+func (c *Client) ListenEvents(opts EventsOptions, ctx context.Context) (<-chan APIEvents, <-chan error) {
+	eventsC := make(chan APIEvents)
+	errC := make(chan error, 1)
+	go func() {
+		defer close(errC)
+		defer close(eventsC)
+		if err := c.listenEvents(ctx, opts, eventsC); err != nil && ctx.Err() == nil {
+			errC <- err
+		}
+	}()
+	return eventsC, errC
+}
+
+func (c *Client) listenEvents(ctx context.Context, opts EventsOptions, eventsC chan<- APIEvents) error {
+	var lastSeen int64
+	var retries int
+	for {
+		received, err := c.streamEvents(ctx, opts, &lastSeen, eventsC)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil && opts.Until != "" {
+			return nil
+		}
+		if received {
+			retries = 0
+		}
+		if lastSeen > 0 {
+			opts.Since = formatEventTime(time.Unix(0, lastSeen))
+		}
+		if retries >= maxMonitorConnRetries {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		waitTime := time.Duration(retryInitialWaitTime*math.Pow(2, float64(retries))) * time.Millisecond
+		retries++
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(waitTime):
+		}
+	}
+}
+
+// streamEvents sends the events of a single connection to the events
+// endpoint to eventsC, skipping the events sent before lastSeen, which is
+// updated with the time of the last event. It reports whether any event was
+// received.
+func (c *Client) streamEvents(ctx context.Context, opts EventsOptions, lastSeen *int64, eventsC chan<- APIEvents) (bool, error) {
+	resp, err := c.do("GET", "/events?"+queryString(opts), doOptions{context: ctx})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	var received bool
+	resumedFrom := *lastSeen
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event APIEvents
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				return received, nil
+			}
+			return received, err
+		}
+		received = true
+		eventTime := eventTimeNano(event)
+		if eventTime <= resumedFrom {
+			// already sent before the reconnection
+			continue
+		}
+		*lastSeen = eventTime
+		transformEvent(&event)
+		select {
+		case eventsC <- event:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
+
+// ReplayEvents returns the events matching opts that happened between since
+// and the time of the call, sorted by time. It's useful to catch up on the
+// events missed while disconnected from the daemon, without running a
+// streaming listener. The since argument replaces opts.Since, and opts.Until
+// defaults to the current time.
+//
+// See https://docs.docker.com/engine/api/v1.39/#operation/SystemEvents for more
+// details.
+func (c *Client) ReplayEvents(since time.Time, opts EventsOptions, ctx context.Context) ([]APIEvents, error) {
+	opts.Since = formatEventTime(since)
+	if opts.Until == "" {
+		opts.Until = formatEventTime(time.Now())
+	}
+	resp, err := c.do("GET", "/events?"+queryString(opts), doOptions{context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var events []APIEvents
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event APIEvents
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		transformEvent(&event)
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTimeNano(events[i]) < eventTimeNano(events[j])
+	})
+	return events, nil
+}
+
+// eventTimeNano returns the time of the event in nanoseconds, falling back
+// to the time in seconds sent by daemons that don't report nanoseconds.
+func eventTimeNano(event APIEvents) int64 {
+	if event.TimeNano != 0 {
+		return event.TimeNano
+	}
+	return event.Time * int64(time.Second)
+}
+
+func formatEventTime(t time.Time) string {
+	nano := t.UnixNano()
+	return fmt.Sprintf("%d.%09d", nano/int64(time.Second), nano%int64(time.Second))
+}
+
 type eventMonitoringState struct {
 	// `sync/atomic` expects the first word in an allocated struct to be 64-bit
 	// aligned on both ARM and x86-32. See https://goo.gl/zW7dgq for more details.
@@ -67,6 +406,11 @@ type eventMonitoringState struct {
 const (
 	maxMonitorConnRetries = 5
 	retryInitialWaitTime  = 10.
+
+	// healthStatusEventPrefix prefixes the action of the events emitted
+	// when the health status of a container changes, as in
+	// "health_status: healthy".
+	healthStatusEventPrefix = "health_status: "
 )
 
 var (
@@ -92,6 +436,9 @@ var (
 // AddEventListener adds a new listener to container events in the Docker API.
 //
 // The parameter is a channel through which events will be sent.
+//
+// Deprecated: Use ListenEvents instead, which supports filters and doesn't
+// share a single connection among all listeners.
 func (c *Client) AddEventListener(listener chan<- *APIEvents) error {
 	var err error
 	if !c.eventMonitor.isEnabled() {
@@ -336,6 +683,7 @@ func (c *Client) eventHijack(startTime int64, eventChan chan *APIEvents, errChan
 	if err != nil {
 		return err
 	}
+	req.Header.Set("User-Agent", c.getUserAgent())
 	res, err := conn.Do(req)
 	if err != nil {
 		return err
@@ -408,5 +756,11 @@ func transformEvent(event *APIEvents) {
 		if event.From == "" {
 			event.From = event.Actor.Attributes["image"]
 		}
+		if event.Type == "container" && strings.HasPrefix(event.Action, healthStatusEventPrefix) {
+			if event.Actor.Attributes == nil {
+				event.Actor.Attributes = map[string]string{}
+			}
+			event.Actor.Attributes["health_status"] = strings.TrimPrefix(event.Action, healthStatusEventPrefix)
+		}
 	}
 }
@@ -6,12 +6,18 @@ package docker
 
 import (
 	"bufio"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -301,3 +307,251 @@ func TestEventListenerReAdding(t *testing.T) {
 	// Give the goroutine of the first eventHijack() time to handle the EOF.
 	time.Sleep(10 * time.Millisecond)
 }
+
+func TestEventFilters(t *testing.T) {
+	t.Parallel()
+	opts := NewEventFilters().
+		Container("4fa6e0f0c678").
+		Image("tsuru/python").
+		Type("container").
+		Event("start").
+		Event("die").
+		Label("app=web").
+		Build()
+	expected := map[string][]string{
+		"container": {"4fa6e0f0c678"},
+		"image":     {"tsuru/python"},
+		"type":      {"container"},
+		"event":     {"start", "die"},
+		"label":     {"app=web"},
+	}
+	if !reflect.DeepEqual(opts.Filters, expected) {
+		t.Errorf("EventFilters: wrong filters. Want %#v. Got %#v.", expected, opts.Filters)
+	}
+	query, err := url.ParseQuery(queryString(opts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON := `{"container":["4fa6e0f0c678"],"event":["start","die"],"image":["tsuru/python"],"label":["app=web"],"type":["container"]}`
+	if got := query.Get("filters"); got != expectedJSON {
+		t.Errorf("EventFilters: wrong filters query. Want %q. Got %q.", expectedJSON, got)
+	}
+}
+
+func TestListenEvents(t *testing.T) {
+	t.Parallel()
+	response := `{"action":"create","type":"container","actor":{"id":"5745704abe9caa5","attributes":{"image":"busybox"}},"time":1442421716,"timeNano":1442421716853979870}
+{"status":"start","id":"dfdf82bd3881","from":"base:latest","time":1374067924}`
+	fakeRT := &FakeRoundTripper{message: response, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	opts := NewEventFilters().Type("container").Build()
+	opts.Until = "1442421800"
+	eventsC, errC := client.ListenEvents(opts, context.Background())
+	var got []APIEvents
+	for event := range eventsC {
+		got = append(got, event)
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ListenEvents: wrong number of events. Want 2. Got %d.", len(got))
+	}
+	if got[0].Status != "create" || got[1].Action != "start" || got[1].Actor.ID != "dfdf82bd3881" {
+		t.Errorf("ListenEvents: wrong events. Got %#v.", got)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if query.Get("until") != "1442421800" || query.Get("filters") != `{"type":["container"]}` {
+		t.Errorf("ListenEvents: wrong query string. Got %q.", fakeRT.requests[0].URL.RawQuery)
+	}
+}
+
+func TestListenEventsReconnect(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var sinces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sinces = append(sinces, r.URL.Query().Get("since"))
+		n := len(sinces)
+		mu.Unlock()
+		switch n {
+		case 1:
+			// the daemon restarts right after sending the first event
+			w.Write([]byte(`{"action":"start","type":"container","actor":{"id":"a"},"time":1442421716,"timeNano":1442421716000000005}`))
+		case 2:
+			w.Write([]byte(`{"action":"start","type":"container","actor":{"id":"a"},"time":1442421716,"timeNano":1442421716000000005}`))
+			w.Write([]byte(`{"action":"die","type":"container","actor":{"id":"a"},"time":1442421720,"timeNano":1442421720000000000}`))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eventsC, errC := client.ListenEvents(EventsOptions{}, ctx)
+	var actions []string
+	for event := range eventsC {
+		actions = append(actions, event.Action)
+		if len(actions) == 2 {
+			cancel()
+		}
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"start", "die"}; !reflect.DeepEqual(actions, expected) {
+		t.Errorf("ListenEvents: wrong events. Want %#v. Got %#v.", expected, actions)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if expected := []string{"", "1442421716.000000005"}; !reflect.DeepEqual(sinces, expected) {
+		t.Errorf("ListenEvents: wrong since parameters. Want %#v. Got %#v.", expected, sinces)
+	}
+}
+
+func TestReplayEvents(t *testing.T) {
+	t.Parallel()
+	response := `{"action":"die","type":"container","actor":{"id":"a"},"time":1442421720,"timeNano":1442421720000000000}
+{"action":"create","type":"container","actor":{"id":"a","attributes":{"image":"busybox"}},"time":1442421716,"timeNano":1442421716853979870}
+{"status":"start","id":"a","from":"busybox","time":1442421718}`
+	fakeRT := &FakeRoundTripper{message: response, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	since := time.Unix(1442421700, 5)
+	before := time.Now()
+	events, err := client.ReplayEvents(since, NewEventFilters().Container("a").Build(), context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	if expected := []string{"create", "start", "die"}; !reflect.DeepEqual(actions, expected) {
+		t.Errorf("ReplayEvents: wrong events. Want %#v. Got %#v.", expected, actions)
+	}
+	if events[1].Type != "container" || events[1].Actor.ID != "a" {
+		t.Errorf("ReplayEvents: event in the old format not transformed. Got %#v.", events[1])
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if got := query.Get("since"); got != "1442421700.000000005" {
+		t.Errorf("ReplayEvents: wrong since parameter. Want %q. Got %q.", "1442421700.000000005", got)
+	}
+	var sec, nsec int64
+	if _, err = fmt.Sscanf(query.Get("until"), "%d.%d", &sec, &nsec); err != nil {
+		t.Fatalf("ReplayEvents: invalid until parameter %q: %s", query.Get("until"), err)
+	}
+	until := time.Unix(sec, nsec)
+	if until.Before(before.Truncate(time.Second)) || until.After(time.Now()) {
+		t.Errorf("ReplayEvents: until parameter is not the current time. Got %s.", until)
+	}
+	if got := query.Get("filters"); got != `{"container":["a"]}` {
+		t.Errorf("ReplayEvents: wrong filters. Got %q.", got)
+	}
+}
+
+func TestReplayEventsUntil(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	events, err := client.ReplayEvents(time.Unix(1442421700, 0), EventsOptions{Until: "1442421800"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("ReplayEvents: expected no events. Got %#v.", events)
+	}
+	if got := fakeRT.requests[0].URL.Query().Get("until"); got != "1442421800" {
+		t.Errorf("ReplayEvents: wrong until parameter. Want %q. Got %q.", "1442421800", got)
+	}
+}
+
+func TestAPIEventsAccessors(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		event     string
+		container string
+		image     string
+		exitCode  int
+		exited    bool
+		signal    string
+		killed    bool
+	}{
+		{
+			event:     `{"Type":"container","Action":"die","Actor":{"ID":"a","Attributes":{"name":"web","image":"nginx:1.15","exitCode":"137"}}}`,
+			container: "web",
+			image:     "nginx:1.15",
+			exitCode:  137,
+			exited:    true,
+		},
+		{
+			event:     `{"Type":"container","Action":"kill","Actor":{"ID":"a","Attributes":{"name":"web","image":"nginx:1.15","signal":"15"}}}`,
+			container: "web",
+			image:     "nginx:1.15",
+			signal:    "15",
+			killed:    true,
+		},
+		{
+			event: `{"Type":"image","Action":"pull","Actor":{"ID":"busybox:latest","Attributes":{"name":"busybox"}}}`,
+			image: "busybox",
+		},
+		{
+			event: `{"Type":"image","Action":"delete","Actor":{"ID":"sha256:3a093384"}}`,
+			image: "sha256:3a093384",
+		},
+		{
+			event: `{"Type":"network","Action":"connect","Actor":{"ID":"n1","Attributes":{"name":"bridge","container":"a"}}}`,
+		},
+		{
+			event: `{"status":"die","id":"a","from":"busybox","time":1374067924}`,
+			image: "busybox",
+		},
+	}
+	for _, test := range tests {
+		var event APIEvents
+		if err := json.Unmarshal([]byte(test.event), &event); err != nil {
+			t.Fatal(err)
+		}
+		transformEvent(&event)
+		if name := event.ContainerName(); name != test.container {
+			t.Errorf("ContainerName(%s): wrong name. Want %q. Got %q.", test.event, test.container, name)
+		}
+		if image := event.ImageName(); image != test.image {
+			t.Errorf("ImageName(%s): wrong image. Want %q. Got %q.", test.event, test.image, image)
+		}
+		if code, ok := event.ExitCode(); code != test.exitCode || ok != test.exited {
+			t.Errorf("ExitCode(%s): wrong result. Want (%d, %v). Got (%d, %v).", test.event, test.exitCode, test.exited, code, ok)
+		}
+		if signal, ok := event.Signal(); signal != test.signal || ok != test.killed {
+			t.Errorf("Signal(%s): wrong result. Want (%q, %v). Got (%q, %v).", test.event, test.signal, test.killed, signal, ok)
+		}
+	}
+}
+
+func TestTransformEventHealthStatus(t *testing.T) {
+	t.Parallel()
+	event := APIEvents{
+		Action: "health_status: unhealthy",
+		Type:   "container",
+		Actor: APIActor{
+			ID:         "dfdf82bd3881",
+			Attributes: map[string]string{"image": "base:latest", "name": "web"},
+		},
+	}
+	transformEvent(&event)
+	if got := event.Actor.Attributes["health_status"]; got != Unhealthy {
+		t.Errorf("transformEvent: wrong health status. Want %q. Got %q.", Unhealthy, got)
+	}
+	if event.Status != "health_status: unhealthy" || event.ID != "dfdf82bd3881" || event.From != "base:latest" {
+		t.Errorf("transformEvent: wrong event. Got %#v.", event)
+	}
+	event = APIEvents{Action: "start", Type: "container", Actor: APIActor{ID: "dfdf82bd3881"}}
+	transformEvent(&event)
+	if _, ok := event.Actor.Attributes["health_status"]; ok {
+		t.Errorf("transformEvent: unexpected health status in %#v.", event)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // Exec is the type representing a `docker exec` instance and containing the
@@ -140,6 +141,80 @@ func (c *Client) StartExecNonBlocking(id string, opts StartExecOptions) (CloseWa
 	})
 }
 
+// ExecTimeout runs the given command in a container, as CreateExec and
+// StartExec do, returning its exit code. Standard input is attached only
+// when stdin is not nil, and the output is discarded when stdout or stderr
+// are nil.
+//
+// When the timeout expires, or the context is done, the connection to the
+// exec instance is closed and, if the daemon still reports the command as
+// running, the whole container is killed with SIGKILL, since the API doesn't
+// allow killing a single exec instance. The kill is imprecise: the command
+// may finish, or start another process, between the check and the kill. In
+// this case the exit code is -1 and the error is the one of the context,
+// such as context.DeadlineExceeded. A timeout of zero or less means no
+// timeout, leaving the command limited by the context only.
+func (c *Client) ExecTimeout(containerID string, timeout time.Duration, cmd []string, stdin io.Reader, stdout, stderr io.Writer, ctx context.Context) (exitCode int, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	exec, err := c.CreateExec(CreateExecOptions{
+		Container:    containerID,
+		Cmd:          cmd,
+		AttachStdin:  stdin != nil,
+		AttachStdout: stdout != nil,
+		AttachStderr: stderr != nil,
+		Context:      ctx,
+	})
+	if err != nil {
+		return -1, err
+	}
+	cw, err := c.StartExecNonBlocking(exec.ID, StartExecOptions{
+		InputStream:  stdin,
+		OutputStream: stdout,
+		ErrorStream:  stderr,
+	})
+	if err != nil {
+		return -1, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cw.Wait()
+	}()
+	select {
+	case err = <-done:
+		if err != nil {
+			return -1, err
+		}
+	case <-ctx.Done():
+		cw.Close()
+		// the context is done, so the cleanup can't use it
+		if inspect, err := c.InspectExec(exec.ID); err == nil && inspect.Running {
+			c.KillContainer(KillContainerOptions{ID: containerID, Signal: SIGKILL})
+		}
+		return -1, ctx.Err()
+	}
+	// the stream may end before the daemon records the exit code
+	inspect, err := c.InspectExec(exec.ID)
+	for err == nil && inspect.Running {
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-time.After(execExitPollInterval):
+		}
+		inspect, err = c.InspectExec(exec.ID)
+	}
+	if err != nil {
+		return -1, err
+	}
+	return inspect.ExitCode, nil
+}
+
 // ResizeExecTTY resizes the tty session used by the exec command id. This API
 // is valid only if Tty was specified as part of creating and starting the exec
 // command.
@@ -6,14 +6,18 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestExecCreate(t *testing.T) {
@@ -306,3 +310,131 @@ func TestExecInspect(t *testing.T) {
 		t.Errorf("ExecInspect: Wrong path in request. Want %q. Got %q.", expectedURL.Path, gotPath)
 	}
 }
+
+func TestExecTimeout(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/web/exec":
+			json.NewEncoder(w).Encode(Exec{ID: "e1"})
+		case "/exec/e1/start":
+			w.Write([]byte(logFrame(1, "hello\n") + logFrame(2, "oops\n")))
+		case "/exec/e1/json":
+			json.NewEncoder(w).Encode(ExecInspect{ID: "e1", ExitCode: 3})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zero and negative timeouts mean no timeout
+	for _, timeout := range []time.Duration{5 * time.Second, 0, -1} {
+		var stdout, stderr bytes.Buffer
+		exitCode, err := client.ExecTimeout("web", timeout, []string{"sh", "-c", "exit 3"}, nil, &stdout, &stderr, context.Background())
+		if err != nil {
+			t.Fatalf("ExecTimeout(%s): %s", timeout, err)
+		}
+		if exitCode != 3 {
+			t.Errorf("ExecTimeout(%s): wrong exit code. Want 3. Got %d.", timeout, exitCode)
+		}
+		if stdout.String() != "hello\n" || stderr.String() != "oops\n" {
+			t.Errorf("ExecTimeout(%s): wrong output. Got stdout %q and stderr %q.", timeout, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestExecTimeoutWaitsForExit(t *testing.T) {
+	t.Parallel()
+	var (
+		mu       sync.Mutex
+		inspects int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/web/exec":
+			json.NewEncoder(w).Encode(Exec{ID: "e1"})
+		case "/exec/e1/start":
+			w.Write([]byte(logFrame(1, "hello\n")))
+		case "/exec/e1/json":
+			// the daemon records the exit after the stream ends
+			mu.Lock()
+			inspects++
+			running := inspects < 3
+			mu.Unlock()
+			if running {
+				json.NewEncoder(w).Encode(ExecInspect{ID: "e1", Running: true})
+				return
+			}
+			json.NewEncoder(w).Encode(ExecInspect{ID: "e1", ExitCode: 3})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exitCode, err := client.ExecTimeout("web", 5*time.Second, []string{"sh", "-c", "exit 3"}, nil, ioutil.Discard, nil, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 3 {
+		t.Errorf("ExecTimeout: wrong exit code. Want 3. Got %d.", exitCode)
+	}
+	mu.Lock()
+	if inspects != 3 {
+		t.Errorf("ExecTimeout: wrong number of inspects. Want 3. Got %d.", inspects)
+	}
+	mu.Unlock()
+}
+
+func TestExecTimeoutExpired(t *testing.T) {
+	t.Parallel()
+	var (
+		mu     sync.Mutex
+		killed string
+	)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/web/exec":
+			json.NewEncoder(w).Encode(Exec{ID: "e1"})
+		case "/exec/e1/start":
+			// the command doesn't finish until the end of the test
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-release
+		case "/exec/e1/json":
+			json.NewEncoder(w).Encode(ExecInspect{ID: "e1", Running: true})
+		case "/containers/web/kill":
+			mu.Lock()
+			killed = r.URL.Query().Get("signal")
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exitCode, err := client.ExecTimeout("web", 50*time.Millisecond, []string{"sleep", "60"}, nil, ioutil.Discard, nil, context.Background())
+	if err != context.DeadlineExceeded {
+		t.Errorf("ExecTimeout: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+	if exitCode != -1 {
+		t.Errorf("ExecTimeout: wrong exit code. Want -1. Got %d.", exitCode)
+	}
+	mu.Lock()
+	if killed != "9" {
+		t.Errorf("ExecTimeout: wrong kill signal. Want %q. Got %q.", "9", killed)
+	}
+	mu.Unlock()
+}
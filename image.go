@@ -5,17 +5,24 @@
 package docker
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/fsouza/go-dockerclient/internal/jsonmessage"
 )
 
 // APIImages represent an image returned in the ListImages call.
@@ -30,6 +37,81 @@ type APIImages struct {
 	Labels      map[string]string `json:"Labels,omitempty" yaml:"Labels,omitempty" toml:"Labels,omitempty"`
 }
 
+// noneTag and noneDigest are the placeholders reported by older daemons in
+// the RepoTags and RepoDigests of dangling images.
+const (
+	noneTag    = "<none>:<none>"
+	noneDigest = "<none>@<none>"
+)
+
+// HasTag returns whether the image is tagged with the given reference, like
+// "busybox" or "localhost:5000/busybox:1.31". A reference without a tag
+// matches the tag "latest", while references by digest, like
+// "busybox@sha256:...", don't match any tag.
+func (img *APIImages) HasTag(ref string) bool {
+	if strings.Contains(ref, "@") {
+		return false
+	}
+	repository, tag := ParseRepositoryTag(ref)
+	if tag == "" {
+		tag = "latest"
+	}
+	for _, repoTag := range img.RepoTags {
+		if repoTag == repository+":"+tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Digest returns the digest of the first entry of RepoDigests, like
+// "sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92",
+// or an empty string when the image has no digest, as with images built
+// locally and never pushed or pulled.
+func (img *APIImages) Digest() string {
+	for _, repoDigest := range img.RepoDigests {
+		if repoDigest == noneDigest {
+			continue
+		}
+		if n := strings.Index(repoDigest, "@"); n > -1 {
+			return repoDigest[n+1:]
+		}
+	}
+	return ""
+}
+
+// IsDangling returns whether the image has no tags, being reported by
+// docker images as <none>:<none>.
+func (img *APIImages) IsDangling() bool {
+	for _, repoTag := range img.RepoTags {
+		if repoTag != noneTag {
+			return false
+		}
+	}
+	return true
+}
+
+// DisplayName returns a name identifying the image: its first tag, or, for
+// dangling images, its first repository digest, like
+// "busybox@sha256:4a731fb4...", falling back to the short form of its ID.
+func (img *APIImages) DisplayName() string {
+	for _, repoTag := range img.RepoTags {
+		if repoTag != noneTag {
+			return repoTag
+		}
+	}
+	for _, repoDigest := range img.RepoDigests {
+		if repoDigest != noneDigest {
+			return repoDigest
+		}
+	}
+	id := strings.TrimPrefix(img.ID, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
 // RootFS represents the underlying layers used by an image
 type RootFS struct {
 	Type   string   `json:"Type,omitempty" yaml:"Type,omitempty" toml:"Type,omitempty"`
@@ -91,6 +173,28 @@ var (
 	// ErrMustSpecifyNames is the error rreturned when the Names field on
 	// ExportImagesOptions is nil or empty
 	ErrMustSpecifyNames = errors.New("must specify at least one name to export")
+
+	// ErrCacheMountsWithRemote is the error returned when CacheMounts are
+	// given in BuildImageOptions along with a Remote build context, which
+	// can't be changed by the client.
+	ErrCacheMountsWithRemote = errors.New("cache mounts require a context dir or an input stream")
+
+	// ErrDockerfileContentWithRemote is the error returned when
+	// DockerfileContent is given in BuildImageOptions along with a Remote
+	// build context.
+	ErrDockerfileContentWithRemote = errors.New("dockerfile content can't be used with a remote build context")
+
+	// ErrBaseImageNotFound is the error returned by GetImageBaseImage when
+	// the base image can't be found in the history of the image.
+	ErrBaseImageNotFound = errors.New("base image not found in the image history")
+
+	// ErrMissingInputStream is the error returned by ImportImageFromReader
+	// when the given reader is nil.
+	ErrMissingInputStream = errors.New("missing input stream")
+
+	// ErrInvalidImportURL is the error returned by ImportImageFromURL when
+	// the given source is not an http or https URL.
+	ErrInvalidImportURL = errors.New("import source must be an http or https URL")
 )
 
 // ListImagesOptions specify parameters to the ListImages function.
@@ -102,12 +206,51 @@ type ListImagesOptions struct {
 	Digests bool
 	Filter  string
 	Context context.Context
+
+	// Dangling, Reference, Label, Before and Since are added to Filters
+	// under the respective filter names. Dangling, when set, lists only
+	// the images that are (or that aren't) dangling; Reference matches
+	// images by name, with wildcards, such as "busybox:*"; Label matches
+	// images by label, in the key or key=value format; and Before and
+	// Since match images created before or after the given image.
+	Dangling  *bool    `qs:"-"`
+	Reference []string `qs:"-"`
+	Label     []string `qs:"-"`
+	Before    string   `qs:"-"`
+	Since     string   `qs:"-"`
+}
+
+// filters returns the Filters of the options, along with the filters given
+// by the typed fields.
+func (opts ListImagesOptions) filters() map[string][]string {
+	filters := make(map[string][]string, len(opts.Filters))
+	for name, values := range opts.Filters {
+		filters[name] = append([]string(nil), values...)
+	}
+	if opts.Dangling != nil {
+		// the daemon only accepts true and false, or 1 and 0
+		filters["dangling"] = []string{strconv.FormatBool(*opts.Dangling)}
+	}
+	if len(opts.Reference) > 0 {
+		filters["reference"] = append(filters["reference"], opts.Reference...)
+	}
+	if len(opts.Label) > 0 {
+		filters["label"] = append(filters["label"], opts.Label...)
+	}
+	if opts.Before != "" {
+		filters["before"] = append(filters["before"], opts.Before)
+	}
+	if opts.Since != "" {
+		filters["since"] = append(filters["since"], opts.Since)
+	}
+	return filters
 }
 
 // ListImages returns the list of available images in the server.
 //
 // See https://goo.gl/BVzauZ for more details.
 func (c *Client) ListImages(opts ListImagesOptions) ([]APIImages, error) {
+	opts.Filters = opts.filters()
 	path := "/images/json?" + queryString(opts)
 	resp, err := c.do("GET", path, doOptions{context: opts.Context})
 	if err != nil {
@@ -135,7 +278,11 @@ type ImageHistory struct {
 //
 // See https://goo.gl/fYtxQa for more details.
 func (c *Client) ImageHistory(name string) ([]ImageHistory, error) {
-	resp, err := c.do("GET", "/images/"+name+"/history", doOptions{})
+	return c.imageHistory(name, nil)
+}
+
+func (c *Client) imageHistory(name string, ctx context.Context) ([]ImageHistory, error) {
+	resp, err := c.do("GET", "/images/"+name+"/history", doOptions{context: ctx})
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, ErrNoSuchImage
@@ -150,6 +297,148 @@ func (c *Client) ImageHistory(name string) ([]ImageHistory, error) {
 	return history, nil
 }
 
+// GetImageLayerCount returns the number of layers of the image that change
+// the filesystem, skipping the empty layers created by instructions such as
+// ENV or CMD.
+func (c *Client) GetImageLayerCount(nameOrID string, ctx context.Context) (int, error) {
+	history, err := c.imageHistory(nameOrID, ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(ImageHistoryList(history).NonEmptyLayers()), nil
+}
+
+// GetImageBaseImage returns the ID of the image the given image was built
+// from, found in its history. The base image is the newest tagged image of
+// the history, below the image itself, or, when none is tagged, the oldest
+// one available in the daemon, as the layers pulled along with the base
+// image are only known by the daemon for its topmost layer. It returns
+// ErrBaseImageNotFound when the image was built from scratch or its base
+// image isn't available in the daemon.
+func (c *Client) GetImageBaseImage(nameOrID string, ctx context.Context) (string, error) {
+	history, err := c.imageHistory(nameOrID, ctx)
+	if err != nil {
+		return "", err
+	}
+	var oldest string
+	for i, layer := range history {
+		if i == 0 {
+			// the first entry of the history is the image itself
+			continue
+		}
+		if layer.ID == "" || layer.ID == "<missing>" {
+			continue
+		}
+		if len(layer.Tags) > 0 {
+			return layer.ID, nil
+		}
+		oldest = layer.ID
+	}
+	if oldest == "" {
+		return "", ErrBaseImageNotFound
+	}
+	return oldest, nil
+}
+
+// ImageHistoryList is the history of an image, from the newest layer to the
+// oldest. The result of ImageHistory can be converted to it, as in
+// ImageHistoryList(history).TotalSize().
+type ImageHistoryList []ImageHistory
+
+// TotalSize returns the sum of the sizes of the layers.
+func (l ImageHistoryList) TotalSize() int64 {
+	var size int64
+	for _, layer := range l {
+		size += layer.Size
+	}
+	return size
+}
+
+// EmptyLayers returns the layers that don't change the filesystem, such as
+// the ones created by ENV or CMD instructions.
+func (l ImageHistoryList) EmptyLayers() []ImageHistory {
+	var layers []ImageHistory
+	for _, layer := range l {
+		if layer.IsEmptyLayer() {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// NonEmptyLayers returns the layers that change the filesystem.
+func (l ImageHistoryList) NonEmptyLayers() []ImageHistory {
+	var layers []ImageHistory
+	for _, layer := range l {
+		if !layer.IsEmptyLayer() {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// Squashable returns the runs of two or more consecutive empty layers, which
+// could be merged into a single instruction of the Dockerfile.
+func (l ImageHistoryList) Squashable() [][]ImageHistory {
+	var runs [][]ImageHistory
+	start := -1
+	for i := 0; i <= len(l); i++ {
+		if i < len(l) && l[i].IsEmptyLayer() {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start > 1 {
+			runs = append(runs, l[start:i])
+		}
+		start = -1
+	}
+	return runs
+}
+
+// CreatedTime returns the time the layer was created.
+func (h ImageHistory) CreatedTime() time.Time {
+	return time.Unix(h.Created, 0)
+}
+
+// IsEmptyLayer reports whether the layer doesn't change the filesystem.
+func (h ImageHistory) IsEmptyLayer() bool {
+	return h.Size == 0
+}
+
+// Instruction returns the Dockerfile instruction that created the layer,
+// such as RUN or COPY, and its arguments, parsed from CreatedBy. The
+// instruction is empty when it can't be determined, in which case the
+// arguments are the whole CreatedBy.
+func (h ImageHistory) Instruction() (instruction, args string) {
+	const shellPrefix = "/bin/sh -c "
+	createdBy := strings.TrimSpace(strings.TrimSuffix(h.CreatedBy, "# buildkit"))
+	if strings.HasPrefix(createdBy, "|") {
+		// RUN instructions with build args are prefixed with their number
+		// and values
+		if i := strings.Index(createdBy, " "+shellPrefix); i >= 0 {
+			createdBy = createdBy[i+1:]
+		}
+	}
+	if strings.HasPrefix(createdBy, shellPrefix) {
+		createdBy = strings.TrimSpace(createdBy[len(shellPrefix):])
+		if !strings.HasPrefix(createdBy, "#(nop)") {
+			return "RUN", createdBy
+		}
+		createdBy = strings.TrimSpace(createdBy[len("#(nop)"):])
+	}
+	fields := strings.SplitN(createdBy, " ", 2)
+	isInstruction := strings.IndexFunc(fields[0], func(r rune) bool { return r < 'A' || r > 'Z' }) < 0
+	if fields[0] == "" || !isInstruction {
+		return "", h.CreatedBy
+	}
+	if len(fields) == 1 {
+		return fields[0], ""
+	}
+	return fields[0], strings.TrimSpace(fields[1])
+}
+
 // RemoveImage removes an image by its name or ID.
 //
 // See https://goo.gl/Vd2Pck for more details.
@@ -234,6 +523,51 @@ func (c *Client) InspectImage(name string) (*Image, error) {
 	return &image, nil
 }
 
+// ImageExists checks whether the given image (name or ID) is available in the
+// Docker daemon. It returns false and a nil error when the image does not
+// exist. Unlike InspectImage, the inspect payload is not decoded.
+//
+// See https://goo.gl/ncLTG8 for more details.
+func (c *Client) ImageExists(name string) (bool, error) {
+	resp, err := c.do("GET", "/images/"+name+"/json", doOptions{})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// InspectImageRaw returns an image by its name or ID, along with the raw JSON
+// payload sent by the daemon. The raw payload gives access to fields that are
+// not yet modeled by the Image type.
+//
+// Unlike InspectImage, this method does not support the image format used by
+// Docker API versions older than 1.12.
+//
+// See https://goo.gl/ncLTG8 for more details.
+func (c *Client) InspectImageRaw(name string) (*Image, json.RawMessage, error) {
+	resp, err := c.do("GET", "/images/"+name+"/json", doOptions{})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, nil, ErrNoSuchImage
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var image Image
+	if err := json.Unmarshal(raw, &image); err != nil {
+		return nil, nil, err
+	}
+	return &image, raw, nil
+}
+
 // PushImageOptions represents options to use in the PushImage method.
 //
 // See https://goo.gl/BZemGg for more details.
@@ -251,6 +585,8 @@ type PushImageOptions struct {
 	RawJSONStream     bool          `qs:"-"`
 	InactivityTimeout time.Duration `qs:"-"`
 
+	// Context, when canceled, aborts the request, closing the connection to
+	// the daemon, which interrupts the push.
 	Context context.Context
 }
 
@@ -264,7 +600,7 @@ func (c *Client) PushImage(opts PushImageOptions, auth AuthConfiguration) error
 	if opts.Name == "" {
 		return ErrNoSuchImage
 	}
-	headers, err := headersWithAuth(auth)
+	headers, err := headersWithAuth(c.resolveAuth(opts.Name, auth))
 	if err != nil {
 		return err
 	}
@@ -297,7 +633,10 @@ type PullImageOptions struct {
 	OutputStream      io.Writer     `qs:"-"`
 	RawJSONStream     bool          `qs:"-"`
 	InactivityTimeout time.Duration `qs:"-"`
-	Context           context.Context
+
+	// Context, when canceled, aborts the request, closing the connection to
+	// the daemon, which interrupts the pull.
+	Context context.Context
 }
 
 // PullImage pulls an image from a remote registry, logging progress to
@@ -309,7 +648,7 @@ func (c *Client) PullImage(opts PullImageOptions, auth AuthConfiguration) error
 		return ErrNoSuchImage
 	}
 
-	headers, err := headersWithAuth(auth)
+	headers, err := headersWithAuth(c.resolveAuth(opts.Repository, auth))
 	if err != nil {
 		return err
 	}
@@ -410,6 +749,10 @@ type ImportImageOptions struct {
 	Source     string `qs:"fromSrc"`
 	Tag        string `qs:"tag"`
 
+	// Changes contains Dockerfile instructions (CMD, ENTRYPOINT, ENV,
+	// EXPOSE, LABEL, USER, WORKDIR) to apply to the imported image.
+	Changes []string `qs:"changes"`
+
 	InputStream       io.Reader     `qs:"-"`
 	OutputStream      io.Writer     `qs:"-"`
 	RawJSONStream     bool          `qs:"-"`
@@ -438,6 +781,162 @@ func (c *Client) ImportImage(opts ImportImageOptions) error {
 	return c.createImage(queryString(&opts), nil, opts.InputStream, opts.OutputStream, opts.RawJSONStream, opts.InactivityTimeout, opts.Context)
 }
 
+// ImportImageFromTar imports the flat filesystem tarball in tarPath as a new
+// image tagged as repo:tag, returning the ID of the image. When config is not
+// nil, its Cmd, Entrypoint, Env, ExposedPorts, Labels, User and WorkingDir
+// are applied to the imported image. The context object can be used to
+// cancel the import.
+//
+// See https://goo.gl/qkoSsn for more details.
+func (c *Client) ImportImageFromTar(tarPath, repo, tag string, config *Config, ctx context.Context) (string, error) {
+	f, err := os.Open(tarPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return c.importImageID(ImportImageOptions{
+		Repository:  repo,
+		Tag:         tag,
+		Source:      "-",
+		Changes:     configChanges(config),
+		InputStream: f,
+		Context:     ctx,
+	})
+}
+
+// ImportImageFromURL makes the daemon download the tarball at the given http
+// or https URL and import it as a new image tagged as repo:tag, returning the
+// ID of the image. The progress reported by the daemon is written to w, which
+// may be nil to discard it. The context object can be used to cancel the
+// import.
+//
+// See https://goo.gl/qkoSsn for more details.
+func (c *Client) ImportImageFromURL(url, repo, tag string, w io.Writer, ctx context.Context) (string, error) {
+	if !isURL(url) {
+		return "", ErrInvalidImportURL
+	}
+	return c.importImageID(ImportImageOptions{
+		Repository:   repo,
+		Tag:          tag,
+		Source:       url,
+		OutputStream: w,
+		Context:      ctx,
+	})
+}
+
+// ImportImageFromReader imports the tarball read from r as a new image tagged
+// as repo:tag, returning the ID of the image. The tarball is sent in the body
+// of the request, the way the daemon expects it when the source is "-". The
+// progress reported by the daemon is written to w, which may be nil to
+// discard it. The context object can be used to cancel the import.
+//
+// See https://goo.gl/qkoSsn for more details.
+func (c *Client) ImportImageFromReader(r io.Reader, repo, tag string, w io.Writer, ctx context.Context) (string, error) {
+	if r == nil {
+		return "", ErrMissingInputStream
+	}
+	return c.importImageID(ImportImageOptions{
+		Repository:   repo,
+		Tag:          tag,
+		Source:       "-",
+		InputStream:  r,
+		OutputStream: w,
+		Context:      ctx,
+	})
+}
+
+// importImageID runs the given import, writing the progress to the
+// OutputStream of opts, and returns the ID of the imported image, which the
+// daemon sends as the status of the last message of the stream.
+func (c *Client) importImageID(opts ImportImageOptions) (string, error) {
+	out := opts.OutputStream
+	if out == nil {
+		out = ioutil.Discard
+	}
+	var buf bytes.Buffer
+	pr, pw := io.Pipe()
+	displayed := make(chan struct{})
+	go func() {
+		defer close(displayed)
+		jsonmessage.DisplayJSONMessagesStream(io.TeeReader(pr, &buf), out, 0, false, nil)
+		io.Copy(&buf, pr)
+	}()
+	opts.OutputStream = pw
+	opts.RawJSONStream = true
+	err := c.ImportImage(opts)
+	pw.Close()
+	<-displayed
+	if err != nil {
+		return "", err
+	}
+	var id string
+	decoder := json.NewDecoder(&buf)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if msg.Error != nil {
+			return "", msg.Error
+		}
+		if msg.Status != "" {
+			id = msg.Status
+		}
+	}
+	if id == "" {
+		return "", errors.New("import response does not contain the image ID")
+	}
+	return id, nil
+}
+
+// configChanges returns the Dockerfile instructions that apply config to an
+// imported image.
+func configChanges(config *Config) []string {
+	if config == nil {
+		return nil
+	}
+	var changes []string
+	if len(config.Cmd) > 0 {
+		cmd, _ := json.Marshal(config.Cmd)
+		changes = append(changes, "CMD "+string(cmd))
+	}
+	if len(config.Entrypoint) > 0 {
+		entrypoint, _ := json.Marshal(config.Entrypoint)
+		changes = append(changes, "ENTRYPOINT "+string(entrypoint))
+	}
+	for _, env := range config.Env {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) == 2 {
+			changes = append(changes, fmt.Sprintf("ENV %s=%q", parts[0], parts[1]))
+		}
+	}
+	ports := make([]string, 0, len(config.ExposedPorts))
+	for port := range config.ExposedPorts {
+		ports = append(ports, string(port))
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		changes = append(changes, "EXPOSE "+port)
+	}
+	labels := make([]string, 0, len(config.Labels))
+	for key := range config.Labels {
+		labels = append(labels, key)
+	}
+	sort.Strings(labels)
+	for _, key := range labels {
+		changes = append(changes, fmt.Sprintf("LABEL %q=%q", key, config.Labels[key]))
+	}
+	if config.User != "" {
+		changes = append(changes, "USER "+config.User)
+	}
+	if config.WorkingDir != "" {
+		changes = append(changes, "WORKDIR "+config.WorkingDir)
+	}
+	return changes
+}
+
 // BuildImageOptions present the set of informations available for building an
 // image from a tarfile with a Dockerfile in it.
 //
@@ -472,8 +971,55 @@ type BuildImageOptions struct {
 	InactivityTimeout   time.Duration      `qs:"-"`
 	CgroupParent        string             `qs:"cgroupparent"`
 	SecurityOpt         []string           `qs:"securityopt"`
-	Target              string             `gs:"target"`
-	Context             context.Context
+	Target              string             `qs:"target"`
+	CacheMounts         []CacheMount       `qs:"-"`
+
+	// Context, when canceled, aborts the request, closing the connection to
+	// the daemon, which interrupts the build.
+	Context context.Context
+
+	// InlineCache builds the image with BuildKit, embedding the cache
+	// metadata in it, so once pushed to a registry it can be listed in the
+	// CacheFrom of other builds, working as a registry-backed cache.
+	InlineCache bool `qs:"-"`
+
+	// DockerfileContent is the content of the Dockerfile to build, added to
+	// the context given in ContextDir or InputStream at the path in
+	// Dockerfile, or "Dockerfile", replacing any file at that path. The
+	// context is empty when neither is given.
+	DockerfileContent []byte `qs:"-"`
+}
+
+// CacheMount is a BuildKit cache mount added to the RUN instructions of the
+// Dockerfile, so directories such as package manager caches are kept across
+// builds.
+//
+// See https://docs.docker.com/develop/develop-images/build_enhancements/ for
+// more details.
+type CacheMount struct {
+	// ID identifies the cache, defaulting to Target.
+	ID string
+
+	// Target is the path where the cache is mounted in the build
+	// container.
+	Target string
+
+	// Sharing is one of shared (the default), private or locked.
+	Sharing string
+}
+
+// String returns the mount in the format used in the RUN instruction, such
+// as type=cache,id=pip,target=/root/.cache/pip.
+func (m CacheMount) String() string {
+	parts := []string{"type=cache"}
+	if m.ID != "" {
+		parts = append(parts, "id="+m.ID)
+	}
+	parts = append(parts, "target="+m.Target)
+	if m.Sharing != "" {
+		parts = append(parts, "sharing="+m.Sharing)
+	}
+	return strings.Join(parts, ",")
 }
 
 // BuildArg represents arguments that can be passed to the image when building
@@ -486,6 +1032,64 @@ type BuildArg struct {
 	Value string `json:"Value,omitempty" yaml:"Value,omitempty" toml:"Value,omitempty"`
 }
 
+// predefinedBuildArgs are the build args accepted by the daemon without a
+// matching ARG instruction in the Dockerfile.
+var predefinedBuildArgs = map[string]bool{
+	"HTTP_PROXY": true, "http_proxy": true,
+	"HTTPS_PROXY": true, "https_proxy": true,
+	"FTP_PROXY": true, "ftp_proxy": true,
+	"NO_PROXY": true, "no_proxy": true,
+	"ALL_PROXY": true, "all_proxy": true,
+}
+
+// ValidateBuildArgs checks that every one of the given build args is
+// declared by an ARG instruction of the given Dockerfile, or is one of the
+// proxy args predefined by Docker. The daemon only warns about build args
+// that are not consumed by the build, so typos go unnoticed otherwise.
+func ValidateBuildArgs(dockerfile io.Reader, args []BuildArg) error {
+	declared := make(map[string]bool)
+	declare := func(instruction string) {
+		fields := strings.Fields(instruction)
+		if len(fields) > 1 && strings.EqualFold(fields[0], "arg") {
+			for _, field := range fields[1:] {
+				declared[strings.SplitN(field, "=", 2)[0]] = true
+			}
+		}
+	}
+	// instruction holds the lines joined so far of an instruction continued
+	// with backslashes
+	var instruction string
+	scanner := bufio.NewScanner(dockerfile)
+	for scanner.Scan() {
+		trimmed := strings.TrimSpace(scanner.Text())
+		// like the daemon, comments and empty lines don't end an
+		// instruction
+		if strings.HasPrefix(trimmed, "#") || (trimmed == "" && instruction != "") {
+			continue
+		}
+		if strings.HasSuffix(trimmed, "\\") {
+			instruction += strings.TrimSuffix(trimmed, "\\") + " "
+			continue
+		}
+		declare(instruction + trimmed)
+		instruction = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	declare(instruction)
+	var unknown []string
+	for _, arg := range args {
+		if !declared[arg.Name] && !predefinedBuildArgs[arg.Name] && !strings.HasPrefix(arg.Name, "BUILDKIT_") {
+			unknown = append(unknown, arg.Name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("build args not declared in the Dockerfile: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
 // BuildImage builds an image from a tarball's url or a Dockerfile in the input
 // stream.
 //
@@ -502,11 +1106,22 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 	if opts.Remote != "" && opts.Name == "" {
 		opts.Name = opts.Remote
 	}
-	if opts.InputStream != nil || opts.ContextDir != "" {
+	if len(opts.DockerfileContent) > 0 {
+		if opts.Remote != "" {
+			return ErrDockerfileContentWithRemote
+		}
+		if opts.Dockerfile == "" {
+			opts.Dockerfile = "Dockerfile"
+		}
+	}
+	if opts.InputStream != nil || opts.ContextDir != "" || len(opts.DockerfileContent) > 0 {
 		headers["Content-Type"] = "application/tar"
 	} else if opts.Remote == "" {
 		return ErrMissingRepo
 	}
+	// ownInput tells whether the input stream was created here, so it can be
+	// closed by the stages that rewrite it, unlike the one of the caller
+	var ownInput bool
 	if opts.ContextDir != "" {
 		if opts.InputStream != nil {
 			return ErrMultipleContexts
@@ -515,10 +1130,28 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 		if opts.InputStream, err = createTarStream(opts.ContextDir, opts.Dockerfile); err != nil {
 			return err
 		}
+		ownInput = true
+	}
+	if len(opts.DockerfileContent) > 0 {
+		opts.InputStream = addDockerfile(opts.InputStream, ownInput, opts.Dockerfile, opts.DockerfileContent)
+		ownInput = true
+	}
+	if len(opts.CacheMounts) > 0 {
+		if opts.InputStream == nil {
+			return ErrCacheMountsWithRemote
+		}
+		opts.InputStream = addCacheMounts(opts.InputStream, ownInput, opts.Dockerfile, opts.CacheMounts)
 	}
 	qs := queryString(&opts)
 
-	if c.serverAPIVersion.GreaterThanOrEqualTo(apiVersion125) && len(opts.CacheFrom) > 0 {
+	if len(opts.CacheMounts) > 0 || opts.InlineCache {
+		// cache mounts and inline cache are only supported by BuildKit
+		qs += "&version=2"
+	}
+
+	// when the version of the server can't be determined, the caller is
+	// trusted
+	if len(opts.CacheFrom) > 0 && (c.serverAPIVersion == nil || c.serverAPIVersion.GreaterThanOrEqualTo(apiVersion125)) {
 		if b, err := json.Marshal(opts.CacheFrom); err == nil {
 			item := url.Values(map[string][]string{})
 			item.Add("cachefrom", string(b))
@@ -534,11 +1167,14 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 		}
 	}
 
-	if len(opts.BuildArgs) > 0 {
+	if len(opts.BuildArgs) > 0 || opts.InlineCache {
 		v := make(map[string]string)
 		for _, arg := range opts.BuildArgs {
 			v[arg.Name] = arg.Value
 		}
+		if opts.InlineCache {
+			v["BUILDKIT_INLINE_CACHE"] = "1"
+		}
 		if b, err := json.Marshal(v); err == nil {
 			item := url.Values(map[string][]string{})
 			item.Add("buildargs", string(b))
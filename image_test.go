@@ -6,17 +6,23 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/fsouza/go-dockerclient/internal/jsonmessage"
 )
 
 func newTestClient(rt http.RoundTripper) Client {
@@ -30,6 +36,7 @@ func newTestClient(rt http.RoundTripper) Client {
 		endpointURL:            u,
 		SkipServerVersionCheck: true,
 		serverAPIVersion:       testAPIVersion,
+		registryAuths:          new(registryAuthStore),
 	}
 	return client
 }
@@ -146,6 +153,115 @@ func TestListImagesParameters(t *testing.T) {
 	}
 }
 
+func TestListImagesTypedFilters(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "null", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	dangling := false
+	opts := ListImagesOptions{
+		Filters:   map[string][]string{"label": {"maintainer"}},
+		Dangling:  &dangling,
+		Reference: []string{"busybox:*", "alpine"},
+		Label:     []string{"env=prod"},
+		Before:    "busybox:latest",
+		Since:     "alpine:3.8",
+	}
+	if _, err := client.ListImages(opts); err != nil {
+		t.Fatal(err)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	var filters map[string][]string
+	if err := json.Unmarshal([]byte(query.Get("filters")), &filters); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"dangling":  {"false"},
+		"reference": {"busybox:*", "alpine"},
+		"label":     {"maintainer", "env=prod"},
+		"before":    {"busybox:latest"},
+		"since":     {"alpine:3.8"},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("ListImages: wrong filters. Want %#v. Got %#v.", expected, filters)
+	}
+	for _, name := range []string{"dangling", "reference", "label", "before", "since"} {
+		if _, ok := query[name]; ok {
+			t.Errorf("ListImages: unexpected query parameter %q.", name)
+		}
+	}
+	if labels := opts.Filters["label"]; !reflect.DeepEqual(labels, []string{"maintainer"}) {
+		t.Errorf("ListImages: the given filters were modified: %#v", opts.Filters)
+	}
+}
+
+func TestAPIImagesHelpers(t *testing.T) {
+	t.Parallel()
+	digest := "sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92"
+	id := "sha256:8dd8107abd2e22bfd3b45b05733f3d2677d4078b09b5edce56ee3d8677d3c648"
+	var tests = []struct {
+		name        string
+		image       APIImages
+		digest      string
+		dangling    bool
+		displayName string
+	}{
+		{
+			"tagged",
+			APIImages{ID: id, RepoTags: []string{"localhost:5000/busybox:latest", "busybox:1.31"}, RepoDigests: []string{"busybox@" + digest}},
+			digest, false, "localhost:5000/busybox:latest",
+		},
+		{
+			"untagged with digest",
+			APIImages{ID: id, RepoDigests: []string{"busybox@" + digest}},
+			digest, true, "busybox@" + digest,
+		},
+		{
+			"legacy dangling",
+			APIImages{ID: id, RepoTags: []string{"<none>:<none>"}, RepoDigests: []string{"<none>@<none>"}},
+			"", true, "8dd8107abd2e",
+		},
+		{
+			"short id",
+			APIImages{ID: "abc123"},
+			"", true, "abc123",
+		},
+	}
+	for _, test := range tests {
+		if got := test.image.Digest(); got != test.digest {
+			t.Errorf("Digest(%s): wrong digest. Want %q. Got %q.", test.name, test.digest, got)
+		}
+		if got := test.image.IsDangling(); got != test.dangling {
+			t.Errorf("IsDangling(%s): wrong result. Want %v. Got %v.", test.name, test.dangling, got)
+		}
+		if got := test.image.DisplayName(); got != test.displayName {
+			t.Errorf("DisplayName(%s): wrong name. Want %q. Got %q.", test.name, test.displayName, got)
+		}
+	}
+}
+
+func TestAPIImagesHasTag(t *testing.T) {
+	t.Parallel()
+	image := APIImages{RepoTags: []string{"localhost:5000/busybox:latest", "busybox:1.31"}}
+	var tests = []struct {
+		ref      string
+		expected bool
+	}{
+		{"localhost:5000/busybox", true},
+		{"localhost:5000/busybox:latest", true},
+		{"busybox:1.31", true},
+		{"busybox", false},
+		{"busybox:1.30", false},
+		{"localhost:5000/busybox:1.31", false},
+		{"localhost:5000/busybox@sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92", false},
+		{"busybox:1.31@sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92", false},
+	}
+	for _, test := range tests {
+		if got := image.HasTag(test.ref); got != test.expected {
+			t.Errorf("HasTag(%q): wrong result. Want %v. Got %v.", test.ref, test.expected, got)
+		}
+	}
+}
+
 func TestImageHistory(t *testing.T) {
 	t.Parallel()
 	body := `[
@@ -189,6 +305,114 @@ func TestImageHistory(t *testing.T) {
 	}
 }
 
+// multiLayerHistory is the history of an image built with the classic builder
+// from python:3.7, which is itself built from debian:stretch.
+const multiLayerHistory = `[
+	{"Id": "sha256:e1", "Tags": ["tsuru/app:latest"], "CreatedBy": "/bin/sh -c #(nop)  CMD [\"python\" \"app.py\"]"},
+	{"Id": "sha256:d1", "CreatedBy": "/bin/sh -c pip install -r requirements.txt", "Size": 2048},
+	{"Id": "sha256:c1", "CreatedBy": "/bin/sh -c #(nop) COPY dir:8a3b in /app", "Size": 1024},
+	{"Id": "sha256:b1", "Tags": ["python:3.7"], "CreatedBy": "/bin/sh -c #(nop)  CMD [\"python3\"]"},
+	{"Id": "<missing>", "CreatedBy": "/bin/sh -c apt-get install -y python3", "Size": 4096},
+	{"Id": "sha256:a1", "Tags": ["debian:stretch"], "CreatedBy": "/bin/sh -c #(nop)  CMD [\"bash\"]"},
+	{"Id": "<missing>", "CreatedBy": "/bin/sh -c #(nop) ADD file:4fc3 in / ", "Size": 8192}
+]`
+
+func TestGetImageLayerCount(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: multiLayerHistory, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	count, err := client.GetImageLayerCount("tsuru/app", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("GetImageLayerCount: wrong count. Want 4. Got %d.", count)
+	}
+	if path := fakeRT.requests[0].URL.Path; path != "/images/tsuru/app/history" {
+		t.Errorf("GetImageLayerCount: wrong path. Want %q. Got %q.", "/images/tsuru/app/history", path)
+	}
+	client = newTestClient(&FakeRoundTripper{message: "no such image", status: http.StatusNotFound})
+	if _, err = client.GetImageLayerCount("tsuru/app", context.Background()); err != ErrNoSuchImage {
+		t.Errorf("GetImageLayerCount: wrong error. Want %#v. Got %#v.", ErrNoSuchImage, err)
+	}
+}
+
+func TestGetImageBaseImage(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		history  string
+		expected string
+		err      error
+	}{
+		{"tagged base", multiLayerHistory, "sha256:b1", nil},
+		{
+			"untagged base",
+			`[{"Id": "sha256:c1", "Tags": ["tsuru/app:latest"]}, {"Id": "sha256:b1"}, {"Id": "sha256:a1"}, {"Id": "<missing>", "Size": 8192}]`,
+			"sha256:a1",
+			nil,
+		},
+		{"scratch", `[{"Id": "sha256:c1", "Tags": ["tsuru/app:latest"]}, {"Id": "<missing>", "Size": 8192}]`, "", ErrBaseImageNotFound},
+		{"empty", `[]`, "", ErrBaseImageNotFound},
+	}
+	for _, test := range tests {
+		client := newTestClient(&FakeRoundTripper{message: test.history, status: http.StatusOK})
+		id, err := client.GetImageBaseImage("tsuru/app", context.Background())
+		if id != test.expected || err != test.err {
+			t.Errorf("GetImageBaseImage(%s): wrong result. Want %q, %#v. Got %q, %#v.", test.name, test.expected, test.err, id, err)
+		}
+	}
+}
+
+func TestImageHistoryList(t *testing.T) {
+	t.Parallel()
+	history := ImageHistoryList{
+		{ID: "4", CreatedBy: `/bin/sh -c #(nop)  CMD ["python" "app.py"]`},
+		{ID: "3", CreatedBy: "/bin/sh -c #(nop)  EXPOSE 8080"},
+		{ID: "2", CreatedBy: "|1 VERSION=1.0 /bin/sh -c pip install -r requirements.txt", Size: 2048},
+		{ID: "1", CreatedBy: "COPY . /app # buildkit", Size: 1024},
+		{ID: "0", CreatedBy: "ENV PATH=/usr/local/bin"},
+		{ID: "<missing>", CreatedBy: "/bin/sh -c #(nop) WORKDIR /app"},
+		{ID: "<missing>", CreatedBy: "/bin/sh -c #(nop)  ENV LANG=C.UTF-8"},
+		{ID: "<missing>", Created: 1409856213, CreatedBy: "something else", Size: 100},
+	}
+	if size := history.TotalSize(); size != 3172 {
+		t.Errorf("TotalSize: wrong size. Want %d. Got %d.", 3172, size)
+	}
+	if n := len(history.EmptyLayers()); n != 5 {
+		t.Errorf("EmptyLayers: wrong number of layers. Want 5. Got %d.", n)
+	}
+	if n := len(history.NonEmptyLayers()); n != 3 {
+		t.Errorf("NonEmptyLayers: wrong number of layers. Want 3. Got %d.", n)
+	}
+	expectedRuns := [][]ImageHistory{history[0:2], history[4:7]}
+	if runs := history.Squashable(); !reflect.DeepEqual(runs, expectedRuns) {
+		t.Errorf("Squashable: wrong runs. Want %#v. Got %#v.", expectedRuns, runs)
+	}
+	if created := history[7].CreatedTime(); !created.Equal(time.Unix(1409856213, 0)) {
+		t.Errorf("CreatedTime: wrong time. Got %s.", created)
+	}
+	var tests = []struct {
+		instruction string
+		args        string
+	}{
+		{"CMD", `["python" "app.py"]`},
+		{"EXPOSE", "8080"},
+		{"RUN", "pip install -r requirements.txt"},
+		{"COPY", ". /app"},
+		{"ENV", "PATH=/usr/local/bin"},
+		{"WORKDIR", "/app"},
+		{"ENV", "LANG=C.UTF-8"},
+		{"", "something else"},
+	}
+	for i, test := range tests {
+		instruction, args := history[i].Instruction()
+		if instruction != test.instruction || args != test.args {
+			t.Errorf("Instruction(%q): wrong result. Want (%q, %q). Got (%q, %q).", history[i].CreatedBy, test.instruction, test.args, instruction, args)
+		}
+	}
+}
+
 func TestRemoveImage(t *testing.T) {
 	t.Parallel()
 	name := "test"
@@ -314,6 +538,73 @@ func TestInspectImageNotFound(t *testing.T) {
 	}
 }
 
+func TestImageExists(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		status   int
+		expected bool
+		err      bool
+	}{
+		{"exists", http.StatusOK, true, false},
+		{"not found", http.StatusNotFound, false, false},
+		{"server error", http.StatusInternalServerError, false, true},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fakeRT := &FakeRoundTripper{message: `{"Id":"b750fe79269d"}`, status: test.status}
+			client := newTestClient(fakeRT)
+			exists, err := client.ImageExists("base")
+			if exists != test.expected {
+				t.Errorf("ImageExists: wrong result. Want %v. Got %v.", test.expected, exists)
+			}
+			if (err != nil) != test.err {
+				t.Errorf("ImageExists: unexpected error value: %v", err)
+			}
+			u, _ := url.Parse(client.getURL("/images/base/json"))
+			if req := fakeRT.requests[0]; req.URL.Path != u.Path {
+				t.Errorf("ImageExists: Wrong request URL. Want %q. Got %q.", u.Path, req.URL.Path)
+			}
+		})
+	}
+}
+
+func TestInspectImageRaw(t *testing.T) {
+	t.Parallel()
+	body := `{"Id":"b750fe79269d","Os":"linux","Metadata":{"LastTagTime":"2019-01-01T00:00:00Z"}}`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	image, raw, err := client.InspectImageRaw("b750fe79269d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.ID != "b750fe79269d" || image.OS != "linux" {
+		t.Errorf("InspectImageRaw: wrong image. Got %#v.", image)
+	}
+	if string(raw) != body {
+		t.Errorf("InspectImageRaw: wrong raw payload. Want %s. Got %s.", body, raw)
+	}
+	req := fakeRT.requests[0]
+	u, _ := url.Parse(client.getURL("/images/b750fe79269d/json"))
+	if req.URL.Path != u.Path {
+		t.Errorf("InspectImageRaw: Wrong request URL. Want %q. Got %q.", u.Path, req.URL.Path)
+	}
+}
+
+func TestInspectImageRawNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such image", status: http.StatusNotFound})
+	image, raw, err := client.InspectImageRaw("test")
+	if image != nil || raw != nil {
+		t.Errorf("InspectImageRaw: expected <nil> image and payload, got %#v and %s.", image, raw)
+	}
+	if err != ErrNoSuchImage {
+		t.Errorf("InspectImageRaw: wrong error. Want %#v. Got %#v.", ErrNoSuchImage, err)
+	}
+}
+
 func TestPushImage(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "Pushing 1/100", status: http.StatusOK}
@@ -464,6 +755,43 @@ func TestPullImage(t *testing.T) {
 	}
 }
 
+func TestPullImageStreamErrors(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		detail       string
+		message      string
+		unauthorized bool
+	}{
+		{`{"code":401,"message":"unauthorized: authentication required"}`, "authentication is required", true},
+		{`{"message":"manifest unknown"}`, "manifest unknown", false},
+	}
+	for _, test := range tests {
+		body := `{"status":"Pulling from tsuru/python"}` + "\n" + `{"errorDetail":` + test.detail + `}` + "\n"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+		client, err := NewClient(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		err = client.PullImage(PullImageOptions{Repository: "tsuru/python", OutputStream: &buf}, AuthConfiguration{})
+		server.Close()
+		if err == nil || err.Error() != test.message {
+			t.Errorf("PullImage(%s): wrong error. Want %q. Got %v.", test.detail, test.message, err)
+			continue
+		}
+		if unauthorized := IsErrUnauthorized(err); unauthorized != test.unauthorized {
+			t.Errorf("PullImage(%s): wrong error chain. Want ErrUnauthorized: %v. Got %#v.", test.detail, test.unauthorized, err)
+		}
+	}
+	jsonErr := &jsonmessage.JSONError{Code: 401, Message: "unauthorized"}
+	if !IsErrUnauthorized(jsonErr) {
+		t.Errorf("IsErrUnauthorized(%#v): want true, got false", jsonErr)
+	}
+}
+
 func TestPullImageWithDigest(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "Pulling 1/100", status: http.StatusOK}
@@ -774,6 +1102,115 @@ func TestImportImageShouldChangeSourceToDashWhenItsAFilePath(t *testing.T) {
 	}
 }
 
+func TestImportImageFromTar(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"status":"sha256:6b6a2a4d8f1b"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	config := Config{
+		Cmd:          []string{"/bin/sh", "-c", "echo hello"},
+		Entrypoint:   []string{"/entrypoint.sh"},
+		Env:          []string{"PATH=/usr/bin:/bin", "GREETING=hello world"},
+		ExposedPorts: map[Port]struct{}{"80/tcp": {}},
+		WorkingDir:   "/app",
+	}
+	id, err := client.ImportImageFromTar("testing/data/container.tar", "tsuru/python", "2.7", &config, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "sha256:6b6a2a4d8f1b"; id != expected {
+		t.Errorf("ImportImageFromTar: wrong ID. Want %q. Got %q.", expected, id)
+	}
+	req := fakeRT.requests[0]
+	expected := map[string][]string{
+		"fromSrc": {"-"},
+		"repo":    {"tsuru/python"},
+		"tag":     {"2.7"},
+		"changes": {
+			`CMD ["/bin/sh","-c","echo hello"]`,
+			`ENTRYPOINT ["/entrypoint.sh"]`,
+			`ENV PATH="/usr/bin:/bin"`,
+			`ENV GREETING="hello world"`,
+			"EXPOSE 80/tcp",
+			"WORKDIR /app",
+		},
+	}
+	got := map[string][]string(req.URL.Query())
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ImportImageFromTar: wrong query string. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestImportImageFromTarError(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"errorDetail":{"message":"unexpected EOF"},"error":"unexpected EOF"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	_, err := client.ImportImageFromTar("testing/data/container.tar", "tsuru/python", "", nil, context.Background())
+	if err == nil || err.Error() != "unexpected EOF" {
+		t.Errorf("ImportImageFromTar: wrong error. Want %q. Got %v.", "unexpected EOF", err)
+	}
+	if _, err = client.ImportImageFromTar("testing/data/missing.tar", "tsuru/python", "", nil, context.Background()); !os.IsNotExist(err) {
+		t.Errorf("ImportImageFromTar: wrong error for missing file. Got %v.", err)
+	}
+}
+
+func TestImportImageFromURL(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"status":"Downloading from https://example.com/rootfs.tar"}
+{"status":"sha256:6b6a2a4d8f1b"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	var progress bytes.Buffer
+	id, err := client.ImportImageFromURL("https://example.com/rootfs.tar", "tsuru/python", "2.7", &progress, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "sha256:6b6a2a4d8f1b"; id != expected {
+		t.Errorf("ImportImageFromURL: wrong ID. Want %q. Got %q.", expected, id)
+	}
+	if !strings.Contains(progress.String(), "Downloading") {
+		t.Errorf("ImportImageFromURL: progress not written. Got %q.", progress.String())
+	}
+	req := fakeRT.requests[0]
+	expected := map[string][]string{"fromSrc": {"https://example.com/rootfs.tar"}, "repo": {"tsuru/python"}, "tag": {"2.7"}}
+	got := map[string][]string(req.URL.Query())
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ImportImageFromURL: wrong query string. Want %#v. Got %#v.", expected, got)
+	}
+	for _, source := range []string{"-", "testing/data/container.tar", "ftp://example.com/rootfs.tar"} {
+		if _, err = client.ImportImageFromURL(source, "tsuru/python", "", nil, context.Background()); err != ErrInvalidImportURL {
+			t.Errorf("ImportImageFromURL(%q): wrong error. Want %#v. Got %#v.", source, ErrInvalidImportURL, err)
+		}
+	}
+}
+
+func TestImportImageFromReader(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"status":"sha256:6b6a2a4d8f1b"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	id, err := client.ImportImageFromReader(strings.NewReader("tar content"), "tsuru/python", "", nil, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "sha256:6b6a2a4d8f1b"; id != expected {
+		t.Errorf("ImportImageFromReader: wrong ID. Want %q. Got %q.", expected, id)
+	}
+	req := fakeRT.requests[0]
+	expected := map[string][]string{"fromSrc": {"-"}, "repo": {"tsuru/python"}}
+	got := map[string][]string(req.URL.Query())
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ImportImageFromReader: wrong query string. Want %#v. Got %#v.", expected, got)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "tar content" {
+		t.Errorf("ImportImageFromReader: wrong body. Want %q. Got %q.", "tar content", body)
+	}
+	if _, err = client.ImportImageFromReader(nil, "tsuru/python", "", nil, context.Background()); err != ErrMissingInputStream {
+		t.Errorf("ImportImageFromReader: wrong error. Want %#v. Got %#v.", ErrMissingInputStream, err)
+	}
+}
+
 func TestBuildImageParameters(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -801,6 +1238,7 @@ func TestBuildImageParameters(t *testing.T) {
 		NetworkMode:         "host",
 		CgroupParent:        "cgparent",
 		SecurityOpt:         []string{"securityoptions"},
+		Target:              "builder",
 	}
 	err := client.BuildImage(opts)
 	if err != nil && !strings.Contains(err.Error(), "build image fail") {
@@ -826,6 +1264,7 @@ func TestBuildImageParameters(t *testing.T) {
 		"networkmode":  {"host"},
 		"cgroupparent": {"cgparent"},
 		"securityopt":  {"securityoptions"},
+		"target":       {"builder"},
 	}
 	got := map[string][]string(req.URL.Query())
 	if !reflect.DeepEqual(got, expected) {
@@ -833,6 +1272,105 @@ func TestBuildImageParameters(t *testing.T) {
 	}
 }
 
+func TestBuildImageCacheFrom(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		serverVersion string
+		expected      []string
+	}{
+		{"", []string{`["registry.example.com/app:latest"]`}},
+		{"1.25", []string{`["registry.example.com/app:latest"]`}},
+		{"1.24", nil},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+		client := newTestClient(fakeRT)
+		client.serverAPIVersion, _ = NewAPIVersion(test.serverVersion)
+		var buf bytes.Buffer
+		opts := BuildImageOptions{
+			Name:         "app",
+			CacheFrom:    []string{"registry.example.com/app:latest"},
+			InputStream:  &buf,
+			OutputStream: &buf,
+		}
+		if err := client.BuildImage(opts); err != nil {
+			t.Fatal(err)
+		}
+		req := fakeRT.requests[len(fakeRT.requests)-1]
+		if got := req.URL.Query()["cachefrom"]; !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("BuildImage(%q): wrong cachefrom. Want %#v. Got %#v.", test.serverVersion, test.expected, got)
+		}
+	}
+}
+
+func TestBuildImageInlineCache(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	var buf bytes.Buffer
+	buildArgs := make([]BuildArg, 1, 2)
+	buildArgs[0] = BuildArg{Name: "VERSION", Value: "1.0"}
+	opts := BuildImageOptions{
+		Name:         "app",
+		CacheFrom:    []string{"app:latest"},
+		InlineCache:  true,
+		BuildArgs:    buildArgs,
+		InputStream:  &buf,
+		OutputStream: &buf,
+	}
+	if err := client.BuildImage(opts); err != nil {
+		t.Fatal(err)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if got := query.Get("version"); got != "2" {
+		t.Errorf("BuildImage: wrong builder version. Want %q. Got %q.", "2", got)
+	}
+	expectedArgs := `{"BUILDKIT_INLINE_CACHE":"1","VERSION":"1.0"}`
+	if got := query.Get("buildargs"); got != expectedArgs {
+		t.Errorf("BuildImage: wrong build args. Want %q. Got %q.", expectedArgs, got)
+	}
+	if len(opts.BuildArgs) != 1 {
+		t.Errorf("BuildImage: modified the build args of the caller: %#v", opts.BuildArgs)
+	}
+}
+
+func TestValidateBuildArgs(t *testing.T) {
+	t.Parallel()
+	dockerfile := `FROM golang:1.11 AS builder
+ARG VERSION
+arg GOOS=linux GOARCH=amd64
+# ARG COMMENTED
+RUN echo \
+    ARG NOT_AN_ARG
+FROM scratch
+ARG PORT=80
+ARG \
+    # the commit of the build
+    COMMIT \
+
+    BRANCH=main
+`
+	var tests = []struct {
+		args     []BuildArg
+		expected string
+	}{
+		{[]BuildArg{{Name: "VERSION"}, {Name: "GOOS"}, {Name: "GOARCH"}, {Name: "PORT"}, {Name: "COMMIT"}, {Name: "BRANCH"}}, ""},
+		{[]BuildArg{{Name: "HTTP_PROXY"}, {Name: "no_proxy"}, {Name: "BUILDKIT_INLINE_CACHE"}}, ""},
+		{[]BuildArg{{Name: "VERSION"}, {Name: "VERSON"}, {Name: "COMMENTED"}}, "build args not declared in the Dockerfile: VERSON, COMMENTED"},
+		{[]BuildArg{{Name: "NOT_AN_ARG"}}, "build args not declared in the Dockerfile: NOT_AN_ARG"},
+	}
+	for _, test := range tests {
+		err := ValidateBuildArgs(strings.NewReader(dockerfile), test.args)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != test.expected {
+			t.Errorf("ValidateBuildArgs(%v): wrong error. Want %q. Got %q.", test.args, test.expected, got)
+		}
+	}
+}
+
 func TestBuildImageParametersForRemoteBuild(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -1175,3 +1713,64 @@ func TestPruneImages(t *testing.T) {
 		t.Errorf("PruneImages: Expected %#v. Got %#v.", expected, got)
 	}
 }
+
+// cancelWriter cancels a context on the first write.
+type cancelWriter struct {
+	once   sync.Once
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.once.Do(w.cancel)
+	return len(p), nil
+}
+
+func TestImageStreamsContextCancel(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name string
+		run  func(*Client, context.Context, io.Writer) error
+	}{
+		{"PullImage", func(c *Client, ctx context.Context, w io.Writer) error {
+			return c.PullImage(PullImageOptions{Repository: "tsuru/python", OutputStream: w, Context: ctx}, AuthConfiguration{})
+		}},
+		{"PushImage", func(c *Client, ctx context.Context, w io.Writer) error {
+			return c.PushImage(PushImageOptions{Name: "tsuru/python", OutputStream: w, Context: ctx}, AuthConfiguration{})
+		}},
+		{"BuildImage", func(c *Client, ctx context.Context, w io.Writer) error {
+			return c.BuildImage(BuildImageOptions{Name: "app", DockerfileContent: []byte("FROM alpine\n"), OutputStream: w, Context: ctx})
+		}},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			closed := make(chan struct{})
+			var once sync.Once
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ioutil.ReadAll(r.Body)
+				w.Write([]byte(`{"status":"working"}` + "\n"))
+				w.(http.Flusher).Flush()
+				// the operation runs until the client goes away
+				<-r.Context().Done()
+				once.Do(func() { close(closed) })
+			}))
+			defer server.Close()
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			err = test.run(client, ctx, &cancelWriter{cancel: cancel})
+			if err != context.Canceled {
+				t.Errorf("%s: wrong error. Want %#v. Got %#v.", test.name, context.Canceled, err)
+			}
+			select {
+			case <-closed:
+			case <-time.After(5 * time.Second):
+				t.Errorf("%s: the connection to the daemon was not closed", test.name)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package jsonmessage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -21,6 +22,10 @@ import (
 // ensure the formatted time isalways the same number of characters.
 const RFC3339NanoFixed = "2006-01-02T15:04:05.000000000Z07:00"
 
+// ErrUnauthorized is the error returned when the stream reports an
+// authentication failure, with the 401 code.
+var ErrUnauthorized = errors.New("authentication is required")
+
 // JSONError wraps a concrete Code and Message, `Code` is
 // is an integer error code, `Message` is the error message.
 type JSONError struct {
@@ -32,6 +37,14 @@ func (e *JSONError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the error matching the code of the error, if any.
+func (e *JSONError) Unwrap() error {
+	if e.Code == 401 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
 // JSONProgress describes a Progress. terminalFd is the fd of the current terminal,
 // Start is the initial value for the operation. Current is the current status and
 // value of the progress made towards Total. Total is the end value describing when
@@ -240,7 +253,7 @@ func cursorDown(out io.Writer, ti termInfo, l int) error {
 func (jm *JSONMessage) Display(out io.Writer, termInfo termInfo) error {
 	if jm.Error != nil {
 		if jm.Error.Code == 401 {
-			return fmt.Errorf("authentication is required")
+			return ErrUnauthorized
 		}
 		return jm.Error
 	}
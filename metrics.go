@@ -0,0 +1,67 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"net/http"
+	"time"
+)
+
+// Metrics receives measurements of the requests sent by a client configured
+// with WithMetrics. Operations are named after the method of the client that
+// sent the request, like "CreateContainer".
+//
+// The dockermetrics package provides an implementation that exports the
+// measurements as Prometheus metrics.
+type Metrics interface {
+	// ObserveRequest is called when the response headers of a request are
+	// received, with the duration of the request so far. The status code
+	// is zero when no response was received.
+	ObserveRequest(operation string, statusCode int, duration time.Duration)
+
+	// StreamOpened and StreamClosed are called when a streaming
+	// connection, such as the one of Logs, Events or AttachToContainer,
+	// starts and stops streaming.
+	StreamOpened(operation string)
+	StreamClosed(operation string)
+}
+
+// WithMetrics makes the client report the duration and the status of its
+// requests, and the number of active streaming connections, to the given
+// Metrics.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *Client) error {
+		c.metrics = metrics
+		return nil
+	}
+}
+
+// streamOpened reports a new streaming connection to the metrics of the
+// client, returning the function that reports its end.
+func (c *Client) streamOpened() func() {
+	if c.metrics == nil {
+		return func() {}
+	}
+	operation := operationName()
+	c.metrics.StreamOpened(operation)
+	return func() {
+		c.metrics.StreamClosed(operation)
+	}
+}
+
+// measured wraps the given function, reporting each request to the metrics
+// of the client.
+func (c *Client) measured(operation string, send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := send(req)
+		var statusCode int
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		c.metrics.ObserveRequest(operation, statusCode, time.Since(start))
+		return resp, err
+	}
+}
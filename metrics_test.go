@@ -0,0 +1,80 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeMetrics struct {
+	mu       sync.Mutex
+	requests []string
+	statuses []int
+	events   []string
+}
+
+func (m *fakeMetrics) ObserveRequest(operation string, statusCode int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, operation)
+	m.statuses = append(m.statuses, statusCode)
+}
+
+func (m *fakeMetrics) StreamOpened(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, "open "+operation)
+}
+
+func (m *fakeMetrics) StreamClosed(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, "close "+operation)
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+	metrics := &fakeMetrics{}
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	if err := client.ApplyOptions(WithMetrics(metrics)); err != nil {
+		t.Fatal(err)
+	}
+	client.InspectContainer("abc123")
+	client.StopContainer("abc123", 10)
+	if expected := []string{"InspectContainer", "StopContainer"}; !reflect.DeepEqual(metrics.requests, expected) {
+		t.Errorf("WithMetrics: wrong operations. Want %#v. Got %#v.", expected, metrics.requests)
+	}
+	if expected := []int{http.StatusNotFound, http.StatusNotFound}; !reflect.DeepEqual(metrics.statuses, expected) {
+		t.Errorf("WithMetrics: wrong status codes. Want %#v. Got %#v.", expected, metrics.statuses)
+	}
+	if len(metrics.events) != 0 {
+		t.Errorf("WithMetrics: unexpected stream events: %#v", metrics.events)
+	}
+}
+
+func TestWithMetricsStream(t *testing.T) {
+	t.Parallel()
+	metrics := &fakeMetrics{}
+	client := newTestClient(&FakeRoundTripper{message: logFrame(1, "hello\n"), status: http.StatusOK})
+	if err := client.ApplyOptions(WithMetrics(metrics)); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	err := client.Logs(LogsOptions{Container: "abc123", OutputStream: &stdout, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Logs"}; !reflect.DeepEqual(metrics.requests, expected) {
+		t.Errorf("WithMetrics: wrong operations. Want %#v. Got %#v.", expected, metrics.requests)
+	}
+	if expected := []string{"open Logs", "close Logs"}; !reflect.DeepEqual(metrics.events, expected) {
+		t.Errorf("WithMetrics: wrong stream events. Want %#v. Got %#v.", expected, metrics.events)
+	}
+}
@@ -5,9 +5,15 @@
 package docker
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/api/types/swarm"
@@ -34,8 +40,49 @@ func (c *Client) VersionWithContext(ctx context.Context) (*Env, error) {
 	return &env, nil
 }
 
+// DockerVersion contains version information about the Docker server.
+//
+// See https://goo.gl/mU7yje for more details.
+type DockerVersion struct {
+	Version       string
+	APIVersion    string `json:"ApiVersion"`
+	MinAPIVersion string `json:"MinAPIVersion,omitempty"`
+	GitCommit     string
+	GoVersion     string
+	Os            string
+	Arch          string
+	KernelVersion string `json:"KernelVersion,omitempty"`
+	Experimental  bool   `json:"Experimental,omitempty"`
+	BuildTime     string `json:"BuildTime,omitempty"`
+}
+
+// ServerVersion returns version information about the docker server.
+//
+// See https://goo.gl/mU7yje for more details.
+func (c *Client) ServerVersion() (*DockerVersion, error) {
+	return c.ServerVersionWithContext(context.TODO())
+}
+
+// ServerVersionWithContext returns version information about the docker
+// server. The context object can be used to cancel the request.
+func (c *Client) ServerVersionWithContext(ctx context.Context) (*DockerVersion, error) {
+	resp, err := c.do("GET", "/version", doOptions{context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var version DockerVersion
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
 // DockerInfo contains information about the Docker server
 //
+// Driver is the name of the storage driver (see GetStorageDriverInfo for its
+// capabilities) and Swarm describes the swarm the daemon is part of, if any.
+//
 // See https://goo.gl/bHUoz9 for more details.
 type DockerInfo struct {
 	ID                 string
@@ -67,6 +114,7 @@ type DockerInfo struct {
 	ExecutionDriver    string
 	LoggingDriver      string
 	CgroupDriver       string
+	CgroupVersion      string
 	NEventsListener    int
 	KernelVersion      string
 	OperatingSystem    string
@@ -162,7 +210,19 @@ type IndexInfo struct {
 //
 // See https://goo.gl/ElTHi2 for more details.
 func (c *Client) Info() (*DockerInfo, error) {
-	resp, err := c.do("GET", "/info", doOptions{})
+	return c.info(context.TODO())
+}
+
+// InfoWithContext returns system-wide information about the Docker server.
+// The context object can be used to cancel the request.
+//
+// See https://goo.gl/ElTHi2 for more details.
+func (c *Client) InfoWithContext(ctx context.Context) (*DockerInfo, error) {
+	return c.info(ctx)
+}
+
+func (c *Client) info(ctx context.Context) (*DockerInfo, error) {
+	resp, err := c.do("GET", "/info", doOptions{context: ctx})
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +234,186 @@ func (c *Client) Info() (*DockerInfo, error) {
 	return &info, nil
 }
 
+// StorageDriverInfo describes the storage driver used by the Docker daemon.
+type StorageDriverInfo struct {
+	// Name of the storage driver (e.g. overlay2, btrfs, zfs).
+	Name string
+
+	// Status holds the driver specific information reported by the daemon,
+	// such as the backing filesystem.
+	Status map[string]string
+
+	// SupportsSnapshotting indicates whether the driver creates layers as
+	// native filesystem snapshots.
+	SupportsSnapshotting bool
+
+	// SupportsQuota indicates whether the driver supports limiting the size
+	// of the container filesystem (the size storage option).
+	SupportsQuota bool
+}
+
+// GetStorageDriverInfo returns information about the storage driver used by
+// the Docker daemon. The capabilities are derived from the driver name (and,
+// for overlay2, from the backing filesystem).
+//
+// See https://goo.gl/ElTHi2 for more details.
+func (c *Client) GetStorageDriverInfo(ctx context.Context) (*StorageDriverInfo, error) {
+	info, err := c.info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[string]string, len(info.DriverStatus))
+	for _, pair := range info.DriverStatus {
+		status[pair[0]] = pair[1]
+	}
+	driver := StorageDriverInfo{Name: info.Driver, Status: status}
+	switch info.Driver {
+	case "btrfs", "zfs", "devicemapper":
+		driver.SupportsSnapshotting = true
+		driver.SupportsQuota = true
+	case "windowsfilter":
+		driver.SupportsQuota = true
+	case "overlay2":
+		driver.SupportsQuota = status["Backing Filesystem"] == "xfs"
+	}
+	return &driver, nil
+}
+
+// GetCgroupVersion returns the version of the cgroup hierarchy used by the
+// daemon, 1 or 2. Daemons older than API 1.41 don't report it and only
+// support cgroup v1.
+func (c *Client) GetCgroupVersion(ctx context.Context) (int, error) {
+	info, err := c.info(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if info.CgroupVersion == "" {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(info.CgroupVersion)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cgroup version %q", info.CgroupVersion)
+	}
+	return version, nil
+}
+
+// ErrConfigReloadNotSupported is the error returned by SetRegistryMirrors
+// when the registry mirrors of the daemon would have to change, as the Docker
+// API provides no way to change the configuration of the daemon.
+var ErrConfigReloadNotSupported = errors.New("the daemon configuration can't be changed through the Docker API")
+
+// GetRegistryMirrors returns the registry mirrors the Docker daemon is
+// configured with.
+//
+// See https://goo.gl/ElTHi2 for more details.
+func (c *Client) GetRegistryMirrors(ctx context.Context) ([]string, error) {
+	info, err := c.info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info.RegistryConfig == nil {
+		return nil, nil
+	}
+	return info.RegistryConfig.Mirrors, nil
+}
+
+// RegistryMirrorsMatch returns whether the Docker daemon is configured with
+// all the given registry mirrors. The Docker API can't change them: they're
+// set with the registry-mirrors option of the daemon.json file of the daemon
+// host, reloaded with a SIGHUP.
+func (c *Client) RegistryMirrorsMatch(mirrors []string, ctx context.Context) (bool, error) {
+	current, err := c.GetRegistryMirrors(ctx)
+	if err != nil {
+		return false, err
+	}
+	configured := make(map[string]bool, len(current))
+	for _, mirror := range current {
+		configured[strings.TrimSuffix(mirror, "/")] = true
+	}
+	for _, mirror := range mirrors {
+		if !configured[strings.TrimSuffix(mirror, "/")] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// SetRegistryMirrors ensures the Docker daemon uses the given registry
+// mirrors. As the Docker API can't change the configuration of the daemon,
+// SetRegistryMirrors only succeeds when the daemon already uses all of them,
+// as reported by RegistryMirrorsMatch, and returns
+// ErrConfigReloadNotSupported otherwise.
+func (c *Client) SetRegistryMirrors(mirrors []string, ctx context.Context) error {
+	match, err := c.RegistryMirrorsMatch(mirrors, ctx)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrConfigReloadNotSupported
+	}
+	return nil
+}
+
+// ErrMetricsNotEnabled is the error returned by GetDaemonMetrics when the
+// Docker daemon doesn't expose the metrics endpoint.
+var ErrMetricsNotEnabled = errors.New("daemon metrics are not enabled")
+
+// GetDaemonMetrics returns the metrics exposed by the Docker daemon in the
+// Prometheus text format, keyed by series. Series with labels are keyed by
+// the name and the labels as exposed by the daemon, such as:
+//
+//	engine_daemon_container_states_containers{state="running"}
+//
+// The metrics endpoint is only served when the daemon is configured with a
+// metrics-addr, and the client must be connected to that address.
+// ErrMetricsNotEnabled is returned when the endpoint is not available.
+func (c *Client) GetDaemonMetrics(ctx context.Context) (map[string]float64, error) {
+	resp, err := c.do("GET", "/metrics", doOptions{context: ctx, unversioned: true})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, ErrMetricsNotEnabled
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return parsePrometheusMetrics(resp.Body)
+}
+
+// parsePrometheusMetrics parses metrics in the Prometheus text format,
+// ignoring comments and timestamps.
+func parsePrometheusMetrics(r io.Reader) (map[string]float64, error) {
+	metrics := make(map[string]float64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var series, rest string
+		if i := strings.IndexAny(line, "{ \t"); i < 0 {
+			return nil, fmt.Errorf("invalid metric line: %q", line)
+		} else if line[i] == '{' {
+			end := strings.LastIndex(line, "}")
+			if end < i {
+				return nil, fmt.Errorf("invalid metric line: %q", line)
+			}
+			series, rest = line[:end+1], line[end+1:]
+		} else {
+			series, rest = line[:i], line[i:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid metric line: %q", line)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric value in line %q: %s", line, err)
+		}
+		metrics[series] = value
+	}
+	return metrics, scanner.Err()
+}
+
 // ParseRepositoryTag gets the name of the repository and returns it splitted
 // in two parts: the repository and the tag. It ignores the digest when it is
 // present.
@@ -5,18 +5,16 @@
 package docker
 
 import (
+	"context"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"reflect"
 	"testing"
-)
 
-type DockerVersion struct {
-	Version   string
-	GitCommit string
-	GoVersion string
-}
+	"github.com/docker/docker/api/types/swarm"
+)
 
 func TestVersion(t *testing.T) {
 	t.Parallel()
@@ -69,6 +67,60 @@ func TestVersionError(t *testing.T) {
 	}
 }
 
+func TestServerVersion(t *testing.T) {
+	t.Parallel()
+	body := `{
+     "Platform":{"Name":"Docker Engine - Community"},
+     "Version":"18.09.1",
+     "ApiVersion":"1.39",
+     "MinAPIVersion":"1.12",
+     "GitCommit":"4c52b90",
+     "GoVersion":"go1.10.6",
+     "Os":"linux",
+     "Arch":"amd64",
+     "KernelVersion":"4.15.0-43-generic",
+     "Experimental":true,
+     "BuildTime":"2019-01-09T19:35:31.000000000+00:00"
+}`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	version, err := client.ServerVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := DockerVersion{
+		Version:       "18.09.1",
+		APIVersion:    "1.39",
+		MinAPIVersion: "1.12",
+		GitCommit:     "4c52b90",
+		GoVersion:     "go1.10.6",
+		Os:            "linux",
+		Arch:          "amd64",
+		KernelVersion: "4.15.0-43-generic",
+		Experimental:  true,
+		BuildTime:     "2019-01-09T19:35:31.000000000+00:00",
+	}
+	if *version != expected {
+		t.Errorf("ServerVersion: wrong result. Want %#v. Got %#v.", expected, *version)
+	}
+	u, _ := url.Parse(client.getURL("/version"))
+	if req := fakeRT.requests[0]; req.Method != "GET" || req.URL.Path != u.Path {
+		t.Errorf("ServerVersion: wrong request. Want GET %s. Got %s %s.", u.Path, req.Method, req.URL.Path)
+	}
+}
+
+func TestServerVersionError(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "internal error", status: http.StatusInternalServerError})
+	version, err := client.ServerVersion()
+	if version != nil {
+		t.Errorf("ServerVersion: expected <nil> value, got %#v.", version)
+	}
+	if err == nil {
+		t.Error("ServerVersion: unexpected <nil> error")
+	}
+}
+
 func TestInfo(t *testing.T) {
 	t.Parallel()
 	body := `{
@@ -169,6 +221,37 @@ func TestInfo(t *testing.T) {
 	}
 }
 
+func TestInfoWithContext(t *testing.T) {
+	t.Parallel()
+	body := `{
+     "NCPU":4,
+     "MemTotal":2095882240,
+     "Driver":"overlay2",
+     "LoggingDriver":"json-file",
+     "CgroupDriver":"cgroupfs",
+     "Swarm":{
+       "NodeID":"node1",
+       "LocalNodeState":"active",
+       "ControlAvailable":true
+     }
+}`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	info, err := client.InfoWithContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.NCPU != 4 || info.MemTotal != 2095882240 {
+		t.Errorf("InfoWithContext: wrong resources. Want 4 CPUs and 2095882240 bytes. Got %d CPUs and %d bytes.", info.NCPU, info.MemTotal)
+	}
+	if info.Driver != "overlay2" || info.LoggingDriver != "json-file" || info.CgroupDriver != "cgroupfs" {
+		t.Errorf("InfoWithContext: wrong drivers. Got %q, %q and %q.", info.Driver, info.LoggingDriver, info.CgroupDriver)
+	}
+	if info.Swarm.NodeID != "node1" || info.Swarm.LocalNodeState != swarm.LocalNodeStateActive || !info.Swarm.ControlAvailable {
+		t.Errorf("InfoWithContext: wrong swarm info. Got %#v.", info.Swarm)
+	}
+}
+
 func TestInfoError(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "internal error", status: http.StatusInternalServerError}
@@ -182,6 +265,129 @@ func TestInfoError(t *testing.T) {
 	}
 }
 
+func TestGetStorageDriverInfo(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		body     string
+		expected StorageDriverInfo
+	}{
+		{
+			`{"Driver":"overlay2","DriverStatus":[["Backing Filesystem","extfs"],["Supports d_type","true"]]}`,
+			StorageDriverInfo{Name: "overlay2", Status: map[string]string{"Backing Filesystem": "extfs", "Supports d_type": "true"}},
+		},
+		{
+			`{"Driver":"overlay2","DriverStatus":[["Backing Filesystem","xfs"]]}`,
+			StorageDriverInfo{Name: "overlay2", Status: map[string]string{"Backing Filesystem": "xfs"}, SupportsQuota: true},
+		},
+		{
+			`{"Driver":"btrfs","DriverStatus":[["Build Version","Btrfs v4.4"]]}`,
+			StorageDriverInfo{Name: "btrfs", Status: map[string]string{"Build Version": "Btrfs v4.4"}, SupportsSnapshotting: true, SupportsQuota: true},
+		},
+		{
+			`{"Driver":"vfs"}`,
+			StorageDriverInfo{Name: "vfs", Status: map[string]string{}},
+		},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.expected.Name, func(t *testing.T) {
+			t.Parallel()
+			client := newTestClient(&FakeRoundTripper{message: test.body, status: http.StatusOK})
+			driver, err := client.GetStorageDriverInfo(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*driver, test.expected) {
+				t.Errorf("GetStorageDriverInfo: wrong result. Want %#v. Got %#v.", test.expected, *driver)
+			}
+		})
+	}
+}
+
+func TestGetCgroupVersion(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		body     string
+		expected int
+	}{
+		{`{"CgroupDriver":"systemd","CgroupVersion":"2"}`, 2},
+		{`{"CgroupDriver":"cgroupfs","CgroupVersion":"1"}`, 1},
+		{`{"CgroupDriver":"cgroupfs"}`, 1},
+	}
+	for _, test := range tests {
+		client := newTestClient(&FakeRoundTripper{message: test.body, status: http.StatusOK})
+		version, err := client.GetCgroupVersion(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != test.expected {
+			t.Errorf("GetCgroupVersion(%s): wrong version. Want %d. Got %d.", test.body, test.expected, version)
+		}
+	}
+}
+
+func TestGetCgroupVersionInvalid(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: `{"CgroupVersion":"v2"}`, status: http.StatusOK})
+	if _, err := client.GetCgroupVersion(context.Background()); err == nil {
+		t.Error("GetCgroupVersion: unexpected <nil> error")
+	}
+}
+
+func TestGetRegistryMirrors(t *testing.T) {
+	t.Parallel()
+	body := `{"RegistryConfig":{"Mirrors":["https://mirror.gcr.io/","https://registry.example.com/"]}}`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	mirrors, err := client.GetRegistryMirrors(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"https://mirror.gcr.io/", "https://registry.example.com/"}
+	if !reflect.DeepEqual(mirrors, expected) {
+		t.Errorf("GetRegistryMirrors: wrong mirrors. Want %#v. Got %#v.", expected, mirrors)
+	}
+	client = newTestClient(&FakeRoundTripper{message: `{}`, status: http.StatusOK})
+	if mirrors, err = client.GetRegistryMirrors(context.Background()); err != nil || mirrors != nil {
+		t.Errorf("GetRegistryMirrors: wrong result without registry config. Got %#v, %v.", mirrors, err)
+	}
+}
+
+func TestRegistryMirrorsMatch(t *testing.T) {
+	t.Parallel()
+	body := `{"RegistryConfig":{"Mirrors":["https://mirror.gcr.io/"]}}`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	var tests = []struct {
+		mirrors  []string
+		expected bool
+	}{
+		{[]string{"https://mirror.gcr.io"}, true},
+		{nil, true},
+		{[]string{"https://mirror.gcr.io", "https://registry.example.com"}, false},
+	}
+	for _, test := range tests {
+		match, err := client.RegistryMirrorsMatch(test.mirrors, context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if match != test.expected {
+			t.Errorf("RegistryMirrorsMatch(%#v): wrong result. Want %v. Got %v.", test.mirrors, test.expected, match)
+		}
+	}
+}
+
+func TestSetRegistryMirrors(t *testing.T) {
+	t.Parallel()
+	body := `{"RegistryConfig":{"Mirrors":["https://mirror.gcr.io/"]}}`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	if err := client.SetRegistryMirrors([]string{"https://mirror.gcr.io"}, context.Background()); err != nil {
+		t.Errorf("SetRegistryMirrors: unexpected error for configured mirrors: %s", err)
+	}
+	err := client.SetRegistryMirrors([]string{"https://mirror.gcr.io", "https://registry.example.com"}, context.Background())
+	if err != ErrConfigReloadNotSupported {
+		t.Errorf("SetRegistryMirrors: wrong error. Want %#v. Got %#v.", ErrConfigReloadNotSupported, err)
+	}
+}
+
 func TestParseRepositoryTag(t *testing.T) {
 	t.Parallel()
 	var tests = []struct {
@@ -234,3 +440,58 @@ func TestParseRepositoryTag(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDaemonMetrics(t *testing.T) {
+	t.Parallel()
+	body := `# HELP engine_daemon_container_states_containers The count of containers in various states
+# TYPE engine_daemon_container_states_containers gauge
+engine_daemon_container_states_containers{state="paused"} 0
+engine_daemon_container_states_containers{state="running"} 3
+engine_daemon_engine_info{architecture="x86_64",commit="4d60db4",kernel="4.9.125-linuxkit",os="Docker for Mac"} 1
+builder_builds_failed_total{reason="build_canceled"} 0
+go_goroutines 87
+process_start_time_seconds 1.54712640078e+09 1547126400
+http_response_size_bytes{handler="prometheus",quantile="0.5"} NaN
+`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	metrics, err := client.GetDaemonMetrics(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]float64{
+		`engine_daemon_container_states_containers{state="paused"}`:                                                       0,
+		`engine_daemon_container_states_containers{state="running"}`:                                                      3,
+		`engine_daemon_engine_info{architecture="x86_64",commit="4d60db4",kernel="4.9.125-linuxkit",os="Docker for Mac"}`: 1,
+		`builder_builds_failed_total{reason="build_canceled"}`:                                                            0,
+		"go_goroutines":              87,
+		"process_start_time_seconds": 1.54712640078e+09,
+	}
+	nanKey := `http_response_size_bytes{handler="prometheus",quantile="0.5"}`
+	if value, ok := metrics[nanKey]; !ok || !math.IsNaN(value) {
+		t.Errorf("GetDaemonMetrics: expected NaN for %s. Got %v.", nanKey, value)
+	}
+	delete(metrics, nanKey)
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("GetDaemonMetrics: wrong metrics.\nWant %#v.\nGot  %#v.", expected, metrics)
+	}
+	if path := fakeRT.requests[0].URL.Path; path != "/metrics" {
+		t.Errorf("GetDaemonMetrics: wrong path. Want %q. Got %q.", "/metrics", path)
+	}
+}
+
+func TestGetDaemonMetricsNotEnabled(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "page not found", status: http.StatusNotFound})
+	if _, err := client.GetDaemonMetrics(context.Background()); err != ErrMetricsNotEnabled {
+		t.Errorf("GetDaemonMetrics: wrong error. Want %#v. Got %#v.", ErrMetricsNotEnabled, err)
+	}
+}
+
+func TestGetDaemonMetricsInvalid(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "go_goroutines lots\n", status: http.StatusOK})
+	if _, err := client.GetDaemonMetrics(context.Background()); err == nil {
+		t.Error("GetDaemonMetrics: expected error for invalid value")
+	}
+}
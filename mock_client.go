@@ -0,0 +1,180 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// mockEndpoint is the endpoint of the clients created by NewMockClient and
+// NewRecordingClient. No connection is ever made to it.
+const mockEndpoint = "http://mock.docker"
+
+// MockResponse is a response given by a client created with NewMockClient.
+type MockResponse struct {
+	StatusCode int
+	Body       string
+	Headers    map[string]string
+}
+
+// NewMockClient returns a Client that, instead of connecting to a Docker
+// daemon, answers each request with one of the given responses. Responses
+// are keyed by an HTTP method and a path prefix, like "GET /containers/",
+// and the longest prefix matching the path of the request, without the API
+// version, is used. Requests matching no response get a 404.
+//
+// A zero StatusCode means 200. Operations that hijack the connection, such
+// as attaching to a container, are not supported.
+func NewMockClient(responses map[string]MockResponse) (*Client, error) {
+	client, err := NewClient(mockEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newMockTransport(responses)
+	if err != nil {
+		return nil, err
+	}
+	client.HTTPClient = &http.Client{Transport: transport}
+	return client, nil
+}
+
+// RecordedRequest is a request received by a RecordingClient.
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// RecordingClient is a mock Client, like the ones returned by
+// NewMockClient, that records the requests it receives.
+type RecordingClient struct {
+	*Client
+
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// NewRecordingClient returns a RecordingClient answering requests with the
+// given responses, matched as in NewMockClient.
+func NewRecordingClient(responses map[string]MockResponse) (*RecordingClient, error) {
+	client, err := NewClient(mockEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newMockTransport(responses)
+	if err != nil {
+		return nil, err
+	}
+	rc := &RecordingClient{Client: client}
+	transport.record = rc.record
+	client.HTTPClient = &http.Client{Transport: transport}
+	return rc, nil
+}
+
+// Requests returns the requests received by the client so far, in order.
+func (c *RecordingClient) Requests() []RecordedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]RecordedRequest(nil), c.requests...)
+}
+
+// Reset discards the requests received by the client so far.
+func (c *RecordingClient) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = nil
+}
+
+func (c *RecordingClient) record(req RecordedRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+}
+
+type mockRoute struct {
+	method string
+	prefix string
+}
+
+type mockTransport struct {
+	responses map[mockRoute]MockResponse
+	record    func(RecordedRequest)
+}
+
+func newMockTransport(responses map[string]MockResponse) (*mockTransport, error) {
+	t := &mockTransport{responses: make(map[mockRoute]MockResponse, len(responses))}
+	for key, resp := range responses {
+		parts := strings.Fields(key)
+		if len(parts) != 2 || !strings.HasPrefix(parts[1], "/") {
+			return nil, fmt.Errorf("invalid mock response key %q: want a method and a path prefix", key)
+		}
+		t.responses[mockRoute{method: strings.ToUpper(parts[0]), prefix: parts[1]}] = resp
+	}
+	return t, nil
+}
+
+func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := versionPrefixRegexp.ReplaceAllString(req.URL.Path, "/")
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t.record != nil {
+		t.record(RecordedRequest{
+			Method: req.Method,
+			Path:   path,
+			Query:  req.URL.Query(),
+			Header: req.Header,
+			Body:   body,
+		})
+	}
+	var (
+		found  bool
+		prefix string
+		mock   MockResponse
+	)
+	for route, resp := range t.responses {
+		if route.method == req.Method && strings.HasPrefix(path, route.prefix) && (!found || len(route.prefix) > len(prefix)) {
+			found, prefix, mock = true, route.prefix, resp
+		}
+	}
+	if !found {
+		mock = MockResponse{
+			StatusCode: http.StatusNotFound,
+			Body:       fmt.Sprintf("no mock response for %s %s", req.Method, path),
+		}
+	}
+	if mock.StatusCode == 0 {
+		mock.StatusCode = http.StatusOK
+	}
+	header := make(http.Header, len(mock.Headers))
+	for key, value := range mock.Headers {
+		header.Set(key, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", mock.StatusCode, http.StatusText(mock.StatusCode)),
+		StatusCode:    mock.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(mock.Body)),
+		ContentLength: int64(len(mock.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMockClient(t *testing.T) {
+	t.Parallel()
+	client, err := NewMockClient(map[string]MockResponse{
+		"GET /containers/":         {StatusCode: http.StatusNotFound, Body: "no such container"},
+		"GET /containers/web/json": {Body: `{"Id":"web","Name":"/web"}`},
+		"POST /containers/":        {StatusCode: http.StatusNotModified},
+		"GET /_ping":               {Body: "OK", Headers: map[string]string{"API-Version": "1.40"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.InspectContainer("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.ID != "web" {
+		t.Errorf("InspectContainer: wrong ID. Want %q. Got %q.", "web", container.ID)
+	}
+	_, err = client.InspectContainer("db")
+	if expected := (&NoSuchContainer{ID: "db"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("InspectContainer: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	err = client.StartContainer("web", nil)
+	if expected := (&ContainerAlreadyRunning{ID: "web"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("StartContainer: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	if err = client.Ping(); err != nil {
+		t.Errorf("Ping: unexpected error: %s", err)
+	}
+	_, err = client.ListImages(ListImagesOptions{})
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusNotFound {
+		t.Errorf("ListImages: wrong error. Want a 404. Got %#v.", err)
+	}
+}
+
+func TestMockClientInvalidKey(t *testing.T) {
+	t.Parallel()
+	for _, key := range []string{"GET", "/containers/", "GET containers/"} {
+		if _, err := NewMockClient(map[string]MockResponse{key: {}}); err == nil {
+			t.Errorf("NewMockClient(%q): unexpected <nil> error", key)
+		}
+	}
+}
+
+func TestRecordingClient(t *testing.T) {
+	t.Parallel()
+	client, err := NewRecordingClient(map[string]MockResponse{
+		"POST /containers/create": {StatusCode: http.StatusCreated, Body: `{"Id":"abc123"}`},
+		"DELETE /containers/":     {StatusCode: http.StatusNoContent},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the API version is not part of the recorded paths
+	client.requestedAPIVersion, _ = NewAPIVersion("1.25")
+	_, err = client.CreateContainer(CreateContainerOptions{Name: "web", Config: &Config{Image: "ubuntu"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.RemoveContainer(RemoveContainerOptions{ID: "abc123", Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := client.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Requests: wrong number of requests. Want 2. Got %d.", len(requests))
+	}
+	if requests[0].Method != http.MethodPost || requests[0].Path != "/containers/create" || requests[0].Query.Get("name") != "web" {
+		t.Errorf("Requests: wrong first request: %#v", requests[0])
+	}
+	var config Config
+	if err = json.Unmarshal(requests[0].Body, &config); err != nil || config.Image != "ubuntu" {
+		t.Errorf("Requests: wrong body: %q", requests[0].Body)
+	}
+	if requests[1].Method != http.MethodDelete || requests[1].Path != "/containers/abc123" || requests[1].Query.Get("force") != "1" {
+		t.Errorf("Requests: wrong second request: %#v", requests[1])
+	}
+	client.Reset()
+	if n := len(client.Requests()); n != 0 {
+		t.Errorf("Reset: wrong number of requests. Want 0. Got %d.", n)
+	}
+}
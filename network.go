@@ -9,14 +9,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ErrNetworkAlreadyExists is the error returned by CreateNetwork when the
 // network already exists.
 var ErrNetworkAlreadyExists = errors.New("network already exists")
 
+// ErrAmbiguousNetworkName is the error returned by NetworkByName when the
+// given name doesn't identify a single network.
+var ErrAmbiguousNetworkName = errors.New("network name matches more than one network")
+
 // Network represents a network.
 //
 // See https://goo.gl/6GugX3 for more details.
@@ -31,6 +39,7 @@ type Network struct {
 	Internal   bool
 	EnableIPv6 bool `json:"EnableIPv6"`
 	Labels     map[string]string
+	Created    time.Time `json:"Created,omitempty" yaml:"Created,omitempty" toml:"Created,omitempty"`
 }
 
 // Endpoint contains network resources allocated and used for a container in a network
@@ -68,6 +77,10 @@ type NetworkFilterOpts map[string]map[string]bool
 //
 // See goo.gl/zd2mx4 for more details.
 func (c *Client) FilteredListNetworks(opts NetworkFilterOpts) ([]Network, error) {
+	return c.filteredListNetworks(opts, nil)
+}
+
+func (c *Client) filteredListNetworks(opts NetworkFilterOpts, ctx context.Context) ([]Network, error) {
 	params, err := json.Marshal(opts)
 	if err != nil {
 		return nil, err
@@ -75,7 +88,7 @@ func (c *Client) FilteredListNetworks(opts NetworkFilterOpts) ([]Network, error)
 	qs := make(url.Values)
 	qs.Add("filters", string(params))
 	path := "/networks?" + qs.Encode()
-	resp, err := c.do("GET", path, doOptions{})
+	resp, err := c.do("GET", path, doOptions{context: ctx})
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +100,44 @@ func (c *Client) FilteredListNetworks(opts NetworkFilterOpts) ([]Network, error)
 	return networks, nil
 }
 
+// NetworkByName returns the network whose name is exactly the given one. It
+// returns NoSuchNetwork when no network has the name, and
+// ErrAmbiguousNetworkName when several networks have it, or when the name
+// only matches part of the names of other networks, as the daemon does when
+// filtering networks by name.
+//
+// See https://goo.gl/6GugX3 for more details.
+func (c *Client) NetworkByName(name string, ctx context.Context) (*Network, error) {
+	networks, err := c.filteredListNetworks(NetworkFilterOpts{"name": {name: true}}, ctx)
+	if err != nil {
+		return nil, err
+	}
+	var exact []Network
+	for _, network := range networks {
+		if network.Name == name {
+			exact = append(exact, network)
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		return &exact[0], nil
+	case len(networks) == 0:
+		return nil, &NoSuchNetwork{ID: name}
+	default:
+		return nil, ErrAmbiguousNetworkName
+	}
+}
+
+// NetworkIDByName returns the ID of the network with the given name. See
+// NetworkByName for how the name is matched.
+func (c *Client) NetworkIDByName(name string, ctx context.Context) (string, error) {
+	network, err := c.NetworkByName(name, ctx)
+	if err != nil {
+		return "", err
+	}
+	return network.ID, nil
+}
+
 // NetworkInfo returns information about a network by its ID.
 //
 // See https://goo.gl/6GugX3 for more details.
@@ -107,6 +158,32 @@ func (c *Client) NetworkInfo(id string) (*Network, error) {
 	return &network, nil
 }
 
+// NetworkInfoRaw returns information about a network by its ID, along with the
+// raw JSON payload sent by the daemon. The raw payload gives access to fields
+// that are not yet modeled by the Network type.
+//
+// See https://goo.gl/6GugX3 for more details.
+func (c *Client) NetworkInfoRaw(id string) (*Network, json.RawMessage, error) {
+	path := "/networks/" + id
+	resp, err := c.do("GET", path, doOptions{})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return nil, nil, &NoSuchNetwork{ID: id}
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var network Network
+	if err := json.Unmarshal(raw, &network); err != nil {
+		return nil, nil, err
+	}
+	return &network, raw, nil
+}
+
 // CreateNetworkOptions specify parameters to the CreateNetwork function and
 // (for now) is the expected body of the "create network" http request message
 //
@@ -236,6 +313,54 @@ type EndpointIPAMConfig struct {
 	IPv6Address string `json:",omitempty"`
 }
 
+// EndpointOption configures an EndpointConfig created with
+// NewEndpointConfig.
+type EndpointOption func(*EndpointConfig)
+
+// NewEndpointConfig returns an EndpointConfig configured with the given
+// options, to be used in ConnectNetwork:
+//
+//	config := docker.NewEndpointConfig(
+//		docker.WithIPv4Address("172.20.0.10"),
+//		docker.WithIPv6Address("2001:db8::10"),
+//	)
+func NewEndpointConfig(opts ...EndpointOption) *EndpointConfig {
+	var config EndpointConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &config
+}
+
+// WithIPv4Address sets the static IPv4 address of the endpoint, which must
+// be in one of the subnets of the network.
+func WithIPv4Address(addr string) EndpointOption {
+	return func(config *EndpointConfig) {
+		if config.IPAMConfig == nil {
+			config.IPAMConfig = new(EndpointIPAMConfig)
+		}
+		config.IPAMConfig.IPv4Address = addr
+	}
+}
+
+// WithIPv6Address sets the static IPv6 address of the endpoint, which must
+// be in one of the subnets of a network with IPv6 enabled.
+func WithIPv6Address(addr string) EndpointOption {
+	return func(config *EndpointConfig) {
+		if config.IPAMConfig == nil {
+			config.IPAMConfig = new(EndpointIPAMConfig)
+		}
+		config.IPAMConfig.IPv6Address = addr
+	}
+}
+
+// WithAliases adds network-scoped aliases to the endpoint.
+func WithAliases(aliases ...string) EndpointOption {
+	return func(config *EndpointConfig) {
+		config.Aliases = append(config.Aliases, aliases...)
+	}
+}
+
 // ConnectNetwork adds a container to a network or returns an error in case of
 // failure.
 //
@@ -276,6 +401,13 @@ func (c *Client) DisconnectNetwork(id string, opts NetworkConnectionOptions) err
 // See https://goo.gl/kX0S9h for more details.
 type PruneNetworksOptions struct {
 	Filters map[string][]string
+
+	// DryRun makes PruneNetworks list the dangling networks instead of
+	// deleting them, so the caller can preview the outcome of the prune.
+	// The until filter is then applied by the client, to the creation time
+	// of the networks.
+	DryRun bool `qs:"-"`
+
 	Context context.Context
 }
 
@@ -288,8 +420,14 @@ type PruneNetworksResults struct {
 
 // PruneNetworks deletes networks which are unused.
 //
+// When opts.DryRun is set, no network is deleted and the results contain the
+// names of the networks that would have been deleted.
+//
 // See https://goo.gl/kX0S9h for more details.
 func (c *Client) PruneNetworks(opts PruneNetworksOptions) (*PruneNetworksResults, error) {
+	if opts.DryRun {
+		return c.pruneNetworksDryRun(opts)
+	}
 	path := "/networks/prune?" + queryString(opts)
 	resp, err := c.do("POST", path, doOptions{context: opts.Context})
 	if err != nil {
@@ -303,6 +441,66 @@ func (c *Client) PruneNetworks(opts PruneNetworksOptions) (*PruneNetworksResults
 	return &results, nil
 }
 
+func (c *Client) pruneNetworksDryRun(opts PruneNetworksOptions) (*PruneNetworksResults, error) {
+	// until is only understood by the prune endpoint, so it's applied here
+	var until time.Time
+	filters := map[string][]string{"dangling": {"true"}}
+	for k, v := range opts.Filters {
+		switch k {
+		case "dangling":
+		case "until":
+			if len(v) != 1 {
+				return nil, errors.New("exactly one until filter is allowed")
+			}
+			var err error
+			if until, err = parseUntilFilter(v[0], time.Now()); err != nil {
+				return nil, err
+			}
+		default:
+			filters[k] = v
+		}
+	}
+	path := "/networks?" + queryString(PruneNetworksOptions{Filters: filters})
+	resp, err := c.do("GET", path, doOptions{context: opts.Context})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var networks []Network
+	if err := json.NewDecoder(resp.Body).Decode(&networks); err != nil {
+		return nil, err
+	}
+	results := PruneNetworksResults{NetworksDeleted: make([]string, 0, len(networks))}
+	for _, network := range networks {
+		if !until.IsZero() && network.Created.After(until) {
+			continue
+		}
+		results.NetworksDeleted = append(results.NetworksDeleted, network.Name)
+	}
+	return &results, nil
+}
+
+// parseUntilFilter parses the value of an until filter like the daemon does:
+// either a duration before now, a Unix timestamp or a date, in the local time
+// zone unless it includes one.
+func parseUntilFilter(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if !strings.ContainsAny(value, "-:") {
+		if secs, err := strconv.ParseFloat(value, 64); err == nil {
+			sec := int64(secs)
+			return time.Unix(sec, int64((secs-float64(sec))*1e9)), nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until filter %q", value)
+}
+
 // NoSuchNetwork is the error returned when a given network does not exist.
 type NoSuchNetwork struct {
 	ID string
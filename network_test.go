@@ -6,11 +6,13 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestListNetworks(t *testing.T) {
@@ -80,6 +82,45 @@ func TestFilteredListNetworks(t *testing.T) {
 	}
 }
 
+func TestNetworkByName(t *testing.T) {
+	t.Parallel()
+	jsonNetworks := `[{"ID":"8dfafdbc3a40","Name":"blah"},{"ID":"9fb1e39c","Name":"blah-test"}]`
+	fakeRT := &FakeRoundTripper{message: jsonNetworks, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	network, err := client.NetworkByName("blah", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if network.ID != "8dfafdbc3a40" {
+		t.Errorf("NetworkByName: wrong network. Want %q. Got %q.", "8dfafdbc3a40", network.ID)
+	}
+	expectedFilters := `{"name":{"blah":true}}`
+	if filters := fakeRT.requests[0].URL.Query().Get("filters"); filters != expectedFilters {
+		t.Errorf("NetworkByName: wrong filters. Want %q. Got %q.", expectedFilters, filters)
+	}
+	if _, err = client.NetworkByName("bl", context.Background()); err != ErrAmbiguousNetworkName {
+		t.Errorf("NetworkByName: wrong error. Want %#v. Got %#v.", ErrAmbiguousNetworkName, err)
+	}
+}
+
+func TestNetworkByNameUniquePartialMatch(t *testing.T) {
+	t.Parallel()
+	jsonNetworks := `[{"ID":"9fb1e39c","Name":"blah-test"}]`
+	client := newTestClient(&FakeRoundTripper{message: jsonNetworks, status: http.StatusOK})
+	if _, err := client.NetworkByName("blah", context.Background()); err != ErrAmbiguousNetworkName {
+		t.Errorf("NetworkByName: wrong error. Want %#v. Got %#v.", ErrAmbiguousNetworkName, err)
+	}
+}
+
+func TestNetworkByNameNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: `[]`, status: http.StatusOK})
+	_, err := client.NetworkByName("blah", context.Background())
+	if expected := (&NoSuchNetwork{ID: "blah"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("NetworkByName: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
 func TestNetworkInfo(t *testing.T) {
 	t.Parallel()
 	jsonNetwork := `{
@@ -109,6 +150,38 @@ func TestNetworkInfo(t *testing.T) {
 	}
 }
 
+func TestNetworkInfoRaw(t *testing.T) {
+	t.Parallel()
+	jsonNetwork := `{"Id": "8dfafdbc3a40", "Name": "blah", "Driver": "bridge", "ConfigOnly": false, "Attachable": true}`
+	fakeRT := &FakeRoundTripper{message: jsonNetwork, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	network, raw, err := client.NetworkInfoRaw("8dfafdbc3a40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Network{ID: "8dfafdbc3a40", Name: "blah", Driver: "bridge"}
+	if !reflect.DeepEqual(network, expected) {
+		t.Errorf("NetworkInfoRaw: Expected %#v. Got %#v.", expected, network)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["Attachable"] != true {
+		t.Errorf("NetworkInfoRaw: raw payload missing unmodeled field. Got %s.", raw)
+	}
+}
+
+func TestNetworkInfoRawNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such network", status: http.StatusNotFound})
+	_, _, err := client.NetworkInfoRaw("8dfafdbc3a40")
+	expected := &NoSuchNetwork{ID: "8dfafdbc3a40"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("NetworkInfoRaw: Wrong error information. Want %#v. Got %#v.", expected, err)
+	}
+}
+
 func TestNetworkCreate(t *testing.T) {
 	jsonID := `{"ID": "8dfafdbc3a40"}`
 	jsonNetwork := `{
@@ -276,3 +349,150 @@ func TestPruneNetworks(t *testing.T) {
 		t.Errorf("PruneNetworks: Expected %#v. Got %#v.", expected, got)
 	}
 }
+
+func TestPruneNetworksDryRun(t *testing.T) {
+	t.Parallel()
+	jsonNetworks := `[
+     {
+             "ID": "8dfafdbc3a40",
+             "Name": "blah",
+             "Driver": "bridge"
+     },
+     {
+             "ID": "9fb1e39c",
+             "Name": "foo",
+             "Driver": "bridge"
+     }
+]`
+	fakeRT := &FakeRoundTripper{message: jsonNetworks, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	got, err := client.PruneNetworks(PruneNetworksOptions{
+		DryRun:  true,
+		Filters: map[string][]string{"label": {"app=web"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &PruneNetworksResults{NetworksDeleted: []string{"blah", "foo"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PruneNetworks: Expected %#v. Got %#v.", expected, got)
+	}
+	req := fakeRT.requests[0]
+	if req.Method != "GET" {
+		t.Errorf("PruneNetworks: wrong HTTP method. Want GET. Got %s.", req.Method)
+	}
+	u, _ := url.Parse(client.getURL("/networks"))
+	if req.URL.Path != u.Path {
+		t.Errorf("PruneNetworks: wrong request path. Want %q. Got %q.", u.Path, req.URL.Path)
+	}
+	expectedFilters := `{"dangling":["true"],"label":["app=web"]}`
+	if filters := req.URL.Query().Get("filters"); filters != expectedFilters {
+		t.Errorf("PruneNetworks: wrong filters. Want %q. Got %q.", expectedFilters, filters)
+	}
+}
+
+func TestPruneNetworksDryRunUntil(t *testing.T) {
+	t.Parallel()
+	jsonNetworks := `[
+     {
+             "ID": "8dfafdbc3a40",
+             "Name": "old",
+             "Created": "2019-01-01T00:00:00Z"
+     },
+     {
+             "ID": "9fb1e39c",
+             "Name": "new",
+             "Created": "2019-03-01T00:00:00Z"
+     }
+]`
+	fakeRT := &FakeRoundTripper{message: jsonNetworks, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	got, err := client.PruneNetworks(PruneNetworksOptions{
+		DryRun:  true,
+		Filters: map[string][]string{"until": {"2019-02-01T00:00:00Z"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &PruneNetworksResults{NetworksDeleted: []string{"old"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PruneNetworks: Expected %#v. Got %#v.", expected, got)
+	}
+	expectedFilters := `{"dangling":["true"]}`
+	if filters := fakeRT.requests[0].URL.Query().Get("filters"); filters != expectedFilters {
+		t.Errorf("PruneNetworks: wrong filters. Want %q. Got %q.", expectedFilters, filters)
+	}
+	_, err = client.PruneNetworks(PruneNetworksOptions{
+		DryRun:  true,
+		Filters: map[string][]string{"until": {"yesterday"}},
+	})
+	if err == nil {
+		t.Error("PruneNetworks: expected error for an invalid until filter")
+	}
+}
+
+func TestParseUntilFilter(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, 2, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"24h", now.Add(-24 * time.Hour)},
+		{"1548979200", time.Unix(1548979200, 0)},
+		{"1548979200.5", time.Unix(1548979200, 5e8)},
+		{"2019-01-31T10:00:00Z", time.Date(2019, 1, 31, 10, 0, 0, 0, time.UTC)},
+		{"2019-01-31", time.Date(2019, 1, 31, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseUntilFilter(tt.value, now)
+		if err != nil {
+			t.Errorf("parseUntilFilter(%q): unexpected error: %s", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseUntilFilter(%q): wrong time. Want %s. Got %s.", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestNewEndpointConfig(t *testing.T) {
+	t.Parallel()
+	config := NewEndpointConfig(
+		WithIPv4Address("172.20.0.10"),
+		WithIPv6Address("2001:db8::10"),
+		WithAliases("web", "www"),
+	)
+	expected := &EndpointConfig{
+		IPAMConfig: &EndpointIPAMConfig{IPv4Address: "172.20.0.10", IPv6Address: "2001:db8::10"},
+		Aliases:    []string{"web", "www"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("NewEndpointConfig: wrong config. Want %#v. Got %#v.", expected, config)
+	}
+	if config := NewEndpointConfig(); !reflect.DeepEqual(config, &EndpointConfig{}) {
+		t.Errorf("NewEndpointConfig: wrong empty config. Got %#v.", config)
+	}
+}
+
+func TestNetworkConnectWithIPv6Endpoint(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusNoContent}
+	client := newTestClient(fakeRT)
+	opts := NetworkConnectionOptions{
+		Container:      "foobar",
+		EndpointConfig: NewEndpointConfig(WithIPv6Address("2001:db8::10")),
+	}
+	if err := client.ConnectNetwork("8dfafdbc3a40", opts); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	ipam := got["EndpointConfig"].(map[string]interface{})["IPAMConfig"]
+	expected := map[string]interface{}{"IPv6Address": "2001:db8::10"}
+	if !reflect.DeepEqual(ipam, expected) {
+		t.Errorf("ConnectNetwork: wrong IPAM config. Want %#v. Got %#v.", expected, ipam)
+	}
+}
@@ -5,12 +5,19 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 )
 
+// ErrPluginPrivilegesNotAccepted is the error returned by InstallPlugin and
+// UpgradePlugin when the privileges requested by the plugin are not accepted.
+var ErrPluginPrivilegesNotAccepted = errors.New("plugin privileges not accepted")
+
 // PluginPrivilege represents a privilege for a plugin.
 type PluginPrivilege struct {
 	Name        string   `json:"Name,omitempty" yaml:"Name,omitempty" toml:"Name,omitempty"`
@@ -28,6 +35,14 @@ type InstallPluginOptions struct {
 
 	Auth AuthConfiguration
 
+	// AcceptPrivileges is called by InstallPlugin with the privileges
+	// requested by the plugin, which is only installed if it returns true.
+	// When it's nil, plugins requesting any privilege are not installed.
+	AcceptPrivileges func([]PluginPrivilege) bool `qs:"-"`
+
+	// Disabled makes InstallPlugin leave the plugin disabled.
+	Disabled bool `qs:"-"`
+
 	Context context.Context
 }
 
@@ -47,6 +62,122 @@ func (c *Client) InstallPlugins(opts InstallPluginOptions) error {
 	return nil
 }
 
+// InstallPlugin pulls a plugin, granting it the privileges accepted by
+// opts.AcceptPrivileges, and enables it, unless opts.Disabled is set. It
+// returns the ID of the installed plugin. The Plugins field of opts is
+// ignored.
+//
+// See https://goo.gl/C4t7Tz for more details.
+func (c *Client) InstallPlugin(opts InstallPluginOptions) (string, error) {
+	privileges, err := c.acceptPluginPrivileges(opts.Remote, opts.Auth, opts.AcceptPrivileges, opts.Context)
+	if err != nil {
+		return "", err
+	}
+	params := make(url.Values)
+	params.Set("remote", opts.Remote)
+	name := opts.Remote
+	if opts.Name != "" {
+		params.Set("name", opts.Name)
+		name = opts.Name
+	}
+	err = c.pullPlugin("/plugins/pull?"+params.Encode(), privileges, opts.Auth, opts.Context)
+	if err != nil {
+		return "", err
+	}
+	plugin, err := c.InspectPlugins(name, opts.Context)
+	if err != nil {
+		return "", err
+	}
+	if !opts.Disabled {
+		err = c.EnablePlugin(EnablePluginOptions{Name: plugin.ID, Context: opts.Context})
+	}
+	return plugin.ID, err
+}
+
+// UpgradePluginOptions specify parameters to the UpgradePlugin function.
+//
+// See https://goo.gl/C4t7Tz for more details.
+type UpgradePluginOptions struct {
+	// The Name of the plugin.
+	Name string
+
+	// Remote is the reference of the new version of the plugin.
+	Remote string
+
+	Auth AuthConfiguration
+
+	// AcceptPrivileges is called with the privileges requested by the new
+	// version of the plugin, which is only installed if it returns true.
+	// When it's nil, versions requesting any privilege are not installed.
+	AcceptPrivileges func([]PluginPrivilege) bool
+
+	Context context.Context
+}
+
+// UpgradePlugin upgrades a plugin to the version referenced by opts.Remote,
+// granting it the privileges accepted by opts.AcceptPrivileges. The plugin
+// must be disabled.
+//
+// See https://goo.gl/C4t7Tz for more details.
+func (c *Client) UpgradePlugin(opts UpgradePluginOptions) error {
+	privileges, err := c.acceptPluginPrivileges(opts.Remote, opts.Auth, opts.AcceptPrivileges, opts.Context)
+	if err != nil {
+		return err
+	}
+	params := make(url.Values)
+	params.Set("remote", opts.Remote)
+	err = c.pullPlugin("/plugins/"+opts.Name+"/upgrade?"+params.Encode(), privileges, opts.Auth, opts.Context)
+	if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+		return &NoSuchPlugin{ID: opts.Name}
+	}
+	return err
+}
+
+func (c *Client) acceptPluginPrivileges(remote string, auth AuthConfiguration, accept func([]PluginPrivilege) bool, ctx context.Context) ([]PluginPrivilege, error) {
+	headers, err := headersWithAuth(auth)
+	if err != nil {
+		return nil, err
+	}
+	params := make(url.Values)
+	params.Set("remote", remote)
+	resp, err := c.do("GET", "/plugins/privileges?"+params.Encode(), doOptions{
+		headers: headers,
+		context: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	privileges := make([]PluginPrivilege, 0)
+	if err = json.NewDecoder(resp.Body).Decode(&privileges); err != nil {
+		return nil, err
+	}
+	if len(privileges) > 0 && (accept == nil || !accept(privileges)) {
+		return nil, ErrPluginPrivilegesNotAccepted
+	}
+	return privileges, nil
+}
+
+// pullPlugin sends the granted privileges to the pull or upgrade endpoint,
+// reading the progress of the operation until it finishes.
+func (c *Client) pullPlugin(path string, privileges []PluginPrivilege, auth AuthConfiguration, ctx context.Context) error {
+	headers, err := headersWithAuth(auth)
+	if err != nil {
+		return err
+	}
+	headers["Content-Type"] = "application/json"
+	data, err := json.Marshal(privileges)
+	if err != nil {
+		return err
+	}
+	return c.stream("POST", path, streamOptions{
+		useJSONDecoder: true,
+		headers:        headers,
+		in:             bytes.NewReader(data),
+		context:        ctx,
+	})
+}
+
 // PluginSettings stores plugin settings.
 //
 // See https://goo.gl/C4t7Tz for more details.
@@ -218,17 +349,13 @@ func (c *Client) InspectPlugins(name string, ctx context.Context) (*PluginDetail
 	resp, err := c.do("GET", "/plugins/"+name+"/json", doOptions{
 		context: ctx,
 	})
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, &NoSuchPlugin{ID: name}
 		}
 		return nil, err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 	var pluginDetail PluginDetail
 	if err := json.NewDecoder(resp.Body).Decode(&pluginDetail); err != nil {
 		return nil, err
@@ -253,17 +380,13 @@ type RemovePluginOptions struct {
 func (c *Client) RemovePlugin(opts RemovePluginOptions) (*PluginDetail, error) {
 	path := "/plugins/" + opts.Name + "?" + queryString(opts)
 	resp, err := c.do("DELETE", path, doOptions{context: opts.Context})
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, &NoSuchPlugin{ID: opts.Name}
 		}
 		return nil, err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 	var pluginDetail PluginDetail
 	if err := json.NewDecoder(resp.Body).Decode(&pluginDetail); err != nil {
 		return nil, err
@@ -289,6 +412,9 @@ func (c *Client) EnablePlugin(opts EnablePluginOptions) error {
 	path := "/plugins/" + opts.Name + "/enable?" + queryString(opts)
 	resp, err := c.do("POST", path, doOptions{context: opts.Context})
 	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return &NoSuchPlugin{ID: opts.Name}
+		}
 		return err
 	}
 	resp.Body.Close()
@@ -312,6 +438,9 @@ func (c *Client) DisablePlugin(opts DisablePluginOptions) error {
 	path := "/plugins/" + opts.Name + "/disable"
 	resp, err := c.do("POST", path, doOptions{context: opts.Context})
 	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return &NoSuchPlugin{ID: opts.Name}
+		}
 		return err
 	}
 	resp.Body.Close()
@@ -414,3 +543,8 @@ func (err *NoSuchPlugin) Error() string {
 	}
 	return "No such plugin: " + err.ID
 }
+
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchPlugin) Unwrap() error {
+	return err.Err
+}
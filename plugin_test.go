@@ -9,7 +9,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -314,3 +317,211 @@ func TestConfigurePlugin(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// fakePluginServer simulates the plugin endpoints involved in the
+// installation and upgrade of a plugin.
+type fakePluginServer struct {
+	privileges []PluginPrivilege
+	pullError  string
+
+	mu       sync.Mutex
+	granted  []PluginPrivilege
+	requests []string
+	auth     string
+	enabled  bool
+}
+
+func (s *fakePluginServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/plugins/privileges":
+		json.NewEncoder(w).Encode(s.privileges)
+	case r.Method == "POST" && (r.URL.Path == "/plugins/pull" || r.URL.Path == "/plugins/sshfs/upgrade"):
+		s.auth = r.Header.Get("X-Registry-Auth")
+		if err := json.NewDecoder(r.Body).Decode(&s.granted); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"status":"Downloading"}`)
+		if s.pullError != "" {
+			fmt.Fprintf(w, `{"errorDetail":{"message":%q},"error":%q}`+"\n", s.pullError, s.pullError)
+			return
+		}
+		fmt.Fprintln(w, `{"status":"Download complete"}`)
+	case r.Method == "GET" && r.URL.Path == "/plugins/sshfs/json":
+		json.NewEncoder(w).Encode(PluginDetail{ID: "2f3b7e5a", Name: "sshfs:latest", Active: s.enabled})
+	case r.Method == "POST" && r.URL.Path == "/plugins/2f3b7e5a/enable":
+		s.enabled = true
+	default:
+		http.Error(w, "plugin not found", http.StatusNotFound)
+	}
+}
+
+func TestInstallPlugin(t *testing.T) {
+	t.Parallel()
+	privileges := []PluginPrivilege{
+		{Name: "network", Value: []string{"host"}},
+		{Name: "capabilities", Value: []string{"CAP_SYS_ADMIN"}},
+	}
+	fake := &fakePluginServer{privileges: privileges}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requested []PluginPrivilege
+	id, err := client.InstallPlugin(InstallPluginOptions{
+		Remote: "vieux/sshfs",
+		Name:   "sshfs",
+		Auth:   AuthConfiguration{Username: "gopher", Password: "secret"},
+		AcceptPrivileges: func(p []PluginPrivilege) bool {
+			requested = p
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "2f3b7e5a" {
+		t.Errorf("InstallPlugin: wrong ID. Want %q. Got %q.", "2f3b7e5a", id)
+	}
+	if !reflect.DeepEqual(requested, privileges) {
+		t.Errorf("InstallPlugin: wrong privileges given to the callback. Want %#v. Got %#v.", privileges, requested)
+	}
+	if !reflect.DeepEqual(fake.granted, privileges) {
+		t.Errorf("InstallPlugin: wrong privileges granted. Want %#v. Got %#v.", privileges, fake.granted)
+	}
+	if fake.auth == "" {
+		t.Error("InstallPlugin: registry auth not sent when pulling the plugin")
+	}
+	if !fake.enabled {
+		t.Error("InstallPlugin: plugin not enabled")
+	}
+	expectedRequests := []string{
+		"GET /plugins/privileges?remote=vieux%2Fsshfs",
+		"POST /plugins/pull?name=sshfs&remote=vieux%2Fsshfs",
+		"GET /plugins/sshfs/json?",
+		"POST /plugins/2f3b7e5a/enable?",
+	}
+	if !reflect.DeepEqual(fake.requests, expectedRequests) {
+		t.Errorf("InstallPlugin: wrong requests.\nWant %#v.\nGot  %#v.", expectedRequests, fake.requests)
+	}
+}
+
+func TestInstallPluginDisabled(t *testing.T) {
+	t.Parallel()
+	fake := &fakePluginServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := client.InstallPlugin(InstallPluginOptions{Remote: "vieux/sshfs", Name: "sshfs", Disabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "2f3b7e5a" {
+		t.Errorf("InstallPlugin: wrong ID. Want %q. Got %q.", "2f3b7e5a", id)
+	}
+	if fake.enabled {
+		t.Error("InstallPlugin: plugin enabled, but Disabled was set")
+	}
+	if len(fake.granted) != 0 {
+		t.Errorf("InstallPlugin: unexpected privileges granted: %#v.", fake.granted)
+	}
+}
+
+func TestInstallPluginPrivilegesNotAccepted(t *testing.T) {
+	t.Parallel()
+	fake := &fakePluginServer{privileges: []PluginPrivilege{{Name: "network", Value: []string{"host"}}}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []func([]PluginPrivilege) bool{
+		nil,
+		func([]PluginPrivilege) bool { return false },
+	}
+	for _, accept := range tests {
+		_, err = client.InstallPlugin(InstallPluginOptions{Remote: "vieux/sshfs", Name: "sshfs", AcceptPrivileges: accept})
+		if err != ErrPluginPrivilegesNotAccepted {
+			t.Errorf("InstallPlugin: wrong error. Want %#v. Got %#v.", ErrPluginPrivilegesNotAccepted, err)
+		}
+	}
+	for _, request := range fake.requests {
+		if strings.Contains(request, "/plugins/pull") {
+			t.Errorf("InstallPlugin: plugin pulled without accepting its privileges: %s", request)
+		}
+	}
+}
+
+func TestInstallPluginPullFailure(t *testing.T) {
+	t.Parallel()
+	fake := &fakePluginServer{pullError: "manifest unknown"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.InstallPlugin(InstallPluginOptions{Remote: "vieux/sshfs", Name: "sshfs"})
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("InstallPlugin: wrong error. Want %q. Got %v.", "manifest unknown", err)
+	}
+	if fake.enabled {
+		t.Error("InstallPlugin: plugin enabled after a failed pull")
+	}
+}
+
+func TestUpgradePlugin(t *testing.T) {
+	t.Parallel()
+	privileges := []PluginPrivilege{{Name: "mount", Value: []string{"/var/lib/docker/plugins/"}}}
+	fake := &fakePluginServer{privileges: privileges}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.UpgradePlugin(UpgradePluginOptions{
+		Name:             "sshfs",
+		Remote:           "vieux/sshfs:next",
+		AcceptPrivileges: func([]PluginPrivilege) bool { return true },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fake.granted, privileges) {
+		t.Errorf("UpgradePlugin: wrong privileges granted. Want %#v. Got %#v.", privileges, fake.granted)
+	}
+	err = client.UpgradePlugin(UpgradePluginOptions{Name: "other", Remote: "vieux/sshfs:next", AcceptPrivileges: func([]PluginPrivilege) bool { return true }})
+	expected := &NoSuchPlugin{ID: "other"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("UpgradePlugin: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestPluginLifecycleNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "plugin not found", status: http.StatusNotFound})
+	expected := &NoSuchPlugin{ID: "sshfs"}
+	if err := client.EnablePlugin(EnablePluginOptions{Name: "sshfs"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("EnablePlugin: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	if err := client.DisablePlugin(DisablePluginOptions{Name: "sshfs"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("DisablePlugin: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	if _, err := client.RemovePlugin(RemovePluginOptions{Name: "sshfs"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("RemovePlugin: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	if _, err := client.InspectPlugins("sshfs", context.Background()); !reflect.DeepEqual(err, expected) {
+		t.Errorf("InspectPlugins: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
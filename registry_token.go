@@ -0,0 +1,183 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrNoBearerChallenge is the error returned by RequestRegistryToken when the
+// registry requires authentication, but not through the bearer token scheme.
+var ErrNoBearerChallenge = errors.New("registry did not send a bearer token challenge")
+
+// RegistryTokenOptions specify parameters to the RequestRegistryToken
+// function.
+type RegistryTokenOptions struct {
+	// Registry is the address of the registry, such as
+	// registry-1.docker.io. HTTPS is used unless a scheme is given.
+	Registry string
+
+	// Repository is the name of the repository in the registry, such as
+	// library/alpine.
+	Repository string
+
+	// Actions are the actions requested in the repository scope. Defaults
+	// to pull.
+	Actions []string
+
+	// Username and Password are sent to the token server, if set. Anonymous
+	// tokens are requested otherwise.
+	Username string
+	Password string
+
+	// HTTPClient is the client used to talk to the registry and to the
+	// token server. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	Context context.Context
+}
+
+// RequestRegistryToken performs the Docker registry v2 token authentication
+// flow: it parses the realm, service and scope from the WWW-Authenticate
+// challenge sent by the registry and requests a bearer token from the token
+// server. The token is returned in the RegistryToken field of an
+// AuthConfiguration, that can be given to PullImage and PushImage.
+//
+// If the registry doesn't require authentication, the returned
+// AuthConfiguration has no token.
+//
+// See https://docs.docker.com/registry/spec/auth/token/ for more details.
+func RequestRegistryToken(opts RegistryTokenOptions) (AuthConfiguration, error) {
+	auth := AuthConfiguration{ServerAddress: opts.Registry}
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	registry := opts.Registry
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	req, err := http.NewRequest("GET", strings.TrimRight(registry, "/")+"/v2/", nil)
+	if err != nil {
+		return auth, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return auth, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return auth, nil
+	}
+	challenge, ok := parseBearerChallenge(resp.Header.Get("WWW-Authenticate"))
+	if !ok || challenge["realm"] == "" {
+		return auth, ErrNoBearerChallenge
+	}
+	actions := opts.Actions
+	if len(actions) == 0 {
+		actions = []string{"pull"}
+	}
+	params := url.Values{}
+	if service := challenge["service"]; service != "" {
+		params.Set("service", service)
+	}
+	if opts.Repository != "" {
+		params.Set("scope", fmt.Sprintf("repository:%s:%s", opts.Repository, strings.Join(actions, ",")))
+	} else if scope := challenge["scope"]; scope != "" {
+		params.Set("scope", scope)
+	}
+	realm, err := url.Parse(challenge["realm"])
+	if err != nil {
+		return auth, err
+	}
+	realm.RawQuery = params.Encode()
+	req, err = http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return auth, err
+	}
+	if opts.Username != "" || opts.Password != "" {
+		req.SetBasicAuth(opts.Username, opts.Password)
+	}
+	resp, err = client.Do(req.WithContext(ctx))
+	if err != nil {
+		return auth, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return auth, newError(resp)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return auth, err
+	}
+	auth.RegistryToken = token.Token
+	if auth.RegistryToken == "" {
+		auth.RegistryToken = token.AccessToken
+	}
+	if auth.RegistryToken == "" {
+		return auth, errors.New("token server did not return a token")
+	}
+	return auth, nil
+}
+
+// parseBearerChallenge parses the parameters of a WWW-Authenticate header
+// using the Bearer scheme, such as:
+//
+//	Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseBearerChallenge(header string) (map[string]string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return nil, false
+	}
+	params := make(map[string]string)
+	s := parts[1]
+	for {
+		s = strings.TrimLeft(s, " ,")
+		i := strings.Index(s, "=")
+		if i < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:i]))
+		s = s[i+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i = 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			i = strings.Index(s, ",")
+			if i < 0 {
+				i = len(s)
+			}
+			value = strings.TrimSpace(s[:i])
+			s = s[i:]
+		}
+		params[key] = value
+	}
+	return params, true
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseBearerChallenge(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		header   string
+		expected map[string]string
+		ok       bool
+	}{
+		{
+			`Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`,
+			map[string]string{"realm": "https://auth.docker.io/token", "service": "registry.docker.io"},
+			true,
+		},
+		{
+			`bearer realm="https://quay.io/v2/auth", service="quay.io", scope="repository:coreos/etcd:pull,push"`,
+			map[string]string{"realm": "https://quay.io/v2/auth", "service": "quay.io", "scope": "repository:coreos/etcd:pull,push"},
+			true,
+		},
+		{
+			`Bearer realm="https://example.com/token",error=invalid_token`,
+			map[string]string{"realm": "https://example.com/token", "error": "invalid_token"},
+			true,
+		},
+		{`Basic realm="Registry Realm"`, nil, false},
+		{"", nil, false},
+	}
+	for _, test := range tests {
+		params, ok := parseBearerChallenge(test.header)
+		if ok != test.ok {
+			t.Errorf("parseBearerChallenge(%q): wrong ok. Want %v. Got %v.", test.header, test.ok, ok)
+		}
+		if !reflect.DeepEqual(params, test.expected) {
+			t.Errorf("parseBearerChallenge(%q): wrong params. Want %#v. Got %#v.", test.header, test.expected, params)
+		}
+	}
+}
+
+func TestRequestRegistryToken(t *testing.T) {
+	t.Parallel()
+	var tokenRequest *http.Request
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.example.com"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequest = r
+		w.Write([]byte(`{"token":"eyJhbGciOiJFUzI1NiJ9","expires_in":300}`))
+	})
+	auth, err := RequestRegistryToken(RegistryTokenOptions{
+		Registry:   server.URL,
+		Repository: "tsuru/python",
+		Actions:    []string{"pull", "push"},
+		Username:   "user",
+		Password:   "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AuthConfiguration{ServerAddress: server.URL, RegistryToken: "eyJhbGciOiJFUzI1NiJ9"}
+	if auth != expected {
+		t.Errorf("RequestRegistryToken: wrong auth. Want %#v. Got %#v.", expected, auth)
+	}
+	query := tokenRequest.URL.Query()
+	if service := query.Get("service"); service != "registry.example.com" {
+		t.Errorf("RequestRegistryToken: wrong service. Want %q. Got %q.", "registry.example.com", service)
+	}
+	if scope := query.Get("scope"); scope != "repository:tsuru/python:pull,push" {
+		t.Errorf("RequestRegistryToken: wrong scope. Want %q. Got %q.", "repository:tsuru/python:pull,push", scope)
+	}
+	if user, pass, ok := tokenRequest.BasicAuth(); !ok || user != "user" || pass != "secret" {
+		t.Errorf("RequestRegistryToken: wrong basic auth. Got %q:%q.", user, pass)
+	}
+}
+
+func TestRequestRegistryTokenAnonymous(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	auth, err := RequestRegistryToken(RegistryTokenOptions{Registry: server.URL, Repository: "tsuru/python"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.RegistryToken != "" {
+		t.Errorf("RequestRegistryToken: unexpected token %q.", auth.RegistryToken)
+	}
+}
+
+func TestRequestRegistryTokenNoBearerChallenge(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Registry Realm"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	_, err := RequestRegistryToken(RegistryTokenOptions{Registry: server.URL, Repository: "tsuru/python"})
+	if err != ErrNoBearerChallenge {
+		t.Errorf("RequestRegistryToken: wrong error. Want %#v. Got %#v.", ErrNoBearerChallenge, err)
+	}
+}
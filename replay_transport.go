@@ -0,0 +1,222 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"unicode/utf8"
+)
+
+// transportRecord is a request and its response, as written by
+// RecorderTransport, one JSON object per line. Bodies that aren't valid UTF-8,
+// like the tar archives of images and containers, are stored base64-encoded
+// in the *Base64 fields.
+type transportRecord struct {
+	Method            string      `json:"method"`
+	Path              string      `json:"path"`
+	Query             string      `json:"query,omitempty"`
+	RequestBody       string      `json:"requestBody,omitempty"`
+	RequestBodyBase64 []byte      `json:"requestBodyBase64,omitempty"`
+	StatusCode        int         `json:"statusCode"`
+	Header            http.Header `json:"header,omitempty"`
+	Body              string      `json:"body,omitempty"`
+	BodyBase64        []byte      `json:"bodyBase64,omitempty"`
+}
+
+func encodeRecordBody(body []byte) (string, []byte) {
+	if utf8.Valid(body) {
+		return string(body), nil
+	}
+	return "", body
+}
+
+func decodeRecordBody(text string, binary []byte) []byte {
+	if binary != nil {
+		return binary
+	}
+	return []byte(text)
+}
+
+// RecorderTransport returns an http.RoundTripper that sends requests through
+// base, or http.DefaultTransport when base is nil, and writes each request
+// along with its response to output, as newline-delimited JSON that can be
+// given to ReplayTransport. It can be installed in a client connected to a
+// real daemon to capture a session:
+//
+//     client.HTTPClient.Transport = docker.RecorderTransport(client.HTTPClient.Transport, f)
+//
+// The headers of the requests, which may carry registry credentials, are not
+// recorded. A response is written once its body is read to the end or
+// closed, so streaming responses are recorded as they were read by the
+// client. Operations that hijack the connection, such as attaching to a
+// container, are not recorded.
+func RecorderTransport(base http.RoundTripper, output io.Writer) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recorderTransport{base: base, encoder: json.NewEncoder(output)}
+}
+
+type recorderTransport struct {
+	base    http.RoundTripper
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (t *recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	record := transportRecord{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		record.RequestBody, record.RequestBodyBase64 = encodeRecordBody(body)
+		clone := *req
+		clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req = &clone
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	record.StatusCode = resp.StatusCode
+	record.Header = resp.Header
+	resp.Body = &recordingBody{ReadCloser: resp.Body, write: func(body []byte) {
+		record.Body, record.BodyBase64 = encodeRecordBody(body)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.encoder.Encode(record)
+	}}
+	return resp, nil
+}
+
+// recordingBody keeps what is read from a response body, handing it to write
+// when the body is read to the end or closed.
+type recordingBody struct {
+	io.ReadCloser
+	buf   bytes.Buffer
+	once  sync.Once
+	write func([]byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
+
+func (b *recordingBody) done() {
+	b.once.Do(func() {
+		b.write(b.buf.Bytes())
+	})
+}
+
+// ReplayTransport returns an http.RoundTripper that answers requests with the
+// responses read from input, as written by RecorderTransport, so that a
+// session captured against a real daemon can be replayed without one:
+//
+//     client.HTTPClient.Transport = docker.ReplayTransport(f)
+//
+// Each request is answered by the first recorded exchange with the same
+// method, path and query string that wasn't replayed yet. Requests matching
+// no exchange fail.
+func ReplayTransport(input io.Reader) http.RoundTripper {
+	return &replayTransport{input: input}
+}
+
+type replayTransport struct {
+	input   io.Reader
+	once    sync.Once
+	mu      sync.Mutex
+	records []*transportRecord
+	err     error
+}
+
+func (t *replayTransport) load() {
+	scanner := bufio.NewScanner(t.input)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record transportRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.err = fmt.Errorf("invalid recorded exchange: %s", err)
+			return
+		}
+		t.records = append(t.records, &record)
+	}
+	t.err = scanner.Err()
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(t.load)
+	if t.err != nil {
+		return nil, t.err
+	}
+	if req.Body != nil {
+		ioutil.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	query := req.URL.Query()
+	t.mu.Lock()
+	var record *transportRecord
+	for i, r := range t.records {
+		if r != nil && r.Method == req.Method && r.Path == req.URL.Path && reflect.DeepEqual(parseQuery(r.Query), query) {
+			record = r
+			t.records[i] = nil
+			break
+		}
+	}
+	t.mu.Unlock()
+	if record == nil {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL.RequestURI())
+	}
+	body := decodeRecordBody(record.Body, record.BodyBase64)
+	header := record.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", record.StatusCode, http.StatusText(record.StatusCode)),
+		StatusCode:    record.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func parseQuery(rawQuery string) url.Values {
+	query, _ := url.ParseQuery(rawQuery)
+	return query
+}
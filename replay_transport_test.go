@@ -0,0 +1,95 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecorderAndReplayTransport(t *testing.T) {
+	t.Parallel()
+	export := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"Id":"abc123","Names":["/web"]}]`))
+		case strings.HasSuffix(r.URL.Path, "/containers/abc123/export"):
+			w.Write(export)
+		case strings.HasSuffix(r.URL.Path, "/containers/abc123/logs"):
+			w.Write([]byte("hello\n"))
+		default:
+			http.Error(w, "no such container", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	var tape bytes.Buffer
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	client.HTTPClient.Transport = RecorderTransport(client.HTTPClient.Transport, &tape)
+	session := func(client *Client) ([]APIContainers, []byte, string, error) {
+		containers, err := client.ListContainers(ListContainersOptions{All: true})
+		if err != nil {
+			return nil, nil, "", err
+		}
+		var exported, logs bytes.Buffer
+		if err = client.ExportContainer(ExportContainerOptions{ID: "abc123", OutputStream: &exported}); err != nil {
+			return nil, nil, "", err
+		}
+		if err = client.Logs(LogsOptions{Container: "abc123", OutputStream: &logs, Stdout: true, RawTerminal: true}); err != nil {
+			return nil, nil, "", err
+		}
+		_, err = client.InspectContainer("db")
+		return containers, exported.Bytes(), logs.String(), err
+	}
+	containers, exported, logs, err := session(client)
+	if expected := (&NoSuchContainer{ID: "db"}); !reflect.DeepEqual(err, expected) {
+		t.Fatalf("InspectContainer: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	if n := strings.Count(tape.String(), "\n"); n != 4 {
+		t.Fatalf("RecorderTransport: wrong number of records. Want 4. Got %d.\n%s", n, tape.String())
+	}
+	server.Close()
+
+	replayClient, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayClient.SkipServerVersionCheck = true
+	replayClient.HTTPClient.Transport = ReplayTransport(&tape)
+	replayed, replayedExport, replayedLogs, err := session(replayClient)
+	if expected := (&NoSuchContainer{ID: "db"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("InspectContainer: wrong replayed error. Want %#v. Got %#v.", expected, err)
+	}
+	if !reflect.DeepEqual(replayed, containers) {
+		t.Errorf("ListContainers: wrong replayed result. Want %#v. Got %#v.", containers, replayed)
+	}
+	if !bytes.Equal(replayedExport, exported) || !bytes.Equal(exported, export) {
+		t.Errorf("ExportContainer: wrong replayed output. Want %v. Got %v.", exported, replayedExport)
+	}
+	if replayedLogs != logs || logs != "hello\n" {
+		t.Errorf("Logs: wrong replayed output. Want %q. Got %q.", logs, replayedLogs)
+	}
+	if _, err = replayClient.ListContainers(ListContainersOptions{All: true}); err == nil || !strings.Contains(err.Error(), "no recorded response for GET") {
+		t.Errorf("ListContainers: wrong error for a request that was already replayed. Got %v.", err)
+	}
+}
+
+func TestReplayTransportInvalidInput(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{})
+	client.HTTPClient.Transport = ReplayTransport(strings.NewReader("not json\n"))
+	if _, err := client.ListContainers(ListContainersOptions{}); err == nil || !strings.Contains(err.Error(), "invalid recorded exchange") {
+		t.Errorf("ListContainers: wrong error. Want an invalid recorded exchange. Got %v.", err)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultMaxLoggedBodySize is the number of bytes of each body given to the
+// logger set with WithBodyLogger, unless another size is given.
+const defaultMaxLoggedBodySize = 4096
+
+// WithRequestLogger makes the client call the given function for each
+// request sent to the Docker daemon, once the response headers are received,
+// with the duration of the request so far. The status code is zero when no
+// response was received. The bodies are not read by the logger.
+//
+// Requests on hijacked connections, such as attaching to a container, are
+// not logged.
+func WithRequestLogger(logger func(method, url string, statusCode int, duration time.Duration)) ClientOption {
+	return func(c *Client) error {
+		c.requestLogger = logger
+		return nil
+	}
+}
+
+// WithBodyLogger makes the client call the given function for each request
+// sent to the Docker daemon, with the first maxBodySize bytes of the request
+// and response bodies, or 4 KB if maxBodySize isn't positive. The function is
+// called when the response body is closed, so the duration includes reading
+// the response, or as soon as the request fails.
+//
+// Requests on hijacked connections, such as attaching to a container, are
+// not logged.
+func WithBodyLogger(logger func(method, url string, statusCode int, duration time.Duration, requestBody, responseBody []byte), maxBodySize int) ClientOption {
+	return func(c *Client) error {
+		if maxBodySize <= 0 {
+			maxBodySize = defaultMaxLoggedBodySize
+		}
+		c.bodyLogger = &bodyLogger{log: logger, maxBodySize: maxBodySize}
+		return nil
+	}
+}
+
+// WithRequestHook makes the client call the given function with each request
+// right before it's sent to the Docker daemon, including the requests that
+// start a stream or hijack the connection, such as attaching to a container.
+// The hook may inspect the request, but must not change it or read its body.
+//
+// Setting the Transport of the HTTPClient of the client to a custom
+// http.RoundTripper gives full control over requests and responses, but
+// isn't used by requests on hijacked connections.
+func WithRequestHook(hook func(req *http.Request)) ClientOption {
+	return func(c *Client) error {
+		c.requestHook = hook
+		return nil
+	}
+}
+
+// WithResponseHook makes the client call the given function with the
+// response of each request sent to the Docker daemon, or the error that
+// prevented it from being received, as soon as its headers are received. The
+// hook must not read nor close the body of the response, which is read by the
+// client afterwards. For requests on hijacked connections, the body is the
+// stream of the connection.
+//
+// Along with WithRequestHook, it can be used to see the exact exchange that
+// led to an unexpected error; WithBodyLogger also gives the bodies.
+func WithResponseHook(hook func(req *http.Request, resp *http.Response, err error)) ClientOption {
+	return func(c *Client) error {
+		c.responseHook = hook
+		return nil
+	}
+}
+
+// hooked wraps the given function, calling the request and response hooks of
+// the client around it.
+func (c *Client) hooked(send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	if c.requestHook == nil && c.responseHook == nil {
+		return send
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		if c.requestHook != nil {
+			c.requestHook(req)
+		}
+		resp, err := send(req)
+		if c.responseHook != nil {
+			c.responseHook(req, resp, err)
+		}
+		return resp, err
+	}
+}
+
+type bodyLogger struct {
+	log         func(method, url string, statusCode int, duration time.Duration, requestBody, responseBody []byte)
+	maxBodySize int
+}
+
+// roundTrip sends the request with the given function, calling the hooks,
+// the loggers, the tracer and the metrics of the client.
+func (c *Client) roundTrip(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	send = c.hooked(send)
+	if c.tracer != nil || c.metrics != nil {
+		operation := operationName()
+		if c.tracer != nil {
+			send = c.traced(operation, send)
+		}
+		if c.metrics != nil {
+			send = c.measured(operation, send)
+		}
+	}
+	if c.requestLogger == nil && c.bodyLogger == nil {
+		return send(req)
+	}
+	method, url := req.Method, req.URL.String()
+	var requestBody, responseBody *cappedBuffer
+	if c.bodyLogger != nil {
+		requestBody = &cappedBuffer{max: c.bodyLogger.maxBodySize}
+		responseBody = &cappedBuffer{max: c.bodyLogger.maxBodySize}
+		if req.Body != nil {
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(req.Body, requestBody), req.Body}
+		}
+	}
+	start := time.Now()
+	resp, err := send(req)
+	var statusCode int
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	if c.requestLogger != nil {
+		c.requestLogger(method, url, statusCode, time.Since(start))
+	}
+	if c.bodyLogger != nil {
+		log := func() {
+			c.bodyLogger.log(method, url, statusCode, time.Since(start), requestBody.Bytes(), responseBody.Bytes())
+		}
+		if err != nil {
+			log()
+		} else {
+			resp.Body = &loggedBody{
+				ReadCloser: resp.Body,
+				reader:     io.TeeReader(resp.Body, responseBody),
+				log:        log,
+			}
+		}
+	}
+	return resp, err
+}
+
+// cappedBuffer keeps the first bytes written to it, discarding the others.
+type cappedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+// loggedBody is a response body that calls the body logger when closed.
+type loggedBody struct {
+	io.ReadCloser
+	reader io.Reader
+	log    func()
+	once   sync.Once
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.log)
+	return err
+}
@@ -0,0 +1,198 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type loggedRequest struct {
+	method       string
+	url          string
+	statusCode   int
+	requestBody  string
+	responseBody string
+}
+
+func TestWithRequestLogger(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/containers/missing/json" {
+			http.Error(w, "no such container", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Id":"c1"}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var (
+		mu     sync.Mutex
+		logged []loggedRequest
+	)
+	client.ApplyOptions(WithRequestLogger(func(method, url string, statusCode int, duration time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, loggedRequest{method: method, url: url, statusCode: statusCode})
+	}))
+	client.InspectContainer("c1")
+	client.InspectContainer("missing")
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []loggedRequest{
+		{method: "GET", url: server.URL + "/containers/c1/json", statusCode: http.StatusOK},
+		{method: "GET", url: server.URL + "/containers/missing/json", statusCode: http.StatusNotFound},
+	}
+	if len(logged) != len(expected) {
+		t.Fatalf("WithRequestLogger: wrong number of logged requests. Want %d. Got %d.", len(expected), len(logged))
+	}
+	for i := range expected {
+		if logged[i] != expected[i] {
+			t.Errorf("WithRequestLogger: wrong logged request. Want %#v. Got %#v.", expected[i], logged[i])
+		}
+	}
+}
+
+func TestWithBodyLogger(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/images/create" {
+			w.Write([]byte(`{"status":"Pulling from library/busybox"}`))
+			return
+		}
+		w.Write([]byte(`{"Id":"0123456789abcdef"}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var (
+		mu     sync.Mutex
+		logged []loggedRequest
+	)
+	client.ApplyOptions(WithBodyLogger(func(method, url string, statusCode int, duration time.Duration, requestBody, responseBody []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, loggedRequest{method, url, statusCode, string(requestBody), string(responseBody)})
+	}, 10))
+	if _, err = client.CreateContainer(CreateContainerOptions{Config: &Config{Image: "busybox"}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = client.PullImage(PullImageOptions{Repository: "busybox", OutputStream: &buf, RawJSONStream: true}, AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(logged) != 2 {
+		t.Fatalf("WithBodyLogger: wrong number of logged requests. Want 2. Got %d.", len(logged))
+	}
+	if logged[0].requestBody != `{"Cmd":nul` || logged[0].responseBody != `{"Id":"012` {
+		t.Errorf("WithBodyLogger: wrong bodies for CreateContainer. Got %q and %q.", logged[0].requestBody, logged[0].responseBody)
+	}
+	if !strings.HasPrefix(logged[1].url, server.URL+"/images/create?") || logged[1].responseBody != `{"status":` {
+		t.Errorf("WithBodyLogger: wrong logged request for PullImage. Got %#v.", logged[1])
+	}
+}
+
+func TestWithRequestAndResponseHooks(t *testing.T) {
+	t.Parallel()
+	server, _ := newRunContainerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		http.Error(w, "container is restarting", http.StatusConflict)
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var (
+		mu        sync.Mutex
+		requests  []string
+		responses []string
+	)
+	client.ApplyOptions(
+		WithRequestHook(func(req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, req.Method+" "+req.URL.Path)
+		}),
+		WithResponseHook(func(req *http.Request, resp *http.Response, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				responses = append(responses, req.URL.Path+" "+err.Error())
+				return
+			}
+			responses = append(responses, fmt.Sprintf("%s %d %s", req.URL.Path, resp.StatusCode, resp.Header.Get("X-Request-Id")))
+		}),
+	)
+	var stdout bytes.Buffer
+	err = client.AttachToContainer(AttachToContainerOptions{Container: "c1", OutputStream: &stdout, ErrorStream: &stdout, Stream: true, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.WaitContainer("c1"); err == nil {
+		t.Fatal("WaitContainer: unexpected <nil> error")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	expectedRequests := []string{
+		"POST /containers/c1/attach",
+		"POST /containers/c1/wait",
+	}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Errorf("WithRequestHook: wrong requests. Want %#v. Got %#v.", expectedRequests, requests)
+	}
+	expectedResponses := []string{
+		"/containers/c1/attach 200 ",
+		"/containers/c1/wait 409 req-1",
+	}
+	if !reflect.DeepEqual(responses, expectedResponses) {
+		t.Errorf("WithResponseHook: wrong responses. Want %#v. Got %#v.", expectedResponses, responses)
+	}
+}
+
+func TestWithResponseHookError(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{})
+	client.HTTPClient.Transport = ReplayTransport(strings.NewReader(""))
+	var hookErr error
+	client.ApplyOptions(WithResponseHook(func(req *http.Request, resp *http.Response, err error) {
+		hookErr = err
+	}))
+	_, err := client.InspectContainer("c1")
+	if err == nil || hookErr == nil || !strings.Contains(err.Error(), hookErr.Error()) {
+		t.Errorf("WithResponseHook: wrong error. Client got %v. Hook got %v.", err, hookErr)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	t.Parallel()
+	buf := cappedBuffer{max: defaultMaxLoggedBodySize}
+	for i := 0; i < 3; i++ {
+		n, err := buf.Write(bytes.Repeat([]byte("x"), 2000))
+		if n != 2000 || err != nil {
+			t.Fatalf("Write: wrong result. Want (2000, <nil>). Got (%d, %v).", n, err)
+		}
+	}
+	if n := len(buf.Bytes()); n != defaultMaxLoggedBodySize {
+		t.Errorf("Bytes: wrong size. Want %d. Got %d.", defaultMaxLoggedBodySize, n)
+	}
+}
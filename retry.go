@@ -0,0 +1,99 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides whether a failed request to the Docker daemon should be
+// sent again, and how long to wait before doing so. The attempt is the number
+// of times the request was sent so far, starting at 1.
+type RetryPolicy interface {
+	ShouldRetry(attempt int, err error) (retry bool, backoff time.Duration)
+}
+
+// WithRetryPolicy makes the client send failed requests again according to
+// the given policy. Only the requests with idempotent methods (GET, HEAD and
+// DELETE) are retried by default; other requests are retried when sent with
+// a context returned by RetryableContext. Streaming operations, such as
+// pulling images or attaching to containers, are never retried.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		c.retryPolicy = p
+		return nil
+	}
+}
+
+type retryableKey struct{}
+
+// RetryableContext returns a copy of parent that makes the requests sent
+// with it, such as the ones of StartContainerWithContext, subject to the
+// retry policy of the client even if their method isn't idempotent. It
+// should only be used for operations that are safe to repeat.
+//
+// When a retried request gets the status 304 (Not Modified), as starting a
+// running container does, the previous attempt is assumed to have taken
+// effect with its response lost, so the request succeeds.
+func RetryableContext(parent context.Context) context.Context {
+	return context.WithValue(parent, retryableKey{}, true)
+}
+
+func isRetryableContext(ctx context.Context) bool {
+	retryable, _ := ctx.Value(retryableKey{}).(bool)
+	return retryable
+}
+
+// ExponentialBackoffRetry returns a RetryPolicy that retries requests that
+// failed because of transient errors, such as network errors and
+// unavailability of the daemon, up to maxAttempts times in total. The wait
+// between attempts starts at base and doubles after each attempt.
+func ExponentialBackoffRetry(maxAttempts int, base time.Duration) RetryPolicy {
+	return exponentialBackoffRetry{maxAttempts: maxAttempts, base: base}
+}
+
+type exponentialBackoffRetry struct {
+	maxAttempts int
+	base        time.Duration
+}
+
+func (r exponentialBackoffRetry) ShouldRetry(attempt int, err error) (bool, time.Duration) {
+	if attempt >= r.maxAttempts || !isTransientError(err) {
+		return false, 0
+	}
+	return true, r.base << uint(attempt-1)
+}
+
+// isTransientError reports whether the error may go away if the request is
+// sent again.
+func isTransientError(err error) bool {
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		// the caller gave up, context.DeadlineExceeded is also a net.Error
+		return false
+	}
+	switch e := err.(type) {
+	case *Error:
+		switch e.Status {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	case net.Error:
+		return true
+	}
+	return err == ErrConnectionRefused || err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "DELETE":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffRetry(t *testing.T) {
+	t.Parallel()
+	policy := ExponentialBackoffRetry(3, 10*time.Millisecond)
+	var tests = []struct {
+		attempt int
+		err     error
+		retry   bool
+		backoff time.Duration
+	}{
+		{1, ErrConnectionRefused, true, 10 * time.Millisecond},
+		{2, &Error{Status: http.StatusServiceUnavailable}, true, 20 * time.Millisecond},
+		{3, ErrConnectionRefused, false, 0},
+		{1, &Error{Status: http.StatusNotFound}, false, 0},
+		{1, &Error{Status: http.StatusInternalServerError}, false, 0},
+		{1, context.DeadlineExceeded, false, 0},
+	}
+	for _, test := range tests {
+		retry, backoff := policy.ShouldRetry(test.attempt, test.err)
+		if retry != test.retry || backoff != test.backoff {
+			t.Errorf("ShouldRetry(%d, %v): wrong result. Want (%v, %s). Got (%v, %s).", test.attempt, test.err, test.retry, test.backoff, retry, backoff)
+		}
+	}
+}
+
+func newFlakyServer(failures int) (*httptest.Server, func() []string) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		n := len(requests)
+		mu.Unlock()
+		if n <= failures {
+			http.Error(w, "daemon is restarting", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"Id":"c1","StatusCode":0}`))
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	t.Parallel()
+	server, requests := newFlakyServer(2)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	if err = client.ApplyOptions(WithRetryPolicy(ExponentialBackoffRetry(3, time.Millisecond))); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.InspectContainer("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.ID != "c1" {
+		t.Errorf("InspectContainer: wrong container. Want %q. Got %q.", "c1", container.ID)
+	}
+	if n := len(requests()); n != 3 {
+		t.Errorf("InspectContainer: wrong number of requests. Want 3. Got %d.", n)
+	}
+}
+
+func TestClientRetryPolicyExhausted(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "daemon is restarting", status: http.StatusServiceUnavailable}
+	client := newTestClient(fakeRT)
+	client.ApplyOptions(WithRetryPolicy(ExponentialBackoffRetry(2, time.Millisecond)))
+	_, err := client.InspectContainer("c1")
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusServiceUnavailable {
+		t.Errorf("InspectContainer: wrong error. Want 503. Got %#v.", err)
+	}
+	if n := len(fakeRT.requests); n != 2 {
+		t.Errorf("InspectContainer: wrong number of requests. Want 2. Got %d.", n)
+	}
+}
+
+func TestClientRetryPolicyNonIdempotent(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "daemon is restarting", status: http.StatusServiceUnavailable}
+	client := newTestClient(fakeRT)
+	client.ApplyOptions(WithRetryPolicy(ExponentialBackoffRetry(3, time.Millisecond)))
+	_, err := client.CreateContainer(CreateContainerOptions{Config: &Config{Image: "busybox"}})
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusServiceUnavailable {
+		t.Errorf("CreateContainer: wrong error. Want 503. Got %#v.", err)
+	}
+	if n := len(fakeRT.requests); n != 1 {
+		t.Errorf("CreateContainer: wrong number of requests. Want 1. Got %d.", n)
+	}
+}
+
+func TestClientRetryPolicyRetryableOperation(t *testing.T) {
+	t.Parallel()
+	server, requests := newFlakyServer(1)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	client.ApplyOptions(WithRetryPolicy(ExponentialBackoffRetry(3, time.Millisecond)))
+	if _, err = client.WaitContainerWithContext("c1", RetryableContext(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+	got := requests()
+	if len(got) != 2 || got[0] != got[1] {
+		t.Errorf("WaitContainer: wrong requests. Want the same request twice. Got %#v.", got)
+	}
+}
+
+func TestClientRetryPolicyNotRetryableOperation(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "daemon is restarting", status: http.StatusServiceUnavailable}
+	client := newTestClient(fakeRT)
+	client.ApplyOptions(WithRetryPolicy(ExponentialBackoffRetry(3, time.Millisecond)))
+	err := client.StartContainer("c1", nil)
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusServiceUnavailable {
+		t.Errorf("StartContainer: wrong error. Want 503. Got %#v.", err)
+	}
+	if n := len(fakeRT.requests); n != 1 {
+		t.Errorf("StartContainer: wrong number of requests. Want 1. Got %d.", n)
+	}
+}
+
+func TestClientRetryPolicyNotModifiedAfterRetry(t *testing.T) {
+	t.Parallel()
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/version" {
+			w.Write([]byte(`{"ApiVersion":"1.25"}`))
+			return
+		}
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		if n == 1 {
+			// the container is started, but the response is lost
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	client.ApplyOptions(WithRetryPolicy(ExponentialBackoffRetry(3, time.Millisecond)))
+	if err = client.StartContainerWithContext("c1", nil, RetryableContext(context.Background())); err != nil {
+		t.Errorf("StartContainer: unexpected error after the retry: %#v", err)
+	}
+	if err = client.StartContainerWithContext("c1", nil, RetryableContext(context.Background())); err == nil {
+		t.Error("StartContainer: unexpected <nil> error for a running container")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 3 {
+		t.Errorf("StartContainer: wrong number of requests. Want 3. Got %d.", requests)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// spotTerminationPollInterval is the interval between the checks for a
+	// termination notice, as recommended by AWS.
+	spotTerminationPollInterval = 5 * time.Second
+
+	// metadataTimeout limits the requests to the metadata services, which
+	// aren't reachable outside of the respective clouds.
+	metadataTimeout = 2 * time.Second
+
+	awsMetadataURL = "http://169.254.169.254"
+	gcpMetadataURL = "http://metadata.google.internal"
+)
+
+// terminationCheck checks a metadata service for a termination notice,
+// returning whether there's one and, when known, the time of the
+// termination.
+type terminationCheck func(ctx context.Context, httpClient *http.Client) (bool, time.Time, error)
+
+// WatchSpotTermination polls the instance metadata services of AWS and
+// Google Cloud until a termination notice is issued for a spot or
+// preemptible instance, or the context is done. When a notice is found, fn
+// is called with the time remaining until the termination, or gracePeriod
+// when the cloud doesn't tell it, as with Google Cloud, which gives
+// preemptible instances 30 seconds. All running containers are then stopped,
+// in parallel, being killed when the remaining time is over, and fn is
+// called again with zero.
+//
+// It returns the first error stopping the containers, or the error of the
+// context. Errors reaching the metadata services are ignored, so outside of
+// AWS and Google Cloud it only returns when the context is done.
+func (c *Client) WatchSpotTermination(gracePeriod time.Duration, fn func(remainingTime time.Duration), ctx context.Context) error {
+	checks := []terminationCheck{awsSpotTermination(awsMetadataURL), gcpPreemption(gcpMetadataURL)}
+	return c.watchTermination(ctx, checks, spotTerminationPollInterval, gracePeriod, fn)
+}
+
+func (c *Client) watchTermination(ctx context.Context, checks []terminationCheck, interval, gracePeriod time.Duration, fn func(time.Duration)) error {
+	httpClient := &http.Client{Timeout: metadataTimeout}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, check := range checks {
+			found, terminationTime, err := check(ctx, httpClient)
+			if err != nil || !found {
+				continue
+			}
+			remaining := gracePeriod
+			if !terminationTime.IsZero() {
+				remaining = time.Until(terminationTime)
+				if remaining < 0 {
+					remaining = 0
+				}
+			}
+			fn(remaining)
+			err = c.stopRunningContainers(ctx, remaining)
+			fn(0)
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopRunningContainers stops all running containers in parallel, killing
+// them after the given timeout.
+func (c *Client) stopRunningContainers(ctx context.Context, timeout time.Duration) error {
+	containers, err := c.ListContainers(ListContainersOptions{Context: ctx})
+	if err != nil {
+		return err
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, container := range containers {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			err := c.StopContainerWithContext(id, uint(timeout/time.Second), ctx)
+			switch err.(type) {
+			case nil, *NoSuchContainer, *ContainerNotRunning:
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}(container.ID)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// awsSpotTermination checks the spot instance action of AWS, which is only
+// available, with a 200 status, after a termination notice was issued. A
+// session token is requested first, as required by IMDSv2, falling back to
+// IMDSv1 when it fails.
+func awsSpotTermination(baseURL string) terminationCheck {
+	return func(ctx context.Context, httpClient *http.Client) (bool, time.Time, error) {
+		var token string
+		req, err := http.NewRequest("PUT", baseURL+"/latest/api/token", nil)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+		if resp, err := httpClient.Do(req.WithContext(ctx)); err == nil {
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				token = string(body)
+			}
+		}
+		req, err = http.NewRequest("GET", baseURL+"/latest/meta-data/spot/instance-action", nil)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		if token != "" {
+			req.Header.Set("X-aws-ec2-metadata-token", token)
+		}
+		resp, err := httpClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false, time.Time{}, nil
+		}
+		var action struct {
+			Action string    `json:"action"`
+			Time   time.Time `json:"time"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&action); err != nil {
+			return false, time.Time{}, err
+		}
+		// hibernation doesn't give the containers a chance to be stopped
+		// either, so it's handled as a termination
+		return action.Action == "terminate" || action.Action == "stop" || action.Action == "hibernate", action.Time, nil
+	}
+}
+
+// gcpPreemption checks the preempted flag of Google Cloud, which doesn't
+// tell the time of the termination.
+func gcpPreemption(baseURL string) terminationCheck {
+	return func(ctx context.Context, httpClient *http.Client) (bool, time.Time, error) {
+		req, err := http.NewRequest("GET", baseURL+"/computeMetadata/v1/instance/preempted", nil)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		resp, err := httpClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false, time.Time{}, nil
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		return strings.TrimSpace(string(body)) == "TRUE", time.Time{}, nil
+	}
+}
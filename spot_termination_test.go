@@ -0,0 +1,144 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stopTimeouts returns the containers stopped through the given round
+// tripper, along with the timeout of each stop request.
+func stopTimeouts(t *testing.T, fakeRT *FakeRoundTripper) map[string]int {
+	timeouts := make(map[string]int)
+	for _, req := range fakeRT.requests {
+		if !strings.HasSuffix(req.URL.Path, "/stop") {
+			continue
+		}
+		timeout, err := strconv.Atoi(req.URL.Query().Get("t"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		timeouts[strings.Split(req.URL.Path, "/")[2]] = timeout
+	}
+	return timeouts
+}
+
+func TestWatchSpotTerminationAWS(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var polls int
+	terminationTime := time.Now().Add(2 * time.Minute).UTC()
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			w.Write([]byte("token"))
+		case "/latest/meta-data/spot/instance-action":
+			if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			mu.Lock()
+			polls++
+			n := polls
+			mu.Unlock()
+			if n < 3 {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"action": "terminate", "time": terminationTime})
+		}
+	}))
+	defer metadata.Close()
+	fakeRT := &FakeRoundTripper{message: `[{"Id":"c1"},{"Id":"c2"}]`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	var remaining []time.Duration
+	checks := []terminationCheck{awsSpotTermination(metadata.URL)}
+	err := client.watchTermination(context.Background(), checks, time.Millisecond, time.Minute, func(d time.Duration) {
+		remaining = append(remaining, d)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 || remaining[0] <= time.Minute || remaining[0] > 2*time.Minute || remaining[1] != 0 {
+		t.Errorf("WatchSpotTermination: wrong remaining times. Got %v.", remaining)
+	}
+	timeouts := stopTimeouts(t, fakeRT)
+	if len(timeouts) != 2 {
+		t.Fatalf("WatchSpotTermination: wrong stop requests. Want c1 and c2. Got %v.", timeouts)
+	}
+	// the timeout is what remains of the two minutes once the notice is
+	// found, truncated to seconds
+	for _, id := range []string{"c1", "c2"} {
+		if timeout, ok := timeouts[id]; !ok || timeout < 60 || timeout > 119 {
+			t.Errorf("WatchSpotTermination: wrong timeout for %s. Want between 60 and 119. Got %d.", id, timeout)
+		}
+	}
+}
+
+func TestWatchSpotTerminationGCP(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var polls int
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/computeMetadata/v1/instance/preempted" || r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+		if n < 3 {
+			w.Write([]byte("FALSE"))
+			return
+		}
+		w.Write([]byte("TRUE"))
+	}))
+	defer metadata.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	fakeRT := &FakeRoundTripper{message: `[{"Id":"c1"},{"Id":"c2"}]`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	var remaining []time.Duration
+	checks := []terminationCheck{awsSpotTermination(unreachable.URL), gcpPreemption(metadata.URL)}
+	err := client.watchTermination(context.Background(), checks, time.Millisecond, 30*time.Second, func(d time.Duration) {
+		remaining = append(remaining, d)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []time.Duration{30 * time.Second, 0}; !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("WatchSpotTermination: wrong remaining times. Want %v. Got %v.", expected, remaining)
+	}
+	expected := map[string]int{"c1": 30, "c2": 30}
+	if timeouts := stopTimeouts(t, fakeRT); !reflect.DeepEqual(timeouts, expected) {
+		t.Errorf("WatchSpotTermination: wrong stop timeouts. Want %v. Got %v.", expected, timeouts)
+	}
+}
+
+func TestWatchSpotTerminationContextDone(t *testing.T) {
+	t.Parallel()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	client := newTestClient(&FakeRoundTripper{message: "[]", status: http.StatusOK})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	checks := []terminationCheck{awsSpotTermination(unreachable.URL), gcpPreemption(unreachable.URL)}
+	err := client.watchTermination(ctx, checks, time.Millisecond, time.Minute, func(d time.Duration) {
+		t.Errorf("WatchSpotTermination: unexpected call with %s", d)
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("WatchSpotTermination: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshProtocol          = "ssh"
+	defaultSSHPort       = "22"
+	defaultSSHSocketPath = "/var/run/docker.sock"
+	sshKeepAliveInterval = 30 * time.Second
+)
+
+// NewSSHClient returns a Client instance ready for communication with the
+// Docker daemon of a remote host, reached through an SSH tunnel. It will use
+// the latest remote API version available in the server.
+//
+// The endpoint must be in the format ssh://user@host[:port][/path], where port
+// defaults to 22 and path, the location of the Docker socket in the remote
+// host, defaults to /var/run/docker.sock. The user in the endpoint, when
+// present, overrides the User in sshConfig.
+//
+// The SSH connection is kept alive with keepalive requests, and is
+// transparently reestablished when it's lost.
+func NewSSHClient(endpoint string, sshConfig *ssh.ClientConfig) (*Client, error) {
+	if sshConfig == nil {
+		return nil, errors.New("ssh client config is nil")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != sshProtocol || u.Hostname() == "" {
+		return nil, ErrInvalidEndpoint
+	}
+	config := *sshConfig
+	if u.User != nil {
+		config.User = u.User.Username()
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultSSHPort
+	}
+	socketPath := u.Path
+	if socketPath == "" || socketPath == "/" {
+		socketPath = defaultSSHSocketPath
+	}
+	dialer := &sshDialer{
+		addr:              net.JoinHostPort(u.Hostname(), port),
+		config:            &config,
+		socketPath:        socketPath,
+		keepAliveInterval: sshKeepAliveInterval,
+	}
+	tr := defaultTransport()
+	tr.Proxy = nil
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.Dial(unixProtocol, socketPath)
+	}
+	return &Client{
+		SkipServerVersionCheck: true,
+		HTTPClient:             &http.Client{Transport: tr},
+		Dialer:                 dialer,
+		endpoint:               endpoint,
+		endpointURL:            &url.URL{Scheme: unixProtocol, Path: socketPath},
+		eventMonitor:           new(eventMonitoringState),
+		registryAuths:          new(registryAuthStore),
+	}, nil
+}
+
+// sshDialer is a Dialer that connects to the Docker socket of a remote host
+// through an SSH connection, which is shared by all the dialed connections.
+type sshDialer struct {
+	addr              string
+	config            *ssh.ClientConfig
+	socketPath        string
+	keepAliveInterval time.Duration
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// Dial opens a connection to the remote Docker socket, ignoring the given
+// network and address.
+func (d *sshDialer) Dial(network, address string) (net.Conn, error) {
+	client, err := d.sshClient()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Dial(unixProtocol, d.socketPath)
+	if err == nil {
+		return conn, nil
+	}
+	// the SSH connection may have been lost without the keepalive
+	// noticing it yet, so reconnect and try again
+	d.reset(client)
+	client, err = d.sshClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.Dial(unixProtocol, d.socketPath)
+}
+
+func (d *sshDialer) sshClient() (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client != nil {
+		return d.client, nil
+	}
+	client, err := ssh.Dial("tcp", d.addr, d.config)
+	if err != nil {
+		return nil, err
+	}
+	d.client = client
+	go d.keepAlive(client)
+	return client, nil
+}
+
+func (d *sshDialer) keepAlive(client *ssh.Client) {
+	closed := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(closed)
+	}()
+	ticker := time.NewTicker(d.keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			d.reset(client)
+			return
+		case <-ticker.C:
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				d.reset(client)
+				return
+			}
+		}
+	}
+}
+
+// reset closes the given SSH connection, so the next call to Dial
+// reconnects, unless a new connection has already been established.
+func (d *sshDialer) reset(client *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client == client {
+		d.client = nil
+	}
+	client.Close()
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewSSHClient(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		endpoint   string
+		addr       string
+		user       string
+		socketPath string
+	}{
+		{"ssh://docker@example.com", "example.com:22", "docker", "/var/run/docker.sock"},
+		{"ssh://docker@example.com:2222", "example.com:2222", "docker", "/var/run/docker.sock"},
+		{"ssh://example.com/run/user/1000/docker.sock", "example.com:22", "root", "/run/user/1000/docker.sock"},
+		{"ssh://docker@[::1]:2222/", "[::1]:2222", "docker", "/var/run/docker.sock"},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.endpoint, func(t *testing.T) {
+			t.Parallel()
+			client, err := NewSSHClient(test.endpoint, &ssh.ClientConfig{User: "root"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.Endpoint() != test.endpoint {
+				t.Errorf("NewSSHClient: wrong endpoint. Want %q. Got %q.", test.endpoint, client.Endpoint())
+			}
+			dialer := client.Dialer.(*sshDialer)
+			if dialer.addr != test.addr {
+				t.Errorf("NewSSHClient: wrong address. Want %q. Got %q.", test.addr, dialer.addr)
+			}
+			if dialer.config.User != test.user {
+				t.Errorf("NewSSHClient: wrong user. Want %q. Got %q.", test.user, dialer.config.User)
+			}
+			if dialer.socketPath != test.socketPath {
+				t.Errorf("NewSSHClient: wrong socket path. Want %q. Got %q.", test.socketPath, dialer.socketPath)
+			}
+		})
+	}
+}
+
+func TestNewSSHClientInvalidEndpoint(t *testing.T) {
+	t.Parallel()
+	for _, endpoint := range []string{"tcp://example.com:2375", "ssh://", "ssh:///var/run/docker.sock"} {
+		if _, err := NewSSHClient(endpoint, &ssh.ClientConfig{}); err != ErrInvalidEndpoint {
+			t.Errorf("NewSSHClient(%q): wrong error. Want %#v. Got %#v.", endpoint, ErrInvalidEndpoint, err)
+		}
+	}
+	if _, err := NewSSHClient("ssh://docker@example.com", nil); err == nil {
+		t.Error("NewSSHClient: expected error for nil config")
+	}
+}
+
+func TestSSHClientReconnect(t *testing.T) {
+	t.Parallel()
+	dockerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer dockerServer.Close()
+	sshServer := newFakeSSHServer(t, "/var/run/docker.sock", dockerServer.Listener.Addr().String())
+	defer sshServer.Close()
+
+	endpoint := "ssh://docker@" + sshServer.listener.Addr().String()
+	client, err := NewSSHClient(endpoint, &ssh.ClientConfig{
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	sshServer.closeConns()
+	if err = client.Ping(); err != nil {
+		t.Fatalf("Ping after disconnection: %s", err)
+	}
+	if n := sshServer.connCount(); n != 2 {
+		t.Errorf("Expected 2 SSH connections. Got %d.", n)
+	}
+}
+
+// fakeSSHServer is an SSH server that forwards connections to the Docker
+// socket to a TCP address.
+type fakeSSHServer struct {
+	listener   net.Listener
+	config     *ssh.ServerConfig
+	socketPath string
+	target     string
+
+	mu    sync.Mutex
+	conns []net.Conn
+	count int
+}
+
+func newFakeSSHServer(t *testing.T, socketPath, target string) *fakeSSHServer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSSHServer{listener: listener, config: config, socketPath: socketPath, target: target}
+	go server.serve()
+	return server
+}
+
+func (s *fakeSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.count++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSSHServer) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		var msg struct {
+			SocketPath string
+			Reserved0  string
+			Reserved1  uint32
+		}
+		if newChannel.ChannelType() != "direct-streamlocal@openssh.com" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &msg); err != nil || msg.SocketPath != s.socketPath {
+			newChannel.Reject(ssh.ConnectionFailed, "no such socket")
+			continue
+		}
+		target, err := net.Dial("tcp", s.target)
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			io.Copy(channel, target)
+			channel.Close()
+		}()
+		go func() {
+			io.Copy(target, channel)
+			target.Close()
+		}()
+	}
+}
+
+func (s *fakeSSHServer) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeSSHServer) connCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func (s *fakeSSHServer) Close() {
+	s.listener.Close()
+	s.closeConns()
+}
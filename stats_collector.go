@@ -0,0 +1,368 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultStatsCollectorSize is the number of samples kept by a
+// StatsCollector, unless changed with SetSize.
+const defaultStatsCollectorSize = 60
+
+// StatsCollector keeps the most recent samples of the statistics of a
+// container, collected periodically by CollectStats.
+type StatsCollector struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	samples []Stats
+	next    int
+	count   int
+	err     error
+}
+
+// StatsAverage summarizes the samples kept by a StatsCollector. Usage
+// percentages and throughputs are averaged over the time between the oldest
+// and the newest samples, while memory usage is the mean of all samples.
+type StatsAverage struct {
+	Samples  int
+	Duration time.Duration
+
+	// CPUPercent is the CPU usage of the container, where 100% is a fully
+	// used CPU. PerCPUPercent has the usage of each CPU of the host, when
+	// reported by the daemon.
+	CPUPercent    float64
+	PerCPUPercent []float64
+
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+
+	NetworkRxBytesPerSecond  float64
+	NetworkTxBytesPerSecond  float64
+	BlockReadBytesPerSecond  float64
+	BlockWriteBytesPerSecond float64
+}
+
+// CollectStats starts polling the statistics of the given container at the
+// given interval, keeping the last samples in the returned StatsCollector.
+// It returns an error if the first sample can't be collected.
+//
+// Collection stops when the context is canceled, when Stop is called or when
+// the container is removed.
+func (c *Client) CollectStats(id string, interval time.Duration, ctx context.Context) (*StatsCollector, error) {
+	if interval <= 0 {
+		return nil, errors.New("stats collection interval must be positive")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stats, err := c.GetContainerStatsOnce(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	collector := &StatsCollector{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		samples: make([]Stats, defaultStatsCollectorSize),
+	}
+	collector.add(*stats)
+	go collector.collect(ctx, c, id, interval)
+	return collector, nil
+}
+
+func (s *StatsCollector) collect(ctx context.Context, c *Client, id string, interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stats, err := c.GetContainerStatsOnce(id, ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		if _, ok := err.(*NoSuchContainer); ok {
+			return
+		}
+		if err == nil {
+			s.add(*stats)
+		}
+	}
+}
+
+func (s *StatsCollector) add(stats Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples[s.next] = stats
+	s.next = (s.next + 1) % len(s.samples)
+	if s.count < len(s.samples) {
+		s.count++
+	}
+}
+
+// SetSize changes the number of samples kept by the collector, discarding
+// the oldest ones if needed. It panics if size is not positive.
+func (s *StatsCollector) SetSize(size int) {
+	if size <= 0 {
+		panic("docker: invalid StatsCollector size")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.snapshot()
+	if len(current) > size {
+		current = current[len(current)-size:]
+	}
+	s.samples = make([]Stats, size)
+	s.count = copy(s.samples, current)
+	s.next = s.count % size
+}
+
+// Snapshot returns the samples kept by the collector, from the oldest to the
+// newest.
+func (s *StatsCollector) Snapshot() []Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+func (s *StatsCollector) snapshot() []Stats {
+	result := make([]Stats, 0, s.count)
+	start := (s.next - s.count + len(s.samples)) % len(s.samples)
+	for i := 0; i < s.count; i++ {
+		result = append(result, s.samples[(start+i)%len(s.samples)])
+	}
+	return result
+}
+
+// Average summarizes the samples kept by the collector.
+func (s *StatsCollector) Average() StatsAverage {
+	return averageStats(s.Snapshot())
+}
+
+// Err returns the error of the last failed attempt to collect a sample, or
+// nil if the last attempt succeeded.
+func (s *StatsCollector) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Done returns a channel that is closed when the collection stops.
+func (s *StatsCollector) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stop stops the collection and waits for it to finish. The samples
+// collected so far remain available.
+func (s *StatsCollector) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+func averageStats(samples []Stats) StatsAverage {
+	avg := StatsAverage{Samples: len(samples)}
+	if len(samples) == 0 {
+		return avg
+	}
+	var memory uint64
+	for _, sample := range samples {
+		memory += sample.MemoryStats.Usage
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	avg.MemoryUsage = memory / uint64(len(samples))
+	avg.MemoryLimit = last.MemoryStats.Limit
+	if avg.MemoryLimit > 0 {
+		avg.MemoryPercent = float64(avg.MemoryUsage) / float64(avg.MemoryLimit) * 100
+	}
+	if len(samples) < 2 {
+		return avg
+	}
+	avg.Duration = last.Read.Sub(first.Read)
+
+	avg.CPUPercent = cpuPercent(first.CPUStats, last.CPUStats)
+	systemDelta := counterDelta(first.CPUStats.SystemCPUUsage, last.CPUStats.SystemCPUUsage)
+	if systemDelta > 0 {
+		onlineCPUs := onlineCPUs(last.CPUStats)
+		firstPerCPU := first.CPUStats.CPUUsage.PercpuUsage
+		lastPerCPU := last.CPUStats.CPUUsage.PercpuUsage
+		if len(firstPerCPU) == len(lastPerCPU) && len(lastPerCPU) > 0 {
+			avg.PerCPUPercent = make([]float64, len(lastPerCPU))
+			for i := range lastPerCPU {
+				avg.PerCPUPercent[i] = counterDelta(firstPerCPU[i], lastPerCPU[i]) / systemDelta * onlineCPUs * 100
+			}
+		}
+	}
+
+	seconds := avg.Duration.Seconds()
+	if seconds <= 0 {
+		return avg
+	}
+	firstRx, firstTx := networkBytes(first)
+	lastRx, lastTx := networkBytes(last)
+	avg.NetworkRxBytesPerSecond = counterDelta(firstRx, lastRx) / seconds
+	avg.NetworkTxBytesPerSecond = counterDelta(firstTx, lastTx) / seconds
+	firstRead, firstWrite := blockIOBytes(first)
+	lastRead, lastWrite := blockIOBytes(last)
+	avg.BlockReadBytesPerSecond = counterDelta(firstRead, lastRead) / seconds
+	avg.BlockWriteBytesPerSecond = counterDelta(firstWrite, lastWrite) / seconds
+	return avg
+}
+
+// ComputeCPUPercent returns the CPU usage of a container between two samples
+// of its statistics, where 100% is a fully used CPU, as shown by docker
+// stats. A meaningful percentage requires two samples, since the daemon only
+// reports the CPU time consumed by the container and by the whole system
+// since they started; previous should be taken before current, usually a
+// second or more earlier. It returns zero when the system CPU time didn't
+// grow between the samples.
+func ComputeCPUPercent(current, previous *Stats) float64 {
+	return cpuPercent(previous.CPUStats, current.CPUStats)
+}
+
+// SingleSampleCPUPercent approximates the CPU usage of a container from a
+// single sample of its statistics. It compares the sample to the previous
+// one kept by the daemon in PreCPUStats, which may be empty, as in the first
+// sample of a stream or with older daemons. In that case, the result is the
+// average usage since the start of the system, given by the ratio between the
+// CPU time of the container and the CPU ticks of the whole system, which
+// underestimates the usage of recently started containers. ComputeCPUPercent
+// should be preferred when two samples are available.
+func SingleSampleCPUPercent(s *Stats) float64 {
+	return cpuPercent(s.PreCPUStats, s.CPUStats)
+}
+
+func cpuPercent(previous, current CPUStats) float64 {
+	systemDelta := counterDelta(previous.SystemCPUUsage, current.SystemCPUUsage)
+	if systemDelta <= 0 {
+		return 0
+	}
+	cpuDelta := counterDelta(previous.CPUUsage.TotalUsage, current.CPUUsage.TotalUsage)
+	return cpuDelta / systemDelta * onlineCPUs(current) * 100
+}
+
+// onlineCPUs returns the number of CPUs of the host, which older daemons
+// only report as the length of the per-CPU usage.
+func onlineCPUs(stats CPUStats) float64 {
+	if stats.OnlineCPUs > 0 {
+		return float64(stats.OnlineCPUs)
+	}
+	return float64(len(stats.CPUUsage.PercpuUsage))
+}
+
+// ThroughputMeasurement is the network throughput of a container, as
+// measured by MeasureContainerNetworkThroughput.
+type ThroughputMeasurement struct {
+	// MeasurementDuration is the time between the two samples the
+	// throughput was computed from.
+	MeasurementDuration time.Duration
+
+	// RxBytesPerSec and TxBytesPerSec are the throughput of all the
+	// network interfaces of the container.
+	RxBytesPerSec float64
+	TxBytesPerSec float64
+
+	// Interfaces has the throughput of each network interface, keyed by
+	// name, such as eth0. It's empty with older daemons, which only
+	// report the total.
+	Interfaces map[string]InterfaceThroughput
+}
+
+// InterfaceThroughput is the throughput of a network interface of a
+// container.
+type InterfaceThroughput struct {
+	RxBytesPerSec float64
+	TxBytesPerSec float64
+}
+
+// MeasureContainerNetworkThroughput measures the network throughput of the
+// given container, taking a sample of its statistics, waiting for the given
+// duration and taking another one. The context object can be used to cancel
+// the measurement.
+func (c *Client) MeasureContainerNetworkThroughput(id string, duration time.Duration, ctx context.Context) (*ThroughputMeasurement, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	first, err := c.GetContainerStatsOnce(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(duration):
+	}
+	last, err := c.GetContainerStatsOnce(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	measurement := ThroughputMeasurement{MeasurementDuration: time.Since(start)}
+	if !first.Read.IsZero() && last.Read.After(first.Read) {
+		measurement.MeasurementDuration = last.Read.Sub(first.Read)
+	}
+	seconds := measurement.MeasurementDuration.Seconds()
+	firstRx, firstTx := networkBytes(*first)
+	lastRx, lastTx := networkBytes(*last)
+	measurement.RxBytesPerSec = counterDelta(firstRx, lastRx) / seconds
+	measurement.TxBytesPerSec = counterDelta(firstTx, lastTx) / seconds
+	measurement.Interfaces = make(map[string]InterfaceThroughput, len(last.Networks))
+	for name, network := range last.Networks {
+		previous, ok := first.Networks[name]
+		if !ok {
+			continue
+		}
+		measurement.Interfaces[name] = InterfaceThroughput{
+			RxBytesPerSec: counterDelta(previous.RxBytes, network.RxBytes) / seconds,
+			TxBytesPerSec: counterDelta(previous.TxBytes, network.TxBytes) / seconds,
+		}
+	}
+	return &measurement, nil
+}
+
+// counterDelta returns the growth of a counter between two samples, which is
+// zero if the counter was reset, for instance by a restart of the container.
+func counterDelta(first, last uint64) float64 {
+	if last < first {
+		return 0
+	}
+	return float64(last - first)
+}
+
+func networkBytes(stats Stats) (rx, tx uint64) {
+	if len(stats.Networks) == 0 {
+		return stats.Network.RxBytes, stats.Network.TxBytes
+	}
+	for _, network := range stats.Networks {
+		rx += network.RxBytes
+		tx += network.TxBytes
+	}
+	return rx, tx
+}
+
+func blockIOBytes(stats Stats) (read, write uint64) {
+	for _, entry := range stats.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			read += entry.Value
+		case "write":
+			write += entry.Value
+		}
+	}
+	return read, write
+}
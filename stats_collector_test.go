@@ -0,0 +1,351 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCollectStats(t *testing.T) {
+	t.Parallel()
+	var (
+		mu      sync.Mutex
+		samples uint64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/c1/stats" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		samples++
+		n := samples
+		mu.Unlock()
+		var stats Stats
+		stats.MemoryStats.Usage = n
+		json.NewEncoder(w).Encode(stats)
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector, err := client.CollectStats("c1", 5*time.Millisecond, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector.SetSize(3)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(collector.Snapshot()) < 3 || collector.Snapshot()[0].MemoryStats.Usage < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("CollectStats: timed out waiting for samples")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	collector.Stop()
+	snapshot := collector.Snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("CollectStats: wrong number of samples. Want 3. Got %d.", len(snapshot))
+	}
+	for i := 1; i < len(snapshot); i++ {
+		if snapshot[i].MemoryStats.Usage != snapshot[i-1].MemoryStats.Usage+1 {
+			t.Errorf("CollectStats: samples out of order: %d after %d.", snapshot[i].MemoryStats.Usage, snapshot[i-1].MemoryStats.Usage)
+		}
+	}
+	if err = collector.Err(); err != nil {
+		t.Errorf("CollectStats: unexpected error: %s", err)
+	}
+}
+
+func TestCollectStatsNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.CollectStats("c1", time.Second, context.Background())
+	expected := &NoSuchContainer{ID: "c1"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("CollectStats: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestCollectStatsContainerRemoved(t *testing.T) {
+	t.Parallel()
+	var (
+		mu      sync.Mutex
+		removed bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if removed {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		removed = true
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector, err := client.CollectStats("c1", 5*time.Millisecond, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-collector.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("CollectStats: collection did not stop after the container was removed")
+	}
+	if _, ok := collector.Err().(*NoSuchContainer); !ok {
+		t.Errorf("CollectStats: wrong error. Want NoSuchContainer. Got %#v.", collector.Err())
+	}
+	if n := len(collector.Snapshot()); n != 1 {
+		t.Errorf("CollectStats: wrong number of samples. Want 1. Got %d.", n)
+	}
+}
+
+func TestStatsCollectorAverage(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2019, 1, 10, 13, 20, 0, 0, time.UTC)
+	sample := func(offset time.Duration, cpu, system uint64, percpu []uint64, memory, rx, tx, read, write uint64) Stats {
+		var stats Stats
+		stats.Read = start.Add(offset)
+		stats.CPUStats.CPUUsage.TotalUsage = cpu
+		stats.CPUStats.CPUUsage.PercpuUsage = percpu
+		stats.CPUStats.SystemCPUUsage = system
+		stats.CPUStats.OnlineCPUs = uint64(len(percpu))
+		stats.MemoryStats.Usage = memory
+		stats.MemoryStats.Limit = 1000
+		stats.Networks = map[string]NetworkStats{
+			"eth0": {RxBytes: rx / 2, TxBytes: tx / 2},
+			"eth1": {RxBytes: rx / 2, TxBytes: tx / 2},
+		}
+		stats.BlkioStats.IOServiceBytesRecursive = []BlkioStatsEntry{
+			{Op: "Read", Value: read},
+			{Op: "Write", Value: write},
+			{Op: "Total", Value: read + write},
+		}
+		return stats
+	}
+	collector := &StatsCollector{samples: make([]Stats, 2)}
+	collector.add(sample(0, 1000, 10000, []uint64{600, 400}, 100, 0, 0, 0, 0))
+	collector.add(sample(time.Second, 2000, 20000, []uint64{1000, 1000}, 200, 1000, 2000, 4096, 8192))
+	collector.add(sample(3*time.Second, 4000, 30000, []uint64{2000, 2000}, 300, 5000, 4000, 8192, 8192))
+	avg := collector.Average()
+	expected := StatsAverage{
+		Samples:                  2,
+		Duration:                 2 * time.Second,
+		CPUPercent:               40,
+		PerCPUPercent:            []float64{20, 20},
+		MemoryUsage:              250,
+		MemoryLimit:              1000,
+		MemoryPercent:            25,
+		NetworkRxBytesPerSecond:  2000,
+		NetworkTxBytesPerSecond:  1000,
+		BlockReadBytesPerSecond:  2048,
+		BlockWriteBytesPerSecond: 0,
+	}
+	if !reflect.DeepEqual(avg, expected) {
+		t.Errorf("Average: wrong result.\nWant %#v.\nGot  %#v.", expected, avg)
+	}
+}
+
+func TestStatsCollectorAverageCounterReset(t *testing.T) {
+	t.Parallel()
+	collector := &StatsCollector{samples: make([]Stats, 2)}
+	var first, last Stats
+	first.Read = time.Now()
+	first.CPUStats.CPUUsage.TotalUsage = 5000
+	first.CPUStats.SystemCPUUsage = 10000
+	first.CPUStats.OnlineCPUs = 1
+	first.Network.RxBytes = 1000
+	last.Read = first.Read.Add(time.Second)
+	last.CPUStats.CPUUsage.TotalUsage = 100
+	last.CPUStats.SystemCPUUsage = 20000
+	last.CPUStats.OnlineCPUs = 1
+	last.Network.RxBytes = 10
+	collector.add(first)
+	collector.add(last)
+	avg := collector.Average()
+	if avg.CPUPercent != 0 || avg.NetworkRxBytesPerSecond != 0 {
+		t.Errorf("Average: expected zero usage after counters reset. Got %#v.", avg)
+	}
+}
+
+func TestStatsCollectorSetSize(t *testing.T) {
+	t.Parallel()
+	collector := &StatsCollector{samples: make([]Stats, 4)}
+	for i := uint64(1); i <= 6; i++ {
+		var stats Stats
+		stats.NumProcs = uint32(i)
+		collector.add(stats)
+	}
+	procs := func() []uint32 {
+		var result []uint32
+		for _, stats := range collector.Snapshot() {
+			result = append(result, stats.NumProcs)
+		}
+		return result
+	}
+	if got := procs(); !reflect.DeepEqual(got, []uint32{3, 4, 5, 6}) {
+		t.Errorf("Snapshot: wrong samples. Got %v.", got)
+	}
+	collector.SetSize(2)
+	if got := procs(); !reflect.DeepEqual(got, []uint32{5, 6}) {
+		t.Errorf("SetSize: wrong samples after shrinking. Got %v.", got)
+	}
+	collector.SetSize(3)
+	var stats Stats
+	stats.NumProcs = 7
+	collector.add(stats)
+	if got := procs(); !reflect.DeepEqual(got, []uint32{5, 6, 7}) {
+		t.Errorf("SetSize: wrong samples after growing. Got %v.", got)
+	}
+}
+
+func TestComputeCPUPercent(t *testing.T) {
+	t.Parallel()
+	var previous, current Stats
+	previous.CPUStats.CPUUsage.TotalUsage = 1000
+	previous.CPUStats.SystemCPUUsage = 10000
+	current.CPUStats.CPUUsage.TotalUsage = 3000
+	current.CPUStats.SystemCPUUsage = 20000
+	current.CPUStats.CPUUsage.PercpuUsage = []uint64{1500, 1500, 0, 0}
+	if percent := ComputeCPUPercent(&current, &previous); percent != 80 {
+		t.Errorf("ComputeCPUPercent: wrong percentage. Want 80. Got %g.", percent)
+	}
+	current.CPUStats.OnlineCPUs = 2
+	if percent := ComputeCPUPercent(&current, &previous); percent != 40 {
+		t.Errorf("ComputeCPUPercent: wrong percentage. Want 40. Got %g.", percent)
+	}
+	if percent := ComputeCPUPercent(&current, &current); percent != 0 {
+		t.Errorf("ComputeCPUPercent: wrong percentage for the same sample. Want 0. Got %g.", percent)
+	}
+}
+
+func TestSingleSampleCPUPercent(t *testing.T) {
+	t.Parallel()
+	var stats Stats
+	stats.CPUStats.CPUUsage.TotalUsage = 3000
+	stats.CPUStats.SystemCPUUsage = 20000
+	stats.CPUStats.OnlineCPUs = 2
+	// without the previous sample, the usage is averaged since the start of
+	// the system
+	if percent := SingleSampleCPUPercent(&stats); percent != 30 {
+		t.Errorf("SingleSampleCPUPercent: wrong percentage. Want 30. Got %g.", percent)
+	}
+	stats.PreCPUStats.CPUUsage.TotalUsage = 1000
+	stats.PreCPUStats.SystemCPUUsage = 10000
+	if percent := SingleSampleCPUPercent(&stats); percent != 40 {
+		t.Errorf("SingleSampleCPUPercent: wrong percentage. Want 40. Got %g.", percent)
+	}
+}
+
+func TestInspectContainerStats(t *testing.T) {
+	t.Parallel()
+	var (
+		mu      sync.Mutex
+		samples uint64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/c1/stats" || r.URL.Query().Get("stream") != "false" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		samples++
+		n := samples
+		mu.Unlock()
+		var stats Stats
+		stats.CPUStats.CPUUsage.TotalUsage = n * 500
+		stats.CPUStats.SystemCPUUsage = n * 10000
+		stats.CPUStats.OnlineCPUs = 4
+		stats.MemoryStats.Usage = n
+		json.NewEncoder(w).Encode(stats)
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := client.InspectContainerStats("c1", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MemoryStats.Usage != 2 {
+		t.Errorf("InspectContainerStats: expected the second sample. Got %#v.", stats.MemoryStats)
+	}
+	if stats.CPUPercentage != 20 {
+		t.Errorf("InspectContainerStats: wrong CPU percentage. Want 20. Got %g.", stats.CPUPercentage)
+	}
+	_, err = client.InspectContainerStats("c2", context.Background())
+	if expected := (&NoSuchContainer{ID: "c2"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("InspectContainerStats: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestMeasureContainerNetworkThroughput(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2019, 1, 10, 13, 20, 0, 0, time.UTC)
+	var (
+		mu      sync.Mutex
+		samples int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/c1/stats" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		samples++
+		n := uint64(samples)
+		mu.Unlock()
+		var stats Stats
+		stats.Read = start.Add(time.Duration(n) * 2 * time.Second)
+		stats.Networks = map[string]NetworkStats{
+			"eth0": {RxBytes: n * 4000, TxBytes: n * 1000},
+			"eth1": {RxBytes: n * 1000, TxBytes: 500},
+		}
+		json.NewEncoder(w).Encode(stats)
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurement, err := client.MeasureContainerNetworkThroughput("c1", time.Millisecond, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ThroughputMeasurement{
+		MeasurementDuration: 2 * time.Second,
+		RxBytesPerSec:       2500,
+		TxBytesPerSec:       500,
+		Interfaces: map[string]InterfaceThroughput{
+			"eth0": {RxBytesPerSec: 2000, TxBytesPerSec: 500},
+			"eth1": {RxBytesPerSec: 500, TxBytesPerSec: 0},
+		},
+	}
+	if !reflect.DeepEqual(*measurement, expected) {
+		t.Errorf("MeasureContainerNetworkThroughput: wrong measurement.\nWant %#v.\nGot  %#v.", expected, *measurement)
+	}
+	_, err = client.MeasureContainerNetworkThroughput("c2", time.Millisecond, context.Background())
+	if expectedErr := (&NoSuchContainer{ID: "c2"}); !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("MeasureContainerNetworkThroughput: wrong error. Want %#v. Got %#v.", expectedErr, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = client.MeasureContainerNetworkThroughput("c1", time.Minute, ctx); err != context.DeadlineExceeded {
+		t.Errorf("MeasureContainerNetworkThroughput: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// StdCopy demultiplexes the stream read from src, in the format used by the
+// attach and logs endpoints of containers without a TTY, writing the stdout
+// frames to dstOut and the stderr frames to dstErr, until src returns
+// io.EOF. It returns the number of bytes written, excluding the frame
+// headers. A nil writer discards its stream.
+//
+// Each frame starts with an 8-byte header, where the first byte identifies
+// the stream and the last four are the size of the payload, as a big endian
+// uint32. As in the stdcopy package of Docker, a stream truncated in the
+// middle of a frame ends the copy without an error, and frames of the daemon
+// error stream are returned as errors.
+func StdCopy(dstOut, dstErr io.Writer, src io.Reader) (written int64, err error) {
+	if dstOut == nil {
+		dstOut = ioutil.Discard
+	}
+	if dstErr == nil {
+		dstErr = ioutil.Discard
+	}
+	for {
+		streamType, size, err := ReadStreamHeader(src)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		var dst io.Writer
+		switch streamType {
+		case StreamStdin, StreamStdout:
+			dst = dstOut
+		case StreamStderr:
+			dst = dstErr
+		case StreamSystemErr:
+			message, err := ioutil.ReadAll(io.LimitReader(src, int64(size)))
+			if err != nil {
+				return written, err
+			}
+			return written, fmt.Errorf("error from daemon in stream: %s", message)
+		default:
+			return written, fmt.Errorf("unrecognized input header: %d", streamType)
+		}
+		n, err := io.CopyN(dst, src, int64(size))
+		written += n
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
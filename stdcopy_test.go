@@ -0,0 +1,97 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStdCopy(t *testing.T) {
+	t.Parallel()
+	input := logFrame(1, "hello ") + logFrame(2, "oops\n") + logFrame(0, "stdin ") + logFrame(1, "world\n")
+	var stdout, stderr bytes.Buffer
+	written, err := StdCopy(&stdout, &stderr, iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "hello stdin world\n"; stdout.String() != expected {
+		t.Errorf("StdCopy: wrong stdout. Want %q. Got %q.", expected, stdout.String())
+	}
+	if expected := "oops\n"; stderr.String() != expected {
+		t.Errorf("StdCopy: wrong stderr. Want %q. Got %q.", expected, stderr.String())
+	}
+	if written != 23 {
+		t.Errorf("StdCopy: wrong number of bytes written. Want %d. Got %d.", 23, written)
+	}
+}
+
+func TestStdCopyLargeFrame(t *testing.T) {
+	t.Parallel()
+	data := strings.Repeat("x", 4<<20)
+	var stdout bytes.Buffer
+	written, err := StdCopy(&stdout, nil, iotest.HalfReader(strings.NewReader(logFrame(1, data)+logFrame(2, "discarded"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != data {
+		t.Errorf("StdCopy: wrong stdout. Want %d bytes. Got %d.", len(data), stdout.Len())
+	}
+	if expected := int64(len(data) + len("discarded")); written != expected {
+		t.Errorf("StdCopy: wrong number of bytes written. Want %d. Got %d.", expected, written)
+	}
+}
+
+func TestStdCopyTruncated(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"mid-header", logFrame(1, "complete") + "\x01\x00\x00", "complete"},
+		{"mid-frame", logFrame(1, "complete") + logFrame(1, "truncated")[:12], "completetrun"},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout bytes.Buffer
+			written, err := StdCopy(&stdout, nil, strings.NewReader(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stdout.String() != test.expected {
+				t.Errorf("StdCopy: wrong stdout. Want %q. Got %q.", test.expected, stdout.String())
+			}
+			if written != int64(len(test.expected)) {
+				t.Errorf("StdCopy: wrong number of bytes written. Want %d. Got %d.", len(test.expected), written)
+			}
+		})
+	}
+}
+
+func TestStdCopyErrors(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		input    string
+		expected string
+	}{
+		{logFrame(3, "something went wrong"), "error from daemon in stream: something went wrong"},
+		{logFrame(7, "unknown"), "unrecognized input header: 7"},
+	}
+	for _, test := range tests {
+		var stdout bytes.Buffer
+		_, err := StdCopy(&stdout, &stdout, strings.NewReader(logFrame(1, "before")+test.input))
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("StdCopy: wrong error. Want %q. Got %v.", test.expected, err)
+		}
+		if stdout.String() != "before" {
+			t.Errorf("StdCopy: wrong stdout. Want %q. Got %q.", "before", stdout.String())
+		}
+	}
+}
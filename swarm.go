@@ -55,6 +55,28 @@ func (c *Client) InitSwarm(opts InitSwarmOptions) (string, error) {
 	return response, nil
 }
 
+// InitSwarmResult is the result of InitSwarmWithTokens: the ID of the node
+// and the tokens other nodes can use to join the new Swarm.
+type InitSwarmResult struct {
+	NodeID     string
+	JoinTokens swarm.JoinTokens
+}
+
+// InitSwarmWithTokens initializes a new Swarm and returns the node ID along
+// with the worker and manager join tokens, so they can be handed to other
+// nodes right away.
+func (c *Client) InitSwarmWithTokens(opts InitSwarmOptions) (*InitSwarmResult, error) {
+	nodeID, err := c.InitSwarm(opts)
+	if err != nil {
+		return nil, err
+	}
+	sw, err := c.InspectSwarm(opts.Context)
+	if err != nil {
+		return nil, err
+	}
+	return &InitSwarmResult{NodeID: nodeID, JoinTokens: sw.JoinTokens}, nil
+}
+
 // JoinSwarmOptions specify parameters to the JoinSwarm function.
 // See https://goo.gl/TdhJWU for more details.
 type JoinSwarmOptions struct {
@@ -154,3 +176,35 @@ func (c *Client) InspectSwarm(ctx context.Context) (swarm.Swarm, error) {
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
+
+// RotateSwarmTokensOptions specify parameters to the RotateSwarmTokens
+// function.
+type RotateSwarmTokensOptions struct {
+	Worker  bool
+	Manager bool
+	Context context.Context
+}
+
+// RotateSwarmTokens rotates the worker and/or manager join tokens of the
+// Swarm, keeping its current spec, and returns the new tokens.
+func (c *Client) RotateSwarmTokens(opts RotateSwarmTokensOptions) (swarm.JoinTokens, error) {
+	sw, err := c.InspectSwarm(opts.Context)
+	if err != nil {
+		return swarm.JoinTokens{}, err
+	}
+	err = c.UpdateSwarm(UpdateSwarmOptions{
+		Version:            int(sw.Version.Index),
+		RotateWorkerToken:  opts.Worker,
+		RotateManagerToken: opts.Manager,
+		Swarm:              sw.Spec,
+		Context:            opts.Context,
+	})
+	if err != nil {
+		return swarm.JoinTokens{}, err
+	}
+	sw, err = c.InspectSwarm(opts.Context)
+	if err != nil {
+		return swarm.JoinTokens{}, err
+	}
+	return sw.JoinTokens, nil
+}
@@ -27,8 +27,16 @@ func (err *NoSuchConfig) Error() string {
 	return "No such config: " + err.ID
 }
 
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchConfig) Unwrap() error {
+	return err.Err
+}
+
 // CreateConfigOptions specify parameters to the CreateConfig function.
 //
+// ConfigSpec.Data holds the raw content of the config, which is base64
+// encoded when sent to the daemon, so it must not be encoded by the caller.
+//
 // See https://goo.gl/KrVjHz for more details.
 type CreateConfigOptions struct {
 	Auth AuthConfiguration `qs:"-"`
@@ -14,6 +14,11 @@ import (
 	"github.com/docker/docker/api/types/swarm"
 )
 
+// defaultNodeUpdateRetries is the number of times the node availability and
+// role helpers retry an update that conflicts with a concurrent update of the
+// node.
+const defaultNodeUpdateRetries = 3
+
 // NoSuchNode is the error returned when a given node does not exist.
 type NoSuchNode struct {
 	ID  string
@@ -27,6 +32,11 @@ func (err *NoSuchNode) Error() string {
 	return "No such node: " + err.ID
 }
 
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchNode) Unwrap() error {
+	return err.Err
+}
+
 // ListNodesOptions specify parameters to the ListNodes function.
 //
 // See http://goo.gl/3K4GwU for more details.
@@ -56,7 +66,11 @@ func (c *Client) ListNodes(opts ListNodesOptions) ([]swarm.Node, error) {
 //
 // See http://goo.gl/WjkTOk for more details.
 func (c *Client) InspectNode(id string) (*swarm.Node, error) {
-	resp, err := c.do("GET", "/nodes/"+id, doOptions{})
+	return c.inspectNode(id, nil)
+}
+
+func (c *Client) inspectNode(id string, ctx context.Context) (*swarm.Node, error) {
+	resp, err := c.do("GET", "/nodes/"+id, doOptions{context: ctx})
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, &NoSuchNode{ID: id}
@@ -102,6 +116,96 @@ func (c *Client) UpdateNode(id string, opts UpdateNodeOptions) error {
 	return nil
 }
 
+// ModifyNodeOptions specify parameters to the ModifyNode function.
+type ModifyNodeOptions struct {
+	// Modify changes the current spec of the node, which is then sent back
+	// to the daemon. It may be called more than once when the update is
+	// retried.
+	Modify func(*swarm.NodeSpec) error
+
+	// MaxRetries is the number of times the node is fetched again and the
+	// update is resent when it conflicts with a concurrent update of the
+	// node. Defaults to no retries.
+	MaxRetries int
+
+	Context context.Context
+}
+
+// ModifyNode fetches the current spec of the node, changes it with
+// opts.Modify and sends it back to the daemon along with the current version
+// of the node, so concurrent updates aren't silently overwritten.
+func (c *Client) ModifyNode(id string, opts ModifyNodeOptions) error {
+	for retries := 0; ; retries++ {
+		node, err := c.inspectNode(id, opts.Context)
+		if err != nil {
+			return err
+		}
+		spec := node.Spec
+		if opts.Modify != nil {
+			if err = opts.Modify(&spec); err != nil {
+				return err
+			}
+		}
+		err = c.UpdateNode(id, UpdateNodeOptions{
+			NodeSpec: spec,
+			Version:  node.Version.Index,
+			Context:  opts.Context,
+		})
+		if e, ok := err.(*Error); ok && e.Status == http.StatusConflict && retries < opts.MaxRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// DrainNode sets the availability of the node to drain, so no new tasks are
+// scheduled on it and its running tasks are moved to other nodes.
+func (c *Client) DrainNode(id string) error {
+	return c.setNodeAvailability(id, swarm.NodeAvailabilityDrain)
+}
+
+// PauseNode sets the availability of the node to pause, so no new tasks are
+// scheduled on it, while its running tasks keep running.
+func (c *Client) PauseNode(id string) error {
+	return c.setNodeAvailability(id, swarm.NodeAvailabilityPause)
+}
+
+// ActivateNode sets the availability of the node to active, so new tasks can
+// be scheduled on it.
+func (c *Client) ActivateNode(id string) error {
+	return c.setNodeAvailability(id, swarm.NodeAvailabilityActive)
+}
+
+// PromoteNode changes the role of the node to manager.
+func (c *Client) PromoteNode(id string) error {
+	return c.setNodeRole(id, swarm.NodeRoleManager)
+}
+
+// DemoteNode changes the role of the node to worker.
+func (c *Client) DemoteNode(id string) error {
+	return c.setNodeRole(id, swarm.NodeRoleWorker)
+}
+
+func (c *Client) setNodeAvailability(id string, availability swarm.NodeAvailability) error {
+	return c.ModifyNode(id, ModifyNodeOptions{
+		Modify: func(spec *swarm.NodeSpec) error {
+			spec.Availability = availability
+			return nil
+		},
+		MaxRetries: defaultNodeUpdateRetries,
+	})
+}
+
+func (c *Client) setNodeRole(id string, role swarm.NodeRole) error {
+	return c.ModifyNode(id, ModifyNodeOptions{
+		Modify: func(spec *swarm.NodeSpec) error {
+			spec.Role = role
+			return nil
+		},
+		MaxRetries: defaultNodeUpdateRetries,
+	})
+}
+
 // RemoveNodeOptions specify parameters to the RemoveNode function.
 //
 // See http://goo.gl/0SNvYg for more details.
@@ -7,8 +7,12 @@ package docker
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/docker/docker/api/types/swarm"
@@ -259,3 +263,132 @@ func TestRemoveNodeNotFound(t *testing.T) {
 		t.Errorf("RemoveNode: Wrong error returned. Want %#v. Got %#v.", expected, err)
 	}
 }
+
+func TestNodeAvailabilityAndRoleHelpers(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		name   string
+		fn     func(*Client, string) error
+		expect swarm.NodeSpec
+	}{
+		{"DrainNode", (*Client).DrainNode, swarm.NodeSpec{Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityDrain}},
+		{"PauseNode", (*Client).PauseNode, swarm.NodeSpec{Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityPause}},
+		{"ActivateNode", (*Client).ActivateNode, swarm.NodeSpec{Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityActive}},
+		{"PromoteNode", (*Client).PromoteNode, swarm.NodeSpec{Role: swarm.NodeRoleManager, Availability: swarm.NodeAvailabilityPause}},
+		{"DemoteNode", (*Client).DemoteNode, swarm.NodeSpec{Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityPause}},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fake, client, cleanup := newFakeNodeServer(t)
+			defer cleanup()
+			if err := test.fn(client, fake.node.ID); err != nil {
+				t.Fatal(err)
+			}
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			expected := test.expect
+			expected.Labels = map[string]string{"zone": "a"}
+			if !reflect.DeepEqual(fake.node.Spec, expected) {
+				t.Errorf("%s: wrong spec. Want %#v. Got %#v.", test.name, expected, fake.node.Spec)
+			}
+			if version := fake.updates[0].URL.Query().Get("version"); version != "10" {
+				t.Errorf("%s: wrong version. Want %q. Got %q.", test.name, "10", version)
+			}
+		})
+	}
+}
+
+func TestModifyNodeRetriesOnConflict(t *testing.T) {
+	t.Parallel()
+	fake, client, cleanup := newFakeNodeServer(t)
+	defer cleanup()
+	fake.conflicts = 2
+	if err := client.DrainNode(fake.node.ID); err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.updates) != 3 {
+		t.Errorf("DrainNode: wrong number of updates. Want 3. Got %d.", len(fake.updates))
+	}
+	if fake.node.Spec.Availability != swarm.NodeAvailabilityDrain {
+		t.Errorf("DrainNode: wrong availability. Want %q. Got %q.", swarm.NodeAvailabilityDrain, fake.node.Spec.Availability)
+	}
+}
+
+func TestModifyNodeConflictNoRetries(t *testing.T) {
+	t.Parallel()
+	fake, client, cleanup := newFakeNodeServer(t)
+	defer cleanup()
+	fake.conflicts = 1
+	err := client.ModifyNode(fake.node.ID, ModifyNodeOptions{})
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusConflict {
+		t.Errorf("ModifyNode: wrong error. Want conflict. Got %#v.", err)
+	}
+}
+
+func TestModifyNodeNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "node not found", status: http.StatusNotFound})
+	err := client.DrainNode("unknown")
+	expected := &NoSuchNode{ID: "unknown"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("DrainNode: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+type fakeNodeServer struct {
+	mu        sync.Mutex
+	node      swarm.Node
+	updates   []*http.Request
+	conflicts int
+}
+
+func (s *fakeNodeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/nodes/"+s.node.ID):
+		json.NewEncoder(w).Encode(s.node)
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/nodes/"+s.node.ID+"/update"):
+		s.updates = append(s.updates, r)
+		if s.conflicts > 0 {
+			// simulates a concurrent update of the node
+			s.conflicts--
+			s.node.Version.Index++
+		}
+		if r.URL.Query().Get("version") != strconv.FormatUint(s.node.Version.Index, 10) {
+			http.Error(w, "update out of sequence", http.StatusConflict)
+			return
+		}
+		var spec swarm.NodeSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.node.Spec = spec
+		s.node.Version.Index++
+	default:
+		http.Error(w, "node not found", http.StatusNotFound)
+	}
+}
+
+func newFakeNodeServer(t *testing.T) (*fakeNodeServer, *Client, func()) {
+	fake := &fakeNodeServer{}
+	fake.node.ID = "24ifsmvkjbyhk"
+	fake.node.Version.Index = 10
+	fake.node.Spec = swarm.NodeSpec{
+		Annotations:  swarm.Annotations{Labels: map[string]string{"zone": "a"}},
+		Role:         swarm.NodeRoleWorker,
+		Availability: swarm.NodeAvailabilityPause,
+	}
+	server := httptest.NewServer(fake)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return fake, client, server.Close
+}
@@ -5,15 +5,25 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types/swarm"
 )
 
+const defaultSecretsTarget = "/run/secrets"
+
 // NoSuchSecret is the error returned when a given secret does not exist.
 type NoSuchSecret struct {
 	ID  string
@@ -27,8 +37,16 @@ func (err *NoSuchSecret) Error() string {
 	return "No such secret: " + err.ID
 }
 
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchSecret) Unwrap() error {
+	return err.Err
+}
+
 // CreateSecretOptions specify parameters to the CreateSecret function.
 //
+// SecretSpec.Data holds the raw content of the secret, which is base64
+// encoded when sent to the daemon, so it must not be encoded by the caller.
+//
 // See https://goo.gl/KrVjHz for more details.
 type CreateSecretOptions struct {
 	Auth AuthConfiguration `qs:"-"`
@@ -127,10 +145,17 @@ func (c *Client) UpdateSecret(id string, opts UpdateSecretOptions) error {
 
 // InspectSecret returns information about a secret by its ID.
 //
+// The data of the secret is never returned by the daemon, so the Data field
+// of the spec is always empty.
+//
 // See https://goo.gl/dHmr75 for more details.
 func (c *Client) InspectSecret(id string) (*swarm.Secret, error) {
+	return c.inspectSecret(id, nil)
+}
+
+func (c *Client) inspectSecret(id string, ctx context.Context) (*swarm.Secret, error) {
 	path := "/secrets/" + id
-	resp, err := c.do("GET", path, doOptions{})
+	resp, err := c.do("GET", path, doOptions{context: ctx})
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, &NoSuchSecret{ID: id}
@@ -169,3 +194,185 @@ func (c *Client) ListSecrets(opts ListSecretsOptions) ([]swarm.Secret, error) {
 	}
 	return secrets, nil
 }
+
+// RotateSecretOptions specify parameters to the RotateSecret function.
+type RotateSecretOptions struct {
+	// ID is the name or ID of the secret being rotated.
+	ID string
+
+	// Name is the name of the new secret. Secrets are immutable, so the
+	// new secret must have a different name.
+	Name string
+
+	// Data is the raw content of the new secret.
+	Data []byte
+
+	// RemoveOld removes the old secret right after the specs of the
+	// services referencing it are updated, without waiting for their tasks
+	// to be replaced.
+	RemoveOld bool
+
+	Context context.Context
+}
+
+// RotateSecret creates a new secret, with the labels and driver of the secret
+// being rotated, and updates the services referencing the old secret to use
+// the new one, keeping the file the secret is mounted at. It returns the new
+// secret.
+func (c *Client) RotateSecret(opts RotateSecretOptions) (*swarm.Secret, error) {
+	if opts.Name == "" {
+		return nil, errors.New("the name of the new secret is required")
+	}
+	old, err := c.inspectSecret(opts.ID, opts.Context)
+	if err != nil {
+		return nil, err
+	}
+	spec := old.Spec
+	spec.Name = opts.Name
+	spec.Data = opts.Data
+	secret, err := c.CreateSecret(CreateSecretOptions{SecretSpec: spec, Context: opts.Context})
+	if err != nil {
+		return nil, err
+	}
+	services, err := c.ListServices(ListServicesOptions{Context: opts.Context})
+	if err != nil {
+		return secret, err
+	}
+	for _, service := range services {
+		if !referencesSecret(service.Spec, old.ID) {
+			continue
+		}
+		err = c.ModifyService(service.ID, ModifyServiceOptions{
+			Modify: func(spec *swarm.ServiceSpec) error {
+				replaceSecret(spec, old.ID, secret.ID, opts.Name)
+				return nil
+			},
+			MaxRetries: defaultServiceUpdateRetries,
+			Context:    opts.Context,
+		})
+		if err != nil {
+			return secret, err
+		}
+	}
+	if opts.RemoveOld {
+		err = c.RemoveSecret(RemoveSecretOptions{ID: old.ID, Context: opts.Context})
+	}
+	return secret, err
+}
+
+// PopulateEnvFromSecrets returns the content of the given secrets as a list of
+// environment variables in the format KEY=VALUE, where KEY is the name of the
+// secret in uppercase, with any character other than letters, digits and
+// underscores replaced by an underscore.
+//
+// The daemon never returns the data of a secret, so it's read from the file
+// the secret is mounted at in the container, which must be a task of a Swarm
+// service that has access to all the secrets. A single trailing newline is
+// removed from the content of each secret.
+func (c *Client) PopulateEnvFromSecrets(containerID string, secretNames []string, ctx context.Context) ([]string, error) {
+	taskID, err := c.GetContainerTaskID(containerID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := c.inspectTask(taskID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	env := make([]string, 0, len(secretNames))
+	for _, name := range secretNames {
+		target, err := secretTarget(task.Spec, name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := c.readContainerFile(containerID, target, ctx)
+		if err != nil {
+			return nil, err
+		}
+		value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		env = append(env, secretEnvName(name)+"="+value)
+	}
+	return env, nil
+}
+
+// secretTarget returns the path of the file the given secret is mounted at in
+// the containers of the task.
+func secretTarget(spec swarm.TaskSpec, name string) (string, error) {
+	if spec.ContainerSpec != nil {
+		for _, ref := range spec.ContainerSpec.Secrets {
+			if ref == nil || ref.SecretName != name {
+				continue
+			}
+			target := name
+			if ref.File != nil && ref.File.Name != "" {
+				target = ref.File.Name
+			}
+			if !path.IsAbs(target) {
+				target = path.Join(defaultSecretsTarget, target)
+			}
+			return target, nil
+		}
+	}
+	return "", &NoSuchSecret{ID: name, Err: fmt.Errorf("secret %s is not available to the container", name)}
+}
+
+func secretEnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// readContainerFile returns the content of a regular file in the container.
+func (c *Client) readContainerFile(containerID, filePath string, ctx context.Context) ([]byte, error) {
+	var buf bytes.Buffer
+	err := c.DownloadFromContainer(containerID, DownloadFromContainerOptions{
+		OutputStream: &buf,
+		Path:         filePath,
+		Context:      ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("file %s not found in container %s", filePath, containerID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA {
+			return ioutil.ReadAll(tr)
+		}
+	}
+}
+
+func referencesSecret(spec swarm.ServiceSpec, secretID string) bool {
+	if spec.TaskTemplate.ContainerSpec == nil {
+		return false
+	}
+	for _, ref := range spec.TaskTemplate.ContainerSpec.Secrets {
+		if ref != nil && ref.SecretID == secretID {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceSecret(spec *swarm.ServiceSpec, oldID, newID, newName string) {
+	if spec.TaskTemplate.ContainerSpec == nil {
+		return
+	}
+	for _, ref := range spec.TaskTemplate.ContainerSpec.Secrets {
+		if ref != nil && ref.SecretID == oldID {
+			ref.SecretID = newID
+			ref.SecretName = newName
+		}
+	}
+}
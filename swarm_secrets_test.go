@@ -5,11 +5,15 @@
 package docker
 
 import (
+	"archive/tar"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/docker/docker/api/types/swarm"
@@ -283,3 +287,196 @@ func TestListSecrets(t *testing.T) {
 		t.Errorf("ListSecrets: Expected %#v. Got %#v.", expected, secrets)
 	}
 }
+
+func TestCreateSecretEncodesData(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ID": "d1c4rm7fh9s3wmo6ouw4g1nq3"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	opts := CreateSecretOptions{}
+	opts.Name = "db-password"
+	opts.Data = []byte("s3cr3t\n")
+	if _, err := client.CreateSecret(opts); err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	expected := base64.StdEncoding.EncodeToString([]byte("s3cr3t\n"))
+	if body["Data"] != expected {
+		t.Errorf("CreateSecret: wrong data. Want %q. Got %q.", expected, body["Data"])
+	}
+}
+
+func TestRotateSecret(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var created swarm.SecretSpec
+	var updated []swarm.ServiceSpec
+	var removed []string
+	newService := func(id string, secretIDs ...string) swarm.Service {
+		var service swarm.Service
+		service.ID = id
+		service.Version.Index = 7
+		service.Spec.Name = id
+		service.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "tsuru/python"}
+		for _, secretID := range secretIDs {
+			service.Spec.TaskTemplate.ContainerSpec.Secrets = append(service.Spec.TaskTemplate.ContainerSpec.Secrets, &swarm.SecretReference{
+				SecretID:   secretID,
+				SecretName: "name-of-" + secretID,
+				File:       &swarm.SecretReferenceFileTarget{Name: "/run/secrets/" + secretID, Mode: 0400},
+			})
+		}
+		return service
+	}
+	services := []swarm.Service{newService("web", "old-secret", "other-secret"), newService("worker", "other-secret")}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/secrets/db-password", func(w http.ResponseWriter, r *http.Request) {
+		var secret swarm.Secret
+		secret.ID = "old-secret"
+		secret.Spec.Name = "db-password"
+		secret.Spec.Labels = map[string]string{"app": "web"}
+		json.NewEncoder(w).Encode(secret)
+	})
+	mux.HandleFunc("/secrets/old-secret", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		removed = append(removed, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+	})
+	mux.HandleFunc("/secrets/create", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		json.NewDecoder(r.Body).Decode(&created)
+		mu.Unlock()
+		w.Write([]byte(`{"ID": "new-secret"}`))
+	})
+	mux.HandleFunc("/services", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(services)
+	})
+	mux.HandleFunc("/services/web", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(services[0])
+	})
+	mux.HandleFunc("/services/web/update", func(w http.ResponseWriter, r *http.Request) {
+		if version := r.URL.Query().Get("version"); version != "7" {
+			http.Error(w, "wrong version "+version, http.StatusBadRequest)
+			return
+		}
+		var spec swarm.ServiceSpec
+		json.NewDecoder(r.Body).Decode(&spec)
+		mu.Lock()
+		updated = append(updated, spec)
+		mu.Unlock()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := client.RotateSecret(RotateSecretOptions{
+		ID:        "db-password",
+		Name:      "db-password-v2",
+		Data:      []byte("n3w s3cr3t"),
+		RemoveOld: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.ID != "new-secret" {
+		t.Errorf("RotateSecret: wrong secret ID. Want %q. Got %q.", "new-secret", secret.ID)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if created.Name != "db-password-v2" || string(created.Data) != "n3w s3cr3t" || created.Labels["app"] != "web" {
+		t.Errorf("RotateSecret: wrong secret spec. Got %#v.", created)
+	}
+	if len(updated) != 1 {
+		t.Fatalf("RotateSecret: wrong number of service updates. Want 1. Got %d.", len(updated))
+	}
+	refs := updated[0].TaskTemplate.ContainerSpec.Secrets
+	expectedRefs := []*swarm.SecretReference{
+		{SecretID: "new-secret", SecretName: "db-password-v2", File: &swarm.SecretReferenceFileTarget{Name: "/run/secrets/old-secret", Mode: 0400}},
+		{SecretID: "other-secret", SecretName: "name-of-other-secret", File: &swarm.SecretReferenceFileTarget{Name: "/run/secrets/other-secret", Mode: 0400}},
+	}
+	if !reflect.DeepEqual(refs, expectedRefs) {
+		t.Errorf("RotateSecret: wrong secret references.\nWant %#v.\nGot  %#v.", expectedRefs, refs)
+	}
+	if expected := []string{"DELETE /secrets/old-secret"}; !reflect.DeepEqual(removed, expected) {
+		t.Errorf("RotateSecret: wrong removals. Want %#v. Got %#v.", expected, removed)
+	}
+}
+
+func TestRotateSecretWithoutName(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if _, err := client.RotateSecret(RotateSecretOptions{ID: "db-password"}); err == nil {
+		t.Error("RotateSecret: expected error when the new name is missing")
+	}
+	if len(fakeRT.requests) != 0 {
+		t.Errorf("RotateSecret: unexpected requests: %d.", len(fakeRT.requests))
+	}
+}
+
+func TestPopulateEnvFromSecrets(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"/run/secrets/db-password": "s3cr3t\n",
+		"/etc/app/api.key":         "abc123",
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/c1/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id":"c1","Config":{"Labels":{"com.docker.swarm.task.id":"t1"}}}`))
+	})
+	mux.HandleFunc("/tasks/t1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(swarm.Task{
+			ID: "t1",
+			Spec: swarm.TaskSpec{
+				ContainerSpec: &swarm.ContainerSpec{
+					Secrets: []*swarm.SecretReference{
+						{SecretID: "s1", SecretName: "db-password"},
+						{SecretID: "s2", SecretName: "api.key", File: &swarm.SecretReferenceFileTarget{Name: "/etc/app/api.key"}},
+					},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/containers/c1/archive", func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Query().Get("path")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tw := tar.NewWriter(w)
+		tw.WriteHeader(&tar.Header{Name: "secret", Mode: 0444, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+		tw.Close()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := client.PopulateEnvFromSecrets("c1", []string{"db-password", "api.key"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"DB_PASSWORD=s3cr3t", "API_KEY=abc123"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("PopulateEnvFromSecrets: wrong env. Want %#v. Got %#v.", expected, env)
+	}
+	_, err = client.PopulateEnvFromSecrets("c1", []string{"other"}, context.Background())
+	if e, ok := err.(*NoSuchSecret); !ok || e.ID != "other" {
+		t.Errorf("PopulateEnvFromSecrets: wrong error. Want NoSuchSecret. Got %#v.", err)
+	}
+}
+
+func TestPopulateEnvFromSecretsNotSwarmTask(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"c1","Config":{"Labels":{}}}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	_, err := client.PopulateEnvFromSecrets("c1", []string{"db-password"}, context.Background())
+	if err != ErrNotSwarmTask {
+		t.Errorf("PopulateEnvFromSecrets: wrong error. Want %#v. Got %#v.", ErrNotSwarmTask, err)
+	}
+}
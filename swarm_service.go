@@ -7,13 +7,27 @@ package docker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/swarm"
 )
 
+// defaultServiceUpdateRetries is the number of times UpdateServiceImage,
+// ScaleService, RollbackService and ForceRedeployService retry an update
+// that conflicts with a concurrent update of the service.
+const defaultServiceUpdateRetries = 3
+
+// ErrServiceNotReplicated is the error returned by ScaleService when the
+// service is not in replicated mode.
+var ErrServiceNotReplicated = errors.New("service is not in replicated mode")
+
 // NoSuchService is the error returned when a given service does not exist.
 type NoSuchService struct {
 	ID  string
@@ -27,6 +41,11 @@ func (err *NoSuchService) Error() string {
 	return "No such service: " + err.ID
 }
 
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchService) Unwrap() error {
+	return err.Err
+}
+
 // CreateServiceOptions specify parameters to the CreateService function.
 //
 // See https://goo.gl/KrVjHz for more details.
@@ -122,12 +141,127 @@ func (c *Client) UpdateService(id string, opts UpdateServiceOptions) error {
 	return nil
 }
 
+// ModifyServiceOptions specify parameters to the ModifyService function.
+type ModifyServiceOptions struct {
+	Auth AuthConfiguration
+
+	// Modify changes the current spec of the service, which is then sent
+	// back to the daemon. It may be called more than once when the update
+	// is retried.
+	Modify func(*swarm.ServiceSpec) error
+
+	// Rollback can be set to "previous" to roll the service back to its
+	// previous spec.
+	Rollback string
+
+	// MaxRetries is the number of times the service is fetched again and
+	// the update is resent when it conflicts with a concurrent update of
+	// the service. Defaults to no retries.
+	MaxRetries int
+
+	Context context.Context
+}
+
+// ModifyService fetches the current spec of the service, changes it with
+// opts.Modify and sends it back to the daemon along with the current version
+// of the service, so concurrent updates aren't silently overwritten.
+func (c *Client) ModifyService(id string, opts ModifyServiceOptions) error {
+	for retries := 0; ; retries++ {
+		service, err := c.inspectService(id, opts.Context)
+		if err != nil {
+			return err
+		}
+		spec := service.Spec
+		if opts.Modify != nil {
+			if err = opts.Modify(&spec); err != nil {
+				return err
+			}
+		}
+		err = c.UpdateService(id, UpdateServiceOptions{
+			Auth:        opts.Auth,
+			ServiceSpec: spec,
+			Version:     service.Version.Index,
+			Rollback:    opts.Rollback,
+			Context:     opts.Context,
+		})
+		if isOutOfSequence(err) && retries < opts.MaxRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// isOutOfSequence returns whether err is the error the daemon reports, with a
+// 500 status, when an update is sent with a stale version of the object.
+func isOutOfSequence(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Status == http.StatusInternalServerError && strings.Contains(e.Message, "update out of sequence")
+}
+
+// UpdateServiceImage changes the image of the service, keeping the rest of
+// its spec.
+func (c *Client) UpdateServiceImage(id, image string) error {
+	return c.ModifyService(id, ModifyServiceOptions{
+		Modify: func(spec *swarm.ServiceSpec) error {
+			if spec.TaskTemplate.ContainerSpec == nil {
+				spec.TaskTemplate.ContainerSpec = new(swarm.ContainerSpec)
+			}
+			spec.TaskTemplate.ContainerSpec.Image = image
+			return nil
+		},
+		MaxRetries: defaultServiceUpdateRetries,
+	})
+}
+
+// ScaleService changes the number of replicas of the service. It returns
+// ErrServiceNotReplicated if the service is not in replicated mode.
+func (c *Client) ScaleService(id string, replicas uint64) error {
+	return c.ModifyService(id, ModifyServiceOptions{
+		Modify: func(spec *swarm.ServiceSpec) error {
+			if spec.Mode.Replicated == nil {
+				return ErrServiceNotReplicated
+			}
+			spec.Mode.Replicated.Replicas = &replicas
+			return nil
+		},
+		MaxRetries: defaultServiceUpdateRetries,
+	})
+}
+
+// RollbackService rolls the service back to its previous spec.
+func (c *Client) RollbackService(id string) error {
+	return c.ModifyService(id, ModifyServiceOptions{
+		Rollback:   "previous",
+		MaxRetries: defaultServiceUpdateRetries,
+	})
+}
+
+// ForceRedeployService makes the swarm replace all the tasks of the service
+// by incrementing the ForceUpdate counter of its task template, leaving the
+// rest of the spec unchanged. The new tasks run the same image as before, as
+// the daemon pins the image of the spec to a digest, so use
+// UpdateServiceImage to deploy a new version of the image.
+func (c *Client) ForceRedeployService(serviceID string, ctx context.Context) error {
+	return c.ModifyService(serviceID, ModifyServiceOptions{
+		Modify: func(spec *swarm.ServiceSpec) error {
+			spec.TaskTemplate.ForceUpdate++
+			return nil
+		},
+		MaxRetries: defaultServiceUpdateRetries,
+		Context:    ctx,
+	})
+}
+
 // InspectService returns information about a service by its ID.
 //
 // See https://goo.gl/dHmr75 for more details.
 func (c *Client) InspectService(id string) (*swarm.Service, error) {
+	return c.inspectService(id, nil)
+}
+
+func (c *Client) inspectService(id string, ctx context.Context) (*swarm.Service, error) {
 	path := "/services/" + id
-	resp, err := c.do("GET", path, doOptions{})
+	resp, err := c.do("GET", path, doOptions{context: ctx})
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, &NoSuchService{ID: id}
@@ -211,3 +345,227 @@ func (c *Client) GetServiceLogs(opts LogsServiceOptions) error {
 		context:           opts.Context,
 	})
 }
+
+// ServiceLogsOptions specify parameters to the ServiceLogs function.
+type ServiceLogsOptions struct {
+	Service string
+
+	// Follow keeps streaming the logs of the service, reconnecting when the
+	// stream is interrupted, until the context is canceled.
+	Follow bool
+
+	// Tail is the number of lines to return from the end of the logs of
+	// each task. Defaults to all.
+	Tail string
+
+	// Since, when set, only returns the logs written after the given time.
+	Since time.Time
+
+	// Stdout and Stderr select the streams to return. Both streams are
+	// returned when neither is set.
+	Stdout bool
+	Stderr bool
+
+	// RawTerminal must be set for services with a TTY, whose logs are not
+	// multiplexed and are all reported as stdout.
+	RawTerminal bool
+
+	Context context.Context
+}
+
+// ServiceLogEntry is a line of the logs of a service, as returned by
+// ServiceLogs, identifying the task that wrote it.
+type ServiceLogEntry struct {
+	LogEntry
+
+	ServiceID string
+	NodeID    string
+	TaskID    string
+
+	// Details are the log details attached to the line by the daemon,
+	// including the IDs above.
+	Details map[string]string
+}
+
+// ServiceLogs streams the logs of the given service, split by stream and
+// annotated with the timestamp and the task of each line, demultiplexing the
+// stdout and stderr streams.
+//
+// When opts.Follow is set, the logs are streamed until the context is
+// canceled: if the connection is interrupted, ServiceLogs reconnects and
+// resumes after the last line received, so the stream continues across task
+// restarts and daemon failures. The error channel receives at most one
+// value, the error that terminated the stream, and is closed along with the
+// entries channel when the stream ends.
+func (c *Client) ServiceLogs(opts ServiceLogsOptions) (<-chan ServiceLogEntry, <-chan error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	entriesC := make(chan ServiceLogEntry)
+	errC := make(chan error, 1)
+	go func() {
+		defer close(errC)
+		defer close(entriesC)
+		if err := c.serviceLogs(ctx, opts, entriesC); err != nil && ctx.Err() == nil {
+			errC <- err
+		}
+	}()
+	return entriesC, errC
+}
+
+func (c *Client) serviceLogs(ctx context.Context, opts ServiceLogsOptions, entriesC chan<- ServiceLogEntry) error {
+	if opts.Service == "" {
+		return &NoSuchService{ID: opts.Service}
+	}
+	params := make(url.Values)
+	params.Set("details", "1")
+	params.Set("timestamps", "1")
+	if opts.Follow {
+		params.Set("follow", "1")
+	}
+	if !opts.Stdout && !opts.Stderr {
+		opts.Stdout, opts.Stderr = true, true
+	}
+	if opts.Stdout {
+		params.Set("stdout", "1")
+	}
+	if opts.Stderr {
+		params.Set("stderr", "1")
+	}
+	if opts.Tail == "" {
+		opts.Tail = "all"
+	}
+	params.Set("tail", opts.Tail)
+	if !opts.Since.IsZero() {
+		params.Set("since", formatLogsTimestamp(opts.Since))
+	}
+	var lastSeen logsPosition
+	var retries int
+	for {
+		received, err := c.streamServiceLogs(ctx, opts, params, &lastSeen, entriesC)
+		if ctx.Err() != nil || !opts.Follow {
+			return err
+		}
+		if e, ok := err.(*NoSuchService); ok {
+			return e
+		}
+		if received {
+			retries = 0
+		}
+		if !lastSeen.timestamp.IsZero() {
+			params.Set("since", formatLogsTimestamp(lastSeen.timestamp))
+			params.Set("tail", "all")
+		}
+		if retries >= maxMonitorConnRetries {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		waitTime := time.Duration(retryInitialWaitTime*math.Pow(2, float64(retries))) * time.Millisecond
+		retries++
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(waitTime):
+		}
+	}
+}
+
+// logsPosition is the position of a log stream: the timestamp of the last
+// entry sent, and the number of entries sent with that timestamp.
+type logsPosition struct {
+	timestamp time.Time
+	count     int
+}
+
+// streamServiceLogs sends the log entries of a single connection to the logs
+// endpoint of the service to entriesC, skipping the entries sent before
+// lastSeen, which is updated with the last entry. It reports whether any
+// entry was received.
+func (c *Client) streamServiceLogs(ctx context.Context, opts ServiceLogsOptions, params url.Values, lastSeen *logsPosition, entriesC chan<- ServiceLogEntry) (bool, error) {
+	path := "/services/" + opts.Service + "/logs?" + params.Encode()
+	resp, err := c.do("GET", path, doOptions{context: ctx})
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
+			return false, &NoSuchService{ID: opts.Service}
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+	var received bool
+	resumedFrom := *lastSeen
+	err = demuxLogs(resp.Body, opts.RawTerminal, func(entries []LogEntry) error {
+		received = true
+		for _, entry := range entries {
+			if !resumedFrom.timestamp.IsZero() {
+				// the stream resumes at the last timestamp, which may
+				// have entries that weren't sent before the reconnection
+				if entry.Timestamp.Before(resumedFrom.timestamp) {
+					continue
+				}
+				if entry.Timestamp.Equal(resumedFrom.timestamp) && resumedFrom.count > 0 {
+					resumedFrom.count--
+					continue
+				}
+			}
+			if entry.Timestamp.After(lastSeen.timestamp) {
+				*lastSeen = logsPosition{timestamp: entry.Timestamp}
+			}
+			if entry.Timestamp.Equal(lastSeen.timestamp) {
+				lastSeen.count++
+			}
+			select {
+			case entriesC <- parseServiceLogEntry(entry):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	return received, err
+}
+
+// parseServiceLogEntry extracts the details prefixed to the message of the
+// entry, such as:
+//
+//	com.docker.swarm.node.id=n1,com.docker.swarm.service.id=s1,com.docker.swarm.task.id=t1 message
+func parseServiceLogEntry(entry LogEntry) ServiceLogEntry {
+	serviceEntry := ServiceLogEntry{LogEntry: entry, Details: make(map[string]string)}
+	parts := strings.SplitN(entry.Message, " ", 2)
+	if len(parts) != 2 {
+		return serviceEntry
+	}
+	details := make(map[string]string)
+	if parts[0] != "" {
+		for _, detail := range strings.Split(parts[0], ",") {
+			kv := strings.SplitN(detail, "=", 2)
+			if len(kv) != 2 {
+				// the message has no details
+				return serviceEntry
+			}
+			key, err := url.QueryUnescape(kv[0])
+			if err != nil {
+				return serviceEntry
+			}
+			value, err := url.QueryUnescape(kv[1])
+			if err != nil {
+				return serviceEntry
+			}
+			details[key] = value
+		}
+	}
+	serviceEntry.Message = parts[1]
+	serviceEntry.Details = details
+	serviceEntry.ServiceID = details["com.docker.swarm.service.id"]
+	serviceEntry.NodeID = details["com.docker.swarm.node.id"]
+	serviceEntry.TaskID = details["com.docker.swarm.task.id"]
+	return serviceEntry
+}
+
+// formatLogsTimestamp formats t in the format accepted by the since parameter
+// of the logs endpoints, with nanosecond precision.
+func formatLogsTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
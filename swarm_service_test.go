@@ -6,13 +6,18 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types/swarm"
 )
@@ -612,3 +617,344 @@ func TestGetServiceLogsNoContainer(t *testing.T) {
 		t.Errorf("AttachToContainer: wrong error. Want %#v. Got %#v.", expected, err)
 	}
 }
+
+// fakeServiceServer serves a single service, rejecting updates that don't
+// match its current version, as the daemon does.
+type fakeServiceServer struct {
+	mu        sync.Mutex
+	service   swarm.Service
+	updates   []*http.Request
+	conflicts int
+}
+
+func (s *fakeServiceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/services/"+s.service.ID):
+		json.NewEncoder(w).Encode(s.service)
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/services/"+s.service.ID+"/update"):
+		s.updates = append(s.updates, r)
+		if s.conflicts > 0 {
+			// simulates a concurrent update of the service
+			s.conflicts--
+			s.service.Version.Index++
+		}
+		if r.URL.Query().Get("version") != strconv.FormatUint(s.service.Version.Index, 10) {
+			http.Error(w, "rpc error: code = Unknown desc = update out of sequence", http.StatusInternalServerError)
+			return
+		}
+		var spec swarm.ServiceSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.service.PreviousSpec = &swarm.ServiceSpec{}
+		*s.service.PreviousSpec = s.service.Spec
+		s.service.Spec = spec
+		s.service.Version.Index++
+	default:
+		http.Error(w, "service not found", http.StatusNotFound)
+	}
+}
+
+func newFakeServiceServer(t *testing.T, replicas uint64) (*fakeServiceServer, *Client, func()) {
+	fake := &fakeServiceServer{}
+	fake.service.ID = "tsuru-web"
+	fake.service.Version.Index = 10
+	fake.service.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "tsuru/python:3.6", Env: []string{"PORT=8080"}}
+	fake.service.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	server := httptest.NewServer(fake)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return fake, client, server.Close
+}
+
+func TestScaleService(t *testing.T) {
+	t.Parallel()
+	fake, client, closeServer := newFakeServiceServer(t, 1)
+	defer closeServer()
+	if err := client.ScaleService("tsuru-web", 5); err != nil {
+		t.Fatal(err)
+	}
+	if got := *fake.service.Spec.Mode.Replicated.Replicas; got != 5 {
+		t.Errorf("ScaleService: wrong replicas. Want 5. Got %d.", got)
+	}
+	if image := fake.service.Spec.TaskTemplate.ContainerSpec.Image; image != "tsuru/python:3.6" {
+		t.Errorf("ScaleService: image should not change. Got %q.", image)
+	}
+	if len(fake.updates) != 1 {
+		t.Errorf("ScaleService: wrong number of updates. Want 1. Got %d.", len(fake.updates))
+	}
+}
+
+func TestScaleServiceNotReplicated(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ID":"tsuru-web","Spec":{"Mode":{"Global":{}}}}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if err := client.ScaleService("tsuru-web", 5); err != ErrServiceNotReplicated {
+		t.Errorf("ScaleService: wrong error. Want %#v. Got %#v.", ErrServiceNotReplicated, err)
+	}
+	if len(fakeRT.requests) != 1 {
+		t.Errorf("ScaleService: unexpected updates: %d.", len(fakeRT.requests)-1)
+	}
+}
+
+func TestUpdateServiceImageRetriesOnConflict(t *testing.T) {
+	t.Parallel()
+	fake, client, closeServer := newFakeServiceServer(t, 2)
+	defer closeServer()
+	fake.conflicts = 2
+	if err := client.UpdateServiceImage("tsuru-web", "tsuru/python:3.7"); err != nil {
+		t.Fatal(err)
+	}
+	spec := fake.service.Spec.TaskTemplate.ContainerSpec
+	if spec.Image != "tsuru/python:3.7" {
+		t.Errorf("UpdateServiceImage: wrong image. Want %q. Got %q.", "tsuru/python:3.7", spec.Image)
+	}
+	if !reflect.DeepEqual(spec.Env, []string{"PORT=8080"}) {
+		t.Errorf("UpdateServiceImage: env should not change. Got %#v.", spec.Env)
+	}
+	if len(fake.updates) != 3 {
+		t.Errorf("UpdateServiceImage: wrong number of updates. Want 3. Got %d.", len(fake.updates))
+	}
+}
+
+func TestForceRedeployService(t *testing.T) {
+	t.Parallel()
+	fake, client, closeServer := newFakeServiceServer(t, 2)
+	defer closeServer()
+	fake.service.Spec.TaskTemplate.ForceUpdate = 4
+	fake.conflicts = 1
+	if err := client.ForceRedeployService("tsuru-web", context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.service.Spec.TaskTemplate.ForceUpdate; got != 5 {
+		t.Errorf("ForceRedeployService: wrong ForceUpdate. Want 5. Got %d.", got)
+	}
+	if image := fake.service.Spec.TaskTemplate.ContainerSpec.Image; image != "tsuru/python:3.6" {
+		t.Errorf("ForceRedeployService: image should not change. Got %q.", image)
+	}
+	if len(fake.updates) != 2 {
+		t.Errorf("ForceRedeployService: wrong number of updates. Want 2. Got %d.", len(fake.updates))
+	}
+	err := client.ForceRedeployService("tsuru-db", context.Background())
+	if expected := (&NoSuchService{ID: "tsuru-db"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("ForceRedeployService: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestModifyServiceConflictNoRetries(t *testing.T) {
+	t.Parallel()
+	fake, client, closeServer := newFakeServiceServer(t, 2)
+	defer closeServer()
+	fake.conflicts = 1
+	err := client.ModifyService("tsuru-web", ModifyServiceOptions{
+		Modify: func(spec *swarm.ServiceSpec) error {
+			spec.Labels = map[string]string{"app": "web"}
+			return nil
+		},
+	})
+	if !isOutOfSequence(err) {
+		t.Errorf("ModifyService: wrong error. Want an out of sequence error. Got %#v.", err)
+	}
+}
+
+func TestIsOutOfSequence(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		err      error
+		expected bool
+	}{
+		{&Error{Status: http.StatusInternalServerError, Message: "rpc error: code = Unknown desc = update out of sequence"}, true},
+		{&Error{Status: http.StatusInternalServerError, Message: "something went wrong"}, false},
+		{&Error{Status: http.StatusConflict, Message: "update out of sequence"}, false},
+		{&NoSuchService{ID: "tsuru-web"}, false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := isOutOfSequence(test.err); got != test.expected {
+			t.Errorf("isOutOfSequence(%#v): wrong result. Want %v. Got %v.", test.err, test.expected, got)
+		}
+	}
+}
+
+func TestRollbackService(t *testing.T) {
+	t.Parallel()
+	fake, client, closeServer := newFakeServiceServer(t, 2)
+	defer closeServer()
+	if err := client.RollbackService("tsuru-web"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.updates) != 1 {
+		t.Fatalf("RollbackService: wrong number of updates. Want 1. Got %d.", len(fake.updates))
+	}
+	query := fake.updates[0].URL.Query()
+	if query.Get("rollback") != "previous" || query.Get("version") != "10" {
+		t.Errorf("RollbackService: wrong query string. Got %q.", fake.updates[0].URL.RawQuery)
+	}
+}
+
+func TestModifyServiceNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "service not found", status: http.StatusNotFound})
+	err := client.ScaleService("unknown", 2)
+	expected := &NoSuchService{ID: "unknown"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("ScaleService: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestServiceLogs(t *testing.T) {
+	t.Parallel()
+	logs := logFrame(1, "2019-01-10T13:20:01Z com.docker.swarm.node.id=n1,com.docker.swarm.service.id=s1,com.docker.swarm.task.id=t1 server started\n") +
+		logFrame(2, "2019-01-10T13:20:02Z com.docker.swarm.node.id=n2,com.docker.swarm.service.id=s1,com.docker.swarm.task.id=t2 low memory\n")
+	fakeRT := &FakeRoundTripper{message: logs, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	entriesC, errC := client.ServiceLogs(ServiceLogsOptions{Service: "s1", Tail: "10", Stderr: true})
+	var entries []ServiceLogEntry
+	for entry := range entriesC {
+		entries = append(entries, entry)
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	expected := []ServiceLogEntry{
+		{
+			LogEntry:  LogEntry{Stream: "stdout", Timestamp: time.Date(2019, 1, 10, 13, 20, 1, 0, time.UTC), Message: "server started"},
+			ServiceID: "s1",
+			NodeID:    "n1",
+			TaskID:    "t1",
+			Details:   map[string]string{"com.docker.swarm.node.id": "n1", "com.docker.swarm.service.id": "s1", "com.docker.swarm.task.id": "t1"},
+		},
+		{
+			LogEntry:  LogEntry{Stream: "stderr", Timestamp: time.Date(2019, 1, 10, 13, 20, 2, 0, time.UTC), Message: "low memory"},
+			ServiceID: "s1",
+			NodeID:    "n2",
+			TaskID:    "t2",
+			Details:   map[string]string{"com.docker.swarm.node.id": "n2", "com.docker.swarm.service.id": "s1", "com.docker.swarm.task.id": "t2"},
+		},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("ServiceLogs: wrong entries.\nWant %#v.\nGot  %#v.", expected, entries)
+	}
+	req := fakeRT.requests[0]
+	if req.URL.Path != "/services/s1/logs" {
+		t.Errorf("ServiceLogs: wrong path. Want %q. Got %q.", "/services/s1/logs", req.URL.Path)
+	}
+	expectedQs := map[string][]string{
+		"details":    {"1"},
+		"timestamps": {"1"},
+		"stderr":     {"1"},
+		"tail":       {"10"},
+	}
+	if got := map[string][]string(req.URL.Query()); !reflect.DeepEqual(got, expectedQs) {
+		t.Errorf("ServiceLogs: wrong query string. Want %#v. Got %#v.", expectedQs, got)
+	}
+}
+
+func TestServiceLogsFollowReconnect(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		n := len(queries)
+		mu.Unlock()
+		switch n {
+		case 1:
+			// the task is restarted after writing these lines
+			w.Write([]byte(logFrame(1, "2019-01-10T13:20:01Z com.docker.swarm.task.id=t1 starting\n")))
+			w.Write([]byte(logFrame(1, "2019-01-10T13:20:02.5Z com.docker.swarm.task.id=t1 crashed\n")))
+		case 2:
+			// another task wrote a line at the same time, which wasn't
+			// sent before the reconnection
+			w.Write([]byte(logFrame(1, "2019-01-10T13:20:02.5Z com.docker.swarm.task.id=t1 crashed\n")))
+			w.Write([]byte(logFrame(1, "2019-01-10T13:20:02.5Z com.docker.swarm.task.id=t3 stopping\n")))
+			w.Write([]byte(logFrame(1, "2019-01-10T13:20:05Z com.docker.swarm.task.id=t2 starting\n")))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	entriesC, errC := client.ServiceLogs(ServiceLogsOptions{Service: "web", Follow: true, Tail: "1", Context: ctx})
+	var lines []string
+	for entry := range entriesC {
+		lines = append(lines, entry.TaskID+": "+entry.Message)
+		if len(lines) == 4 {
+			cancel()
+		}
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"t1: starting", "t1: crashed", "t3: stopping", "t2: starting"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("ServiceLogs: wrong lines. Want %#v. Got %#v.", expected, lines)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(queries) != 2 {
+		t.Fatalf("ServiceLogs: wrong number of connections. Want 2. Got %d.", len(queries))
+	}
+	if since := queries[1].Get("since"); since != "1547126402.500000000" {
+		t.Errorf("ServiceLogs: wrong since on reconnection. Want %q. Got %q.", "1547126402.500000000", since)
+	}
+	if tail := queries[1].Get("tail"); tail != "all" {
+		t.Errorf("ServiceLogs: wrong tail on reconnection. Want %q. Got %q.", "all", tail)
+	}
+}
+
+func TestServiceLogsNoSuchService(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "service not found", status: http.StatusNotFound})
+	entriesC, errC := client.ServiceLogs(ServiceLogsOptions{Service: "web", Follow: true})
+	for range entriesC {
+	}
+	err := <-errC
+	expected := &NoSuchService{ID: "web"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("ServiceLogs: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestParseServiceLogEntry(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		message  string
+		expected ServiceLogEntry
+	}{
+		{
+			"com.docker.swarm.task.id=t1,env%3Dkey=a%2Cb hello world",
+			ServiceLogEntry{
+				LogEntry: LogEntry{Message: "hello world"},
+				TaskID:   "t1",
+				Details:  map[string]string{"com.docker.swarm.task.id": "t1", "env=key": "a,b"},
+			},
+		},
+		{
+			" no details",
+			ServiceLogEntry{LogEntry: LogEntry{Message: "no details"}, Details: map[string]string{}},
+		},
+		{
+			"plain message",
+			ServiceLogEntry{LogEntry: LogEntry{Message: "plain message"}, Details: map[string]string{}},
+		},
+	}
+	for _, test := range tests {
+		got := parseServiceLogEntry(LogEntry{Message: test.message})
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("parseServiceLogEntry(%q): wrong entry. Want %#v. Got %#v.", test.message, test.expected, got)
+		}
+	}
+}
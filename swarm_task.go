@@ -7,7 +7,10 @@ package docker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/docker/docker/api/types/swarm"
 )
@@ -25,6 +28,48 @@ func (err *NoSuchTask) Error() string {
 	return "No such task: " + err.ID
 }
 
+// Unwrap returns the underlying error, if any.
+func (err *NoSuchTask) Unwrap() error {
+	return err.Err
+}
+
+// Labels set by Swarm on the containers of its tasks.
+const (
+	swarmTaskIDLabel    = "com.docker.swarm.task.id"
+	swarmServiceIDLabel = "com.docker.swarm.service.id"
+	swarmNodeIDLabel    = "com.docker.swarm.node.id"
+)
+
+// ErrNotSwarmContainer is the error returned when a container was expected to
+// be a task of a Swarm service, but it isn't.
+var ErrNotSwarmContainer = errors.New("container is not a swarm task")
+
+// ErrNotSwarmTask is the error returned by PopulateEnvFromSecrets when the
+// container was not created by a Swarm service. It's an alias of
+// ErrNotSwarmContainer.
+var ErrNotSwarmTask = ErrNotSwarmContainer
+
+// serviceConvergedPollInterval is the interval between the checks of the
+// tasks of a service in WaitServiceConverged.
+const serviceConvergedPollInterval = 500 * time.Millisecond
+
+// ErrServiceConvergedTimeout is the error returned by WaitServiceConverged
+// when the tasks of the service don't reach their desired state in time.
+var ErrServiceConvergedTimeout = errors.New("timed out waiting for the service to converge")
+
+// TaskError is the error returned by WaitServiceConverged when a task of the
+// service fails or can't be scheduled.
+type TaskError struct {
+	ServiceID string
+	TaskID    string
+	State     swarm.TaskState
+	Err       string
+}
+
+func (err *TaskError) Error() string {
+	return fmt.Sprintf("task %s of service %s is %s: %s", err.TaskID, err.ServiceID, err.State, err.Err)
+}
+
 // ListTasksOptions specify parameters to the ListTasks function.
 //
 // See http://goo.gl/rByLzw for more details.
@@ -50,11 +95,140 @@ func (c *Client) ListTasks(opts ListTasksOptions) ([]swarm.Task, error) {
 	return tasks, nil
 }
 
+// TaskFilters is a builder of filters for ListTasks, created with
+// NewTaskFilters:
+//
+//	opts := docker.NewTaskFilters().Service("web").DesiredState(swarm.TaskStateRunning).Build()
+//	tasks, err := client.ListTasks(opts)
+type TaskFilters struct {
+	filters filterSet
+}
+
+// NewTaskFilters returns an empty set of task filters.
+func NewTaskFilters() *TaskFilters {
+	return &TaskFilters{filters: make(filterSet)}
+}
+
+func (f *TaskFilters) add(key, value string) *TaskFilters {
+	f.filters.add(key, value)
+	return f
+}
+
+// DesiredState filters tasks by desired state, such as running or shutdown.
+func (f *TaskFilters) DesiredState(state swarm.TaskState) *TaskFilters {
+	return f.add("desired-state", string(state))
+}
+
+// ID filters tasks by ID.
+func (f *TaskFilters) ID(id string) *TaskFilters {
+	return f.add("id", id)
+}
+
+// Label filters tasks by label, in the format key or key=value.
+func (f *TaskFilters) Label(label string) *TaskFilters {
+	return f.add("label", label)
+}
+
+// Name filters tasks by name.
+func (f *TaskFilters) Name(name string) *TaskFilters {
+	return f.add("name", name)
+}
+
+// Node filters tasks by node name or ID.
+func (f *TaskFilters) Node(node string) *TaskFilters {
+	return f.add("node", node)
+}
+
+// Service filters tasks by service name or ID.
+func (f *TaskFilters) Service(service string) *TaskFilters {
+	return f.add("service", service)
+}
+
+// Build returns a ListTasksOptions with the filters.
+func (f *TaskFilters) Build() ListTasksOptions {
+	return ListTasksOptions{Filters: f.filters.copy()}
+}
+
+// WaitServiceConverged waits until the tasks of the given service reach
+// their desired state: for replicated services, until the number of running
+// tasks matches the replicas of the service, and for global services, until
+// all the tasks that should be running are running.
+//
+// It fails fast with a *TaskError when a task fails or can't be scheduled,
+// for example when there's no suitable node for it, and returns
+// ErrServiceConvergedTimeout when the service doesn't converge in time.
+func (c *Client) WaitServiceConverged(serviceID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	for {
+		converged, err := c.serviceConverged(ctx, serviceID, start)
+		if ctx.Err() != nil {
+			return ErrServiceConvergedTimeout
+		}
+		if err != nil || converged {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ErrServiceConvergedTimeout
+		case <-time.After(serviceConvergedPollInterval):
+		}
+	}
+}
+
+// serviceConverged reports whether the tasks of the service reached their
+// desired state, returning an error for the tasks that failed since the
+// given time.
+func (c *Client) serviceConverged(ctx context.Context, serviceID string, since time.Time) (bool, error) {
+	service, err := c.inspectService(serviceID, ctx)
+	if err != nil {
+		return false, err
+	}
+	opts := NewTaskFilters().Service(service.ID).Build()
+	opts.Context = ctx
+	tasks, err := c.ListTasks(opts)
+	if err != nil {
+		return false, err
+	}
+	var desired, running int
+	for _, task := range tasks {
+		state := task.Status.State
+		failed := state == swarm.TaskStateFailed || state == swarm.TaskStateRejected
+		if failed && task.Status.Timestamp.After(since) ||
+			task.DesiredState == swarm.TaskStateRunning && state == swarm.TaskStatePending && task.Status.Err != "" {
+			return false, &TaskError{ServiceID: service.ID, TaskID: task.ID, State: state, Err: task.Status.Err}
+		}
+		if task.DesiredState != swarm.TaskStateRunning {
+			continue
+		}
+		desired++
+		if state == swarm.TaskStateRunning {
+			running++
+		}
+	}
+	if service.UpdateStatus != nil && service.UpdateStatus.State == swarm.UpdateStateUpdating {
+		return false, nil
+	}
+	if replicated := service.Spec.Mode.Replicated; replicated != nil {
+		var replicas uint64
+		if replicated.Replicas != nil {
+			replicas = *replicated.Replicas
+		}
+		return uint64(running) == replicas && running == desired, nil
+	}
+	return desired > 0 && running == desired, nil
+}
+
 // InspectTask returns information about a task by its ID.
 //
 // See http://goo.gl/kyziuq for more details.
 func (c *Client) InspectTask(id string) (*swarm.Task, error) {
-	resp, err := c.do("GET", "/tasks/"+id, doOptions{})
+	return c.inspectTask(id, nil)
+}
+
+func (c *Client) inspectTask(id string, ctx context.Context) (*swarm.Task, error) {
+	resp, err := c.do("GET", "/tasks/"+id, doOptions{context: ctx})
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, &NoSuchTask{ID: id}
@@ -68,3 +242,39 @@ func (c *Client) InspectTask(id string) (*swarm.Task, error) {
 	}
 	return &task, nil
 }
+
+// GetContainerTaskID returns the ID of the Swarm task that created the given
+// container, or ErrNotSwarmContainer if the container isn't a task of a Swarm
+// service.
+func (c *Client) GetContainerTaskID(containerID string, ctx context.Context) (string, error) {
+	return c.containerSwarmLabel(ctx, containerID, swarmTaskIDLabel)
+}
+
+// GetContainerServiceID returns the ID of the Swarm service of the given
+// container, or ErrNotSwarmContainer if the container isn't a task of a Swarm
+// service.
+func (c *Client) GetContainerServiceID(containerID string, ctx context.Context) (string, error) {
+	return c.containerSwarmLabel(ctx, containerID, swarmServiceIDLabel)
+}
+
+// GetContainerNodeID returns the ID of the Swarm node the given container was
+// scheduled on, or ErrNotSwarmContainer if the container isn't a task of a
+// Swarm service.
+func (c *Client) GetContainerNodeID(containerID string, ctx context.Context) (string, error) {
+	return c.containerSwarmLabel(ctx, containerID, swarmNodeIDLabel)
+}
+
+func (c *Client) containerSwarmLabel(ctx context.Context, containerID, label string) (string, error) {
+	container, err := c.InspectContainerWithContext(containerID, ctx)
+	if err != nil {
+		return "", err
+	}
+	var value string
+	if container.Config != nil {
+		value = container.Config.Labels[label]
+	}
+	if value == "" {
+		return "", ErrNotSwarmContainer
+	}
+	return value, nil
+}
@@ -5,11 +5,16 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types/swarm"
 )
@@ -374,3 +379,189 @@ func TestInspectTaskNotFound(t *testing.T) {
 		t.Errorf("InspectTask: Wrong error returned. Want %#v. Got %#v.", expected, err)
 	}
 }
+
+func TestTaskFilters(t *testing.T) {
+	t.Parallel()
+	opts := NewTaskFilters().
+		Service("web").
+		Service("worker").
+		DesiredState(swarm.TaskStateRunning).
+		Node("node-1").
+		Label("app=web").
+		Build()
+	expected := map[string][]string{
+		"service":       {"web", "worker"},
+		"desired-state": {"running"},
+		"node":          {"node-1"},
+		"label":         {"app=web"},
+	}
+	if !reflect.DeepEqual(opts.Filters, expected) {
+		t.Errorf("TaskFilters: wrong filters. Want %#v. Got %#v.", expected, opts.Filters)
+	}
+}
+
+// fakeTasksServer serves a service and the tasks returned by the tasks
+// function, called with the number of the request to the tasks endpoint.
+func fakeTasksServer(service swarm.Service, tasks func(n int) []swarm.Task) *httptest.Server {
+	var mu sync.Mutex
+	var n int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/services/"+service.ID):
+			json.NewEncoder(w).Encode(service)
+		case strings.HasSuffix(r.URL.Path, "/tasks"):
+			if filters := r.URL.Query().Get("filters"); filters != `{"service":["`+service.ID+`"]}` {
+				http.Error(w, "wrong filters: "+filters, http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			n++
+			current := n
+			mu.Unlock()
+			json.NewEncoder(w).Encode(tasks(current))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+}
+
+func newReplicatedService(id string, replicas uint64) swarm.Service {
+	var service swarm.Service
+	service.ID = id
+	service.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	return service
+}
+
+func newTask(id string, desired, state swarm.TaskState, err string) swarm.Task {
+	task := swarm.Task{ID: id, DesiredState: desired}
+	task.Status.State = state
+	task.Status.Err = err
+	task.Status.Timestamp = time.Now()
+	return task
+}
+
+func TestWaitServiceConverged(t *testing.T) {
+	t.Parallel()
+	server := fakeTasksServer(newReplicatedService("web", 2), func(n int) []swarm.Task {
+		tasks := []swarm.Task{
+			newTask("t1", swarm.TaskStateRunning, swarm.TaskStateRunning, ""),
+			newTask("t0", swarm.TaskStateShutdown, swarm.TaskStateRunning, ""),
+		}
+		if n == 1 {
+			return append(tasks, newTask("t2", swarm.TaskStateRunning, swarm.TaskStateStarting, ""))
+		}
+		return append(tasks, newTask("t2", swarm.TaskStateRunning, swarm.TaskStateRunning, ""))
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.WaitServiceConverged("web", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitServiceConvergedGlobal(t *testing.T) {
+	t.Parallel()
+	var service swarm.Service
+	service.ID = "agent"
+	service.Spec.Mode.Global = &swarm.GlobalService{}
+	server := fakeTasksServer(service, func(n int) []swarm.Task {
+		return []swarm.Task{
+			newTask("t1", swarm.TaskStateRunning, swarm.TaskStateRunning, ""),
+			newTask("t2", swarm.TaskStateRunning, swarm.TaskStateRunning, ""),
+		}
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.WaitServiceConverged("agent", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitServiceConvergedTaskError(t *testing.T) {
+	t.Parallel()
+	server := fakeTasksServer(newReplicatedService("web", 1), func(n int) []swarm.Task {
+		return []swarm.Task{
+			newTask("t1", swarm.TaskStateRunning, swarm.TaskStatePending, "no suitable node (insufficient resources on 1 node)"),
+		}
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.WaitServiceConverged("web", 10*time.Second)
+	expected := &TaskError{
+		ServiceID: "web",
+		TaskID:    "t1",
+		State:     swarm.TaskStatePending,
+		Err:       "no suitable node (insufficient resources on 1 node)",
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("WaitServiceConverged: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
+
+func TestWaitServiceConvergedTimeout(t *testing.T) {
+	t.Parallel()
+	server := fakeTasksServer(newReplicatedService("web", 3), func(n int) []swarm.Task {
+		return []swarm.Task{newTask("t1", swarm.TaskStateRunning, swarm.TaskStateRunning, "")}
+	})
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.WaitServiceConverged("web", 100*time.Millisecond); err != ErrServiceConvergedTimeout {
+		t.Errorf("WaitServiceConverged: wrong error. Want %#v. Got %#v.", ErrServiceConvergedTimeout, err)
+	}
+}
+
+func TestGetContainerSwarmIDs(t *testing.T) {
+	t.Parallel()
+	body := `{"Id":"c1","Config":{"Labels":{"com.docker.swarm.task.id":"task1","com.docker.swarm.service.id":"service1","com.docker.swarm.node.id":"node1"}}}`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	var tests = []struct {
+		name     string
+		get      func(string, context.Context) (string, error)
+		expected string
+	}{
+		{"GetContainerTaskID", client.GetContainerTaskID, "task1"},
+		{"GetContainerServiceID", client.GetContainerServiceID, "service1"},
+		{"GetContainerNodeID", client.GetContainerNodeID, "node1"},
+	}
+	for _, test := range tests {
+		id, err := test.get("c1", context.Background())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if id != test.expected {
+			t.Errorf("%s: wrong ID. Want %q. Got %q.", test.name, test.expected, id)
+		}
+	}
+}
+
+func TestGetContainerTaskIDNotSwarmContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: `{"Id":"c1","Config":{"Labels":{"app":"web"}}}`, status: http.StatusOK})
+	_, err := client.GetContainerTaskID("c1", context.Background())
+	if err != ErrNotSwarmContainer {
+		t.Errorf("GetContainerTaskID: wrong error. Want %#v. Got %#v.", ErrNotSwarmContainer, err)
+	}
+}
+
+func TestGetContainerTaskIDNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.GetContainerTaskID("c1", context.Background())
+	expected := &NoSuchContainer{ID: "c1"}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("GetContainerTaskID: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+}
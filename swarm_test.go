@@ -209,3 +209,12 @@ func TestInspectSwarmNotInSwarm(t *testing.T) {
 		t.Errorf("InspectSwarm: Wrong error type. Want %#v. Got %#v", ErrNodeNotInSwarm, err)
 	}
 }
+
+func TestRotateSwarmTokensNotInSwarm(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "", status: http.StatusServiceUnavailable})
+	_, err := client.RotateSwarmTokens(RotateSwarmTokensOptions{Worker: true})
+	if err != ErrNodeNotInSwarm {
+		t.Errorf("RotateSwarmTokens: Wrong error type. Want %#v. Got %#v", ErrNodeNotInSwarm, err)
+	}
+}
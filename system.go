@@ -70,3 +70,24 @@ func (c *Client) DiskUsage(opts DiskUsageOptions) (*DiskUsage, error) {
 	}
 	return du, nil
 }
+
+// ContainersDiskUsage returns the size, in bytes, of the writable layer of
+// every container (running or not), keyed by container ID.
+//
+// The sizes are fetched with a single list call, making this considerably
+// cheaper than calling GetContainerWritableLayerSize for each container.
+func (c *Client) ContainersDiskUsage(opts DiskUsageOptions) (map[string]int64, error) {
+	containers, err := c.ListContainers(ListContainersOptions{
+		All:     true,
+		Size:    true,
+		Context: opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(containers))
+	for _, container := range containers {
+		sizes[container.ID] = container.SizeRw
+	}
+	return sizes, nil
+}
@@ -106,3 +106,25 @@ func TestDiskUsage(t *testing.T) {
 		t.Errorf("DiskUsage: Wrong return value. Want %#v. Got %#v.", expected, du)
 	}
 }
+
+func TestContainersDiskUsage(t *testing.T) {
+	t.Parallel()
+	jsonContainers := `[
+     {"Id": "8dfafdbc3a40", "Image": "base:latest", "SizeRw": 12288, "SizeRootFs": 1162769},
+     {"Id": "9cd87474be90", "Image": "base:latest", "SizeRootFs": 1162769}
+]`
+	fakeRT := &FakeRoundTripper{message: jsonContainers, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	sizes, err := client.ContainersDiskUsage(DiskUsageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int64{"8dfafdbc3a40": 12288, "9cd87474be90": 0}
+	if !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("ContainersDiskUsage: Wrong return value. Want %#v. Got %#v.", expected, sizes)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if query.Get("all") != "1" || query.Get("size") != "1" {
+		t.Errorf("ContainersDiskUsage: Wrong query string. Got %q.", fakeRT.requests[0].URL.RawQuery)
+	}
+}
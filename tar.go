@@ -5,6 +5,9 @@
 package docker
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -120,3 +123,135 @@ func parseDockerignore(root string) ([]string, error) {
 
 	return excludes, nil
 }
+
+// addCacheMounts returns a copy of the build context in the given tar stream,
+// with the mounts added to the RUN instructions of the Dockerfile. The input
+// is closed along with the copy only when closeIn is set.
+func addCacheMounts(in io.Reader, closeIn bool, dockerfilePath string, mounts []CacheMount) io.Reader {
+	if dockerfilePath == "" {
+		dockerfilePath = "Dockerfile"
+	}
+	return rewriteTarFile(in, closeIn, dockerfilePath, false, func(dockerfile []byte) ([]byte, error) {
+		return addCacheMountsToDockerfile(dockerfile, mounts)
+	})
+}
+
+// addDockerfile returns a copy of the build context in the given tar stream,
+// which may be nil for an empty context, with the Dockerfile at the given
+// path replaced by, or created with, the given content. The input is closed
+// along with the copy only when closeIn is set.
+func addDockerfile(in io.Reader, closeIn bool, dockerfilePath string, content []byte) io.Reader {
+	return rewriteTarFile(in, closeIn, dockerfilePath, true, func([]byte) ([]byte, error) {
+		return content, nil
+	})
+}
+
+// rewriteTarFile returns a copy of the given tar stream with the content of
+// the file at the given path changed by the rewrite function. When the file
+// is missing and create is true, it's added with the content returned by the
+// function for an empty file. Errors of the function abort the stream.
+//
+// closeIn must only be set for inputs created by the library, like the pipes
+// returned by rewriteTarFile, never for the streams given by the caller.
+func rewriteTarFile(in io.Reader, closeIn bool, filePath string, create bool, rewrite func([]byte) ([]byte, error)) io.Reader {
+	filePath = path.Clean(filepath.ToSlash(filePath))
+	r, w := io.Pipe()
+	go func() {
+		// when the request is aborted, the pipe is closed and the input,
+		// which may be another pipe, must be released too
+		if closer, ok := in.(io.Closer); ok && closeIn {
+			defer closer.Close()
+		}
+		tw := tar.NewWriter(w)
+		var found bool
+		if in != nil {
+			tr := tar.NewReader(in)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					w.CloseWithError(err)
+					return
+				}
+				if path.Clean(hdr.Name) != filePath {
+					if err = tw.WriteHeader(hdr); err == nil {
+						_, err = io.Copy(tw, tr)
+					}
+					if err != nil {
+						w.CloseWithError(err)
+						return
+					}
+					continue
+				}
+				found = true
+				content, err := ioutil.ReadAll(tr)
+				if err != nil {
+					w.CloseWithError(err)
+					return
+				}
+				if content, err = rewrite(content); err == nil {
+					hdr.Size = int64(len(content))
+					err = tw.WriteHeader(hdr)
+				}
+				if err == nil {
+					_, err = tw.Write(content)
+				}
+				if err != nil {
+					w.CloseWithError(err)
+					return
+				}
+			}
+		}
+		if !found && create {
+			content, err := rewrite(nil)
+			if err == nil {
+				hdr := &tar.Header{Name: filePath, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+				err = tw.WriteHeader(hdr)
+			}
+			if err == nil {
+				_, err = tw.Write(content)
+			}
+			if err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+		w.CloseWithError(tw.Close())
+	}()
+	return r
+}
+
+// addCacheMountsToDockerfile adds the mounts to every RUN instruction of the
+// Dockerfile, adding the syntax directive required by the --mount flag unless
+// the Dockerfile already has one.
+func addCacheMountsToDockerfile(dockerfile []byte, mounts []CacheMount) ([]byte, error) {
+	var flags string
+	for _, mount := range mounts {
+		flags += " --mount=" + mount.String()
+	}
+	var out bytes.Buffer
+	if !bytes.HasPrefix(bytes.ToLower(bytes.TrimSpace(dockerfile)), []byte("# syntax=")) {
+		out.WriteString("# syntax=docker/dockerfile:experimental\n")
+	}
+	var continued bool
+	scanner := bufio.NewScanner(bytes.NewReader(dockerfile))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if !continued && len(trimmed) > 3 && strings.EqualFold(trimmed[:3], "run") && (trimmed[3] == ' ' || trimmed[3] == '\t') {
+			indent := line[:strings.Index(line, trimmed)]
+			line = indent + trimmed[:3] + flags + trimmed[3:]
+		}
+		if !strings.HasPrefix(trimmed, "#") {
+			continued = strings.HasSuffix(trimmed, "\\")
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -203,6 +203,7 @@ func (s *DockerServer) buildMuxer() {
 	s.mux.Path("/networks").Methods("GET").HandlerFunc(s.handlerWrapper(s.listNetworks))
 	s.mux.Path("/networks/{id:.*}").Methods("GET").HandlerFunc(s.handlerWrapper(s.networkInfo))
 	s.mux.Path("/networks/{id:.*}").Methods("DELETE").HandlerFunc(s.handlerWrapper(s.removeNetwork))
+	s.mux.Path("/networks/prune").Methods("POST").HandlerFunc(s.handlerWrapper(s.pruneNetworks))
 	s.mux.Path("/networks/create").Methods("POST").HandlerFunc(s.handlerWrapper(s.createNetwork))
 	s.mux.Path("/networks/{id:.*}/connect").Methods("POST").HandlerFunc(s.handlerWrapper(s.networksConnect))
 	s.mux.Path("/volumes").Methods("GET").HandlerFunc(s.handlerWrapper(s.listVolumes))
@@ -215,6 +216,7 @@ func (s *DockerServer) buildMuxer() {
 	s.mux.Path("/swarm").Methods("GET").HandlerFunc(s.handlerWrapper(s.swarmInspect))
 	s.mux.Path("/swarm/join").Methods("POST").HandlerFunc(s.handlerWrapper(s.swarmJoin))
 	s.mux.Path("/swarm/leave").Methods("POST").HandlerFunc(s.handlerWrapper(s.swarmLeave))
+	s.mux.Path("/swarm/update").Methods("POST").HandlerFunc(s.handlerWrapper(s.swarmUpdate))
 	s.mux.Path("/nodes/{id:.+}/update").Methods("POST").HandlerFunc(s.handlerWrapper(s.nodeUpdate))
 	s.mux.Path("/nodes/{id:.+}").Methods("GET").HandlerFunc(s.handlerWrapper(s.nodeInspect))
 	s.mux.Path("/nodes/{id:.+}").Methods("DELETE").HandlerFunc(s.handlerWrapper(s.nodeDelete))
@@ -333,6 +335,17 @@ func (s *DockerServer) URL() string {
 	return "http://" + s.listener.Addr().String() + "/"
 }
 
+// NewClient returns a client connected to the server, so tests can run
+// against the in-memory state of the server instead of a Docker daemon. For
+// servers created with NewTLSServer, use docker.NewTLSClient with the URL of
+// the server instead.
+func (s *DockerServer) NewClient() (*docker.Client, error) {
+	if s.listener == nil {
+		return nil, errors.New("server is not listening")
+	}
+	return docker.NewClient(s.URL())
+}
+
 // ServeHTTP handles HTTP requests sent to the server.
 func (s *DockerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.handlerMutex.RLock()
@@ -576,8 +589,13 @@ func (s *DockerServer) renameContainer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	name := r.URL.Query().Get("name")
+	if otherID, ok := s.contNameToID[name]; ok && otherID != container.ID {
+		http.Error(w, "there's already a container with this name", http.StatusConflict)
+		return
+	}
 	delete(s.contNameToID, container.Name)
-	container.Name = r.URL.Query().Get("name")
+	container.Name = name
 	s.contNameToID[container.Name] = container.ID
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -751,6 +769,7 @@ func (s *DockerServer) stopContainer(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 	container.State.Running = false
+	container.State.FinishedAt = time.Now()
 	s.notify(container)
 }
 
@@ -1013,6 +1032,10 @@ func (s *DockerServer) buildImage(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DockerServer) pullImage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("fromSrc") != "" {
+		s.importImage(w, r)
+		return
+	}
 	fromImageName := r.URL.Query().Get("fromImage")
 	tag := r.URL.Query().Get("tag")
 	if fromImageName != "" {
@@ -1038,6 +1061,27 @@ func (s *DockerServer) pullImage(w http.ResponseWriter, r *http.Request) {
 	s.iMut.Unlock()
 }
 
+func (s *DockerServer) importImage(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+	tag := r.URL.Query().Get("tag")
+	if tag == "" {
+		tag = "latest"
+	}
+	io.Copy(ioutil.Discard, r.Body)
+	image := docker.Image{
+		ID:     s.generateID(),
+		Config: &docker.Config{},
+	}
+	s.iMut.Lock()
+	s.images[image.ID] = image
+	if repo != "" {
+		s.imgIDs[repo+":"+tag] = image.ID
+	}
+	s.iMut.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": image.ID})
+}
+
 func (s *DockerServer) pushImage(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	tag := r.URL.Query().Get("tag")
@@ -1149,6 +1193,16 @@ func (s *DockerServer) listEvents(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DockerServer) pingDocker(w http.ResponseWriter, r *http.Request) {
+	s.swarmMut.RLock()
+	swarmStatus := "inactive"
+	if s.swarm != nil {
+		swarmStatus = "active/manager"
+	}
+	s.swarmMut.RUnlock()
+	w.Header().Set("API-Version", "1.22")
+	w.Header().Set("OSType", "linux")
+	w.Header().Set("Docker-Experimental", "false")
+	w.Header().Set("Swarm", swarmStatus)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1292,9 +1346,17 @@ func (s *DockerServer) findNetwork(idOrName string) (*docker.Network, int, error
 }
 
 func (s *DockerServer) listNetworks(w http.ResponseWriter, r *http.Request) {
+	filters := parseFilters(r.FormValue("filters"))
+	dangling := len(filters["dangling"]) > 0 && filters["dangling"][0] == "true"
 	s.netMut.RLock()
 	result := make([]docker.Network, 0, len(s.networks))
 	for _, network := range s.networks {
+		if dangling && len(network.Containers) > 0 {
+			continue
+		}
+		if !matchesNameFilter(network.Name, filters["name"]) {
+			continue
+		}
 		result = append(result, *network)
 	}
 	s.netMut.RUnlock()
@@ -1303,6 +1365,38 @@ func (s *DockerServer) listNetworks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// parseFilters parses the filters of a request, given either as lists of
+// values or, as NetworkFilterOpts does, as sets of values.
+func parseFilters(raw string) map[string][]string {
+	filters := make(map[string][]string)
+	if json.Unmarshal([]byte(raw), &filters) == nil {
+		return filters
+	}
+	var sets map[string]map[string]bool
+	json.Unmarshal([]byte(raw), &sets)
+	filters = make(map[string][]string, len(sets))
+	for key, values := range sets {
+		for value := range values {
+			filters[key] = append(filters[key], value)
+		}
+	}
+	return filters
+}
+
+// matchesNameFilter reports whether the name contains any of the names in
+// the filter, as the daemon does when filtering networks by name.
+func matchesNameFilter(name string, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *DockerServer) networkInfo(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	network, _, err := s.findNetwork(id)
@@ -1346,6 +1440,12 @@ func (s *DockerServer) createNetwork(w http.ResponseWriter, r *http.Request) {
 		ID:         generatedID,
 		Driver:     config.Driver,
 		Containers: map[string]docker.Endpoint{},
+		Internal:   config.Internal,
+		EnableIPv6: config.EnableIPv6,
+		Labels:     config.Labels,
+	}
+	if config.IPAM != nil {
+		network.IPAM = *config.IPAM
 	}
 	s.netMut.Lock()
 	s.networks = append(s.networks, &network)
@@ -1369,6 +1469,24 @@ func (s *DockerServer) removeNetwork(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (s *DockerServer) pruneNetworks(w http.ResponseWriter, r *http.Request) {
+	s.netMut.Lock()
+	result := docker.PruneNetworksResults{NetworksDeleted: []string{}}
+	networks := make([]*docker.Network, 0, len(s.networks))
+	for _, network := range s.networks {
+		if len(network.Containers) > 0 {
+			networks = append(networks, network)
+			continue
+		}
+		result.NetworksDeleted = append(result.NetworksDeleted, network.Name)
+	}
+	s.networks = networks
+	s.netMut.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
 func (s *DockerServer) networksConnect(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	var config *docker.NetworkConnectionOptions
@@ -1391,13 +1509,82 @@ func (s *DockerServer) networksConnect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	endpoint, err := newContainerNetwork(network, config.EndpointConfig)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	endpoint.EndpointID = s.generateID()
+
 	s.netMut.Lock()
-	s.networks[index].Containers[config.Container] = docker.Endpoint{}
+	s.networks[index].Containers[config.Container] = docker.Endpoint{
+		ID:          endpoint.EndpointID,
+		IPv4Address: endpoint.IPAddress,
+		IPv6Address: endpoint.GlobalIPv6Address,
+	}
 	s.netMut.Unlock()
 
+	s.cMut.Lock()
+	if container.NetworkSettings == nil {
+		container.NetworkSettings = &docker.NetworkSettings{}
+	}
+	if container.NetworkSettings.Networks == nil {
+		container.NetworkSettings.Networks = make(map[string]docker.ContainerNetwork)
+	}
+	container.NetworkSettings.Networks[network.Name] = endpoint
+	s.cMut.Unlock()
+
 	w.WriteHeader(http.StatusOK)
 }
 
+// newContainerNetwork returns the endpoint of a container connected to the
+// network, with the static addresses in the endpoint config, which must be
+// in the subnets of the network.
+func newContainerNetwork(network *docker.Network, config *docker.EndpointConfig) (docker.ContainerNetwork, error) {
+	endpoint := docker.ContainerNetwork{NetworkID: network.ID}
+	if config == nil {
+		return endpoint, nil
+	}
+	endpoint.Aliases = config.Aliases
+	if config.IPAMConfig == nil {
+		return endpoint, nil
+	}
+	endpoint.IPAMConfig = config.IPAMConfig
+	if addr := config.IPAMConfig.IPv4Address; addr != "" {
+		prefixLen, gateway, err := findSubnet(network, addr)
+		if err != nil {
+			return endpoint, err
+		}
+		endpoint.IPAddress, endpoint.IPPrefixLen, endpoint.Gateway = addr, prefixLen, gateway
+	}
+	if addr := config.IPAMConfig.IPv6Address; addr != "" {
+		if !network.EnableIPv6 {
+			return endpoint, fmt.Errorf("IPv6 is not enabled in network %s", network.Name)
+		}
+		prefixLen, gateway, err := findSubnet(network, addr)
+		if err != nil {
+			return endpoint, err
+		}
+		endpoint.GlobalIPv6Address, endpoint.GlobalIPv6PrefixLen, endpoint.IPv6Gateway = addr, prefixLen, gateway
+	}
+	return endpoint, nil
+}
+
+func findSubnet(network *docker.Network, addr string) (int, string, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return 0, "", fmt.Errorf("invalid address: %s", addr)
+	}
+	for _, config := range network.IPAM.Config {
+		_, subnet, err := net.ParseCIDR(config.Subnet)
+		if err == nil && subnet.Contains(ip) {
+			prefixLen, _ := subnet.Mask.Size()
+			return prefixLen, config.Gateway, nil
+		}
+	}
+	return 0, "", fmt.Errorf("no configured subnet contains the address %s", addr)
+}
+
 func (s *DockerServer) listVolumes(w http.ResponseWriter, r *http.Request) {
 	s.volMut.RLock()
 	result := make([]docker.Volume, 0, len(s.volStore))
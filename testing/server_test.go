@@ -8,6 +8,7 @@ import (
 	"archive/tar"
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -17,6 +18,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
@@ -106,6 +108,123 @@ func TestServerURL(t *testing.T) {
 	}
 }
 
+func TestServerNewClient(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python", Tag: "3.7", OutputStream: &buf}, docker.AuthConfiguration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.InspectImage("tsuru/python:3.7"); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Name: "web", Config: &docker.Config{Image: "tsuru/python:3.7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if container, err = client.InspectContainer("web"); err != nil || !container.State.Running {
+		t.Fatalf("InspectContainer: wrong container after start. Got %#v, %v.", container, err)
+	}
+	if err = client.StopContainer(container.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	if container, err = client.InspectContainer("web"); err != nil || container.State.Running {
+		t.Fatalf("InspectContainer: wrong container after stop. Got %#v, %v.", container, err)
+	}
+	if err = client.RemoveContainer(docker.RemoveContainerOptions{ID: container.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.InspectContainer("web"); !docker.IsErrNoSuchContainer(err) {
+		t.Errorf("InspectContainer: wrong error after remove. Got %v.", err)
+	}
+	if err = client.RemoveImage("tsuru/python:3.7"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.InspectImage("tsuru/python:3.7"); err != docker.ErrNoSuchImage {
+		t.Errorf("InspectImage: wrong error after remove. Want %#v. Got %#v.", docker.ErrNoSuchImage, err)
+	}
+}
+
+func TestGetContainerTiming(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "base"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Config: &docker.Config{Image: "base"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.PrepareStats(container.ID, func(string) docker.Stats {
+		var stats docker.Stats
+		stats.CPUStats.CPUUsage.TotalUsage = uint64(1500 * time.Millisecond)
+		return stats
+	})
+	ctx := context.Background()
+	timing, err := client.GetContainerTiming(container.ID, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.CreatedAt.IsZero() || !timing.StartedAt.IsZero() || !timing.FinishedAt.IsZero() {
+		t.Errorf("GetContainerTiming: wrong timing before start. Got %#v.", timing)
+	}
+	if err = client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	timing, err = client.GetContainerTiming(container.ID, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.StartedAt.IsZero() || !timing.FinishedAt.IsZero() || timing.Uptime <= 0 {
+		t.Errorf("GetContainerTiming: wrong timing of running container. Got %#v.", timing)
+	}
+	if timing.TotalCPUTime != 1500*time.Millisecond {
+		t.Errorf("GetContainerTiming: wrong CPU time. Want %s. Got %s.", 1500*time.Millisecond, timing.TotalCPUTime)
+	}
+	if err = client.StopContainer(container.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	timing, err = client.GetContainerTiming(container.ID, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !timing.FinishedAt.After(timing.StartedAt) {
+		t.Errorf("GetContainerTiming: FinishedAt (%s) is not after StartedAt (%s).", timing.FinishedAt, timing.StartedAt)
+	}
+	if timing.Uptime != 0 || timing.TotalCPUTime != 0 {
+		t.Errorf("GetContainerTiming: wrong timing of stopped container. Got %#v.", timing)
+	}
+}
+
+func TestServerNewClientNoListener(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	if _, err := server.NewClient(); err == nil {
+		t.Error("NewClient: unexpected <nil> error")
+	}
+}
+
 func TestServerURLNoListener(t *testing.T) {
 	t.Parallel()
 	server := baseDockerServer()
@@ -500,6 +619,25 @@ func TestRenameContainerNotFound(t *testing.T) {
 	}
 }
 
+func TestRenameContainerConflict(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	containers := addContainers(&server, 2)
+	server.contNameToID[containers[1].Name] = containers[1].ID
+	server.buildMuxer()
+	recorder := httptest.NewRecorder()
+	oldName := containers[0].Name
+	path := fmt.Sprintf("/containers/%s/rename?name=%s", containers[0].ID, containers[1].Name)
+	request, _ := http.NewRequest("POST", path, nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("RenameContainer: wrong status. Want %d. Got %d.", http.StatusConflict, recorder.Code)
+	}
+	if containers[0].Name != oldName {
+		t.Errorf("RenameContainer: renamed the container. Want %q. Got %q.", oldName, containers[0].Name)
+	}
+}
+
 func TestCommitContainer(t *testing.T) {
 	t.Parallel()
 	server := baseDockerServer()
@@ -1967,6 +2105,12 @@ func TestPing(t *testing.T) {
 	if recorder.Code != http.StatusOK {
 		t.Errorf("Ping: Expected code %d, got: %d", http.StatusOK, recorder.Code)
 	}
+	if osType := recorder.Header().Get("OSType"); osType != "linux" {
+		t.Errorf("Ping: wrong OSType header. Want %q. Got %q.", "linux", osType)
+	}
+	if swarmStatus := recorder.Header().Get("Swarm"); swarmStatus != "inactive" {
+		t.Errorf("Ping: wrong Swarm header. Want %q. Got %q.", "inactive", swarmStatus)
+	}
 }
 
 func TestDefaultHandler(t *testing.T) {
@@ -2334,6 +2478,275 @@ func TestListNetworks(t *testing.T) {
 	}
 }
 
+func TestImportImageFromTar(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "go-dockerclient-import-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := []byte("hello world\n")
+	tw.WriteHeader(&tar.Header{Name: "hello.txt", Mode: 0644, Size: int64(len(content))})
+	tw.Write(content)
+	tw.Close()
+	tarPath := filepath.Join(tmpDir, "rootfs.tar")
+	if err = ioutil.WriteFile(tarPath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := client.ImportImageFromTar(tarPath, "tsuru/hello", "v1", &docker.Config{Cmd: []string{"cat", "/hello.txt"}}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, err := client.InspectImage("tsuru/hello:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.ID != id {
+		t.Errorf("ImportImageFromTar: wrong image ID. Want %q. Got %q.", id, image.ID)
+	}
+}
+
+func TestRestartWithEnv(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:   "web",
+		Config: &docker.Config{Image: "tsuru/python", Env: []string{"PORT=8080", "GREETING=hello"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	newContainer, err := client.RestartWithEnv("web", map[string]string{"GREETING": "hi"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEnv := []string{"PORT=8080", "GREETING=hi"}
+	if !reflect.DeepEqual(newContainer.Config.Env, expectedEnv) {
+		t.Errorf("RestartWithEnv: wrong env. Want %#v. Got %#v.", expectedEnv, newContainer.Config.Env)
+	}
+	if !newContainer.State.Running {
+		t.Error("RestartWithEnv: the new container is not running")
+	}
+	if newContainer.Name != "web" {
+		t.Errorf("RestartWithEnv: wrong name. Want %q. Got %q.", "web", newContainer.Name)
+	}
+	if _, err = client.InspectContainer(container.ID); err == nil {
+		t.Error("RestartWithEnv: the old container was not removed")
+	}
+}
+
+func TestRestartWithEnvStopFailure(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Config: &docker.Config{Image: "tsuru/python"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.CustomHandler("/containers/"+container.ID+"/stop", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "cannot stop container", http.StatusInternalServerError)
+	}))
+	_, err = client.RestartWithEnv(container.ID, map[string]string{"GREETING": "hi"}, context.Background())
+	e, ok := err.(*docker.ContainerNotStopped)
+	if !ok || e.ID != container.ID {
+		t.Fatalf("RestartWithEnv: wrong error. Want a *docker.ContainerNotStopped. Got %#v.", err)
+	}
+	if cause, ok := e.Err.(*docker.Error); !ok || cause.Status != http.StatusInternalServerError {
+		t.Errorf("RestartWithEnv: wrong cause. Want the error of the daemon. Got %#v.", e.Err)
+	}
+}
+
+func TestRestartWithEnvCreateFailure(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:   "web",
+		Config: &docker.Config{Image: "tsuru/python"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	server.CustomHandler("/containers/create", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no space left on device", http.StatusInternalServerError)
+	}))
+	if _, err = client.RestartWithEnv("web", map[string]string{"GREETING": "hi"}, context.Background()); err == nil {
+		t.Fatal("RestartWithEnv: unexpected <nil> error")
+	}
+	restored, err := client.InspectContainer(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.State.Running {
+		t.Error("RestartWithEnv: the old container was not started again")
+	}
+}
+
+func TestListNetworksDangling(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	server.networks = []*docker.Network{
+		{ID: "id1", Name: "name1", Containers: map[string]docker.Endpoint{"blah": {Name: "blah"}}},
+		{ID: "id2", Name: "name2"},
+	}
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", `/networks?filters={"dangling":["true"]}`, nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("ListNetworks: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	var got []docker.Network
+	err := json.NewDecoder(recorder.Body).Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []docker.Network{{ID: "id2", Name: "name2"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ListNetworks. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestPruneNetworks(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	addNetworks(server, 1)
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := client.CreateNetwork(docker.CreateNetworkOptions{Name: "dangling"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := client.PruneNetworks(docker.PruneNetworksOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &docker.PruneNetworksResults{NetworksDeleted: []string{"dangling"}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("PruneNetworks: wrong dry-run results. Want %#v. Got %#v.", expected, results)
+	}
+	if _, err = client.NetworkInfo(network.ID); err != nil {
+		t.Errorf("PruneNetworks: dry-run should not remove the network: %s", err)
+	}
+	results, err = client.PruneNetworks(docker.PruneNetworksOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("PruneNetworks: wrong results. Want %#v. Got %#v.", expected, results)
+	}
+	if _, err = client.NetworkInfo(network.ID); err == nil {
+		t.Error("PruneNetworks: the dangling network was not removed")
+	}
+	if len(server.networks) != 1 {
+		t.Errorf("PruneNetworks: wrong number of networks. Want 1. Got %d.", len(server.networks))
+	}
+}
+
+func TestNetworkByName(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]string)
+	for _, name := range []string{"web", "web-frontend", "web-backend"} {
+		network, err := client.CreateNetwork(docker.CreateNetworkOptions{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = network.ID
+	}
+	for _, name := range []string{"web", "web-frontend"} {
+		network, err := client.NetworkByName(name, context.Background())
+		if err != nil {
+			t.Errorf("NetworkByName(%q): unexpected error: %s", name, err)
+			continue
+		}
+		if network.Name != name || network.ID != ids[name] {
+			t.Errorf("NetworkByName(%q): wrong network. Want %q. Got %q.", name, name, network.Name)
+		}
+	}
+	id, err := client.NetworkIDByName("web-frontend", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != ids["web-frontend"] {
+		t.Errorf("NetworkIDByName: wrong ID. Want %q. Got %q.", ids["web-frontend"], id)
+	}
+	for _, name := range []string{"web-", "backend"} {
+		if _, err = client.NetworkByName(name, context.Background()); err != docker.ErrAmbiguousNetworkName {
+			t.Errorf("NetworkByName(%q): wrong error for a partial name. Want %#v. Got %#v.", name, docker.ErrAmbiguousNetworkName, err)
+		}
+	}
+	_, err = client.NetworkIDByName("db", context.Background())
+	expectedErr := &docker.NoSuchNetwork{ID: "db"}
+	if !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("NetworkIDByName: wrong error. Want %#v. Got %#v.", expectedErr, err)
+	}
+}
+
 type createNetworkResponse struct {
 	ID string `json:"ID"`
 }
@@ -2836,3 +3249,137 @@ func TestDownloadFromContainer(t *testing.T) {
 		t.Errorf("DownloadFromContainer: wrong Content-Type. Want 'application/x-tar'. Got %s.", resp.Header.Get("Content-Type"))
 	}
 }
+
+func TestGetContainerPortInfo(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Config: &docker.Config{
+			Image:        "tsuru/python",
+			ExposedPorts: map[docker.Port]struct{}{"80/tcp": {}, "443/tcp": {}, "53/udp": {}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostConfig := docker.HostConfig{
+		PortBindings: map[docker.Port][]docker.PortBinding{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}},
+	}
+	if err = client.StartContainer(container.ID, &hostConfig); err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.GetContainerPortInfo(container.ID, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &docker.PortInfo{
+		ExposedPorts:        []docker.Port{"80/tcp", "443/tcp", "53/udp"},
+		BoundPorts:          map[docker.Port][]docker.PortBinding{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}}},
+		UnboundExposedPorts: []docker.Port{"443/tcp", "53/udp"},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("GetContainerPortInfo: wrong port info.\nWant %#v.\nGot  %#v.", expected, info)
+	}
+}
+
+func TestConnectNetworkIPv6(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := client.CreateNetwork(docker.CreateNetworkOptions{
+		Name:       "dual-stack",
+		Driver:     "bridge",
+		EnableIPv6: true,
+		IPAM: &docker.IPAMOptions{
+			Config: []docker.IPAMConfig{
+				{Subnet: "172.20.0.0/16", Gateway: "172.20.0.1"},
+				{Subnet: "2001:db8::/64", Gateway: "2001:db8::1"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Config: &docker.Config{Image: "tsuru/python"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.ConnectNetwork(network.ID, docker.NetworkConnectionOptions{
+		Container:      container.ID,
+		EndpointConfig: docker.NewEndpointConfig(docker.WithIPv4Address("172.20.0.10"), docker.WithIPv6Address("2001:db8::10")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	container, err = client.InspectContainer(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := container.NetworkSettings.Networks["dual-stack"]
+	if endpoint.GlobalIPv6Address != "2001:db8::10" || endpoint.GlobalIPv6PrefixLen != 64 || endpoint.IPv6Gateway != "2001:db8::1" {
+		t.Errorf("ConnectNetwork: wrong IPv6 settings. Got %#v.", endpoint)
+	}
+	if endpoint.IPAddress != "172.20.0.10" || endpoint.IPPrefixLen != 16 || endpoint.Gateway != "172.20.0.1" {
+		t.Errorf("ConnectNetwork: wrong IPv4 settings. Got %#v.", endpoint)
+	}
+	expectedIPAM := &docker.EndpointIPAMConfig{IPv4Address: "172.20.0.10", IPv6Address: "2001:db8::10"}
+	if !reflect.DeepEqual(endpoint.IPAMConfig, expectedIPAM) {
+		t.Errorf("ConnectNetwork: wrong IPAM config. Want %#v. Got %#v.", expectedIPAM, endpoint.IPAMConfig)
+	}
+}
+
+func TestConnectNetworkIPv6Disabled(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := client.CreateNetwork(docker.CreateNetworkOptions{
+		Name:   "ipv4-only",
+		Driver: "bridge",
+		IPAM:   &docker.IPAMOptions{Config: []docker.IPAMConfig{{Subnet: "2001:db8::/64"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Config: &docker.Config{Image: "tsuru/python"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.ConnectNetwork(network.ID, docker.NetworkConnectionOptions{
+		Container:      container.ID,
+		EndpointConfig: docker.NewEndpointConfig(docker.WithIPv6Address("2001:db8::10")),
+	})
+	if e, ok := err.(*docker.Error); !ok || e.Status != http.StatusBadRequest {
+		t.Errorf("ConnectNetwork: wrong error. Want a bad request. Got %#v.", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -117,6 +118,36 @@ func (s *DockerServer) swarmInspect(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *DockerServer) swarmUpdate(w http.ResponseWriter, r *http.Request) {
+	s.swarmMut.Lock()
+	defer s.swarmMut.Unlock()
+	if s.swarm == nil {
+		w.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+	version, err := strconv.ParseUint(r.URL.Query().Get("version"), 10, 64)
+	if err != nil || version != s.swarm.Version.Index {
+		http.Error(w, "update out of sequence", http.StatusInternalServerError)
+		return
+	}
+	var spec swarm.Spec
+	err = json.NewDecoder(r.Body).Decode(&spec)
+	if err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.swarm.Spec = spec
+	s.swarm.Version.Index++
+	s.swarm.UpdatedAt = time.Now()
+	if r.URL.Query().Get("rotateWorkerToken") == "true" {
+		s.swarm.JoinTokens.Worker = s.generateID()
+	}
+	if r.URL.Query().Get("rotateManagerToken") == "true" {
+		s.swarm.JoinTokens.Manager = s.generateID()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (s *DockerServer) swarmJoin(w http.ResponseWriter, r *http.Request) {
 	s.swarmMut.Lock()
 	defer s.swarmMut.Unlock()
@@ -6,6 +6,7 @@ package testing
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -1109,6 +1110,44 @@ func TestServiceUpdate(t *testing.T) {
 	}
 }
 
+func TestServiceForceRedeploy(t *testing.T) {
+	server, unused := setUpSwarm(t)
+	defer server.Stop()
+	defer unused.Stop()
+	srv, err := addTestService(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := docker.ListTasksOptions{Filters: map[string][]string{"service": {srv.ID}}}
+	before, err := client.ListTasks(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.ForceRedeployService(srv.ID, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	after, err := client.ListTasks(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 1 || len(after) != 1 {
+		t.Fatalf("ForceRedeployService: wrong number of tasks. Before %d. After %d.", len(before), len(after))
+	}
+	if after[0].ID == before[0].ID {
+		t.Errorf("ForceRedeployService: task %q was not replaced", before[0].ID)
+	}
+	if after[0].Spec.ForceUpdate != 1 {
+		t.Errorf("ForceRedeployService: wrong ForceUpdate. Want 1. Got %d.", after[0].Spec.ForceUpdate)
+	}
+	if after[0].Spec.ContainerSpec.Image != before[0].Spec.ContainerSpec.Image {
+		t.Errorf("ForceRedeployService: image should not change. Want %q. Got %q.", before[0].Spec.ContainerSpec.Image, after[0].Spec.ContainerSpec.Image)
+	}
+}
+
 func TestServiceUpdateMoreReplicas(t *testing.T) {
 	server, unused := setUpSwarm(t)
 	defer server.Stop()
@@ -1396,3 +1435,59 @@ func TestMutateTaskNotFound(t *testing.T) {
 		t.Errorf("wrong error message. Want %q. Got %q.", "task not found", err)
 	}
 }
+
+func TestSwarmInitWithTokensAndRotate(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.InitSwarmWithTokens(docker.InitSwarmOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NodeID != server.nodeID {
+		t.Errorf("InitSwarmWithTokens: wrong node ID. Want %q. Got %q.", server.nodeID, result.NodeID)
+	}
+	if result.JoinTokens != server.swarm.JoinTokens || result.JoinTokens.Worker == "" || result.JoinTokens.Manager == "" {
+		t.Fatalf("InitSwarmWithTokens: wrong join tokens. Want %#v. Got %#v.", server.swarm.JoinTokens, result.JoinTokens)
+	}
+	tokens, err := client.RotateSwarmTokens(docker.RotateSwarmTokensOptions{Worker: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.Worker == result.JoinTokens.Worker {
+		t.Errorf("RotateSwarmTokens: worker token was not rotated")
+	}
+	if tokens.Manager != result.JoinTokens.Manager {
+		t.Errorf("RotateSwarmTokens: manager token should not change. Want %q. Got %q.", result.JoinTokens.Manager, tokens.Manager)
+	}
+	tokens2, err := client.RotateSwarmTokens(docker.RotateSwarmTokensOptions{Manager: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens2.Worker != tokens.Worker || tokens2.Manager == tokens.Manager {
+		t.Errorf("RotateSwarmTokens: wrong tokens after rotating the manager token. Before %#v. After %#v.", tokens, tokens2)
+	}
+}
+
+func TestSwarmUpdateOutOfSequence(t *testing.T) {
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	server.buildMuxer()
+	server.swarm = &swarm.Swarm{}
+	server.swarm.Version.Index = 3
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/swarm/update?version=2", strings.NewReader("{}"))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("SwarmUpdate: wrong status. Want %d. Got %d.", http.StatusInternalServerError, recorder.Code)
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+// gen generates the DockerClient interface, from the exported methods of
+// docker.Client, and the MockDockerClient implementing it.
+//
+// Run it with go generate in the testutil directory whenever a method is added
+// to the client.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const header = `// Code generated by testutil/gen.go. DO NOT EDIT.
+
+`
+
+type method struct {
+	name    string
+	group   string
+	params  []param
+	results []string
+}
+
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+// groups maps the files declaring the methods of the client to the
+// sub-interface of DockerClient they belong to. Methods of other files belong
+// to SystemAPI.
+var groups = map[string]string{
+	"container.go":       "ContainerAPI",
+	"stats_collector.go": "ContainerAPI",
+	"exec.go":            "ExecAPI",
+	"image.go":           "ImageAPI",
+	"distribution.go":    "ImageAPI",
+	"network.go":         "NetworkAPI",
+	"plugin.go":          "PluginAPI",
+	"swarm.go":           "SwarmAPI",
+	"swarm_configs.go":   "SwarmAPI",
+	"swarm_node.go":      "SwarmAPI",
+	"swarm_secrets.go":   "SwarmAPI",
+	"swarm_service.go":   "SwarmAPI",
+	"swarm_task.go":      "SwarmAPI",
+	"volume.go":          "VolumeAPI",
+}
+
+var groupDocs = map[string]string{
+	"ContainerAPI": "manages containers",
+	"ExecAPI":      "runs commands in containers",
+	"ImageAPI":     "manages images",
+	"NetworkAPI":   "manages networks",
+	"PluginAPI":    "manages plugins",
+	"SwarmAPI":     "manages a swarm and its nodes, services, tasks, secrets and configs",
+	"SystemAPI":    "queries the daemon, authenticates with registries and configures the client",
+	"VolumeAPI":    "manages volumes",
+}
+
+var builtins = map[string]bool{
+	"bool": true, "byte": true, "error": true, "float32": true, "float64": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"rune": true, "string": true, "uint": true, "uint8": true, "uint16": true,
+	"uint32": true, "uint64": true, "uintptr": true,
+}
+
+func main() {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "..", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkg, ok := pkgs["docker"]
+	if !ok {
+		log.Fatal("package docker not found")
+	}
+	var methods []method
+	imports := map[string]string{}
+	for filename, file := range pkg.Files {
+		group, ok := groups[filepath.Base(filename)]
+		if !ok {
+			group = "SystemAPI"
+		}
+		fileImports := map[string]string{}
+		for _, spec := range file.Imports {
+			path := strings.Trim(spec.Path.Value, `"`)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			fileImports[name] = path
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || !fn.Name.IsExported() || !isClientReceiver(fn.Recv) {
+				continue
+			}
+			m := method{name: fn.Name.Name, group: group}
+			for i, field := range fn.Type.Params.List {
+				typ, variadic := field.Type, false
+				if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+					typ, variadic = ellipsis.Elt, true
+				}
+				typeName := qualify(typ, fileImports, imports)
+				if len(field.Names) == 0 {
+					m.params = append(m.params, param{name: fmt.Sprintf("arg%d", i), typ: typeName, variadic: variadic})
+				}
+				for _, name := range field.Names {
+					m.params = append(m.params, param{name: name.Name, typ: typeName, variadic: variadic})
+				}
+			}
+			if fn.Type.Results != nil {
+				for _, field := range fn.Type.Results.List {
+					typeName := qualify(field.Type, fileImports, imports)
+					for n := 0; n < len(field.Names) || n == 0; n++ {
+						m.results = append(m.results, typeName)
+					}
+				}
+			}
+			methods = append(methods, m)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].name < methods[j].name
+	})
+	write("../client_interface.go", generateInterface(methods, imports))
+	write("mock_docker_client.go", generateMock(methods, imports))
+}
+
+func isClientReceiver(recv *ast.FieldList) bool {
+	star, ok := recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "Client"
+}
+
+// qualify returns the source of the given type as seen from outside of the
+// docker package, recording the imports it requires.
+func qualify(expr ast.Expr, fileImports, imports map[string]string) string {
+	return types.ExprString(qualifyExpr(expr, fileImports, imports))
+}
+
+func qualifyExpr(expr ast.Expr, fileImports, imports map[string]string) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if builtins[e.Name] {
+			return e
+		}
+		if !e.IsExported() {
+			log.Fatalf("unexported type %s in the signature of an exported method", e.Name)
+		}
+		return &ast.SelectorExpr{X: ast.NewIdent("docker"), Sel: e}
+	case *ast.SelectorExpr:
+		pkg := e.X.(*ast.Ident).Name
+		imports[pkg] = fileImports[pkg]
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualifyExpr(e.X, fileImports, imports)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: qualifyExpr(e.Elt, fileImports, imports)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualifyExpr(e.Key, fileImports, imports), Value: qualifyExpr(e.Value, fileImports, imports)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: qualifyExpr(e.Value, fileImports, imports)}
+	case *ast.InterfaceType:
+		return e
+	case *ast.FuncType:
+		params := &ast.FieldList{}
+		for _, field := range e.Params.List {
+			params.List = append(params.List, &ast.Field{Names: field.Names, Type: qualifyExpr(field.Type, fileImports, imports)})
+		}
+		var results *ast.FieldList
+		if e.Results != nil {
+			results = &ast.FieldList{}
+			for _, field := range e.Results.List {
+				results.List = append(results.List, &ast.Field{Names: field.Names, Type: qualifyExpr(field.Type, fileImports, imports)})
+			}
+		}
+		return &ast.FuncType{Params: params, Results: results}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualifyExpr(e.Elt, fileImports, imports)}
+	}
+	log.Fatalf("unsupported type %T", expr)
+	return nil
+}
+
+func (m method) signature(qualified bool) string {
+	params := make([]string, len(m.params))
+	for i, p := range m.params {
+		typ := p.typ
+		if !qualified {
+			typ = strings.Replace(typ, "docker.", "", -1)
+		}
+		if p.variadic {
+			typ = "..." + typ
+		}
+		params[i] = p.name + " " + typ
+	}
+	results := make([]string, len(m.results))
+	for i, r := range m.results {
+		if !qualified {
+			r = strings.Replace(r, "docker.", "", -1)
+		}
+		results[i] = r
+	}
+	s := m.name + "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		s += " " + results[0]
+	default:
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
+
+func generateInterface(methods []method, imports map[string]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("package docker\n\n")
+	var paths []string
+	for _, path := range imports {
+		paths = append(paths, path)
+	}
+	writeImports(&buf, paths)
+	var names []string
+	for name := range groupDocs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf.WriteString(`// DockerClient is the set of methods of Client. Code that depends on
+// DockerClient rather than on *Client can be tested with a fake
+// implementation, such as the MockDockerClient of the testutil package,
+// instead of a Docker daemon. Code that only needs some of the methods can
+// depend on the smaller interfaces embedded in DockerClient instead.
+type DockerClient interface {
+`)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%s\n", name)
+	}
+	buf.WriteString("}\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\n// %s is the subset of DockerClient that %s.\n", name, groupDocs[name])
+		fmt.Fprintf(&buf, "type %s interface {\n", name)
+		for _, m := range methods {
+			if m.group == name {
+				fmt.Fprintf(&buf, "\t%s\n", m.signature(false))
+			}
+		}
+		buf.WriteString("}\n")
+	}
+	buf.WriteString("\nvar _ DockerClient = (*Client)(nil)\n")
+	return buf.Bytes()
+}
+
+func generateMock(methods []method, imports map[string]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("package testutil\n\n")
+	paths := []string{"github.com/fsouza/go-dockerclient", "sync"}
+	for _, path := range imports {
+		paths = append(paths, path)
+	}
+	writeImports(&buf, paths)
+	buf.WriteString(`// MockDockerClient is a docker.DockerClient that records its calls and
+// returns the responses of its function fields, named after the methods of
+// the client. Methods whose function is nil return zero values.
+type MockDockerClient struct {
+`)
+	for _, m := range methods {
+		fmt.Fprintf(&buf, "\t%sFunc func%s\n", m.name, strings.TrimPrefix(m.signature(true), m.name))
+	}
+	buf.WriteString(`
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ docker.DockerClient = &MockDockerClient{}
+`)
+	for _, m := range methods {
+		args := make([]string, len(m.params))
+		names := make([]string, len(m.params))
+		for i, p := range m.params {
+			args[i] = p.name
+			names[i] = p.name
+			if p.variadic {
+				names[i] += "..."
+			}
+		}
+		fmt.Fprintf(&buf, "\n// %s calls %sFunc, if set, and records the call.\n", m.name, m.name)
+		fmt.Fprintf(&buf, "func (m *MockDockerClient) %s {\n", m.signature(true))
+		fmt.Fprintf(&buf, "\tm.record(%q, []interface{}{%s})\n", m.name, strings.Join(args, ", "))
+		call := fmt.Sprintf("m.%sFunc(%s)", m.name, strings.Join(names, ", "))
+		if len(m.results) == 0 {
+			fmt.Fprintf(&buf, "\tif m.%sFunc != nil {\n\t\t%s\n\t}\n}\n", m.name, call)
+			continue
+		}
+		fmt.Fprintf(&buf, "\tif m.%sFunc != nil {\n\t\treturn %s\n\t}\n", m.name, call)
+		zeros := make([]string, len(m.results))
+		for i, r := range m.results {
+			fmt.Fprintf(&buf, "\tvar r%d %s\n", i, r)
+			zeros[i] = fmt.Sprintf("r%d", i)
+		}
+		fmt.Fprintf(&buf, "\treturn %s\n}\n", strings.Join(zeros, ", "))
+	}
+	return buf.Bytes()
+}
+
+// writeImports writes the import declaration of the given packages, with the
+// standard library in the first group.
+func writeImports(buf *bytes.Buffer, paths []string) {
+	sort.Strings(paths)
+	buf.WriteString("import (\n")
+	for _, std := range []bool{true, false} {
+		for _, path := range paths {
+			if !strings.Contains(strings.Split(path, "/")[0], ".") == std {
+				fmt.Fprintf(buf, "\t%q\n", path)
+			}
+		}
+		if std {
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString(")\n\n")
+}
+
+func write(path string, src []byte) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		log.Fatalf("%s: %s\n%s", path, err, src)
+	}
+	if err := ioutil.WriteFile(path, formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
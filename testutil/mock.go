@@ -0,0 +1,51 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package testutil provides a mock of docker.DockerClient, for unit testing
+// code that talks to Docker without running a daemon.
+package testutil
+
+//go:generate go run gen.go
+
+// Call is a call received by a MockDockerClient.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Calls returns the calls received by the mock, in order.
+func (m *MockDockerClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([]Call, len(m.calls))
+	copy(calls, m.calls)
+	return calls
+}
+
+// CallsTo returns the calls received by the mock for the given method, in
+// order.
+func (m *MockDockerClient) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset discards the calls received by the mock.
+func (m *MockDockerClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *MockDockerClient) record(method string, args []interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
@@ -0,0 +1,2382 @@
+// Code generated by testutil/gen.go. DO NOT EDIT.
+
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/fsouza/go-dockerclient"
+)
+
+// MockDockerClient is a docker.DockerClient that records its calls and
+// returns the responses of its function fields, named after the methods of
+// the client. Methods whose function is nil return zero values.
+type MockDockerClient struct {
+	ActivateNodeFunc                      func(id string) error
+	AddEventListenerFunc                  func(listener chan<- *docker.APIEvents) error
+	ApplyOptionsFunc                      func(opts ...docker.ClientOption) error
+	AttachAndWaitFunc                     func(id string, stdout io.Writer, stderr io.Writer, ctx context.Context) (int, error)
+	AttachToContainerFunc                 func(opts docker.AttachToContainerOptions) error
+	AttachToContainerDemuxFunc            func(opts docker.AttachToContainerOptions) (io.ReadCloser, io.ReadCloser, io.WriteCloser, error)
+	AttachToContainerNonBlockingFunc      func(opts docker.AttachToContainerOptions) (docker.CloseWaiter, error)
+	AttachToContainerStreamFunc           func(opts docker.AttachToContainerOptions) (io.ReadWriteCloser, error)
+	AuthCheckFunc                         func(conf *docker.AuthConfiguration) (docker.AuthStatus, error)
+	BuildImageFunc                        func(opts docker.BuildImageOptions) error
+	CollectStatsFunc                      func(id string, interval time.Duration, ctx context.Context) (*docker.StatsCollector, error)
+	CommitContainerFunc                   func(opts docker.CommitContainerOptions) (*docker.Image, error)
+	ConfigurePluginFunc                   func(opts docker.ConfigurePluginOptions) error
+	ConnectNetworkFunc                    func(id string, opts docker.NetworkConnectionOptions) error
+	ContainerChangesFunc                  func(id string) ([]docker.Change, error)
+	ContainerLogsReaderFunc               func(id string, opts docker.LogsOptions, ctx context.Context) (io.ReadCloser, error)
+	ContainersDiskUsageFunc               func(opts docker.DiskUsageOptions) (map[string]int64, error)
+	CopyFromContainerFunc                 func(opts docker.CopyFromContainerOptions) error
+	CreateConfigFunc                      func(opts docker.CreateConfigOptions) (*swarm.Config, error)
+	CreateContainerFunc                   func(opts docker.CreateContainerOptions) (*docker.Container, error)
+	CreateExecFunc                        func(opts docker.CreateExecOptions) (*docker.Exec, error)
+	CreateNetworkFunc                     func(opts docker.CreateNetworkOptions) (*docker.Network, error)
+	CreatePluginFunc                      func(opts docker.CreatePluginOptions) (string, error)
+	CreateSecretFunc                      func(opts docker.CreateSecretOptions) (*swarm.Secret, error)
+	CreateServiceFunc                     func(opts docker.CreateServiceOptions) (*swarm.Service, error)
+	CreateVolumeFunc                      func(opts docker.CreateVolumeOptions) (*docker.Volume, error)
+	DemoteNodeFunc                        func(id string) error
+	DisablePluginFunc                     func(opts docker.DisablePluginOptions) error
+	DisconnectNetworkFunc                 func(id string, opts docker.NetworkConnectionOptions) error
+	DiskUsageFunc                         func(opts docker.DiskUsageOptions) (*docker.DiskUsage, error)
+	DownloadFromContainerFunc             func(id string, opts docker.DownloadFromContainerOptions) error
+	DrainNodeFunc                         func(id string) error
+	EnablePluginFunc                      func(opts docker.EnablePluginOptions) error
+	EndpointFunc                          func() string
+	ExecTimeoutFunc                       func(containerID string, timeout time.Duration, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, ctx context.Context) (int, error)
+	ExportContainerFunc                   func(opts docker.ExportContainerOptions) error
+	ExportImageFunc                       func(opts docker.ExportImageOptions) error
+	ExportImagesFunc                      func(opts docker.ExportImagesOptions) error
+	FilteredListNetworksFunc              func(opts docker.NetworkFilterOpts) ([]docker.Network, error)
+	ForceRedeployServiceFunc              func(serviceID string, ctx context.Context) error
+	FreezeContainerFSFunc                 func(id string, ctx context.Context) error
+	GetCgroupVersionFunc                  func(ctx context.Context) (int, error)
+	GetContainerAppArmorProfileFunc       func(id string, ctx context.Context) (string, error)
+	GetContainerCFSQuotaFunc              func(id string, ctx context.Context) (int64, int64, error)
+	GetContainerEnvVarFunc                func(id string, key string, ctx context.Context) (string, bool, error)
+	GetContainerLogsStructuredFunc        func(id string, opts docker.LogsOptions) ([]docker.LogEntry, error)
+	GetContainerNetNamespaceFunc          func(id string, ctx context.Context) (uint64, error)
+	GetContainerNetworkStatsFunc          func(id string, interfaceName string, ctx context.Context) (*docker.NetworkStats, error)
+	GetContainerNodeIDFunc                func(containerID string, ctx context.Context) (string, error)
+	GetContainerPIDNamespaceFunc          func(id string, ctx context.Context) (uint64, error)
+	GetContainerPortInfoFunc              func(id string, ctx context.Context) (*docker.PortInfo, error)
+	GetContainerRootFSPathFunc            func(id string, ctx context.Context) (string, error)
+	GetContainerSeccompProfileFunc        func(id string, ctx context.Context) (string, error)
+	GetContainerServiceIDFunc             func(containerID string, ctx context.Context) (string, error)
+	GetContainerStatsOnceFunc             func(id string, ctx context.Context) (*docker.Stats, error)
+	GetContainerTaskIDFunc                func(containerID string, ctx context.Context) (string, error)
+	GetContainerTimingFunc                func(id string, ctx context.Context) (*docker.ContainerTiming, error)
+	GetContainerWritableLayerSizeFunc     func(id string, ctx context.Context) (int64, error)
+	GetDaemonMetricsFunc                  func(ctx context.Context) (map[string]float64, error)
+	GetImageBaseImageFunc                 func(nameOrID string, ctx context.Context) (string, error)
+	GetImageLayerCountFunc                func(nameOrID string, ctx context.Context) (int, error)
+	GetPluginPrivilegesFunc               func(name string, ctx context.Context) ([]docker.PluginPrivilege, error)
+	GetRegistryMirrorsFunc                func(ctx context.Context) ([]string, error)
+	GetServiceLogsFunc                    func(opts docker.LogsServiceOptions) error
+	GetStorageDriverInfoFunc              func(ctx context.Context) (*docker.StorageDriverInfo, error)
+	ImageExistsFunc                       func(name string) (bool, error)
+	ImageHistoryFunc                      func(name string) ([]docker.ImageHistory, error)
+	ImportImageFunc                       func(opts docker.ImportImageOptions) error
+	ImportImageFromReaderFunc             func(r io.Reader, repo string, tag string, w io.Writer, ctx context.Context) (string, error)
+	ImportImageFromTarFunc                func(tarPath string, repo string, tag string, config *docker.Config, ctx context.Context) (string, error)
+	ImportImageFromURLFunc                func(url string, repo string, tag string, w io.Writer, ctx context.Context) (string, error)
+	InfoFunc                              func() (*docker.DockerInfo, error)
+	InfoWithContextFunc                   func(ctx context.Context) (*docker.DockerInfo, error)
+	InitSwarmFunc                         func(opts docker.InitSwarmOptions) (string, error)
+	InitSwarmWithTokensFunc               func(opts docker.InitSwarmOptions) (*docker.InitSwarmResult, error)
+	InspectConfigFunc                     func(id string) (*swarm.Config, error)
+	InspectContainerFunc                  func(id string) (*docker.Container, error)
+	InspectContainerRawFunc               func(id string) (*docker.Container, json.RawMessage, error)
+	InspectContainerStatsFunc             func(id string, ctx context.Context) (*docker.Stats, error)
+	InspectContainerWithContextFunc       func(id string, ctx context.Context) (*docker.Container, error)
+	InspectDistributionFunc               func(name string) (*registry.DistributionInspect, error)
+	InspectDistributionWithAuthFunc       func(name string, auth docker.AuthConfiguration) (*registry.DistributionInspect, error)
+	InspectExecFunc                       func(id string) (*docker.ExecInspect, error)
+	InspectImageFunc                      func(name string) (*docker.Image, error)
+	InspectImageRawFunc                   func(name string) (*docker.Image, json.RawMessage, error)
+	InspectNodeFunc                       func(id string) (*swarm.Node, error)
+	InspectPluginsFunc                    func(name string, ctx context.Context) (*docker.PluginDetail, error)
+	InspectSecretFunc                     func(id string) (*swarm.Secret, error)
+	InspectServiceFunc                    func(id string) (*swarm.Service, error)
+	InspectSwarmFunc                      func(ctx context.Context) (swarm.Swarm, error)
+	InspectTaskFunc                       func(id string) (*swarm.Task, error)
+	InspectVolumeFunc                     func(name string) (*docker.Volume, error)
+	InstallPluginFunc                     func(opts docker.InstallPluginOptions) (string, error)
+	InstallPluginsFunc                    func(opts docker.InstallPluginOptions) error
+	JoinSwarmFunc                         func(opts docker.JoinSwarmOptions) error
+	KillContainerFunc                     func(opts docker.KillContainerOptions) error
+	LeaveSwarmFunc                        func(opts docker.LeaveSwarmOptions) error
+	ListConfigsFunc                       func(opts docker.ListConfigsOptions) ([]swarm.Config, error)
+	ListContainerNetworkInterfacesFunc    func(id string, ctx context.Context) ([]string, error)
+	ListContainersFunc                    func(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+	ListContainersByVolumeFunc            func(volumeName string, ctx context.Context) ([]docker.APIContainers, error)
+	ListFilteredPluginsFunc               func(opts docker.ListFilteredPluginsOptions) ([]docker.PluginDetail, error)
+	ListImagesFunc                        func(opts docker.ListImagesOptions) ([]docker.APIImages, error)
+	ListNetworksFunc                      func() ([]docker.Network, error)
+	ListNodesFunc                         func(opts docker.ListNodesOptions) ([]swarm.Node, error)
+	ListPluginsFunc                       func(ctx context.Context) ([]docker.PluginDetail, error)
+	ListSecretsFunc                       func(opts docker.ListSecretsOptions) ([]swarm.Secret, error)
+	ListServicesFunc                      func(opts docker.ListServicesOptions) ([]swarm.Service, error)
+	ListTasksFunc                         func(opts docker.ListTasksOptions) ([]swarm.Task, error)
+	ListVolumesFunc                       func(opts docker.ListVolumesOptions) ([]docker.Volume, error)
+	ListenEventsFunc                      func(opts docker.EventsOptions, ctx context.Context) (<-chan docker.APIEvents, <-chan error)
+	LoadImageFunc                         func(opts docker.LoadImageOptions) error
+	LogsFunc                              func(opts docker.LogsOptions) error
+	MeasureContainerNetworkThroughputFunc func(id string, duration time.Duration, ctx context.Context) (*docker.ThroughputMeasurement, error)
+	ModifyNodeFunc                        func(id string, opts docker.ModifyNodeOptions) error
+	ModifyServiceFunc                     func(id string, opts docker.ModifyServiceOptions) error
+	MultiLogsFunc                         func(specs []docker.LogSpec, out io.Writer, ctx context.Context) error
+	NegotiateAPIVersionFunc               func() error
+	NegotiatedAPIVersionFunc              func() string
+	NetworkByNameFunc                     func(name string, ctx context.Context) (*docker.Network, error)
+	NetworkIDByNameFunc                   func(name string, ctx context.Context) (string, error)
+	NetworkInfoFunc                       func(id string) (*docker.Network, error)
+	NetworkInfoRawFunc                    func(id string) (*docker.Network, json.RawMessage, error)
+	PauseContainerFunc                    func(id string) error
+	PauseNodeFunc                         func(id string) error
+	PingFunc                              func() error
+	PingWithContextFunc                   func(ctx context.Context) error
+	PingWithResponseFunc                  func(ctx context.Context) (*docker.PingResponse, error)
+	PopulateEnvFromSecretsFunc            func(containerID string, secretNames []string, ctx context.Context) ([]string, error)
+	PromoteNodeFunc                       func(id string) error
+	PruneContainersFunc                   func(opts docker.PruneContainersOptions) (*docker.PruneContainersResults, error)
+	PruneImagesFunc                       func(opts docker.PruneImagesOptions) (*docker.PruneImagesResults, error)
+	PruneNetworksFunc                     func(opts docker.PruneNetworksOptions) (*docker.PruneNetworksResults, error)
+	PruneVolumesFunc                      func(opts docker.PruneVolumesOptions) (*docker.PruneVolumesResults, error)
+	PullImageFunc                         func(opts docker.PullImageOptions, auth docker.AuthConfiguration) error
+	PushImageFunc                         func(opts docker.PushImageOptions, auth docker.AuthConfiguration) error
+	PushPluginFunc                        func(opts docker.PushPluginOptions) error
+	RegistryAuthFunc                      func(serverAddress string) (docker.AuthConfiguration, bool)
+	RegistryLoginFunc                     func(auth docker.AuthConfiguration) (docker.AuthStatus, error)
+	RegistryMirrorsMatchFunc              func(mirrors []string, ctx context.Context) (bool, error)
+	RemoveConfigFunc                      func(opts docker.RemoveConfigOptions) error
+	RemoveContainerFunc                   func(opts docker.RemoveContainerOptions) error
+	RemoveEventListenerFunc               func(listener chan *docker.APIEvents) error
+	RemoveImageFunc                       func(name string) error
+	RemoveImageExtendedFunc               func(name string, opts docker.RemoveImageOptions) error
+	RemoveNetworkFunc                     func(id string) error
+	RemoveNodeFunc                        func(opts docker.RemoveNodeOptions) error
+	RemovePluginFunc                      func(opts docker.RemovePluginOptions) (*docker.PluginDetail, error)
+	RemoveSecretFunc                      func(opts docker.RemoveSecretOptions) error
+	RemoveServiceFunc                     func(opts docker.RemoveServiceOptions) error
+	RemoveVolumeFunc                      func(name string) error
+	RemoveVolumeWithOptionsFunc           func(opts docker.RemoveVolumeOptions) error
+	RenameContainerFunc                   func(opts docker.RenameContainerOptions) error
+	ReplayEventsFunc                      func(since time.Time, opts docker.EventsOptions, ctx context.Context) ([]docker.APIEvents, error)
+	ResizeContainerTTYFunc                func(id string, height int, width int) error
+	ResizeExecTTYFunc                     func(id string, height int, width int) error
+	RestartContainerFunc                  func(id string, timeout uint) error
+	RestartWithEnvFunc                    func(id string, newEnv map[string]string, ctx context.Context) (*docker.Container, error)
+	RollbackServiceFunc                   func(id string) error
+	RotateSecretFunc                      func(opts docker.RotateSecretOptions) (*swarm.Secret, error)
+	RotateSwarmTokensFunc                 func(opts docker.RotateSwarmTokensOptions) (swarm.JoinTokens, error)
+	RunContainerFunc                      func(opts docker.RunOptions) (*docker.RunResult, error)
+	ScaleServiceFunc                      func(id string, replicas uint64) error
+	SearchImagesFunc                      func(term string) ([]docker.APIImageSearch, error)
+	SearchImagesExFunc                    func(term string, auth docker.AuthConfiguration) ([]docker.APIImageSearch, error)
+	ServerVersionFunc                     func() (*docker.DockerVersion, error)
+	ServerVersionWithContextFunc          func(ctx context.Context) (*docker.DockerVersion, error)
+	ServiceLogsFunc                       func(opts docker.ServiceLogsOptions) (<-chan docker.ServiceLogEntry, <-chan error)
+	SetContainerAppArmorProfileFunc       func(id string, profile string, ctx context.Context) (*docker.Container, error)
+	SetContainerTimezoneFunc              func(id string, timezone string, ctx context.Context) (*docker.Container, error)
+	SetRegistryMirrorsFunc                func(mirrors []string, ctx context.Context) error
+	SetTimeoutFunc                        func(t time.Duration)
+	SetUserAgentFunc                      func(ua string)
+	StartContainerFunc                    func(id string, hostConfig *docker.HostConfig) error
+	StartContainerWithContextFunc         func(id string, hostConfig *docker.HostConfig, ctx context.Context) error
+	StartExecFunc                         func(id string, opts docker.StartExecOptions) error
+	StartExecNonBlockingFunc              func(id string, opts docker.StartExecOptions) (docker.CloseWaiter, error)
+	StatContainerPathFunc                 func(id string, path string) (docker.ContainerPathStat, error)
+	StatsFunc                             func(opts docker.StatsOptions) error
+	StopContainerFunc                     func(id string, timeout uint) error
+	StopContainerWithContextFunc          func(id string, timeout uint, ctx context.Context) error
+	TagImageFunc                          func(name string, opts docker.TagImageOptions) error
+	ThawContainerFSFunc                   func(id string, ctx context.Context) error
+	TopContainerFunc                      func(id string, psArgs string) (docker.TopResult, error)
+	UnpauseContainerFunc                  func(id string) error
+	UpdateConfigFunc                      func(id string, opts docker.UpdateConfigOptions) error
+	UpdateContainerFunc                   func(id string, opts docker.UpdateContainerOptions) error
+	UpdateNodeFunc                        func(id string, opts docker.UpdateNodeOptions) error
+	UpdateSecretFunc                      func(id string, opts docker.UpdateSecretOptions) error
+	UpdateServiceFunc                     func(id string, opts docker.UpdateServiceOptions) error
+	UpdateServiceImageFunc                func(id string, image string) error
+	UpdateSwarmFunc                       func(opts docker.UpdateSwarmOptions) error
+	UpgradePluginFunc                     func(opts docker.UpgradePluginOptions) error
+	UploadToContainerFunc                 func(id string, opts docker.UploadToContainerOptions) error
+	VersionFunc                           func() (*docker.Env, error)
+	VersionWithContextFunc                func(ctx context.Context) (*docker.Env, error)
+	WaitContainerFunc                     func(id string) (int, error)
+	WaitContainerWithContextFunc          func(id string, ctx context.Context) (int, error)
+	WaitForLogPatternFunc                 func(id string, pattern *regexp.Regexp, timeout time.Duration, ctx context.Context) (string, error)
+	WaitServiceConvergedFunc              func(serviceID string, timeout time.Duration) error
+	WatchSpotTerminationFunc              func(gracePeriod time.Duration, fn func(remainingTime time.Duration), ctx context.Context) error
+	WithDefaultTimeoutFunc                func(d time.Duration) *docker.Client
+	WithTransportFunc                     func(trFunc func() *http.Transport)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ docker.DockerClient = &MockDockerClient{}
+
+// ActivateNode calls ActivateNodeFunc, if set, and records the call.
+func (m *MockDockerClient) ActivateNode(id string) error {
+	m.record("ActivateNode", []interface{}{id})
+	if m.ActivateNodeFunc != nil {
+		return m.ActivateNodeFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// AddEventListener calls AddEventListenerFunc, if set, and records the call.
+func (m *MockDockerClient) AddEventListener(listener chan<- *docker.APIEvents) error {
+	m.record("AddEventListener", []interface{}{listener})
+	if m.AddEventListenerFunc != nil {
+		return m.AddEventListenerFunc(listener)
+	}
+	var r0 error
+	return r0
+}
+
+// ApplyOptions calls ApplyOptionsFunc, if set, and records the call.
+func (m *MockDockerClient) ApplyOptions(opts ...docker.ClientOption) error {
+	m.record("ApplyOptions", []interface{}{opts})
+	if m.ApplyOptionsFunc != nil {
+		return m.ApplyOptionsFunc(opts...)
+	}
+	var r0 error
+	return r0
+}
+
+// AttachAndWait calls AttachAndWaitFunc, if set, and records the call.
+func (m *MockDockerClient) AttachAndWait(id string, stdout io.Writer, stderr io.Writer, ctx context.Context) (int, error) {
+	m.record("AttachAndWait", []interface{}{id, stdout, stderr, ctx})
+	if m.AttachAndWaitFunc != nil {
+		return m.AttachAndWaitFunc(id, stdout, stderr, ctx)
+	}
+	var r0 int
+	var r1 error
+	return r0, r1
+}
+
+// AttachToContainer calls AttachToContainerFunc, if set, and records the call.
+func (m *MockDockerClient) AttachToContainer(opts docker.AttachToContainerOptions) error {
+	m.record("AttachToContainer", []interface{}{opts})
+	if m.AttachToContainerFunc != nil {
+		return m.AttachToContainerFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// AttachToContainerDemux calls AttachToContainerDemuxFunc, if set, and records the call.
+func (m *MockDockerClient) AttachToContainerDemux(opts docker.AttachToContainerOptions) (io.ReadCloser, io.ReadCloser, io.WriteCloser, error) {
+	m.record("AttachToContainerDemux", []interface{}{opts})
+	if m.AttachToContainerDemuxFunc != nil {
+		return m.AttachToContainerDemuxFunc(opts)
+	}
+	var r0 io.ReadCloser
+	var r1 io.ReadCloser
+	var r2 io.WriteCloser
+	var r3 error
+	return r0, r1, r2, r3
+}
+
+// AttachToContainerNonBlocking calls AttachToContainerNonBlockingFunc, if set, and records the call.
+func (m *MockDockerClient) AttachToContainerNonBlocking(opts docker.AttachToContainerOptions) (docker.CloseWaiter, error) {
+	m.record("AttachToContainerNonBlocking", []interface{}{opts})
+	if m.AttachToContainerNonBlockingFunc != nil {
+		return m.AttachToContainerNonBlockingFunc(opts)
+	}
+	var r0 docker.CloseWaiter
+	var r1 error
+	return r0, r1
+}
+
+// AttachToContainerStream calls AttachToContainerStreamFunc, if set, and records the call.
+func (m *MockDockerClient) AttachToContainerStream(opts docker.AttachToContainerOptions) (io.ReadWriteCloser, error) {
+	m.record("AttachToContainerStream", []interface{}{opts})
+	if m.AttachToContainerStreamFunc != nil {
+		return m.AttachToContainerStreamFunc(opts)
+	}
+	var r0 io.ReadWriteCloser
+	var r1 error
+	return r0, r1
+}
+
+// AuthCheck calls AuthCheckFunc, if set, and records the call.
+func (m *MockDockerClient) AuthCheck(conf *docker.AuthConfiguration) (docker.AuthStatus, error) {
+	m.record("AuthCheck", []interface{}{conf})
+	if m.AuthCheckFunc != nil {
+		return m.AuthCheckFunc(conf)
+	}
+	var r0 docker.AuthStatus
+	var r1 error
+	return r0, r1
+}
+
+// BuildImage calls BuildImageFunc, if set, and records the call.
+func (m *MockDockerClient) BuildImage(opts docker.BuildImageOptions) error {
+	m.record("BuildImage", []interface{}{opts})
+	if m.BuildImageFunc != nil {
+		return m.BuildImageFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// CollectStats calls CollectStatsFunc, if set, and records the call.
+func (m *MockDockerClient) CollectStats(id string, interval time.Duration, ctx context.Context) (*docker.StatsCollector, error) {
+	m.record("CollectStats", []interface{}{id, interval, ctx})
+	if m.CollectStatsFunc != nil {
+		return m.CollectStatsFunc(id, interval, ctx)
+	}
+	var r0 *docker.StatsCollector
+	var r1 error
+	return r0, r1
+}
+
+// CommitContainer calls CommitContainerFunc, if set, and records the call.
+func (m *MockDockerClient) CommitContainer(opts docker.CommitContainerOptions) (*docker.Image, error) {
+	m.record("CommitContainer", []interface{}{opts})
+	if m.CommitContainerFunc != nil {
+		return m.CommitContainerFunc(opts)
+	}
+	var r0 *docker.Image
+	var r1 error
+	return r0, r1
+}
+
+// ConfigurePlugin calls ConfigurePluginFunc, if set, and records the call.
+func (m *MockDockerClient) ConfigurePlugin(opts docker.ConfigurePluginOptions) error {
+	m.record("ConfigurePlugin", []interface{}{opts})
+	if m.ConfigurePluginFunc != nil {
+		return m.ConfigurePluginFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ConnectNetwork calls ConnectNetworkFunc, if set, and records the call.
+func (m *MockDockerClient) ConnectNetwork(id string, opts docker.NetworkConnectionOptions) error {
+	m.record("ConnectNetwork", []interface{}{id, opts})
+	if m.ConnectNetworkFunc != nil {
+		return m.ConnectNetworkFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ContainerChanges calls ContainerChangesFunc, if set, and records the call.
+func (m *MockDockerClient) ContainerChanges(id string) ([]docker.Change, error) {
+	m.record("ContainerChanges", []interface{}{id})
+	if m.ContainerChangesFunc != nil {
+		return m.ContainerChangesFunc(id)
+	}
+	var r0 []docker.Change
+	var r1 error
+	return r0, r1
+}
+
+// ContainerLogsReader calls ContainerLogsReaderFunc, if set, and records the call.
+func (m *MockDockerClient) ContainerLogsReader(id string, opts docker.LogsOptions, ctx context.Context) (io.ReadCloser, error) {
+	m.record("ContainerLogsReader", []interface{}{id, opts, ctx})
+	if m.ContainerLogsReaderFunc != nil {
+		return m.ContainerLogsReaderFunc(id, opts, ctx)
+	}
+	var r0 io.ReadCloser
+	var r1 error
+	return r0, r1
+}
+
+// ContainersDiskUsage calls ContainersDiskUsageFunc, if set, and records the call.
+func (m *MockDockerClient) ContainersDiskUsage(opts docker.DiskUsageOptions) (map[string]int64, error) {
+	m.record("ContainersDiskUsage", []interface{}{opts})
+	if m.ContainersDiskUsageFunc != nil {
+		return m.ContainersDiskUsageFunc(opts)
+	}
+	var r0 map[string]int64
+	var r1 error
+	return r0, r1
+}
+
+// CopyFromContainer calls CopyFromContainerFunc, if set, and records the call.
+func (m *MockDockerClient) CopyFromContainer(opts docker.CopyFromContainerOptions) error {
+	m.record("CopyFromContainer", []interface{}{opts})
+	if m.CopyFromContainerFunc != nil {
+		return m.CopyFromContainerFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// CreateConfig calls CreateConfigFunc, if set, and records the call.
+func (m *MockDockerClient) CreateConfig(opts docker.CreateConfigOptions) (*swarm.Config, error) {
+	m.record("CreateConfig", []interface{}{opts})
+	if m.CreateConfigFunc != nil {
+		return m.CreateConfigFunc(opts)
+	}
+	var r0 *swarm.Config
+	var r1 error
+	return r0, r1
+}
+
+// CreateContainer calls CreateContainerFunc, if set, and records the call.
+func (m *MockDockerClient) CreateContainer(opts docker.CreateContainerOptions) (*docker.Container, error) {
+	m.record("CreateContainer", []interface{}{opts})
+	if m.CreateContainerFunc != nil {
+		return m.CreateContainerFunc(opts)
+	}
+	var r0 *docker.Container
+	var r1 error
+	return r0, r1
+}
+
+// CreateExec calls CreateExecFunc, if set, and records the call.
+func (m *MockDockerClient) CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error) {
+	m.record("CreateExec", []interface{}{opts})
+	if m.CreateExecFunc != nil {
+		return m.CreateExecFunc(opts)
+	}
+	var r0 *docker.Exec
+	var r1 error
+	return r0, r1
+}
+
+// CreateNetwork calls CreateNetworkFunc, if set, and records the call.
+func (m *MockDockerClient) CreateNetwork(opts docker.CreateNetworkOptions) (*docker.Network, error) {
+	m.record("CreateNetwork", []interface{}{opts})
+	if m.CreateNetworkFunc != nil {
+		return m.CreateNetworkFunc(opts)
+	}
+	var r0 *docker.Network
+	var r1 error
+	return r0, r1
+}
+
+// CreatePlugin calls CreatePluginFunc, if set, and records the call.
+func (m *MockDockerClient) CreatePlugin(opts docker.CreatePluginOptions) (string, error) {
+	m.record("CreatePlugin", []interface{}{opts})
+	if m.CreatePluginFunc != nil {
+		return m.CreatePluginFunc(opts)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// CreateSecret calls CreateSecretFunc, if set, and records the call.
+func (m *MockDockerClient) CreateSecret(opts docker.CreateSecretOptions) (*swarm.Secret, error) {
+	m.record("CreateSecret", []interface{}{opts})
+	if m.CreateSecretFunc != nil {
+		return m.CreateSecretFunc(opts)
+	}
+	var r0 *swarm.Secret
+	var r1 error
+	return r0, r1
+}
+
+// CreateService calls CreateServiceFunc, if set, and records the call.
+func (m *MockDockerClient) CreateService(opts docker.CreateServiceOptions) (*swarm.Service, error) {
+	m.record("CreateService", []interface{}{opts})
+	if m.CreateServiceFunc != nil {
+		return m.CreateServiceFunc(opts)
+	}
+	var r0 *swarm.Service
+	var r1 error
+	return r0, r1
+}
+
+// CreateVolume calls CreateVolumeFunc, if set, and records the call.
+func (m *MockDockerClient) CreateVolume(opts docker.CreateVolumeOptions) (*docker.Volume, error) {
+	m.record("CreateVolume", []interface{}{opts})
+	if m.CreateVolumeFunc != nil {
+		return m.CreateVolumeFunc(opts)
+	}
+	var r0 *docker.Volume
+	var r1 error
+	return r0, r1
+}
+
+// DemoteNode calls DemoteNodeFunc, if set, and records the call.
+func (m *MockDockerClient) DemoteNode(id string) error {
+	m.record("DemoteNode", []interface{}{id})
+	if m.DemoteNodeFunc != nil {
+		return m.DemoteNodeFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// DisablePlugin calls DisablePluginFunc, if set, and records the call.
+func (m *MockDockerClient) DisablePlugin(opts docker.DisablePluginOptions) error {
+	m.record("DisablePlugin", []interface{}{opts})
+	if m.DisablePluginFunc != nil {
+		return m.DisablePluginFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// DisconnectNetwork calls DisconnectNetworkFunc, if set, and records the call.
+func (m *MockDockerClient) DisconnectNetwork(id string, opts docker.NetworkConnectionOptions) error {
+	m.record("DisconnectNetwork", []interface{}{id, opts})
+	if m.DisconnectNetworkFunc != nil {
+		return m.DisconnectNetworkFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// DiskUsage calls DiskUsageFunc, if set, and records the call.
+func (m *MockDockerClient) DiskUsage(opts docker.DiskUsageOptions) (*docker.DiskUsage, error) {
+	m.record("DiskUsage", []interface{}{opts})
+	if m.DiskUsageFunc != nil {
+		return m.DiskUsageFunc(opts)
+	}
+	var r0 *docker.DiskUsage
+	var r1 error
+	return r0, r1
+}
+
+// DownloadFromContainer calls DownloadFromContainerFunc, if set, and records the call.
+func (m *MockDockerClient) DownloadFromContainer(id string, opts docker.DownloadFromContainerOptions) error {
+	m.record("DownloadFromContainer", []interface{}{id, opts})
+	if m.DownloadFromContainerFunc != nil {
+		return m.DownloadFromContainerFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// DrainNode calls DrainNodeFunc, if set, and records the call.
+func (m *MockDockerClient) DrainNode(id string) error {
+	m.record("DrainNode", []interface{}{id})
+	if m.DrainNodeFunc != nil {
+		return m.DrainNodeFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// EnablePlugin calls EnablePluginFunc, if set, and records the call.
+func (m *MockDockerClient) EnablePlugin(opts docker.EnablePluginOptions) error {
+	m.record("EnablePlugin", []interface{}{opts})
+	if m.EnablePluginFunc != nil {
+		return m.EnablePluginFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// Endpoint calls EndpointFunc, if set, and records the call.
+func (m *MockDockerClient) Endpoint() string {
+	m.record("Endpoint", []interface{}{})
+	if m.EndpointFunc != nil {
+		return m.EndpointFunc()
+	}
+	var r0 string
+	return r0
+}
+
+// ExecTimeout calls ExecTimeoutFunc, if set, and records the call.
+func (m *MockDockerClient) ExecTimeout(containerID string, timeout time.Duration, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, ctx context.Context) (int, error) {
+	m.record("ExecTimeout", []interface{}{containerID, timeout, cmd, stdin, stdout, stderr, ctx})
+	if m.ExecTimeoutFunc != nil {
+		return m.ExecTimeoutFunc(containerID, timeout, cmd, stdin, stdout, stderr, ctx)
+	}
+	var r0 int
+	var r1 error
+	return r0, r1
+}
+
+// ExportContainer calls ExportContainerFunc, if set, and records the call.
+func (m *MockDockerClient) ExportContainer(opts docker.ExportContainerOptions) error {
+	m.record("ExportContainer", []interface{}{opts})
+	if m.ExportContainerFunc != nil {
+		return m.ExportContainerFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ExportImage calls ExportImageFunc, if set, and records the call.
+func (m *MockDockerClient) ExportImage(opts docker.ExportImageOptions) error {
+	m.record("ExportImage", []interface{}{opts})
+	if m.ExportImageFunc != nil {
+		return m.ExportImageFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ExportImages calls ExportImagesFunc, if set, and records the call.
+func (m *MockDockerClient) ExportImages(opts docker.ExportImagesOptions) error {
+	m.record("ExportImages", []interface{}{opts})
+	if m.ExportImagesFunc != nil {
+		return m.ExportImagesFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// FilteredListNetworks calls FilteredListNetworksFunc, if set, and records the call.
+func (m *MockDockerClient) FilteredListNetworks(opts docker.NetworkFilterOpts) ([]docker.Network, error) {
+	m.record("FilteredListNetworks", []interface{}{opts})
+	if m.FilteredListNetworksFunc != nil {
+		return m.FilteredListNetworksFunc(opts)
+	}
+	var r0 []docker.Network
+	var r1 error
+	return r0, r1
+}
+
+// ForceRedeployService calls ForceRedeployServiceFunc, if set, and records the call.
+func (m *MockDockerClient) ForceRedeployService(serviceID string, ctx context.Context) error {
+	m.record("ForceRedeployService", []interface{}{serviceID, ctx})
+	if m.ForceRedeployServiceFunc != nil {
+		return m.ForceRedeployServiceFunc(serviceID, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// FreezeContainerFS calls FreezeContainerFSFunc, if set, and records the call.
+func (m *MockDockerClient) FreezeContainerFS(id string, ctx context.Context) error {
+	m.record("FreezeContainerFS", []interface{}{id, ctx})
+	if m.FreezeContainerFSFunc != nil {
+		return m.FreezeContainerFSFunc(id, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// GetCgroupVersion calls GetCgroupVersionFunc, if set, and records the call.
+func (m *MockDockerClient) GetCgroupVersion(ctx context.Context) (int, error) {
+	m.record("GetCgroupVersion", []interface{}{ctx})
+	if m.GetCgroupVersionFunc != nil {
+		return m.GetCgroupVersionFunc(ctx)
+	}
+	var r0 int
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerAppArmorProfile calls GetContainerAppArmorProfileFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerAppArmorProfile(id string, ctx context.Context) (string, error) {
+	m.record("GetContainerAppArmorProfile", []interface{}{id, ctx})
+	if m.GetContainerAppArmorProfileFunc != nil {
+		return m.GetContainerAppArmorProfileFunc(id, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerCFSQuota calls GetContainerCFSQuotaFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerCFSQuota(id string, ctx context.Context) (int64, int64, error) {
+	m.record("GetContainerCFSQuota", []interface{}{id, ctx})
+	if m.GetContainerCFSQuotaFunc != nil {
+		return m.GetContainerCFSQuotaFunc(id, ctx)
+	}
+	var r0 int64
+	var r1 int64
+	var r2 error
+	return r0, r1, r2
+}
+
+// GetContainerEnvVar calls GetContainerEnvVarFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerEnvVar(id string, key string, ctx context.Context) (string, bool, error) {
+	m.record("GetContainerEnvVar", []interface{}{id, key, ctx})
+	if m.GetContainerEnvVarFunc != nil {
+		return m.GetContainerEnvVarFunc(id, key, ctx)
+	}
+	var r0 string
+	var r1 bool
+	var r2 error
+	return r0, r1, r2
+}
+
+// GetContainerLogsStructured calls GetContainerLogsStructuredFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerLogsStructured(id string, opts docker.LogsOptions) ([]docker.LogEntry, error) {
+	m.record("GetContainerLogsStructured", []interface{}{id, opts})
+	if m.GetContainerLogsStructuredFunc != nil {
+		return m.GetContainerLogsStructuredFunc(id, opts)
+	}
+	var r0 []docker.LogEntry
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerNetNamespace calls GetContainerNetNamespaceFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerNetNamespace(id string, ctx context.Context) (uint64, error) {
+	m.record("GetContainerNetNamespace", []interface{}{id, ctx})
+	if m.GetContainerNetNamespaceFunc != nil {
+		return m.GetContainerNetNamespaceFunc(id, ctx)
+	}
+	var r0 uint64
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerNetworkStats calls GetContainerNetworkStatsFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerNetworkStats(id string, interfaceName string, ctx context.Context) (*docker.NetworkStats, error) {
+	m.record("GetContainerNetworkStats", []interface{}{id, interfaceName, ctx})
+	if m.GetContainerNetworkStatsFunc != nil {
+		return m.GetContainerNetworkStatsFunc(id, interfaceName, ctx)
+	}
+	var r0 *docker.NetworkStats
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerNodeID calls GetContainerNodeIDFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerNodeID(containerID string, ctx context.Context) (string, error) {
+	m.record("GetContainerNodeID", []interface{}{containerID, ctx})
+	if m.GetContainerNodeIDFunc != nil {
+		return m.GetContainerNodeIDFunc(containerID, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerPIDNamespace calls GetContainerPIDNamespaceFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerPIDNamespace(id string, ctx context.Context) (uint64, error) {
+	m.record("GetContainerPIDNamespace", []interface{}{id, ctx})
+	if m.GetContainerPIDNamespaceFunc != nil {
+		return m.GetContainerPIDNamespaceFunc(id, ctx)
+	}
+	var r0 uint64
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerPortInfo calls GetContainerPortInfoFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerPortInfo(id string, ctx context.Context) (*docker.PortInfo, error) {
+	m.record("GetContainerPortInfo", []interface{}{id, ctx})
+	if m.GetContainerPortInfoFunc != nil {
+		return m.GetContainerPortInfoFunc(id, ctx)
+	}
+	var r0 *docker.PortInfo
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerRootFSPath calls GetContainerRootFSPathFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerRootFSPath(id string, ctx context.Context) (string, error) {
+	m.record("GetContainerRootFSPath", []interface{}{id, ctx})
+	if m.GetContainerRootFSPathFunc != nil {
+		return m.GetContainerRootFSPathFunc(id, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerSeccompProfile calls GetContainerSeccompProfileFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerSeccompProfile(id string, ctx context.Context) (string, error) {
+	m.record("GetContainerSeccompProfile", []interface{}{id, ctx})
+	if m.GetContainerSeccompProfileFunc != nil {
+		return m.GetContainerSeccompProfileFunc(id, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerServiceID calls GetContainerServiceIDFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerServiceID(containerID string, ctx context.Context) (string, error) {
+	m.record("GetContainerServiceID", []interface{}{containerID, ctx})
+	if m.GetContainerServiceIDFunc != nil {
+		return m.GetContainerServiceIDFunc(containerID, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerStatsOnce calls GetContainerStatsOnceFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerStatsOnce(id string, ctx context.Context) (*docker.Stats, error) {
+	m.record("GetContainerStatsOnce", []interface{}{id, ctx})
+	if m.GetContainerStatsOnceFunc != nil {
+		return m.GetContainerStatsOnceFunc(id, ctx)
+	}
+	var r0 *docker.Stats
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerTaskID calls GetContainerTaskIDFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerTaskID(containerID string, ctx context.Context) (string, error) {
+	m.record("GetContainerTaskID", []interface{}{containerID, ctx})
+	if m.GetContainerTaskIDFunc != nil {
+		return m.GetContainerTaskIDFunc(containerID, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerTiming calls GetContainerTimingFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerTiming(id string, ctx context.Context) (*docker.ContainerTiming, error) {
+	m.record("GetContainerTiming", []interface{}{id, ctx})
+	if m.GetContainerTimingFunc != nil {
+		return m.GetContainerTimingFunc(id, ctx)
+	}
+	var r0 *docker.ContainerTiming
+	var r1 error
+	return r0, r1
+}
+
+// GetContainerWritableLayerSize calls GetContainerWritableLayerSizeFunc, if set, and records the call.
+func (m *MockDockerClient) GetContainerWritableLayerSize(id string, ctx context.Context) (int64, error) {
+	m.record("GetContainerWritableLayerSize", []interface{}{id, ctx})
+	if m.GetContainerWritableLayerSizeFunc != nil {
+		return m.GetContainerWritableLayerSizeFunc(id, ctx)
+	}
+	var r0 int64
+	var r1 error
+	return r0, r1
+}
+
+// GetDaemonMetrics calls GetDaemonMetricsFunc, if set, and records the call.
+func (m *MockDockerClient) GetDaemonMetrics(ctx context.Context) (map[string]float64, error) {
+	m.record("GetDaemonMetrics", []interface{}{ctx})
+	if m.GetDaemonMetricsFunc != nil {
+		return m.GetDaemonMetricsFunc(ctx)
+	}
+	var r0 map[string]float64
+	var r1 error
+	return r0, r1
+}
+
+// GetImageBaseImage calls GetImageBaseImageFunc, if set, and records the call.
+func (m *MockDockerClient) GetImageBaseImage(nameOrID string, ctx context.Context) (string, error) {
+	m.record("GetImageBaseImage", []interface{}{nameOrID, ctx})
+	if m.GetImageBaseImageFunc != nil {
+		return m.GetImageBaseImageFunc(nameOrID, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// GetImageLayerCount calls GetImageLayerCountFunc, if set, and records the call.
+func (m *MockDockerClient) GetImageLayerCount(nameOrID string, ctx context.Context) (int, error) {
+	m.record("GetImageLayerCount", []interface{}{nameOrID, ctx})
+	if m.GetImageLayerCountFunc != nil {
+		return m.GetImageLayerCountFunc(nameOrID, ctx)
+	}
+	var r0 int
+	var r1 error
+	return r0, r1
+}
+
+// GetPluginPrivileges calls GetPluginPrivilegesFunc, if set, and records the call.
+func (m *MockDockerClient) GetPluginPrivileges(name string, ctx context.Context) ([]docker.PluginPrivilege, error) {
+	m.record("GetPluginPrivileges", []interface{}{name, ctx})
+	if m.GetPluginPrivilegesFunc != nil {
+		return m.GetPluginPrivilegesFunc(name, ctx)
+	}
+	var r0 []docker.PluginPrivilege
+	var r1 error
+	return r0, r1
+}
+
+// GetRegistryMirrors calls GetRegistryMirrorsFunc, if set, and records the call.
+func (m *MockDockerClient) GetRegistryMirrors(ctx context.Context) ([]string, error) {
+	m.record("GetRegistryMirrors", []interface{}{ctx})
+	if m.GetRegistryMirrorsFunc != nil {
+		return m.GetRegistryMirrorsFunc(ctx)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// GetServiceLogs calls GetServiceLogsFunc, if set, and records the call.
+func (m *MockDockerClient) GetServiceLogs(opts docker.LogsServiceOptions) error {
+	m.record("GetServiceLogs", []interface{}{opts})
+	if m.GetServiceLogsFunc != nil {
+		return m.GetServiceLogsFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// GetStorageDriverInfo calls GetStorageDriverInfoFunc, if set, and records the call.
+func (m *MockDockerClient) GetStorageDriverInfo(ctx context.Context) (*docker.StorageDriverInfo, error) {
+	m.record("GetStorageDriverInfo", []interface{}{ctx})
+	if m.GetStorageDriverInfoFunc != nil {
+		return m.GetStorageDriverInfoFunc(ctx)
+	}
+	var r0 *docker.StorageDriverInfo
+	var r1 error
+	return r0, r1
+}
+
+// ImageExists calls ImageExistsFunc, if set, and records the call.
+func (m *MockDockerClient) ImageExists(name string) (bool, error) {
+	m.record("ImageExists", []interface{}{name})
+	if m.ImageExistsFunc != nil {
+		return m.ImageExistsFunc(name)
+	}
+	var r0 bool
+	var r1 error
+	return r0, r1
+}
+
+// ImageHistory calls ImageHistoryFunc, if set, and records the call.
+func (m *MockDockerClient) ImageHistory(name string) ([]docker.ImageHistory, error) {
+	m.record("ImageHistory", []interface{}{name})
+	if m.ImageHistoryFunc != nil {
+		return m.ImageHistoryFunc(name)
+	}
+	var r0 []docker.ImageHistory
+	var r1 error
+	return r0, r1
+}
+
+// ImportImage calls ImportImageFunc, if set, and records the call.
+func (m *MockDockerClient) ImportImage(opts docker.ImportImageOptions) error {
+	m.record("ImportImage", []interface{}{opts})
+	if m.ImportImageFunc != nil {
+		return m.ImportImageFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ImportImageFromReader calls ImportImageFromReaderFunc, if set, and records the call.
+func (m *MockDockerClient) ImportImageFromReader(r io.Reader, repo string, tag string, w io.Writer, ctx context.Context) (string, error) {
+	m.record("ImportImageFromReader", []interface{}{r, repo, tag, w, ctx})
+	if m.ImportImageFromReaderFunc != nil {
+		return m.ImportImageFromReaderFunc(r, repo, tag, w, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// ImportImageFromTar calls ImportImageFromTarFunc, if set, and records the call.
+func (m *MockDockerClient) ImportImageFromTar(tarPath string, repo string, tag string, config *docker.Config, ctx context.Context) (string, error) {
+	m.record("ImportImageFromTar", []interface{}{tarPath, repo, tag, config, ctx})
+	if m.ImportImageFromTarFunc != nil {
+		return m.ImportImageFromTarFunc(tarPath, repo, tag, config, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// ImportImageFromURL calls ImportImageFromURLFunc, if set, and records the call.
+func (m *MockDockerClient) ImportImageFromURL(url string, repo string, tag string, w io.Writer, ctx context.Context) (string, error) {
+	m.record("ImportImageFromURL", []interface{}{url, repo, tag, w, ctx})
+	if m.ImportImageFromURLFunc != nil {
+		return m.ImportImageFromURLFunc(url, repo, tag, w, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// Info calls InfoFunc, if set, and records the call.
+func (m *MockDockerClient) Info() (*docker.DockerInfo, error) {
+	m.record("Info", []interface{}{})
+	if m.InfoFunc != nil {
+		return m.InfoFunc()
+	}
+	var r0 *docker.DockerInfo
+	var r1 error
+	return r0, r1
+}
+
+// InfoWithContext calls InfoWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) InfoWithContext(ctx context.Context) (*docker.DockerInfo, error) {
+	m.record("InfoWithContext", []interface{}{ctx})
+	if m.InfoWithContextFunc != nil {
+		return m.InfoWithContextFunc(ctx)
+	}
+	var r0 *docker.DockerInfo
+	var r1 error
+	return r0, r1
+}
+
+// InitSwarm calls InitSwarmFunc, if set, and records the call.
+func (m *MockDockerClient) InitSwarm(opts docker.InitSwarmOptions) (string, error) {
+	m.record("InitSwarm", []interface{}{opts})
+	if m.InitSwarmFunc != nil {
+		return m.InitSwarmFunc(opts)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// InitSwarmWithTokens calls InitSwarmWithTokensFunc, if set, and records the call.
+func (m *MockDockerClient) InitSwarmWithTokens(opts docker.InitSwarmOptions) (*docker.InitSwarmResult, error) {
+	m.record("InitSwarmWithTokens", []interface{}{opts})
+	if m.InitSwarmWithTokensFunc != nil {
+		return m.InitSwarmWithTokensFunc(opts)
+	}
+	var r0 *docker.InitSwarmResult
+	var r1 error
+	return r0, r1
+}
+
+// InspectConfig calls InspectConfigFunc, if set, and records the call.
+func (m *MockDockerClient) InspectConfig(id string) (*swarm.Config, error) {
+	m.record("InspectConfig", []interface{}{id})
+	if m.InspectConfigFunc != nil {
+		return m.InspectConfigFunc(id)
+	}
+	var r0 *swarm.Config
+	var r1 error
+	return r0, r1
+}
+
+// InspectContainer calls InspectContainerFunc, if set, and records the call.
+func (m *MockDockerClient) InspectContainer(id string) (*docker.Container, error) {
+	m.record("InspectContainer", []interface{}{id})
+	if m.InspectContainerFunc != nil {
+		return m.InspectContainerFunc(id)
+	}
+	var r0 *docker.Container
+	var r1 error
+	return r0, r1
+}
+
+// InspectContainerRaw calls InspectContainerRawFunc, if set, and records the call.
+func (m *MockDockerClient) InspectContainerRaw(id string) (*docker.Container, json.RawMessage, error) {
+	m.record("InspectContainerRaw", []interface{}{id})
+	if m.InspectContainerRawFunc != nil {
+		return m.InspectContainerRawFunc(id)
+	}
+	var r0 *docker.Container
+	var r1 json.RawMessage
+	var r2 error
+	return r0, r1, r2
+}
+
+// InspectContainerStats calls InspectContainerStatsFunc, if set, and records the call.
+func (m *MockDockerClient) InspectContainerStats(id string, ctx context.Context) (*docker.Stats, error) {
+	m.record("InspectContainerStats", []interface{}{id, ctx})
+	if m.InspectContainerStatsFunc != nil {
+		return m.InspectContainerStatsFunc(id, ctx)
+	}
+	var r0 *docker.Stats
+	var r1 error
+	return r0, r1
+}
+
+// InspectContainerWithContext calls InspectContainerWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) InspectContainerWithContext(id string, ctx context.Context) (*docker.Container, error) {
+	m.record("InspectContainerWithContext", []interface{}{id, ctx})
+	if m.InspectContainerWithContextFunc != nil {
+		return m.InspectContainerWithContextFunc(id, ctx)
+	}
+	var r0 *docker.Container
+	var r1 error
+	return r0, r1
+}
+
+// InspectDistribution calls InspectDistributionFunc, if set, and records the call.
+func (m *MockDockerClient) InspectDistribution(name string) (*registry.DistributionInspect, error) {
+	m.record("InspectDistribution", []interface{}{name})
+	if m.InspectDistributionFunc != nil {
+		return m.InspectDistributionFunc(name)
+	}
+	var r0 *registry.DistributionInspect
+	var r1 error
+	return r0, r1
+}
+
+// InspectDistributionWithAuth calls InspectDistributionWithAuthFunc, if set, and records the call.
+func (m *MockDockerClient) InspectDistributionWithAuth(name string, auth docker.AuthConfiguration) (*registry.DistributionInspect, error) {
+	m.record("InspectDistributionWithAuth", []interface{}{name, auth})
+	if m.InspectDistributionWithAuthFunc != nil {
+		return m.InspectDistributionWithAuthFunc(name, auth)
+	}
+	var r0 *registry.DistributionInspect
+	var r1 error
+	return r0, r1
+}
+
+// InspectExec calls InspectExecFunc, if set, and records the call.
+func (m *MockDockerClient) InspectExec(id string) (*docker.ExecInspect, error) {
+	m.record("InspectExec", []interface{}{id})
+	if m.InspectExecFunc != nil {
+		return m.InspectExecFunc(id)
+	}
+	var r0 *docker.ExecInspect
+	var r1 error
+	return r0, r1
+}
+
+// InspectImage calls InspectImageFunc, if set, and records the call.
+func (m *MockDockerClient) InspectImage(name string) (*docker.Image, error) {
+	m.record("InspectImage", []interface{}{name})
+	if m.InspectImageFunc != nil {
+		return m.InspectImageFunc(name)
+	}
+	var r0 *docker.Image
+	var r1 error
+	return r0, r1
+}
+
+// InspectImageRaw calls InspectImageRawFunc, if set, and records the call.
+func (m *MockDockerClient) InspectImageRaw(name string) (*docker.Image, json.RawMessage, error) {
+	m.record("InspectImageRaw", []interface{}{name})
+	if m.InspectImageRawFunc != nil {
+		return m.InspectImageRawFunc(name)
+	}
+	var r0 *docker.Image
+	var r1 json.RawMessage
+	var r2 error
+	return r0, r1, r2
+}
+
+// InspectNode calls InspectNodeFunc, if set, and records the call.
+func (m *MockDockerClient) InspectNode(id string) (*swarm.Node, error) {
+	m.record("InspectNode", []interface{}{id})
+	if m.InspectNodeFunc != nil {
+		return m.InspectNodeFunc(id)
+	}
+	var r0 *swarm.Node
+	var r1 error
+	return r0, r1
+}
+
+// InspectPlugins calls InspectPluginsFunc, if set, and records the call.
+func (m *MockDockerClient) InspectPlugins(name string, ctx context.Context) (*docker.PluginDetail, error) {
+	m.record("InspectPlugins", []interface{}{name, ctx})
+	if m.InspectPluginsFunc != nil {
+		return m.InspectPluginsFunc(name, ctx)
+	}
+	var r0 *docker.PluginDetail
+	var r1 error
+	return r0, r1
+}
+
+// InspectSecret calls InspectSecretFunc, if set, and records the call.
+func (m *MockDockerClient) InspectSecret(id string) (*swarm.Secret, error) {
+	m.record("InspectSecret", []interface{}{id})
+	if m.InspectSecretFunc != nil {
+		return m.InspectSecretFunc(id)
+	}
+	var r0 *swarm.Secret
+	var r1 error
+	return r0, r1
+}
+
+// InspectService calls InspectServiceFunc, if set, and records the call.
+func (m *MockDockerClient) InspectService(id string) (*swarm.Service, error) {
+	m.record("InspectService", []interface{}{id})
+	if m.InspectServiceFunc != nil {
+		return m.InspectServiceFunc(id)
+	}
+	var r0 *swarm.Service
+	var r1 error
+	return r0, r1
+}
+
+// InspectSwarm calls InspectSwarmFunc, if set, and records the call.
+func (m *MockDockerClient) InspectSwarm(ctx context.Context) (swarm.Swarm, error) {
+	m.record("InspectSwarm", []interface{}{ctx})
+	if m.InspectSwarmFunc != nil {
+		return m.InspectSwarmFunc(ctx)
+	}
+	var r0 swarm.Swarm
+	var r1 error
+	return r0, r1
+}
+
+// InspectTask calls InspectTaskFunc, if set, and records the call.
+func (m *MockDockerClient) InspectTask(id string) (*swarm.Task, error) {
+	m.record("InspectTask", []interface{}{id})
+	if m.InspectTaskFunc != nil {
+		return m.InspectTaskFunc(id)
+	}
+	var r0 *swarm.Task
+	var r1 error
+	return r0, r1
+}
+
+// InspectVolume calls InspectVolumeFunc, if set, and records the call.
+func (m *MockDockerClient) InspectVolume(name string) (*docker.Volume, error) {
+	m.record("InspectVolume", []interface{}{name})
+	if m.InspectVolumeFunc != nil {
+		return m.InspectVolumeFunc(name)
+	}
+	var r0 *docker.Volume
+	var r1 error
+	return r0, r1
+}
+
+// InstallPlugin calls InstallPluginFunc, if set, and records the call.
+func (m *MockDockerClient) InstallPlugin(opts docker.InstallPluginOptions) (string, error) {
+	m.record("InstallPlugin", []interface{}{opts})
+	if m.InstallPluginFunc != nil {
+		return m.InstallPluginFunc(opts)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// InstallPlugins calls InstallPluginsFunc, if set, and records the call.
+func (m *MockDockerClient) InstallPlugins(opts docker.InstallPluginOptions) error {
+	m.record("InstallPlugins", []interface{}{opts})
+	if m.InstallPluginsFunc != nil {
+		return m.InstallPluginsFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// JoinSwarm calls JoinSwarmFunc, if set, and records the call.
+func (m *MockDockerClient) JoinSwarm(opts docker.JoinSwarmOptions) error {
+	m.record("JoinSwarm", []interface{}{opts})
+	if m.JoinSwarmFunc != nil {
+		return m.JoinSwarmFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// KillContainer calls KillContainerFunc, if set, and records the call.
+func (m *MockDockerClient) KillContainer(opts docker.KillContainerOptions) error {
+	m.record("KillContainer", []interface{}{opts})
+	if m.KillContainerFunc != nil {
+		return m.KillContainerFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// LeaveSwarm calls LeaveSwarmFunc, if set, and records the call.
+func (m *MockDockerClient) LeaveSwarm(opts docker.LeaveSwarmOptions) error {
+	m.record("LeaveSwarm", []interface{}{opts})
+	if m.LeaveSwarmFunc != nil {
+		return m.LeaveSwarmFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ListConfigs calls ListConfigsFunc, if set, and records the call.
+func (m *MockDockerClient) ListConfigs(opts docker.ListConfigsOptions) ([]swarm.Config, error) {
+	m.record("ListConfigs", []interface{}{opts})
+	if m.ListConfigsFunc != nil {
+		return m.ListConfigsFunc(opts)
+	}
+	var r0 []swarm.Config
+	var r1 error
+	return r0, r1
+}
+
+// ListContainerNetworkInterfaces calls ListContainerNetworkInterfacesFunc, if set, and records the call.
+func (m *MockDockerClient) ListContainerNetworkInterfaces(id string, ctx context.Context) ([]string, error) {
+	m.record("ListContainerNetworkInterfaces", []interface{}{id, ctx})
+	if m.ListContainerNetworkInterfacesFunc != nil {
+		return m.ListContainerNetworkInterfacesFunc(id, ctx)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// ListContainers calls ListContainersFunc, if set, and records the call.
+func (m *MockDockerClient) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	m.record("ListContainers", []interface{}{opts})
+	if m.ListContainersFunc != nil {
+		return m.ListContainersFunc(opts)
+	}
+	var r0 []docker.APIContainers
+	var r1 error
+	return r0, r1
+}
+
+// ListContainersByVolume calls ListContainersByVolumeFunc, if set, and records the call.
+func (m *MockDockerClient) ListContainersByVolume(volumeName string, ctx context.Context) ([]docker.APIContainers, error) {
+	m.record("ListContainersByVolume", []interface{}{volumeName, ctx})
+	if m.ListContainersByVolumeFunc != nil {
+		return m.ListContainersByVolumeFunc(volumeName, ctx)
+	}
+	var r0 []docker.APIContainers
+	var r1 error
+	return r0, r1
+}
+
+// ListFilteredPlugins calls ListFilteredPluginsFunc, if set, and records the call.
+func (m *MockDockerClient) ListFilteredPlugins(opts docker.ListFilteredPluginsOptions) ([]docker.PluginDetail, error) {
+	m.record("ListFilteredPlugins", []interface{}{opts})
+	if m.ListFilteredPluginsFunc != nil {
+		return m.ListFilteredPluginsFunc(opts)
+	}
+	var r0 []docker.PluginDetail
+	var r1 error
+	return r0, r1
+}
+
+// ListImages calls ListImagesFunc, if set, and records the call.
+func (m *MockDockerClient) ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error) {
+	m.record("ListImages", []interface{}{opts})
+	if m.ListImagesFunc != nil {
+		return m.ListImagesFunc(opts)
+	}
+	var r0 []docker.APIImages
+	var r1 error
+	return r0, r1
+}
+
+// ListNetworks calls ListNetworksFunc, if set, and records the call.
+func (m *MockDockerClient) ListNetworks() ([]docker.Network, error) {
+	m.record("ListNetworks", []interface{}{})
+	if m.ListNetworksFunc != nil {
+		return m.ListNetworksFunc()
+	}
+	var r0 []docker.Network
+	var r1 error
+	return r0, r1
+}
+
+// ListNodes calls ListNodesFunc, if set, and records the call.
+func (m *MockDockerClient) ListNodes(opts docker.ListNodesOptions) ([]swarm.Node, error) {
+	m.record("ListNodes", []interface{}{opts})
+	if m.ListNodesFunc != nil {
+		return m.ListNodesFunc(opts)
+	}
+	var r0 []swarm.Node
+	var r1 error
+	return r0, r1
+}
+
+// ListPlugins calls ListPluginsFunc, if set, and records the call.
+func (m *MockDockerClient) ListPlugins(ctx context.Context) ([]docker.PluginDetail, error) {
+	m.record("ListPlugins", []interface{}{ctx})
+	if m.ListPluginsFunc != nil {
+		return m.ListPluginsFunc(ctx)
+	}
+	var r0 []docker.PluginDetail
+	var r1 error
+	return r0, r1
+}
+
+// ListSecrets calls ListSecretsFunc, if set, and records the call.
+func (m *MockDockerClient) ListSecrets(opts docker.ListSecretsOptions) ([]swarm.Secret, error) {
+	m.record("ListSecrets", []interface{}{opts})
+	if m.ListSecretsFunc != nil {
+		return m.ListSecretsFunc(opts)
+	}
+	var r0 []swarm.Secret
+	var r1 error
+	return r0, r1
+}
+
+// ListServices calls ListServicesFunc, if set, and records the call.
+func (m *MockDockerClient) ListServices(opts docker.ListServicesOptions) ([]swarm.Service, error) {
+	m.record("ListServices", []interface{}{opts})
+	if m.ListServicesFunc != nil {
+		return m.ListServicesFunc(opts)
+	}
+	var r0 []swarm.Service
+	var r1 error
+	return r0, r1
+}
+
+// ListTasks calls ListTasksFunc, if set, and records the call.
+func (m *MockDockerClient) ListTasks(opts docker.ListTasksOptions) ([]swarm.Task, error) {
+	m.record("ListTasks", []interface{}{opts})
+	if m.ListTasksFunc != nil {
+		return m.ListTasksFunc(opts)
+	}
+	var r0 []swarm.Task
+	var r1 error
+	return r0, r1
+}
+
+// ListVolumes calls ListVolumesFunc, if set, and records the call.
+func (m *MockDockerClient) ListVolumes(opts docker.ListVolumesOptions) ([]docker.Volume, error) {
+	m.record("ListVolumes", []interface{}{opts})
+	if m.ListVolumesFunc != nil {
+		return m.ListVolumesFunc(opts)
+	}
+	var r0 []docker.Volume
+	var r1 error
+	return r0, r1
+}
+
+// ListenEvents calls ListenEventsFunc, if set, and records the call.
+func (m *MockDockerClient) ListenEvents(opts docker.EventsOptions, ctx context.Context) (<-chan docker.APIEvents, <-chan error) {
+	m.record("ListenEvents", []interface{}{opts, ctx})
+	if m.ListenEventsFunc != nil {
+		return m.ListenEventsFunc(opts, ctx)
+	}
+	var r0 <-chan docker.APIEvents
+	var r1 <-chan error
+	return r0, r1
+}
+
+// LoadImage calls LoadImageFunc, if set, and records the call.
+func (m *MockDockerClient) LoadImage(opts docker.LoadImageOptions) error {
+	m.record("LoadImage", []interface{}{opts})
+	if m.LoadImageFunc != nil {
+		return m.LoadImageFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// Logs calls LogsFunc, if set, and records the call.
+func (m *MockDockerClient) Logs(opts docker.LogsOptions) error {
+	m.record("Logs", []interface{}{opts})
+	if m.LogsFunc != nil {
+		return m.LogsFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// MeasureContainerNetworkThroughput calls MeasureContainerNetworkThroughputFunc, if set, and records the call.
+func (m *MockDockerClient) MeasureContainerNetworkThroughput(id string, duration time.Duration, ctx context.Context) (*docker.ThroughputMeasurement, error) {
+	m.record("MeasureContainerNetworkThroughput", []interface{}{id, duration, ctx})
+	if m.MeasureContainerNetworkThroughputFunc != nil {
+		return m.MeasureContainerNetworkThroughputFunc(id, duration, ctx)
+	}
+	var r0 *docker.ThroughputMeasurement
+	var r1 error
+	return r0, r1
+}
+
+// ModifyNode calls ModifyNodeFunc, if set, and records the call.
+func (m *MockDockerClient) ModifyNode(id string, opts docker.ModifyNodeOptions) error {
+	m.record("ModifyNode", []interface{}{id, opts})
+	if m.ModifyNodeFunc != nil {
+		return m.ModifyNodeFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ModifyService calls ModifyServiceFunc, if set, and records the call.
+func (m *MockDockerClient) ModifyService(id string, opts docker.ModifyServiceOptions) error {
+	m.record("ModifyService", []interface{}{id, opts})
+	if m.ModifyServiceFunc != nil {
+		return m.ModifyServiceFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// MultiLogs calls MultiLogsFunc, if set, and records the call.
+func (m *MockDockerClient) MultiLogs(specs []docker.LogSpec, out io.Writer, ctx context.Context) error {
+	m.record("MultiLogs", []interface{}{specs, out, ctx})
+	if m.MultiLogsFunc != nil {
+		return m.MultiLogsFunc(specs, out, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// NegotiateAPIVersion calls NegotiateAPIVersionFunc, if set, and records the call.
+func (m *MockDockerClient) NegotiateAPIVersion() error {
+	m.record("NegotiateAPIVersion", []interface{}{})
+	if m.NegotiateAPIVersionFunc != nil {
+		return m.NegotiateAPIVersionFunc()
+	}
+	var r0 error
+	return r0
+}
+
+// NegotiatedAPIVersion calls NegotiatedAPIVersionFunc, if set, and records the call.
+func (m *MockDockerClient) NegotiatedAPIVersion() string {
+	m.record("NegotiatedAPIVersion", []interface{}{})
+	if m.NegotiatedAPIVersionFunc != nil {
+		return m.NegotiatedAPIVersionFunc()
+	}
+	var r0 string
+	return r0
+}
+
+// NetworkByName calls NetworkByNameFunc, if set, and records the call.
+func (m *MockDockerClient) NetworkByName(name string, ctx context.Context) (*docker.Network, error) {
+	m.record("NetworkByName", []interface{}{name, ctx})
+	if m.NetworkByNameFunc != nil {
+		return m.NetworkByNameFunc(name, ctx)
+	}
+	var r0 *docker.Network
+	var r1 error
+	return r0, r1
+}
+
+// NetworkIDByName calls NetworkIDByNameFunc, if set, and records the call.
+func (m *MockDockerClient) NetworkIDByName(name string, ctx context.Context) (string, error) {
+	m.record("NetworkIDByName", []interface{}{name, ctx})
+	if m.NetworkIDByNameFunc != nil {
+		return m.NetworkIDByNameFunc(name, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// NetworkInfo calls NetworkInfoFunc, if set, and records the call.
+func (m *MockDockerClient) NetworkInfo(id string) (*docker.Network, error) {
+	m.record("NetworkInfo", []interface{}{id})
+	if m.NetworkInfoFunc != nil {
+		return m.NetworkInfoFunc(id)
+	}
+	var r0 *docker.Network
+	var r1 error
+	return r0, r1
+}
+
+// NetworkInfoRaw calls NetworkInfoRawFunc, if set, and records the call.
+func (m *MockDockerClient) NetworkInfoRaw(id string) (*docker.Network, json.RawMessage, error) {
+	m.record("NetworkInfoRaw", []interface{}{id})
+	if m.NetworkInfoRawFunc != nil {
+		return m.NetworkInfoRawFunc(id)
+	}
+	var r0 *docker.Network
+	var r1 json.RawMessage
+	var r2 error
+	return r0, r1, r2
+}
+
+// PauseContainer calls PauseContainerFunc, if set, and records the call.
+func (m *MockDockerClient) PauseContainer(id string) error {
+	m.record("PauseContainer", []interface{}{id})
+	if m.PauseContainerFunc != nil {
+		return m.PauseContainerFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// PauseNode calls PauseNodeFunc, if set, and records the call.
+func (m *MockDockerClient) PauseNode(id string) error {
+	m.record("PauseNode", []interface{}{id})
+	if m.PauseNodeFunc != nil {
+		return m.PauseNodeFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// Ping calls PingFunc, if set, and records the call.
+func (m *MockDockerClient) Ping() error {
+	m.record("Ping", []interface{}{})
+	if m.PingFunc != nil {
+		return m.PingFunc()
+	}
+	var r0 error
+	return r0
+}
+
+// PingWithContext calls PingWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) PingWithContext(ctx context.Context) error {
+	m.record("PingWithContext", []interface{}{ctx})
+	if m.PingWithContextFunc != nil {
+		return m.PingWithContextFunc(ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// PingWithResponse calls PingWithResponseFunc, if set, and records the call.
+func (m *MockDockerClient) PingWithResponse(ctx context.Context) (*docker.PingResponse, error) {
+	m.record("PingWithResponse", []interface{}{ctx})
+	if m.PingWithResponseFunc != nil {
+		return m.PingWithResponseFunc(ctx)
+	}
+	var r0 *docker.PingResponse
+	var r1 error
+	return r0, r1
+}
+
+// PopulateEnvFromSecrets calls PopulateEnvFromSecretsFunc, if set, and records the call.
+func (m *MockDockerClient) PopulateEnvFromSecrets(containerID string, secretNames []string, ctx context.Context) ([]string, error) {
+	m.record("PopulateEnvFromSecrets", []interface{}{containerID, secretNames, ctx})
+	if m.PopulateEnvFromSecretsFunc != nil {
+		return m.PopulateEnvFromSecretsFunc(containerID, secretNames, ctx)
+	}
+	var r0 []string
+	var r1 error
+	return r0, r1
+}
+
+// PromoteNode calls PromoteNodeFunc, if set, and records the call.
+func (m *MockDockerClient) PromoteNode(id string) error {
+	m.record("PromoteNode", []interface{}{id})
+	if m.PromoteNodeFunc != nil {
+		return m.PromoteNodeFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// PruneContainers calls PruneContainersFunc, if set, and records the call.
+func (m *MockDockerClient) PruneContainers(opts docker.PruneContainersOptions) (*docker.PruneContainersResults, error) {
+	m.record("PruneContainers", []interface{}{opts})
+	if m.PruneContainersFunc != nil {
+		return m.PruneContainersFunc(opts)
+	}
+	var r0 *docker.PruneContainersResults
+	var r1 error
+	return r0, r1
+}
+
+// PruneImages calls PruneImagesFunc, if set, and records the call.
+func (m *MockDockerClient) PruneImages(opts docker.PruneImagesOptions) (*docker.PruneImagesResults, error) {
+	m.record("PruneImages", []interface{}{opts})
+	if m.PruneImagesFunc != nil {
+		return m.PruneImagesFunc(opts)
+	}
+	var r0 *docker.PruneImagesResults
+	var r1 error
+	return r0, r1
+}
+
+// PruneNetworks calls PruneNetworksFunc, if set, and records the call.
+func (m *MockDockerClient) PruneNetworks(opts docker.PruneNetworksOptions) (*docker.PruneNetworksResults, error) {
+	m.record("PruneNetworks", []interface{}{opts})
+	if m.PruneNetworksFunc != nil {
+		return m.PruneNetworksFunc(opts)
+	}
+	var r0 *docker.PruneNetworksResults
+	var r1 error
+	return r0, r1
+}
+
+// PruneVolumes calls PruneVolumesFunc, if set, and records the call.
+func (m *MockDockerClient) PruneVolumes(opts docker.PruneVolumesOptions) (*docker.PruneVolumesResults, error) {
+	m.record("PruneVolumes", []interface{}{opts})
+	if m.PruneVolumesFunc != nil {
+		return m.PruneVolumesFunc(opts)
+	}
+	var r0 *docker.PruneVolumesResults
+	var r1 error
+	return r0, r1
+}
+
+// PullImage calls PullImageFunc, if set, and records the call.
+func (m *MockDockerClient) PullImage(opts docker.PullImageOptions, auth docker.AuthConfiguration) error {
+	m.record("PullImage", []interface{}{opts, auth})
+	if m.PullImageFunc != nil {
+		return m.PullImageFunc(opts, auth)
+	}
+	var r0 error
+	return r0
+}
+
+// PushImage calls PushImageFunc, if set, and records the call.
+func (m *MockDockerClient) PushImage(opts docker.PushImageOptions, auth docker.AuthConfiguration) error {
+	m.record("PushImage", []interface{}{opts, auth})
+	if m.PushImageFunc != nil {
+		return m.PushImageFunc(opts, auth)
+	}
+	var r0 error
+	return r0
+}
+
+// PushPlugin calls PushPluginFunc, if set, and records the call.
+func (m *MockDockerClient) PushPlugin(opts docker.PushPluginOptions) error {
+	m.record("PushPlugin", []interface{}{opts})
+	if m.PushPluginFunc != nil {
+		return m.PushPluginFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RegistryAuth calls RegistryAuthFunc, if set, and records the call.
+func (m *MockDockerClient) RegistryAuth(serverAddress string) (docker.AuthConfiguration, bool) {
+	m.record("RegistryAuth", []interface{}{serverAddress})
+	if m.RegistryAuthFunc != nil {
+		return m.RegistryAuthFunc(serverAddress)
+	}
+	var r0 docker.AuthConfiguration
+	var r1 bool
+	return r0, r1
+}
+
+// RegistryLogin calls RegistryLoginFunc, if set, and records the call.
+func (m *MockDockerClient) RegistryLogin(auth docker.AuthConfiguration) (docker.AuthStatus, error) {
+	m.record("RegistryLogin", []interface{}{auth})
+	if m.RegistryLoginFunc != nil {
+		return m.RegistryLoginFunc(auth)
+	}
+	var r0 docker.AuthStatus
+	var r1 error
+	return r0, r1
+}
+
+// RegistryMirrorsMatch calls RegistryMirrorsMatchFunc, if set, and records the call.
+func (m *MockDockerClient) RegistryMirrorsMatch(mirrors []string, ctx context.Context) (bool, error) {
+	m.record("RegistryMirrorsMatch", []interface{}{mirrors, ctx})
+	if m.RegistryMirrorsMatchFunc != nil {
+		return m.RegistryMirrorsMatchFunc(mirrors, ctx)
+	}
+	var r0 bool
+	var r1 error
+	return r0, r1
+}
+
+// RemoveConfig calls RemoveConfigFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveConfig(opts docker.RemoveConfigOptions) error {
+	m.record("RemoveConfig", []interface{}{opts})
+	if m.RemoveConfigFunc != nil {
+		return m.RemoveConfigFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveContainer calls RemoveContainerFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveContainer(opts docker.RemoveContainerOptions) error {
+	m.record("RemoveContainer", []interface{}{opts})
+	if m.RemoveContainerFunc != nil {
+		return m.RemoveContainerFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveEventListener calls RemoveEventListenerFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveEventListener(listener chan *docker.APIEvents) error {
+	m.record("RemoveEventListener", []interface{}{listener})
+	if m.RemoveEventListenerFunc != nil {
+		return m.RemoveEventListenerFunc(listener)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveImage calls RemoveImageFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveImage(name string) error {
+	m.record("RemoveImage", []interface{}{name})
+	if m.RemoveImageFunc != nil {
+		return m.RemoveImageFunc(name)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveImageExtended calls RemoveImageExtendedFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveImageExtended(name string, opts docker.RemoveImageOptions) error {
+	m.record("RemoveImageExtended", []interface{}{name, opts})
+	if m.RemoveImageExtendedFunc != nil {
+		return m.RemoveImageExtendedFunc(name, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveNetwork calls RemoveNetworkFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveNetwork(id string) error {
+	m.record("RemoveNetwork", []interface{}{id})
+	if m.RemoveNetworkFunc != nil {
+		return m.RemoveNetworkFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveNode calls RemoveNodeFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveNode(opts docker.RemoveNodeOptions) error {
+	m.record("RemoveNode", []interface{}{opts})
+	if m.RemoveNodeFunc != nil {
+		return m.RemoveNodeFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RemovePlugin calls RemovePluginFunc, if set, and records the call.
+func (m *MockDockerClient) RemovePlugin(opts docker.RemovePluginOptions) (*docker.PluginDetail, error) {
+	m.record("RemovePlugin", []interface{}{opts})
+	if m.RemovePluginFunc != nil {
+		return m.RemovePluginFunc(opts)
+	}
+	var r0 *docker.PluginDetail
+	var r1 error
+	return r0, r1
+}
+
+// RemoveSecret calls RemoveSecretFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveSecret(opts docker.RemoveSecretOptions) error {
+	m.record("RemoveSecret", []interface{}{opts})
+	if m.RemoveSecretFunc != nil {
+		return m.RemoveSecretFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveService calls RemoveServiceFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveService(opts docker.RemoveServiceOptions) error {
+	m.record("RemoveService", []interface{}{opts})
+	if m.RemoveServiceFunc != nil {
+		return m.RemoveServiceFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveVolume calls RemoveVolumeFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveVolume(name string) error {
+	m.record("RemoveVolume", []interface{}{name})
+	if m.RemoveVolumeFunc != nil {
+		return m.RemoveVolumeFunc(name)
+	}
+	var r0 error
+	return r0
+}
+
+// RemoveVolumeWithOptions calls RemoveVolumeWithOptionsFunc, if set, and records the call.
+func (m *MockDockerClient) RemoveVolumeWithOptions(opts docker.RemoveVolumeOptions) error {
+	m.record("RemoveVolumeWithOptions", []interface{}{opts})
+	if m.RemoveVolumeWithOptionsFunc != nil {
+		return m.RemoveVolumeWithOptionsFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// RenameContainer calls RenameContainerFunc, if set, and records the call.
+func (m *MockDockerClient) RenameContainer(opts docker.RenameContainerOptions) error {
+	m.record("RenameContainer", []interface{}{opts})
+	if m.RenameContainerFunc != nil {
+		return m.RenameContainerFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ReplayEvents calls ReplayEventsFunc, if set, and records the call.
+func (m *MockDockerClient) ReplayEvents(since time.Time, opts docker.EventsOptions, ctx context.Context) ([]docker.APIEvents, error) {
+	m.record("ReplayEvents", []interface{}{since, opts, ctx})
+	if m.ReplayEventsFunc != nil {
+		return m.ReplayEventsFunc(since, opts, ctx)
+	}
+	var r0 []docker.APIEvents
+	var r1 error
+	return r0, r1
+}
+
+// ResizeContainerTTY calls ResizeContainerTTYFunc, if set, and records the call.
+func (m *MockDockerClient) ResizeContainerTTY(id string, height int, width int) error {
+	m.record("ResizeContainerTTY", []interface{}{id, height, width})
+	if m.ResizeContainerTTYFunc != nil {
+		return m.ResizeContainerTTYFunc(id, height, width)
+	}
+	var r0 error
+	return r0
+}
+
+// ResizeExecTTY calls ResizeExecTTYFunc, if set, and records the call.
+func (m *MockDockerClient) ResizeExecTTY(id string, height int, width int) error {
+	m.record("ResizeExecTTY", []interface{}{id, height, width})
+	if m.ResizeExecTTYFunc != nil {
+		return m.ResizeExecTTYFunc(id, height, width)
+	}
+	var r0 error
+	return r0
+}
+
+// RestartContainer calls RestartContainerFunc, if set, and records the call.
+func (m *MockDockerClient) RestartContainer(id string, timeout uint) error {
+	m.record("RestartContainer", []interface{}{id, timeout})
+	if m.RestartContainerFunc != nil {
+		return m.RestartContainerFunc(id, timeout)
+	}
+	var r0 error
+	return r0
+}
+
+// RestartWithEnv calls RestartWithEnvFunc, if set, and records the call.
+func (m *MockDockerClient) RestartWithEnv(id string, newEnv map[string]string, ctx context.Context) (*docker.Container, error) {
+	m.record("RestartWithEnv", []interface{}{id, newEnv, ctx})
+	if m.RestartWithEnvFunc != nil {
+		return m.RestartWithEnvFunc(id, newEnv, ctx)
+	}
+	var r0 *docker.Container
+	var r1 error
+	return r0, r1
+}
+
+// RollbackService calls RollbackServiceFunc, if set, and records the call.
+func (m *MockDockerClient) RollbackService(id string) error {
+	m.record("RollbackService", []interface{}{id})
+	if m.RollbackServiceFunc != nil {
+		return m.RollbackServiceFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// RotateSecret calls RotateSecretFunc, if set, and records the call.
+func (m *MockDockerClient) RotateSecret(opts docker.RotateSecretOptions) (*swarm.Secret, error) {
+	m.record("RotateSecret", []interface{}{opts})
+	if m.RotateSecretFunc != nil {
+		return m.RotateSecretFunc(opts)
+	}
+	var r0 *swarm.Secret
+	var r1 error
+	return r0, r1
+}
+
+// RotateSwarmTokens calls RotateSwarmTokensFunc, if set, and records the call.
+func (m *MockDockerClient) RotateSwarmTokens(opts docker.RotateSwarmTokensOptions) (swarm.JoinTokens, error) {
+	m.record("RotateSwarmTokens", []interface{}{opts})
+	if m.RotateSwarmTokensFunc != nil {
+		return m.RotateSwarmTokensFunc(opts)
+	}
+	var r0 swarm.JoinTokens
+	var r1 error
+	return r0, r1
+}
+
+// RunContainer calls RunContainerFunc, if set, and records the call.
+func (m *MockDockerClient) RunContainer(opts docker.RunOptions) (*docker.RunResult, error) {
+	m.record("RunContainer", []interface{}{opts})
+	if m.RunContainerFunc != nil {
+		return m.RunContainerFunc(opts)
+	}
+	var r0 *docker.RunResult
+	var r1 error
+	return r0, r1
+}
+
+// ScaleService calls ScaleServiceFunc, if set, and records the call.
+func (m *MockDockerClient) ScaleService(id string, replicas uint64) error {
+	m.record("ScaleService", []interface{}{id, replicas})
+	if m.ScaleServiceFunc != nil {
+		return m.ScaleServiceFunc(id, replicas)
+	}
+	var r0 error
+	return r0
+}
+
+// SearchImages calls SearchImagesFunc, if set, and records the call.
+func (m *MockDockerClient) SearchImages(term string) ([]docker.APIImageSearch, error) {
+	m.record("SearchImages", []interface{}{term})
+	if m.SearchImagesFunc != nil {
+		return m.SearchImagesFunc(term)
+	}
+	var r0 []docker.APIImageSearch
+	var r1 error
+	return r0, r1
+}
+
+// SearchImagesEx calls SearchImagesExFunc, if set, and records the call.
+func (m *MockDockerClient) SearchImagesEx(term string, auth docker.AuthConfiguration) ([]docker.APIImageSearch, error) {
+	m.record("SearchImagesEx", []interface{}{term, auth})
+	if m.SearchImagesExFunc != nil {
+		return m.SearchImagesExFunc(term, auth)
+	}
+	var r0 []docker.APIImageSearch
+	var r1 error
+	return r0, r1
+}
+
+// ServerVersion calls ServerVersionFunc, if set, and records the call.
+func (m *MockDockerClient) ServerVersion() (*docker.DockerVersion, error) {
+	m.record("ServerVersion", []interface{}{})
+	if m.ServerVersionFunc != nil {
+		return m.ServerVersionFunc()
+	}
+	var r0 *docker.DockerVersion
+	var r1 error
+	return r0, r1
+}
+
+// ServerVersionWithContext calls ServerVersionWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) ServerVersionWithContext(ctx context.Context) (*docker.DockerVersion, error) {
+	m.record("ServerVersionWithContext", []interface{}{ctx})
+	if m.ServerVersionWithContextFunc != nil {
+		return m.ServerVersionWithContextFunc(ctx)
+	}
+	var r0 *docker.DockerVersion
+	var r1 error
+	return r0, r1
+}
+
+// ServiceLogs calls ServiceLogsFunc, if set, and records the call.
+func (m *MockDockerClient) ServiceLogs(opts docker.ServiceLogsOptions) (<-chan docker.ServiceLogEntry, <-chan error) {
+	m.record("ServiceLogs", []interface{}{opts})
+	if m.ServiceLogsFunc != nil {
+		return m.ServiceLogsFunc(opts)
+	}
+	var r0 <-chan docker.ServiceLogEntry
+	var r1 <-chan error
+	return r0, r1
+}
+
+// SetContainerAppArmorProfile calls SetContainerAppArmorProfileFunc, if set, and records the call.
+func (m *MockDockerClient) SetContainerAppArmorProfile(id string, profile string, ctx context.Context) (*docker.Container, error) {
+	m.record("SetContainerAppArmorProfile", []interface{}{id, profile, ctx})
+	if m.SetContainerAppArmorProfileFunc != nil {
+		return m.SetContainerAppArmorProfileFunc(id, profile, ctx)
+	}
+	var r0 *docker.Container
+	var r1 error
+	return r0, r1
+}
+
+// SetContainerTimezone calls SetContainerTimezoneFunc, if set, and records the call.
+func (m *MockDockerClient) SetContainerTimezone(id string, timezone string, ctx context.Context) (*docker.Container, error) {
+	m.record("SetContainerTimezone", []interface{}{id, timezone, ctx})
+	if m.SetContainerTimezoneFunc != nil {
+		return m.SetContainerTimezoneFunc(id, timezone, ctx)
+	}
+	var r0 *docker.Container
+	var r1 error
+	return r0, r1
+}
+
+// SetRegistryMirrors calls SetRegistryMirrorsFunc, if set, and records the call.
+func (m *MockDockerClient) SetRegistryMirrors(mirrors []string, ctx context.Context) error {
+	m.record("SetRegistryMirrors", []interface{}{mirrors, ctx})
+	if m.SetRegistryMirrorsFunc != nil {
+		return m.SetRegistryMirrorsFunc(mirrors, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// SetTimeout calls SetTimeoutFunc, if set, and records the call.
+func (m *MockDockerClient) SetTimeout(t time.Duration) {
+	m.record("SetTimeout", []interface{}{t})
+	if m.SetTimeoutFunc != nil {
+		m.SetTimeoutFunc(t)
+	}
+}
+
+// SetUserAgent calls SetUserAgentFunc, if set, and records the call.
+func (m *MockDockerClient) SetUserAgent(ua string) {
+	m.record("SetUserAgent", []interface{}{ua})
+	if m.SetUserAgentFunc != nil {
+		m.SetUserAgentFunc(ua)
+	}
+}
+
+// StartContainer calls StartContainerFunc, if set, and records the call.
+func (m *MockDockerClient) StartContainer(id string, hostConfig *docker.HostConfig) error {
+	m.record("StartContainer", []interface{}{id, hostConfig})
+	if m.StartContainerFunc != nil {
+		return m.StartContainerFunc(id, hostConfig)
+	}
+	var r0 error
+	return r0
+}
+
+// StartContainerWithContext calls StartContainerWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) StartContainerWithContext(id string, hostConfig *docker.HostConfig, ctx context.Context) error {
+	m.record("StartContainerWithContext", []interface{}{id, hostConfig, ctx})
+	if m.StartContainerWithContextFunc != nil {
+		return m.StartContainerWithContextFunc(id, hostConfig, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// StartExec calls StartExecFunc, if set, and records the call.
+func (m *MockDockerClient) StartExec(id string, opts docker.StartExecOptions) error {
+	m.record("StartExec", []interface{}{id, opts})
+	if m.StartExecFunc != nil {
+		return m.StartExecFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// StartExecNonBlocking calls StartExecNonBlockingFunc, if set, and records the call.
+func (m *MockDockerClient) StartExecNonBlocking(id string, opts docker.StartExecOptions) (docker.CloseWaiter, error) {
+	m.record("StartExecNonBlocking", []interface{}{id, opts})
+	if m.StartExecNonBlockingFunc != nil {
+		return m.StartExecNonBlockingFunc(id, opts)
+	}
+	var r0 docker.CloseWaiter
+	var r1 error
+	return r0, r1
+}
+
+// StatContainerPath calls StatContainerPathFunc, if set, and records the call.
+func (m *MockDockerClient) StatContainerPath(id string, path string) (docker.ContainerPathStat, error) {
+	m.record("StatContainerPath", []interface{}{id, path})
+	if m.StatContainerPathFunc != nil {
+		return m.StatContainerPathFunc(id, path)
+	}
+	var r0 docker.ContainerPathStat
+	var r1 error
+	return r0, r1
+}
+
+// Stats calls StatsFunc, if set, and records the call.
+func (m *MockDockerClient) Stats(opts docker.StatsOptions) error {
+	m.record("Stats", []interface{}{opts})
+	if m.StatsFunc != nil {
+		return m.StatsFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// StopContainer calls StopContainerFunc, if set, and records the call.
+func (m *MockDockerClient) StopContainer(id string, timeout uint) error {
+	m.record("StopContainer", []interface{}{id, timeout})
+	if m.StopContainerFunc != nil {
+		return m.StopContainerFunc(id, timeout)
+	}
+	var r0 error
+	return r0
+}
+
+// StopContainerWithContext calls StopContainerWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) StopContainerWithContext(id string, timeout uint, ctx context.Context) error {
+	m.record("StopContainerWithContext", []interface{}{id, timeout, ctx})
+	if m.StopContainerWithContextFunc != nil {
+		return m.StopContainerWithContextFunc(id, timeout, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// TagImage calls TagImageFunc, if set, and records the call.
+func (m *MockDockerClient) TagImage(name string, opts docker.TagImageOptions) error {
+	m.record("TagImage", []interface{}{name, opts})
+	if m.TagImageFunc != nil {
+		return m.TagImageFunc(name, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// ThawContainerFS calls ThawContainerFSFunc, if set, and records the call.
+func (m *MockDockerClient) ThawContainerFS(id string, ctx context.Context) error {
+	m.record("ThawContainerFS", []interface{}{id, ctx})
+	if m.ThawContainerFSFunc != nil {
+		return m.ThawContainerFSFunc(id, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// TopContainer calls TopContainerFunc, if set, and records the call.
+func (m *MockDockerClient) TopContainer(id string, psArgs string) (docker.TopResult, error) {
+	m.record("TopContainer", []interface{}{id, psArgs})
+	if m.TopContainerFunc != nil {
+		return m.TopContainerFunc(id, psArgs)
+	}
+	var r0 docker.TopResult
+	var r1 error
+	return r0, r1
+}
+
+// UnpauseContainer calls UnpauseContainerFunc, if set, and records the call.
+func (m *MockDockerClient) UnpauseContainer(id string) error {
+	m.record("UnpauseContainer", []interface{}{id})
+	if m.UnpauseContainerFunc != nil {
+		return m.UnpauseContainerFunc(id)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateConfig calls UpdateConfigFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateConfig(id string, opts docker.UpdateConfigOptions) error {
+	m.record("UpdateConfig", []interface{}{id, opts})
+	if m.UpdateConfigFunc != nil {
+		return m.UpdateConfigFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateContainer calls UpdateContainerFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateContainer(id string, opts docker.UpdateContainerOptions) error {
+	m.record("UpdateContainer", []interface{}{id, opts})
+	if m.UpdateContainerFunc != nil {
+		return m.UpdateContainerFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateNode calls UpdateNodeFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateNode(id string, opts docker.UpdateNodeOptions) error {
+	m.record("UpdateNode", []interface{}{id, opts})
+	if m.UpdateNodeFunc != nil {
+		return m.UpdateNodeFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateSecret calls UpdateSecretFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateSecret(id string, opts docker.UpdateSecretOptions) error {
+	m.record("UpdateSecret", []interface{}{id, opts})
+	if m.UpdateSecretFunc != nil {
+		return m.UpdateSecretFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateService calls UpdateServiceFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateService(id string, opts docker.UpdateServiceOptions) error {
+	m.record("UpdateService", []interface{}{id, opts})
+	if m.UpdateServiceFunc != nil {
+		return m.UpdateServiceFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateServiceImage calls UpdateServiceImageFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateServiceImage(id string, image string) error {
+	m.record("UpdateServiceImage", []interface{}{id, image})
+	if m.UpdateServiceImageFunc != nil {
+		return m.UpdateServiceImageFunc(id, image)
+	}
+	var r0 error
+	return r0
+}
+
+// UpdateSwarm calls UpdateSwarmFunc, if set, and records the call.
+func (m *MockDockerClient) UpdateSwarm(opts docker.UpdateSwarmOptions) error {
+	m.record("UpdateSwarm", []interface{}{opts})
+	if m.UpdateSwarmFunc != nil {
+		return m.UpdateSwarmFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UpgradePlugin calls UpgradePluginFunc, if set, and records the call.
+func (m *MockDockerClient) UpgradePlugin(opts docker.UpgradePluginOptions) error {
+	m.record("UpgradePlugin", []interface{}{opts})
+	if m.UpgradePluginFunc != nil {
+		return m.UpgradePluginFunc(opts)
+	}
+	var r0 error
+	return r0
+}
+
+// UploadToContainer calls UploadToContainerFunc, if set, and records the call.
+func (m *MockDockerClient) UploadToContainer(id string, opts docker.UploadToContainerOptions) error {
+	m.record("UploadToContainer", []interface{}{id, opts})
+	if m.UploadToContainerFunc != nil {
+		return m.UploadToContainerFunc(id, opts)
+	}
+	var r0 error
+	return r0
+}
+
+// Version calls VersionFunc, if set, and records the call.
+func (m *MockDockerClient) Version() (*docker.Env, error) {
+	m.record("Version", []interface{}{})
+	if m.VersionFunc != nil {
+		return m.VersionFunc()
+	}
+	var r0 *docker.Env
+	var r1 error
+	return r0, r1
+}
+
+// VersionWithContext calls VersionWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) VersionWithContext(ctx context.Context) (*docker.Env, error) {
+	m.record("VersionWithContext", []interface{}{ctx})
+	if m.VersionWithContextFunc != nil {
+		return m.VersionWithContextFunc(ctx)
+	}
+	var r0 *docker.Env
+	var r1 error
+	return r0, r1
+}
+
+// WaitContainer calls WaitContainerFunc, if set, and records the call.
+func (m *MockDockerClient) WaitContainer(id string) (int, error) {
+	m.record("WaitContainer", []interface{}{id})
+	if m.WaitContainerFunc != nil {
+		return m.WaitContainerFunc(id)
+	}
+	var r0 int
+	var r1 error
+	return r0, r1
+}
+
+// WaitContainerWithContext calls WaitContainerWithContextFunc, if set, and records the call.
+func (m *MockDockerClient) WaitContainerWithContext(id string, ctx context.Context) (int, error) {
+	m.record("WaitContainerWithContext", []interface{}{id, ctx})
+	if m.WaitContainerWithContextFunc != nil {
+		return m.WaitContainerWithContextFunc(id, ctx)
+	}
+	var r0 int
+	var r1 error
+	return r0, r1
+}
+
+// WaitForLogPattern calls WaitForLogPatternFunc, if set, and records the call.
+func (m *MockDockerClient) WaitForLogPattern(id string, pattern *regexp.Regexp, timeout time.Duration, ctx context.Context) (string, error) {
+	m.record("WaitForLogPattern", []interface{}{id, pattern, timeout, ctx})
+	if m.WaitForLogPatternFunc != nil {
+		return m.WaitForLogPatternFunc(id, pattern, timeout, ctx)
+	}
+	var r0 string
+	var r1 error
+	return r0, r1
+}
+
+// WaitServiceConverged calls WaitServiceConvergedFunc, if set, and records the call.
+func (m *MockDockerClient) WaitServiceConverged(serviceID string, timeout time.Duration) error {
+	m.record("WaitServiceConverged", []interface{}{serviceID, timeout})
+	if m.WaitServiceConvergedFunc != nil {
+		return m.WaitServiceConvergedFunc(serviceID, timeout)
+	}
+	var r0 error
+	return r0
+}
+
+// WatchSpotTermination calls WatchSpotTerminationFunc, if set, and records the call.
+func (m *MockDockerClient) WatchSpotTermination(gracePeriod time.Duration, fn func(remainingTime time.Duration), ctx context.Context) error {
+	m.record("WatchSpotTermination", []interface{}{gracePeriod, fn, ctx})
+	if m.WatchSpotTerminationFunc != nil {
+		return m.WatchSpotTerminationFunc(gracePeriod, fn, ctx)
+	}
+	var r0 error
+	return r0
+}
+
+// WithDefaultTimeout calls WithDefaultTimeoutFunc, if set, and records the call.
+func (m *MockDockerClient) WithDefaultTimeout(d time.Duration) *docker.Client {
+	m.record("WithDefaultTimeout", []interface{}{d})
+	if m.WithDefaultTimeoutFunc != nil {
+		return m.WithDefaultTimeoutFunc(d)
+	}
+	var r0 *docker.Client
+	return r0
+}
+
+// WithTransport calls WithTransportFunc, if set, and records the call.
+func (m *MockDockerClient) WithTransport(trFunc func() *http.Transport) {
+	m.record("WithTransport", []interface{}{trFunc})
+	if m.WithTransportFunc != nil {
+		m.WithTransportFunc(trFunc)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+func stopAll(client docker.DockerClient, ids ...string) error {
+	for _, id := range ids {
+		if err := client.StopContainer(id, 10); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestMockDockerClient(t *testing.T) {
+	t.Parallel()
+	var mock MockDockerClient
+	mock.StopContainerFunc = func(id string, timeout uint) error {
+		if id == "db" {
+			return &docker.ContainerNotRunning{ID: id}
+		}
+		return nil
+	}
+	err := stopAll(&mock, "web", "db", "cache")
+	if expected := (&docker.ContainerNotRunning{ID: "db"}); !reflect.DeepEqual(err, expected) {
+		t.Errorf("StopContainer: wrong error. Want %#v. Got %#v.", expected, err)
+	}
+	mock.InspectContainer("web")
+	expected := []Call{
+		{Method: "StopContainer", Args: []interface{}{"web", uint(10)}},
+		{Method: "StopContainer", Args: []interface{}{"db", uint(10)}},
+		{Method: "InspectContainer", Args: []interface{}{"web"}},
+	}
+	if calls := mock.Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Calls: wrong calls. Want %#v. Got %#v.", expected, calls)
+	}
+	if calls := mock.CallsTo("StopContainer"); !reflect.DeepEqual(calls, expected[:2]) {
+		t.Errorf("CallsTo: wrong calls. Want %#v. Got %#v.", expected[:2], calls)
+	}
+	mock.Reset()
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("Reset: unexpected calls: %#v", calls)
+	}
+}
+
+func TestMockDockerClientZeroValues(t *testing.T) {
+	t.Parallel()
+	var mock MockDockerClient
+	container, err := mock.InspectContainer("web")
+	if container != nil || err != nil {
+		t.Errorf("InspectContainer: want zero values. Got %#v, %#v.", container, err)
+	}
+	exists, err := mock.ImageExists("alpine")
+	if exists || err != nil {
+		t.Errorf("ImageExists: want zero values. Got %#v, %#v.", exists, err)
+	}
+}
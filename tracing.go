@@ -0,0 +1,124 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Tracer starts the spans of the requests sent by a client configured with
+// WithTracer. It's satisfied by a thin adapter of an OpenTelemetry tracer,
+// keeping the client free of tracing dependencies.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	SetError(err error)
+	End()
+}
+
+// WithTracer makes the client start a span for each request sent to the
+// Docker daemon, named after the method of the client that sent it, like
+// "docker.CreateContainer". Spans have the http.status_code attribute and,
+// when the request targets a container or an image, the container.id or
+// image.name attributes. Failed requests and error responses are recorded
+// with SetError.
+//
+// The span ends when the response headers are received. Requests on
+// hijacked connections, such as attaching to a container, are not traced.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) error {
+		c.tracer = tracer
+		return nil
+	}
+}
+
+var versionPrefixRegexp = regexp.MustCompile(`^/v[0-9]+(\.[0-9]+)*/`)
+
+// traced wraps the given function, starting a span around each request.
+func (c *Client) traced(operation string, send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx, span := c.tracer.Start(req.Context(), "docker."+operation)
+		defer span.End()
+		for key, value := range requestAttributes(req) {
+			span.SetAttribute(key, value)
+		}
+		resp, err := send(req.WithContext(ctx))
+		if err != nil {
+			span.SetError(err)
+			return resp, err
+		}
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		if resp.StatusCode >= 400 {
+			span.SetError(fmt.Errorf("API error (%d)", resp.StatusCode))
+		}
+		return resp, err
+	}
+}
+
+// operationName returns the name of the innermost exported method of the
+// client in the call stack.
+func operationName() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if i := strings.Index(frame.Function, ".(*Client)."); i >= 0 {
+			name := frame.Function[i+len(".(*Client)."):]
+			if name != "" && unicode.IsUpper(rune(name[0])) {
+				return strings.TrimSuffix(name, "WithContext")
+			}
+		}
+		if !more {
+			return "request"
+		}
+	}
+}
+
+// requestAttributes returns the container.id or image.name attributes of
+// the given request.
+func requestAttributes(req *http.Request) map[string]interface{} {
+	path := versionPrefixRegexp.ReplaceAllString(req.URL.Path, "/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	query := req.URL.Query()
+	switch parts[0] {
+	case "containers":
+		if len(parts) > 1 && parts[1] != "create" && parts[1] != "json" && parts[1] != "prune" {
+			return map[string]interface{}{"container.id": parts[1]}
+		}
+	case "images":
+		var name string
+		switch {
+		case len(parts) == 2 && parts[1] == "create":
+			name = query.Get("fromImage")
+			if tag := query.Get("tag"); name != "" && tag != "" {
+				name += ":" + tag
+			}
+		case len(parts) > 2 && req.Method != http.MethodDelete:
+			// image names may have slashes, the last part is the
+			// operation
+			name = strings.Join(parts[1:len(parts)-1], "/")
+		case len(parts) > 1 && req.Method == http.MethodDelete:
+			name = strings.Join(parts[1:], "/")
+		}
+		if name != "" {
+			return map[string]interface{}{"image.name": name}
+		}
+	case "build":
+		if name := query.Get("t"); name != "" {
+			return map[string]interface{}{"image.name": name}
+		}
+	}
+	return nil
+}
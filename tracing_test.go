@@ -0,0 +1,127 @@
+// Copyright 2019 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *fakeSpan) SetError(err error) {
+	s.err = err
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &fakeSpan{name: name, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestWithTracer(t *testing.T) {
+	t.Parallel()
+	tracer := &fakeTracer{}
+	client := newTestClient(&FakeRoundTripper{message: `{"Id":"abc123"}`, status: http.StatusCreated})
+	if err := client.ApplyOptions(WithTracer(tracer)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateContainer(CreateContainerOptions{Config: &Config{Image: "ubuntu"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("WithTracer: wrong number of spans. Want 1. Got %d.", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "docker.CreateContainer" {
+		t.Errorf("WithTracer: wrong span name. Want %q. Got %q.", "docker.CreateContainer", span.name)
+	}
+	expected := map[string]interface{}{"http.status_code": http.StatusCreated}
+	if !reflect.DeepEqual(span.attributes, expected) {
+		t.Errorf("WithTracer: wrong attributes. Want %#v. Got %#v.", expected, span.attributes)
+	}
+	if span.err != nil || !span.ended {
+		t.Errorf("WithTracer: wrong span state: %#v", span)
+	}
+}
+
+func TestWithTracerError(t *testing.T) {
+	t.Parallel()
+	tracer := &fakeTracer{}
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	if err := client.ApplyOptions(WithTracer(tracer)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.InspectContainer("abc123"); err == nil {
+		t.Fatal("InspectContainer: unexpected <nil> error")
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("WithTracer: wrong number of spans. Want 1. Got %d.", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "docker.InspectContainer" {
+		t.Errorf("WithTracer: wrong span name. Want %q. Got %q.", "docker.InspectContainer", span.name)
+	}
+	expected := map[string]interface{}{"container.id": "abc123", "http.status_code": http.StatusNotFound}
+	if !reflect.DeepEqual(span.attributes, expected) {
+		t.Errorf("WithTracer: wrong attributes. Want %#v. Got %#v.", expected, span.attributes)
+	}
+	if span.err == nil {
+		t.Error("WithTracer: error response not recorded in the span")
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	t.Parallel()
+	var tests = []struct {
+		method   string
+		url      string
+		expected map[string]interface{}
+	}{
+		{http.MethodGet, "/v1.25/containers/abc/json", map[string]interface{}{"container.id": "abc"}},
+		{http.MethodPost, "/containers/create?name=web", nil},
+		{http.MethodGet, "/containers/json", nil},
+		{http.MethodGet, "/images/library/ubuntu/json", map[string]interface{}{"image.name": "library/ubuntu"}},
+		{http.MethodDelete, "/images/ubuntu:18.04", map[string]interface{}{"image.name": "ubuntu:18.04"}},
+		{http.MethodPost, "/images/create?fromImage=ubuntu&tag=18.04", map[string]interface{}{"image.name": "ubuntu:18.04"}},
+		{http.MethodGet, "/images/json", nil},
+		{http.MethodPost, "/build?t=app", map[string]interface{}{"image.name": "app"}},
+		{http.MethodGet, "/info", nil},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := requestAttributes(&http.Request{Method: test.method, URL: u})
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("requestAttributes(%s %s): wrong result. Want %#v. Got %#v.", test.method, test.url, test.expected, got)
+		}
+	}
+}
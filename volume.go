@@ -104,7 +104,11 @@ func (c *Client) CreateVolume(opts CreateVolumeOptions) (*Volume, error) {
 //
 // See https://goo.gl/GMjsMc for more details.
 func (c *Client) InspectVolume(name string) (*Volume, error) {
-	resp, err := c.do("GET", "/volumes/"+name, doOptions{})
+	return c.inspectVolume(name, nil)
+}
+
+func (c *Client) inspectVolume(name string, ctx context.Context) (*Volume, error) {
+	resp, err := c.do("GET", "/volumes/"+name, doOptions{context: ctx})
 	if err != nil {
 		if e, ok := err.(*Error); ok && e.Status == http.StatusNotFound {
 			return nil, ErrNoSuchVolume
@@ -119,6 +123,61 @@ func (c *Client) InspectVolume(name string) (*Volume, error) {
 	return &volume, nil
 }
 
+// ListContainersByVolume returns all the containers, including the stopped
+// ones, that mount the given volume. It returns ErrNoSuchVolume if the volume
+// does not exist.
+//
+// The volume filter of ListContainers is used when the daemon supports it,
+// otherwise every container is inspected.
+func (c *Client) ListContainersByVolume(volumeName string, ctx context.Context) ([]APIContainers, error) {
+	if _, err := c.inspectVolume(volumeName, ctx); err != nil {
+		return nil, err
+	}
+	opts := ListContainersOptions{All: true, Context: ctx}
+	filtered := c.serverAPIVersion == nil || c.serverAPIVersion.GreaterThanOrEqualTo(apiVersion124)
+	if filtered {
+		opts.Filters = map[string][]string{"volume": {volumeName}}
+	}
+	containers, err := c.ListContainers(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]APIContainers, 0, len(containers))
+	for _, container := range containers {
+		if filtered {
+			// the filter also matches containers by the destination
+			// of their mounts
+			if mountsVolume(container.Mounts, volumeName) {
+				result = append(result, container)
+			}
+			continue
+		}
+		details, err := c.InspectContainerWithContext(container.ID, ctx)
+		if err != nil {
+			if _, ok := err.(*NoSuchContainer); ok {
+				continue
+			}
+			return nil, err
+		}
+		for _, mount := range details.Mounts {
+			if mount.Name == volumeName {
+				result = append(result, container)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func mountsVolume(mounts []APIMount, volumeName string) bool {
+	for _, mount := range mounts {
+		if mount.Name == volumeName {
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveVolume removes a volume by its name.
 //
 // Deprecated: Use RemoveVolumeWithOptions instead.
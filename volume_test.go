@@ -5,10 +5,14 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -205,3 +209,106 @@ func TestPruneVolumes(t *testing.T) {
 		t.Errorf("PruneContainers: Expected %#v. Got %#v.", expected, got)
 	}
 }
+
+func newContainersByVolumeServer() (*httptest.Server, *[]string) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/volumes/data", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Name":"data","Driver":"local"}`))
+	})
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RawQuery)
+		mu.Unlock()
+		if r.URL.Query().Get("all") != "1" {
+			http.Error(w, "stopped containers not listed", http.StatusBadRequest)
+			return
+		}
+		containers := []APIContainers{
+			{ID: "c1", Mounts: []APIMount{{Name: "data", Destination: "/var/lib/data"}}},
+			{ID: "c2", Mounts: []APIMount{{Source: "/srv", Destination: "data"}}},
+			{ID: "c3", Mounts: []APIMount{{Name: "cache", Destination: "/cache"}, {Name: "data", Destination: "/data"}}},
+		}
+		if r.URL.Query().Get("filters") == "" {
+			containers = append(containers, APIContainers{ID: "c4"})
+		}
+		json.NewEncoder(w).Encode(containers)
+	})
+	for id, mounts := range map[string]string{
+		"c1": `[{"Name":"data","Destination":"/var/lib/data"}]`,
+		"c2": `[{"Source":"/srv","Destination":"data"}]`,
+		"c3": `[{"Name":"cache","Destination":"/cache"},{"Name":"data","Destination":"/data"}]`,
+		"c4": `[]`,
+	} {
+		body := `{"Id":"` + id + `","Mounts":` + mounts + `}`
+		mux.HandleFunc("/containers/"+id+"/json", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+	}
+	return httptest.NewServer(mux), &requests
+}
+
+func TestListContainersByVolume(t *testing.T) {
+	t.Parallel()
+	server, requests := newContainersByVolumeServer()
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers, err := client.ListContainersByVolume("data", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, container := range containers {
+		ids = append(ids, container.ID)
+	}
+	if expected := []string{"c1", "c3"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ListContainersByVolume: wrong containers. Want %#v. Got %#v.", expected, ids)
+	}
+	query, err := url.ParseQuery((*requests)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"volume":["data"]}`; query.Get("filters") != expected {
+		t.Errorf("ListContainersByVolume: wrong filters. Want %q. Got %q.", expected, query.Get("filters"))
+	}
+}
+
+func TestListContainersByVolumeWithoutFilter(t *testing.T) {
+	t.Parallel()
+	server, requests := newContainersByVolumeServer()
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.serverAPIVersion = apiVersion119
+	containers, err := client.ListContainersByVolume("data", context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, container := range containers {
+		ids = append(ids, container.ID)
+	}
+	if expected := []string{"c1", "c3"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ListContainersByVolume: wrong containers. Want %#v. Got %#v.", expected, ids)
+	}
+	if query := (*requests)[0]; strings.Contains(query, "filters") {
+		t.Errorf("ListContainersByVolume: unexpected filters for old API versions: %q.", query)
+	}
+}
+
+func TestListContainersByVolumeNoSuchVolume(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such volume", status: http.StatusNotFound})
+	_, err := client.ListContainersByVolume("data", context.Background())
+	if err != ErrNoSuchVolume {
+		t.Errorf("ListContainersByVolume: wrong error. Want %#v. Got %#v.", ErrNoSuchVolume, err)
+	}
+}